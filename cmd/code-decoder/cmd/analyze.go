@@ -3,8 +3,31 @@
 package cmd
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/progress"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+	"github.com/ksylvan/code-decoder/internal/render"
+	"github.com/ksylvan/code-decoder/internal/scanner"
+	"github.com/ksylvan/code-decoder/internal/source"
 	"github.com/spf13/cobra"
 )
 
@@ -12,35 +35,933 @@ import (
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "Analyze a codebase and save the analysis",
-	Long: `Processes a codebase from a local directory or GitHub repository,
+	Long: `Processes a codebase from a local directory, GitHub repository or source archive,
 extracts structural information and high-level knowledge,
 and saves the analysis to a specified file.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("analyze called")
-		// TODO: Implement analysis logic here
-		// 1. Get source (dir or repo)
-		// 2. Validate source
-		// 3. List files based on config (include/exclude/size)
-		// 4. Parse files
-		// 5. Extract knowledge using LLM
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		savePath, _ := cmd.Flags().GetString("save-analysis")
+		format, _ := cmd.Flags().GetString("format")
+		if listOnly, _ := cmd.Flags().GetBool("list-only"); listOnly {
+			return listFiles(cmd)
+		}
+		if stats, _ := cmd.Flags().GetBool("stats"); stats {
+			return printStats(cmd)
+		}
+		if promptPreviewRequested(cmd) {
+			return previewAnalysis(cmd)
+		}
+		switch {
+		case format != "" && format != "json":
+			return usageErrorf("unsupported analysis format: %q (supported: json)", format)
+		case format == "json" && jsonOutput:
+			return usageErrorf("--format json cannot be combined with --json, which also writes to stdout")
+		case savePath == "" && format == "":
+			return usageErrorf("--save-analysis is required: specify the file to save the analysis to (or print it with --format json)")
+		case savePath == pipedAnalysis && (format != "" || jsonOutput):
+			return usageErrorf("--save-analysis - cannot be combined with --format or --json, which also write to stdout")
+		}
+		if update, _ := cmd.Flags().GetBool("update"); update {
+			switch {
+			case savePath == "" || savePath == pipedAnalysis:
+				return usageErrorf("--update requires --save-analysis with the file of the analysis to refresh")
+			case cmd.Flags().Changed("incremental") || cmd.Flags().Changed("load-analysis"):
+				return usageErrorf("--update cannot be combined with --incremental or --load-analysis: it updates the analysis at --save-analysis")
+			}
+			if _, err := os.Stat(savePath); errors.Is(err, fs.ErrNotExist) {
+				return usageErrorf("--update: %s does not exist; run analyze without --update to create it", savePath)
+			}
+		} else if cmd.Flags().Changed("force") {
+			return usageErrorf("--force is only used with --update")
+		}
+		if cmd.Flags().Changed("load-analysis") && !cmd.Flags().Changed("incremental") {
+			return usageErrorf("--load-analysis is only used with --incremental, to update an existing analysis")
+		}
+
+		if savePath != pipedAnalysis {
+			runOutput = savePath
+		}
+
+		a, err := analyzeSource(cmd)
+		if err != nil {
+			return err
+		}
+
 		// 6. Save analysis to file
+		if savePath != "" {
+			if err := saveAnalysis(cmd, savePath, a); err != nil {
+				return err
+			}
+		}
+		run := newRunSummary(a, nil, start)
+		run.print(cmd.ErrOrStderr())
+		if format == "json" {
+			return render.WriteExport(cmd.OutOrStdout(), render.NewExport(a, nil))
+		}
+		if jsonOutput {
+			summary := newAnalysisSummary(a, savePath)
+			summary.Run = run
+			return printJSON(cmd.OutOrStdout(), summary)
+		}
+		return nil
 	},
 }
 
+// listFiles prints the files analyze would analyze, with their size and
+// detected language, without making any LLM request.
+func listFiles(cmd *cobra.Command) error {
+	a, _, cleanup, err := scanSource(cmd, true)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	list := fileList{Files: make([]listedFile, 0, len(a.Files)), Skipped: a.Skipped}
+	for _, f := range a.Files {
+		language := f.Language
+		if language == "" {
+			language = "unknown"
+		}
+		list.Files = append(list.Files, listedFile{Path: f.Path, Size: f.Size, Language: language})
+		list.TotalSize += f.Size
+	}
+	if jsonOutput {
+		return printJSON(cmd.OutOrStdout(), list)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SIZE\tLANGUAGE\tPATH")
+	for _, f := range list.Files {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", f.Size, f.Language, f.Path)
+	}
+	fmt.Fprintf(w, "%d\t\t%d files\n", list.TotalSize, len(list.Files))
+	return w.Flush()
+}
+
+// fileList is the --json output of analyze --list-only.
+type fileList struct {
+	Files     []listedFile   `json:"files"`
+	TotalSize int64          `json:"total_size"`              // In bytes
+	Skipped   map[string]int `json:"skipped_files,omitempty"` // Files left out as binary or generated, by reason
+}
+
+type listedFile struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`     // In bytes
+	Language string `json:"language"` // Detected language, or "unknown"
+}
+
+// formatSkipped formats the counts of skipped files by reason, most common
+// first (e.g., "12 binary, 3 lockfile").
+func formatSkipped(skipped map[string]int) string {
+	reasons := make([]string, 0, len(skipped))
+	for reason := range skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if skipped[reasons[i]] != skipped[reasons[j]] {
+			return skipped[reasons[i]] > skipped[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", skipped[reason], reason)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// skippedHint tells how to analyze the skipped files, by the reasons they
+// were skipped for.
+func skippedHint(skipped map[string]int) string {
+	var flags []string
+	for reason := range skipped {
+		if reason != scanner.ReasonTest {
+			flags = append(flags, "--include-generated")
+			break
+		}
+	}
+	if skipped[scanner.ReasonTest] > 0 {
+		flags = append(flags, "--include-tests")
+	}
+	return "pass " + strings.Join(flags, " or ") + " to analyze them"
+}
+
+// loadPrompts loads the prompt templates, overridden by the files in
+// --prompts-dir or, without the flag, defaults.prompts_dir from the config.
+func loadPrompts(cmd *cobra.Command) (*prompts.Set, error) {
+	dir, _ := cmd.Flags().GetString("prompts-dir")
+	if dir == "" {
+		dir = cfg.Defaults.PromptsDir
+	}
+	return prompts.Load(dir)
+}
+
+// describeFile returns the analysis entry for the file at the slash-separated
+// path p under dir, with its size, detected language and content hash.
+func describeFile(dir, p string) (analysis.File, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(p)))
+	if err != nil {
+		return analysis.File{}, fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return analysis.File{}, fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+
+	// The start of the file is enough to recognize a shebang line
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return analysis.File{}, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	hash := sha256.New()
+	hash.Write(head[:n])
+	if _, err := io.Copy(hash, f); err != nil {
+		return analysis.File{}, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return analysis.File{
+		Path:     p,
+		Size:     info.Size(),
+		Language: scanner.DetectLanguage(p, head[:n]),
+		Hash:     hex.EncodeToString(hash.Sum(nil)),
+		Docs:     analysis.IsDoc(p),
+	}, nil
+}
+
+// analysisSummary is the --json output of analyze.
+type analysisSummary struct {
+	Project      string                   `json:"project"`
+	AnalysisFile string                   `json:"analysis_file"`
+	Files        int                      `json:"files"`
+	Languages    []analysis.LanguageShare `json:"languages"`
+	Skipped      map[string]int           `json:"skipped_files,omitempty"` // Files left out as binary or generated, by reason
+	Failed       map[string]string        `json:"failed_files,omitempty"`  // Files the LLM could not analyze, with the reason
+	Abstractions []abstractionSummary     `json:"abstractions"`
+	Run          runSummary               `json:"run"`
+}
+
+type abstractionSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Files       int    `json:"files"` // Number of files implementing the abstraction
+}
+
+func newAnalysisSummary(a *analysis.Analysis, path string) analysisSummary {
+	summary := analysisSummary{
+		Project:      a.ProjectName,
+		AnalysisFile: path,
+		Files:        len(a.Files),
+		Languages:    a.Languages(),
+		Skipped:      a.Skipped,
+		Failed:       a.Failed,
+		Abstractions: make([]abstractionSummary, 0, len(a.Abstractions)),
+	}
+	for _, abs := range a.Abstractions {
+		summary.Abstractions = append(summary.Abstractions, abstractionSummary{Name: abs.Name, Description: abs.Description, Files: len(abs.Files)})
+	}
+	return summary
+}
+
+// pipedAnalysis is the --load-analysis value reading the analysis from
+// stdin, and the --save-analysis value writing it to stdout, for pipelines
+// such as analyze --save-analysis - | generate --load-analysis -.
+const pipedAnalysis = "-"
+
+// loadAnalysis loads the analysis file at path, or reads the analysis from
+// stdin if path is pipedAnalysis.
+func loadAnalysis(cmd *cobra.Command, path string) (*analysis.Analysis, error) {
+	if path != pipedAnalysis {
+		return analysis.Load(path)
+	}
+	if f, ok := cmd.InOrStdin().(*os.File); ok && progress.IsTerminal(f) {
+		return nil, usageErrorf("--load-analysis - reads the analysis from stdin, but nothing is piped into it (e.g., code-decoder analyze --dir . --save-analysis - | %s --load-analysis -)", cmd.CommandPath())
+	}
+	return analysis.Read(cmd.InOrStdin(), "the analysis on stdin")
+}
+
+// saveAnalysis saves a to the file at path, or writes it to stdout if path
+// is pipedAnalysis.
+func saveAnalysis(cmd *cobra.Command, path string, a *analysis.Analysis) error {
+	if path == pipedAnalysis {
+		if err := analysis.Write(cmd.OutOrStdout(), a); err != nil {
+			return err
+		}
+		slog.Info("Analysis written to stdout")
+		return nil
+	}
+	if err := analysis.Save(path, a); err != nil {
+		return err
+	}
+	slog.Info("Analysis saved", "path", path)
+	return nil
+}
+
+// analyzeSource analyzes the codebase selected by the command's --dir,
+// --repo or --archive flag and returns the resulting analysis.
+func analyzeSource(cmd *cobra.Command) (*analysis.Analysis, error) {
+	// An incremental analysis only extracts the files that changed since the
+	// baseline, which --update reads from the file it rewrites
+	var baseline *analysis.Analysis
+	var basePath string
+	if incremental, _ := cmd.Flags().GetBool("incremental"); incremental {
+		if basePath, _ = cmd.Flags().GetString("load-analysis"); basePath == "" {
+			return nil, usageErrorf("--incremental requires --load-analysis with the analysis to update")
+		}
+	}
+	update, _ := cmd.Flags().GetBool("update")
+	if update {
+		basePath, _ = cmd.Flags().GetString("save-analysis")
+	}
+	if basePath != "" {
+		var err error
+		if baseline, err = loadAnalysis(cmd, basePath); err != nil {
+			return nil, err
+		}
+		slog.Info("Loaded baseline analysis", "path", basePath, "files", len(baseline.Files))
+	}
+	if force, _ := cmd.Flags().GetBool("force"); update && !force {
+		if current := flagSource(cmd); !sameSource(baseline.Source, current) {
+			return nil, usageErrorf("--update: %s is the analysis of %s %s, not of %s %s; pass --force to update it anyway",
+				basePath, baseline.Source.Type, baseline.Source.Location, current.Type, current.Location)
+		}
+	}
+
+	// 1-3. Get the source and list its files, or, for a fresh analysis,
+	// start listing them as they are extracted
+	var (
+		a       *analysis.Analysis
+		dir     string
+		stream  *fileStream
+		cleanup func()
+		err     error
+	)
+	if since, _ := cmd.Flags().GetString("since"); baseline == nil && since == "" {
+		var opts scanner.ScanOptions
+		if a, dir, opts, cleanup, err = openSource(cmd); err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if stream, err = streamFiles(cmd, dir, opts); err != nil {
+			return nil, err
+		}
+		defer stream.stop()
+	} else {
+		if a, dir, cleanup, err = scanSource(cmd, false); err != nil {
+			return nil, err
+		}
+		defer cleanup()
+	}
+
+	// 4. Parse files and 5. Extract knowledge using LLM
+	llmCfg, err := llmConfig(cmd, "extraction-model")
+	if err != nil {
+		return nil, err
+	}
+	provider, err := newProvider(cmd, llmCfg)
+	if err != nil {
+		return nil, err
+	}
+	completion := completionOptions()
+	contextWindow, tokenizer, err := contextGuard(llmCfg, completion)
+	if err != nil {
+		return nil, err
+	}
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if !cmd.Flags().Changed("concurrency") && cfg.Defaults.Concurrency > 0 {
+		concurrency = cfg.Defaults.Concurrency
+	}
+	if concurrency < 1 {
+		return nil, usageErrorf("--concurrency must be at least 1, got %d", concurrency)
+	}
+	maxAbstractions, _ := cmd.Flags().GetInt("max-abstractions")
+	if maxAbstractions < 1 {
+		return nil, usageErrorf("--max-abstractions must be at least 1, got %d", maxAbstractions)
+	}
+	noReadmeContext, _ := cmd.Flags().GetBool("no-readme-context")
+	templates, err := loadPrompts(cmd)
+	if err != nil {
+		return nil, err
+	}
+	completion.System = templates.System()
+	extractor := &analysis.Extractor{
+		Provider:    provider,
+		Options:     completion,
+		Root:        dir,
+		Progress:    newProgress(),
+		Prompts:     templates,
+		Concurrency: concurrency,
+
+		MaxAbstractions: maxAbstractions,
+		ContextWindow:   contextWindow,
+		Tokenizer:       tokenizer,
+		DocsContext:     !noReadmeContext,
+	}
+	if !noCache {
+		cacheDir, err := llm.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		extractor.Cache = analysis.NewExtractionCache(cacheDir, llmCfg.Model, refresh)
+	}
+	switch {
+	case baseline != nil:
+		var changes analysis.Changes
+		if changes, err = extractor.ExtractIncremental(cmd.Context(), a, baseline); err == nil {
+			slog.Info("Incremental analysis", "added", changes.Added, "changed", changes.Changed, "removed", changes.Removed, "unchanged", changes.Unchanged)
+		}
+	case stream != nil:
+		err = stream.extract(cmd, extractor, a)
+	default:
+		err = extractor.Extract(cmd.Context(), a)
+	}
+	if err != nil {
+		var budgetErr *llm.BudgetExceededError
+		if (errors.Is(err, context.Canceled) || errors.As(err, &budgetErr)) && !noCache {
+			// Each response is cached as it arrives, so the work done is not lost
+			slog.Info("The files analyzed so far are cached; re-run the command to continue from there")
+		}
+		return nil, err
+	}
+	slog.Info("Extracted candidate abstractions", "count", len(a.Abstractions))
+
+	// 6. Identify the core abstractions the tutorial chapters are built from
+	if err := extractor.IdentifyAbstractions(cmd.Context(), a); err != nil {
+		return nil, err
+	}
+	slog.Info("Identified core abstractions", "count", len(a.Abstractions))
+
+	// 7. Find how the abstractions depend on each other
+	if err := extractor.ExtractRelationships(cmd.Context(), a); err != nil {
+		return nil, err
+	}
+	slog.Info("Extracted relationships", "count", len(a.Relationships))
+	return a, nil
+}
+
+// scanSource gets the codebase selected by the command's --dir, --repo or
+// --archive flag and lists the files to analyze in it. It returns an
+// analysis of those files, with no knowledge extracted yet, the directory
+// holding them, and a function removing the cloned repository or extracted
+// archive, which the caller must call when done with the directory. The
+// number of files is checked against --max-files unless listOnly is set,
+// since listing them costs nothing.
+func scanSource(cmd *cobra.Command, listOnly bool) (*analysis.Analysis, string, func(), error) {
+	dir, src, cleanup, err := fetchSource(cmd)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	a, err := scanFiles(cmd, dir, src, listOnly)
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return a, dir, cleanup, nil
+}
+
+// openSource is like scanSource, but leaves the files to be listed as they
+// are extracted (see extractStream): the analysis it returns has none yet,
+// and the scan options to list them with are returned instead.
+func openSource(cmd *cobra.Command) (*analysis.Analysis, string, scanner.ScanOptions, func(), error) {
+	dir, src, cleanup, err := fetchSource(cmd)
+	if err != nil {
+		return nil, "", scanner.ScanOptions{}, nil, err
+	}
+	opts, err := scanOptions(cmd)
+	if err == nil {
+		err = addLanguageExcludes(dir, &opts)
+	}
+	if err != nil {
+		cleanup()
+		return nil, "", scanner.ScanOptions{}, nil, err
+	}
+	return newAnalysis(cmd, src, opts), dir, opts, cleanup, nil
+}
+
+// fetchSource gets the codebase selected by the command's --dir, --repo or
+// --archive flag, returning the directory holding it, the source it was
+// fetched from, and the function removing the directory (see scanSource).
+func fetchSource(cmd *cobra.Command) (string, analysis.Source, func(), error) {
+	// 1. Get source (dir, repo or archive)
+	dir, _ := cmd.Flags().GetString("dir")
+	repo, _ := cmd.Flags().GetString("repo")
+	archive, _ := cmd.Flags().GetString("archive")
+	src := analysis.Source{Type: analysis.SourceDir, Location: dir}
+	cleanup := func() {}
+	if repo != "" {
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = cfg.GitHub.Token
+		}
+		if skip, _ := cmd.Flags().GetBool("skip-token-check"); token != "" && !skip {
+			if err := checkToken(cmd, repo, token); err != nil {
+				return "", src, nil, err
+			}
+		}
+		localPath, remove, err := source.FetchRepo(cmd.Context(), repo, token)
+		if err != nil {
+			return "", src, nil, err
+		}
+		dir, cleanup = localPath, remove
+		src = analysis.Source{Type: analysis.SourceRepo, Location: repo}
+	}
+	if archive != "" {
+		root, remove, err := source.ExtractArchive(archive)
+		if err != nil {
+			return "", src, nil, err
+		}
+		slog.Debug("Extracted archive", "archive", archive, "dir", root)
+		dir, cleanup = root, remove
+		src = analysis.Source{Type: analysis.SourceArchive, Location: archive}
+	}
+	if dir == "" {
+		return "", src, nil, usageErrorf("a source is required: use --dir for a local directory, --repo for a GitHub repository or --archive for a source archive")
+	}
+	return dir, src, cleanup, nil
+}
+
+// scanFiles lists the files to analyze in dir, the source src was fetched
+// to, and describes them (see scanSource).
+func scanFiles(cmd *cobra.Command, dir string, src analysis.Source, listOnly bool) (*analysis.Analysis, error) {
+	// 2. Validate source and 3. List files based on config (include/exclude/size)
+	opts, err := scanOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := addLanguageExcludes(dir, &opts); err != nil {
+		return nil, err
+	}
+	scanned, err := scanner.Scan(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := scanned.Files
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		if paths, err = changedSince(cmd, dir, since, paths); err != nil {
+			return nil, err
+		}
+		src.Since = since
+	}
+	a := newAnalysis(cmd, src, opts)
+	reportScan(a, opts, scanned, len(paths))
+	if !listOnly {
+		if err := checkMaxFiles(cmd, len(paths)); err != nil {
+			return nil, err
+		}
+	}
+
+	a.Files = make([]analysis.File, 0, len(paths))
+	for _, p := range paths {
+		file, err := describeFile(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("Selected file", "path", p, "size", file.Size, "language", file.Language)
+		a.Files = append(a.Files, file)
+	}
+	if len(a.Files) > 0 {
+		slog.Info("Languages", "breakdown", analysis.FormatLanguages(a.Languages()))
+	}
+	return a, nil
+}
+
+// addLanguageExcludes detects the primary language of the files of dir
+// selected by opts, and adds the directories its tooling leaves in the tree
+// (see scanner.LanguageExcludes) to opts.DefaultExcludes, which include
+// patterns override as usual. Without default excludes
+// (--no-default-excludes), nothing is detected.
+func addLanguageExcludes(dir string, opts *scanner.ScanOptions) error {
+	if len(opts.DefaultExcludes) == 0 {
+		return nil
+	}
+	primary, err := scanner.PrimaryLanguage(dir, *opts)
+	if err != nil || primary == "" {
+		return err
+	}
+	excludes := slices.Clone(opts.DefaultExcludes)
+	var added []string
+	for _, exclude := range scanner.LanguageExcludes[primary] {
+		if !slices.Contains(excludes, exclude) {
+			excludes = append(excludes, exclude)
+			added = append(added, exclude)
+		}
+	}
+	opts.DefaultExcludes = excludes
+	slog.Info("Detected primary language", "language", primary, "excludes", strings.Join(added, ", "))
+	return nil
+}
+
+// newAnalysis returns an analysis of src, scanned with opts, with no files yet.
+func newAnalysis(cmd *cobra.Command, src analysis.Source, opts scanner.ScanOptions) *analysis.Analysis {
+	if len(opts.Paths) > 0 {
+		src.Paths = opts.Paths
+		slog.Info("Restricted the analysis to subpaths", "subpaths", strings.Join(opts.Paths, ", "))
+	}
+	return &analysis.Analysis{
+		ProjectName: projectName(cmd),
+		Source:      src,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// reportScan logs what the scan of a with opts found: count files to
+// analyze, and those left out, which are recorded in a.
+func reportScan(a *analysis.Analysis, opts scanner.ScanOptions, scanned *scanner.Result, count int) {
+	slog.Info("Found files to analyze", "count", count, "source", a.Source.Location)
+	if !opts.Languages.IsZero() {
+		slog.Info("Filtered files by language", "include", strings.Join(opts.Languages.Include, ", "), "exclude", strings.Join(opts.Languages.Exclude, ", "),
+			"dropped", formatSkipped(scanned.FilteredLanguages))
+	}
+	if len(scanned.Skipped) > 0 {
+		a.Skipped = scanned.Skipped
+		slog.Info("Skipped files not worth analyzing", "counts", formatSkipped(scanned.Skipped), "hint", skippedHint(scanned.Skipped))
+	}
+}
+
+// changedSince returns the paths that changed since the git ref given with
+// --since, in their order, and leaves out the rest.
+func changedSince(cmd *cobra.Command, dir, ref string, paths []string) ([]string, error) {
+	changed, err := source.ChangedFiles(cmd.Context(), dir, ref)
+	if err != nil {
+		if errors.Is(err, source.ErrNotGitRepo) || errors.Is(err, source.ErrUnknownRef) {
+			return nil, usageErrorf("--since: %w", err)
+		}
+		return nil, err
+	}
+	isChanged := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		isChanged[p] = true
+	}
+	var kept []string
+	for _, p := range paths {
+		if isChanged[p] {
+			kept = append(kept, p)
+		}
+	}
+	slog.Info("Restricted the analysis to files changed since a git revision", "since", ref, "changed", len(changed), "kept", len(kept))
+	return kept, nil
+}
+
+// checkMaxFiles guards against runaway cost (e.g., analyzing / by mistake):
+// it fails if count, the number of files found, exceeds --max-files (or,
+// without the flag, defaults.max_files), unless --yes is set.
+func checkMaxFiles(cmd *cobra.Command, count int) error {
+	maxFiles, err := maxFilesLimit(cmd)
+	if err != nil {
+		return err
+	}
+	if maxFiles == 0 || count <= maxFiles {
+		return nil
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		slog.Warn("Analyzing more files than --max-files, as confirmed by --yes", "files", count, "max_files", maxFiles)
+		return nil
+	}
+	return usageErrorf("found %d files to analyze, more than the limit of %d (each costs an LLM request): "+
+		"narrow the selection with --subpath, --include or --exclude, raise --max-files, or pass --yes to analyze them all", count, maxFiles)
+}
+
+// maxFilesLimit returns the limit checkMaxFiles enforces; 0 means none.
+func maxFilesLimit(cmd *cobra.Command) (int, error) {
+	maxFiles, _ := cmd.Flags().GetInt("max-files")
+	if !cmd.Flags().Changed("max-files") {
+		maxFiles = cfg.Defaults.MaxFiles
+	}
+	if maxFiles < 0 {
+		return 0, usageErrorf("--max-files must not be negative, got %d", maxFiles)
+	}
+	return maxFiles, nil
+}
+
+// checkToken validates the GitHub token before cloning repo with it, so that
+// a bad token gets a clear error rather than a failed clone, and logs the
+// user it belongs to and its remaining rate limit.
+func checkToken(cmd *cobra.Command, repo, token string) error {
+	apiURL, err := source.APIURL(repo)
+	if err != nil {
+		return err
+	}
+	info, err := source.CheckToken(cmd.Context(), apiURL, token)
+	if err != nil {
+		if errors.Is(err, source.ErrInvalidToken) {
+			return err
+		}
+		return fmt.Errorf("%w (pass --skip-token-check to clone without checking the token)", err)
+	}
+	user := info.Login
+	if user == "" {
+		user = "(not readable with this token)"
+	}
+	attrs := []any{"user", user, "rate_limit_remaining", info.RateLimit.Remaining, "rate_limit", info.RateLimit.Limit}
+	if !info.RateLimit.Reset.IsZero() {
+		attrs = append(attrs, "resets", info.RateLimit.Reset.Local().Format(time.Kitchen))
+	}
+	slog.Info("GitHub token is valid", attrs...)
+	return nil
+}
+
+// progressLogInterval is how often progress is logged when output is not a terminal.
+const progressLogInterval = 10 * time.Second
+
+// newProgress returns the progress reporter for analysis: a progress bar on
+// an interactive terminal, periodic log lines when output is redirected, or
+// nothing with --quiet.
+func newProgress() progress.Reporter {
+	switch {
+	case quiet:
+		return progress.Nop{}
+	case progress.IsTerminal(os.Stdout) && progress.IsTerminal(os.Stderr):
+		// Drawn on stderr with the logs, keeping stdout clean
+		return progress.NewBar(os.Stderr)
+	default:
+		return progress.NewLogger(progressLogInterval)
+	}
+}
+
+// projectName returns the command's --name, or the name derived from the
+// source its --dir, --repo or --archive flag selects.
+func projectName(cmd *cobra.Command) string {
+	if name, _ := cmd.Flags().GetString("name"); name != "" {
+		return name
+	}
+	return defaultProjectName(flagSource(cmd))
+}
+
+// flagSource returns the source the command's --dir, --repo or --archive
+// flag selects, as scanSource records it.
+func flagSource(cmd *cobra.Command) analysis.Source {
+	src := analysis.Source{Type: analysis.SourceDir}
+	src.Location, _ = cmd.Flags().GetString("dir")
+	if repo, _ := cmd.Flags().GetString("repo"); repo != "" {
+		src = analysis.Source{Type: analysis.SourceRepo, Location: repo}
+	}
+	if archive, _ := cmd.Flags().GetString("archive"); archive != "" {
+		src = analysis.Source{Type: analysis.SourceArchive, Location: archive}
+	}
+	return src
+}
+
+// sameSource reports whether a and b are the same source: the same
+// directory or archive, whatever the relative or absolute path naming it, or
+// the same repository, whatever the URL or shorthand naming it.
+func sameSource(a, b analysis.Source) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if a.Type == analysis.SourceRepo {
+		urlA, errA := source.NormalizeRepoURL(a.Location)
+		urlB, errB := source.NormalizeRepoURL(b.Location)
+		if errA == nil && errB == nil {
+			return strings.EqualFold(strings.TrimSuffix(urlA, ".git"), strings.TrimSuffix(urlB, ".git"))
+		}
+		return a.Location == b.Location
+	}
+	absA, errA := filepath.Abs(a.Location)
+	absB, errB := filepath.Abs(b.Location)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a.Location) == filepath.Clean(b.Location)
+	}
+	return absA == absB
+}
+
+// defaultProjectName derives a project name from the base name of the
+// analyzed directory or repository.
+func defaultProjectName(src analysis.Source) string {
+	switch src.Type {
+	case analysis.SourceRepo:
+		return strings.TrimSuffix(path.Base(strings.TrimSuffix(src.Location, "/")), ".git")
+	case analysis.SourceArchive:
+		name := filepath.Base(src.Location)
+		for _, ext := range source.ArchiveFormats {
+			if len(name) > len(ext) && strings.EqualFold(name[len(name)-len(ext):], ext) {
+				return name[:len(name)-len(ext)]
+			}
+		}
+		return name
+	}
+	if abs, err := filepath.Abs(src.Location); err == nil {
+		return filepath.Base(abs)
+	}
+	return filepath.Base(src.Location)
+}
+
+// scanOptions builds the scanner options for a command from its --include,
+// --exclude, --include-from, --exclude-from, --max-size, --no-gitignore and
+// --include-tests flags merged with the config defaults. Flag patterns take
+// precedence over the patterns read from files, which take precedence over
+// the config patterns.
+func scanOptions(cmd *cobra.Command) (scanner.ScanOptions, error) {
+	// Commands that analyze without offering the scanning flags (e.g.,
+	// generate) get zero values here and use the config defaults.
+	include, _ := cmd.Flags().GetStringSlice("include")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	includeFrom, _ := cmd.Flags().GetStringSlice("include-from")
+	excludeFrom, _ := cmd.Flags().GetStringSlice("exclude-from")
+	maxSize, _ := cmd.Flags().GetString("max-size")
+	noGitignore, _ := cmd.Flags().GetBool("no-gitignore")
+	noDefaultExcludes, _ := cmd.Flags().GetBool("no-default-excludes")
+	includeGenerated, _ := cmd.Flags().GetBool("include-generated")
+	includeTests, _ := cmd.Flags().GetBool("include-tests")
+	if !cmd.Flags().Changed("include-tests") {
+		// Tests show how the code is exercised, which matters to contributors.
+		// The --audience flag of generate overrides defaults.audience.
+		includeTests = cfg.Defaults.Audience == prompts.AudienceContributor
+	}
+	subpaths, _ := cmd.Flags().GetStringSlice("subpath")
+	if !cmd.Flags().Changed("subpath") {
+		subpaths = cfg.Defaults.Paths
+	}
+
+	var fromFiles scanner.PatternSet
+	for _, file := range includeFrom {
+		patterns, err := scanner.ReadPatternFile(file)
+		if err != nil {
+			return scanner.ScanOptions{}, usageErrorf("--include-from: %w", err)
+		}
+		fromFiles.Include = append(fromFiles.Include, patterns...)
+	}
+	for _, file := range excludeFrom {
+		patterns, err := scanner.ReadPatternFile(file)
+		if err != nil {
+			return scanner.ScanOptions{}, usageErrorf("--exclude-from: %w", err)
+		}
+		fromFiles.Exclude = append(fromFiles.Exclude, patterns...)
+	}
+
+	opts := scanner.ScanOptions{
+		Patterns: []scanner.PatternSet{
+			{Include: include, Exclude: exclude},
+			fromFiles,
+			{Include: cfg.Defaults.Include, Exclude: cfg.Defaults.Exclude},
+		},
+		MaxSize:          int64(cfg.Defaults.MaxSize),
+		DefaultExcludes:  scanner.DefaultExcludes,
+		RespectGitignore: !noGitignore,
+		Paths:            subpaths,
+		SkipGenerated:    !includeGenerated,
+	}
+	if cmd.Flags().Changed("max-size") {
+		size, err := config.ParseByteSize(maxSize)
+		if err != nil {
+			return scanner.ScanOptions{}, usageErrorf("--max-size: %w", err)
+		}
+		opts.MaxSize = int64(size)
+	}
+	if noDefaultExcludes {
+		opts.DefaultExcludes = nil
+	}
+	if !includeTests {
+		opts.TestPatterns = cfg.Defaults.TestPatterns
+		if len(opts.TestPatterns) == 0 {
+			opts.TestPatterns = scanner.DefaultTestPatterns
+		}
+	}
+	for _, name := range []string{"include-lang", "exclude-lang"} {
+		values, _ := cmd.Flags().GetStringSlice(name)
+		langs, err := lookupLanguages(name, values)
+		if err != nil {
+			return scanner.ScanOptions{}, err
+		}
+		if name == "include-lang" {
+			opts.Languages.Include = langs
+		} else {
+			opts.Languages.Exclude = langs
+		}
+	}
+	return opts, nil
+}
+
+// lookupLanguages resolves the languages given to the flag name to the
+// names the scanner detects (see scanner.LookupLanguage).
+func lookupLanguages(name string, values []string) ([]string, error) {
+	var langs []string
+	for _, value := range values {
+		lang, ok := scanner.LookupLanguage(value)
+		if !ok {
+			return nil, usageErrorf("--%s: unknown language %q (known: %s)", name, value, strings.Join(scanner.LanguageNames(), ", "))
+		}
+		if !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs, nil
+}
+
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
 
 	// Flags for analyze command
 	analyzeCmd.Flags().String("dir", "", "Path to the local directory to analyze")
-	analyzeCmd.Flags().String("repo", "", "URL of the GitHub repository to analyze")
-	analyzeCmd.Flags().String("save-analysis", "", "File path to save the analysis results (required)")
-	analyzeCmd.Flags().String("name", "", "Custom project name")
-	analyzeCmd.Flags().String("token", "", "GitHub token for private repositories")
+	analyzeCmd.Flags().String("repo", "", "URL (or owner/repo shorthand) of the GitHub repository to analyze")
+	analyzeCmd.Flags().String("archive", "", "Path to a local .tar.gz, .tgz, .tar or .zip archive of the source to analyze")
+	analyzeCmd.Flags().String("since", "", "Only analyze the files changed since this git commit, branch or tag (with --dir on a git repository)")
+	analyzeCmd.Flags().String("save-analysis", "", "File path to save the analysis results, gzipped if it ends in .gz, or - to write them to stdout, e.g., to pipe into generate --load-analysis - (required unless --format is given)")
+	analyzeCmd.Flags().Bool("list-only", false, "Print the files that would be analyzed, with their size and language, and exit without any LLM request")
+	analyzeCmd.Flags().Bool("stats", false, "Print the count, size and lines of the files that would be analyzed by language, and the largest files, and exit without any LLM request")
+	analyzeCmd.Flags().Bool("prompt-preview", false, "Print the extraction prompts that would be sent for each file, rendered from the prompt templates, and exit without any LLM request")
+	analyzeCmd.Flags().String("prompt-preview-dir", "", "Write the previewed prompts to numbered files in this directory instead of stdout (implies --prompt-preview)")
+	analyzeCmd.Flags().String("format", "", "Also print the analysis to stdout in this format: json, the public export schema for other tools")
+	analyzeCmd.Flags().Bool("incremental", false, "Only re-analyze the files that changed since the analysis given with --load-analysis")
+	analyzeCmd.Flags().String("load-analysis", "", "Existing analysis to update with --incremental (may be the same file as --save-analysis), or - to read it from stdin")
+	analyzeCmd.Flags().Bool("update", false, "Refresh the existing analysis at --save-analysis in place, only re-analyzing the files changed since it was saved")
+	analyzeCmd.Flags().Bool("force", false, "With --update, update the analysis even if it was made from another source than --dir, --repo or --archive")
+	analyzeCmd.Flags().String("name", "", "Project name, the title of its tutorials (defaults to the name of the directory, repository or archive)")
+	analyzeCmd.Flags().String("token", "", "GitHub token for private repositories (defaults to github.token from the config)")
+	analyzeCmd.Flags().Bool("skip-token-check", false, "Clone without first validating the GitHub token with the GitHub API (e.g., for offline mirrors)")
+	analyzeCmd.Flags().StringSlice("subpath", nil, "Only analyze these subdirectories of the source (comma-separated or multiple flags; defaults to defaults.paths from the config)")
 	analyzeCmd.Flags().StringSlice("include", nil, "File patterns to include (comma-separated or multiple flags)")
+	analyzeCmd.Flags().StringSlice("include-lang", nil, "Only analyze files in these detected languages, by name or extension (e.g., go,rust); files of unknown language are kept")
+	analyzeCmd.Flags().StringSlice("exclude-lang", nil, "Do not analyze files in these detected languages (e.g., javascript,unknown)")
 	analyzeCmd.Flags().StringSlice("exclude", nil, "File patterns to exclude (comma-separated or multiple flags)")
-	analyzeCmd.Flags().Int64("max-size", 0, "Maximum file size in bytes to include")
-	analyzeCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	analyzeCmd.Flags().StringSlice("include-from", nil, "Files of patterns to include, one per line in .gitignore syntax (comma-separated or multiple flags)")
+	analyzeCmd.Flags().StringSlice("exclude-from", nil, "Files of patterns to exclude, one per line in .gitignore syntax (comma-separated or multiple flags)")
+	analyzeCmd.Flags().String("max-size", "", "Maximum file size to include, in bytes or with a unit (e.g., 512KB, 10MB)")
+	analyzeCmd.Flags().Int("max-files", config.DefaultMaxFiles, "Refuse to analyze more files than this without --yes; 0 means no limit (defaults to defaults.max_files from the config)")
+	analyzeCmd.Flags().BoolP("yes", "y", false, "Analyze the files even if there are more than --max-files")
+	analyzeCmd.Flags().Int("concurrency", config.DefaultConcurrency, "Number of files to analyze in parallel (defaults to defaults.concurrency from the config)")
+	analyzeCmd.Flags().Int("scan-buffer", config.DefaultScanBuffer, "Number of files the scan may find ahead of the extraction, bounding the memory it takes (defaults to defaults.scan_buffer from the config)")
+	analyzeCmd.Flags().Int("max-abstractions", analysis.DefaultMaxAbstractions, "Maximum number of core abstractions to identify")
+	analyzeCmd.Flags().String("model", "", "Override the LLM model specified in the config (llm.model)")
+	analyzeCmd.Flags().Float64("max-cost", 0, "Stop once the run's estimated cost reaches this many US dollars, keeping the work done; 0 means no limit (overrides llm.max_cost)")
+	analyzeCmd.Flags().Int("max-tokens-total", 0, "Stop once the run's requests have used this many input and output tokens, keeping the work done; 0 means no limit")
+	analyzeCmd.Flags().Bool("pull-model", false, "Download the configured model first if a local provider (ollama) does not have it")
+	analyzeCmd.Flags().String("prompts-dir", "", "Directory of .tmpl files overriding the built-in prompts (defaults to defaults.prompts_dir from the config)")
+	analyzeCmd.Flags().Bool("no-readme-context", false, "Do not ground the identification of abstractions in the project's README, CONTRIBUTING and docs/ files")
+	analyzeCmd.Flags().Bool("no-gitignore", false, "Do not skip files ignored by .gitignore")
+	analyzeCmd.Flags().Bool("no-default-excludes", false, "Do not skip node_modules, .git, vendor, dist, build and .venv directories, nor those of the primary language's tooling (e.g., target for Rust)")
+	analyzeCmd.Flags().Bool("include-generated", false, "Do not skip binary files, lockfiles, minified bundles and files marked as generated")
+	analyzeCmd.Flags().Bool("include-tests", false, "Analyze test files (default true for the contributor audience, from defaults.audience in the config)")
+	analyzeCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output (same as --log-level debug)")
+	addNotifyFlags(analyzeCmd)
 
 	// Ensure either --dir or --repo is provided, but not both
-	analyzeCmd.MarkFlagsMutuallyExclusive("dir", "repo")
+	analyzeCmd.MarkFlagsMutuallyExclusive("dir", "repo", "archive")
+	analyzeCmd.MarkFlagsMutuallyExclusive("since", "repo")
+	analyzeCmd.MarkFlagsMutuallyExclusive("since", "archive")
+	analyzeCmd.MarkFlagsMutuallyExclusive("since", "incremental")
+	analyzeCmd.MarkFlagsMutuallyExclusive("list-only", "save-analysis")
+	analyzeCmd.MarkFlagsMutuallyExclusive("list-only", "format")
+	analyzeCmd.MarkFlagsMutuallyExclusive("list-only", "incremental")
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "list-only")
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "save-analysis")
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "format")
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "incremental")
+	for _, preview := range []string{"prompt-preview", "prompt-preview-dir"} {
+		for _, other := range []string{"list-only", "stats", "save-analysis", "format", "incremental"} {
+			analyzeCmd.MarkFlagsMutuallyExclusive(preview, other)
+		}
+	}
+
+	// Flags overriding the config (the include and exclude patterns add to it instead)
+	bindConfigFlag(analyzeCmd, "model", "llm.model")
+	bindConfigFlag(analyzeCmd, "max-cost", "llm.max_cost")
+	bindConfigFlag(analyzeCmd, "token", "github.token")
+	bindConfigFlag(analyzeCmd, "subpath", "defaults.paths")
+	bindConfigFlag(analyzeCmd, "max-size", "defaults.max_size")
+	bindConfigFlag(analyzeCmd, "max-files", "defaults.max_files")
+	bindConfigFlag(analyzeCmd, "concurrency", "defaults.concurrency")
+	bindConfigFlag(analyzeCmd, "scan-buffer", "defaults.scan_buffer")
+	bindConfigFlag(analyzeCmd, "prompts-dir", "defaults.prompts_dir")
 }
@@ -0,0 +1,530 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte("package src\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	// Nothing listens on the endpoint, so the analysis fails right after the check
+	llmConfig := "llm:\n  provider: ollama\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\n"
+	for name, config := range map[string]string{
+		"default.yaml": llmConfig,
+		"limit.yaml":   llmConfig + "defaults:\n  max_files: 2\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantLimit bool
+	}{
+		{name: "under the default limit", args: []string{"--config", "default.yaml"}, wantLimit: false},
+		{name: "over the flag", args: []string{"--config", "default.yaml", "--max-files", "2"}, wantLimit: true},
+		{name: "over the config", args: []string{"--config", "limit.yaml"}, wantLimit: true},
+		{name: "flag overrides the config", args: []string{"--config", "limit.yaml", "--max-files", "3"}, wantLimit: false},
+		{name: "no limit", args: []string{"--config", "limit.yaml", "--max-files", "0"}, wantLimit: false},
+		{name: "confirmed", args: []string{"--config", "limit.yaml", "--yes"}, wantLimit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"analyze", "--dir", "src", "--save-analysis", "out.json"}, tt.args...)
+			_, stderr, err := execute(t, dir, args...)
+			if err == nil {
+				t.Fatalf("Expected the analysis to fail, got stderr:\n%s", stderr)
+			}
+			if got := strings.Contains(stderr, "found 3 files to analyze, more than the limit of 2"); got != tt.wantLimit {
+				t.Errorf("Expected the file limit error: %v, got stderr:\n%s", tt.wantLimit, stderr)
+			}
+			if tt.wantLimit && !strings.Contains(stderr, "--yes") {
+				t.Errorf("Expected the error to suggest --yes, got:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeArchive(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "project-1.0.zip"))
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"project-1.0/a.go", "project-1.0/b.go", "project-1.0/vendor/c.go", "project-1.0/README.md"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte("package src\n"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	f.Close()
+	config := "llm:\n  provider: ollama\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// The file limit error reports how many files passed the filters
+	_, stderr, err := execute(t, dir, "analyze", "--config", "config.yaml", "--archive", "project-1.0.zip",
+		"--include", "*.go", "--exclude", "vendor/*", "--max-files", "1", "--save-analysis", "out.json")
+	if err == nil || !strings.Contains(stderr, "found 2 files to analyze") {
+		t.Errorf("Expected the filters to leave 2 files, got stderr:\n%s", stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "analyze", "--dir", ".", "--archive", "project-1.0.zip", "--save-analysis", "out.json")
+	if err == nil || !strings.Contains(stderr, "none of the others can be") {
+		t.Errorf("Expected --dir and --archive to be mutually exclusive, got stderr:\n%s", stderr)
+	}
+}
+
+func TestAnalyzePatternFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/a.go":      "package src\n",
+		"src/b.go":      "package src\n",
+		"src/gen/c.go":  "package gen\n",
+		"src/README.md": "# src\n",
+		"exclude.txt":   "# generated code\n\ngen/\n",
+		"config.yaml":   "llm:\n  provider: ollama\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\ndefaults:\n  include: [\"*.go\"]\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "file over config", args: []string{"--exclude-from", "exclude.txt"}, want: "found 2 files to analyze"},
+		{name: "flag over file", args: []string{"--exclude-from", "exclude.txt", "--include", "gen/*.go"}, want: "found 3 files to analyze"},
+		{name: "unreadable file", args: []string{"--exclude-from", "missing.txt"}, want: "--exclude-from: failed to read patterns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"analyze", "--config", "config.yaml", "--dir", "src", "--max-files", "1", "--save-analysis", "out.json"}, tt.args...)
+			_, stderr, err := execute(t, dir, args...)
+			if err == nil || !strings.Contains(stderr, tt.want) {
+				t.Errorf("Expected %q, got stderr:\n%s", tt.want, stderr)
+			}
+		})
+	}
+}
+
+func TestIncludeTests(t *testing.T) {
+	dir := t.TempDir()
+	llmConfig := "llm:\n  provider: ollama\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\n"
+	files := map[string]string{
+		"src/main.go":      "package main\n",
+		"src/main_test.go": "package main\n",
+		"src/util.go":      "package main\n",
+		"src/util_test.go": "package main\n",
+		"developer.yaml":   llmConfig,
+		"contributor.yaml": llmConfig + "defaults:\n  audience: contributor\n",
+		"custom.yaml":      llmConfig + "defaults:\n  test_patterns: [\"util_test.go\"]\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		files int
+	}{
+		{name: "excluded by default", args: []string{"--config", "developer.yaml"}, files: 2},
+		{name: "included by the flag", args: []string{"--config", "developer.yaml", "--include-tests"}, files: 4},
+		{name: "included for contributors", args: []string{"--config", "contributor.yaml"}, files: 4},
+		{name: "excluded for contributors by the flag", args: []string{"--config", "contributor.yaml", "--include-tests=false"}, files: 2},
+		{name: "patterns from the config", args: []string{"--config", "custom.yaml"}, files: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"analyze", "--dir", "src", "--max-files", "1", "--save-analysis", "out.json"}, tt.args...)
+			_, stderr, err := execute(t, dir, args...)
+			want := fmt.Sprintf("found %d files to analyze", tt.files)
+			if err == nil || !strings.Contains(stderr, want) {
+				t.Errorf("Expected %q, got stderr:\n%s", want, stderr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeExtractionCache(t *testing.T) {
+	dir := t.TempDir()
+	var extractions atomic.Int32
+	writePipelineProject(t, dir, newPipelineServer(t, func(req pipelineRequest) {
+		if strings.Contains(req.Prompt, "a source file") {
+			extractions.Add(1)
+		}
+	}))
+
+	// A new project name changes the prompts, but not the unchanged file
+	tests := []struct {
+		args []string
+		want int32
+	}{
+		{[]string{"--name", "first", "--save-analysis", "first.json"}, 1},
+		{[]string{"--name", "second", "--save-analysis", "second.json"}, 0},
+		{[]string{"--name", "second", "--save-analysis", "third.json", "--refresh"}, 1},
+	}
+	for _, tt := range tests {
+		extractions.Store(0)
+		args := append([]string{"analyze", "--dir", "src"}, tt.args...)
+		if _, stderr, err := execute(t, dir, args...); err != nil {
+			t.Fatalf("analyze %v failed: %v\nstderr:\n%s", tt.args, err, stderr)
+		}
+		if got := extractions.Load(); got != tt.want {
+			t.Errorf("Expected %d extraction call(s) for %v, got %d", tt.want, tt.args, got)
+		}
+	}
+}
+
+func TestAnalyzeFormatJSON(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+
+	// The export goes to stdout, and --save-analysis becomes optional
+	stdout, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--name", "demo", "--format", "json", "--no-cache")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var export struct {
+		Schema        string `json:"schema"`
+		SchemaVersion int    `json:"schema_version"`
+		Project       string `json:"project"`
+		Files         []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+		Chapters []any `json:"chapters"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &export); err != nil {
+		t.Fatalf("Expected the export on stdout: %v\nstdout:\n%s", err, stdout)
+	}
+	if export.Schema != "code-decoder/export" || export.SchemaVersion != 1 || export.Project != "demo" {
+		t.Errorf("Expected a version 1 export of demo, got %+v", export)
+	}
+	if len(export.Files) != 1 || export.Files[0].Path != "a.go" || export.Chapters == nil {
+		t.Errorf("Expected a.go and no chapters, got %+v", export)
+	}
+
+	for _, args := range [][]string{{"--format", "yaml"}, {"--format", "json", "--json"}} {
+		args = append([]string{"analyze", "--dir", "src", "--no-cache"}, args...)
+		if _, _, err := execute(t, dir, args...); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
+
+func TestAnalyzeLanguageFilter(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	for name, content := range map[string]string{"src/b.rs": "fn main() {}\n", "src/c.js": "export {}\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := execute(t, dir, "--json", "analyze", "--dir", "src", "--save-analysis", "analysis.json", "--no-cache", "--include-lang", "go,rs")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var summary analysisSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	if summary.Files != 2 || strings.Join(summary.Run.Languages, ",") != "Go,Rust" {
+		t.Errorf("Expected the Go and Rust files, got %d files in %v", summary.Files, summary.Run.Languages)
+	}
+	if !strings.Contains(stderr, "dropped=\"1 JavaScript\"") {
+		t.Errorf("Expected the filtered files to be logged, got stderr:\n%s", stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "analysis.json", "--exclude-lang", "cobol")
+	if err == nil || !strings.Contains(stderr, `--exclude-lang: unknown language "cobol"`) {
+		t.Errorf("Expected an unknown language to be rejected, got stderr:\n%s", stderr)
+	}
+}
+
+func TestAnalyzeListOnly(t *testing.T) {
+	dir := t.TempDir()
+	var calls atomic.Int32
+	writePipelineProject(t, dir, newPipelineServer(t, func(pipelineRequest) { calls.Add(1) }))
+	for name, content := range map[string]string{"src/b.md": "# B\n", "src/logo.png": "\x89PNG\x00\x00"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// The list ignores --max-files, since it costs nothing
+	stdout, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--list-only", "--no-cache", "--max-files", "1")
+	if err != nil {
+		t.Fatalf("analyze --list-only failed: %v\nstderr:\n%s", err, stderr)
+	}
+	for _, want := range []string{"SIZE", "Go", "a.go", "Markdown", "b.md", "2 files"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected the list to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "logo.png") {
+		t.Errorf("Expected the binary file to be left out, got:\n%s", stdout)
+	}
+
+	stdout, stderr, err = execute(t, dir, "--json", "analyze", "--dir", "src", "--list-only", "--no-cache", "--exclude-lang", "markdown")
+	if err != nil {
+		t.Fatalf("analyze --list-only --json failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var list fileList
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	if len(list.Files) != 1 || list.Files[0].Path != "a.go" || list.Files[0].Language != "Go" || list.TotalSize != list.Files[0].Size {
+		t.Errorf("Expected only a.go, got %+v", list)
+	}
+	if list.Skipped["binary"] != 1 {
+		t.Errorf("Expected the skipped binary file to be counted, got %v", list.Skipped)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected no LLM requests, got %d", n)
+	}
+
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--list-only", "--save-analysis", "out.json")
+	if err == nil || !strings.Contains(stderr, "list-only") {
+		t.Errorf("Expected --list-only and --save-analysis to conflict, got stderr:\n%s", stderr)
+	}
+}
+
+func TestAnalyzeReadmeContext(t *testing.T) {
+	dir := t.TempDir()
+	var grounded atomic.Bool
+	writePipelineProject(t, dir, newPipelineServer(t, func(req pipelineRequest) {
+		if strings.Contains(req.Prompt, "These are its files") && strings.Contains(req.Prompt, "Widgets are loaded lazily.") {
+			grounded.Store(true)
+		}
+	}))
+	if err := os.WriteFile(filepath.Join(dir, "src", "README.md"), []byte("# Demo\n\nWidgets are loaded lazily.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README.md: %v", err)
+	}
+
+	_, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "analysis.json", "--no-cache")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if !grounded.Load() {
+		t.Error("Expected the README in the prompt identifying the abstractions")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "analysis.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the saved analysis: %v", err)
+	}
+	if !strings.Contains(string(data), `"docs": true`) {
+		t.Errorf("Expected the README to be marked as a document, got:\n%s", data)
+	}
+
+	grounded.Store(false)
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "analysis.json", "--no-cache", "--no-readme-context")
+	if err != nil {
+		t.Fatalf("analyze --no-readme-context failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if grounded.Load() {
+		t.Error("Expected no README in the prompt with --no-readme-context")
+	}
+}
+
+func TestAnalyzeSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = filepath.Join(dir, "src")
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+	git("tag", "v1")
+	for name, content := range map[string]string{"src/b.go": "package src\n", "src/c.md": "# C\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	git("add", ".")
+	git("commit", "--quiet", "-m", "second")
+
+	// The changed files still go through the other filters
+	stdout, stderr, err := execute(t, dir, "--json", "analyze", "--dir", "src", "--list-only", "--since", "v1", "--exclude", "*.md")
+	if err != nil {
+		t.Fatalf("analyze --since failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var list fileList
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	if len(list.Files) != 1 || list.Files[0].Path != "b.go" {
+		t.Errorf("Expected only b.go, got %+v", list.Files)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--dir", "src", "--since", "v9"}, "unknown git revision"},
+		{[]string{"--dir", ".", "--since", "v1"}, "not a git repository"},
+	} {
+		_, stderr, err := execute(t, dir, append([]string{"analyze", "--list-only"}, tt.args...)...)
+		if err == nil || !strings.Contains(stderr, tt.want) {
+			t.Errorf("Expected %v to fail with %q, got stderr:\n%s", tt.args, tt.want, stderr)
+		}
+	}
+}
+
+func TestAnalyzeUpdate(t *testing.T) {
+	dir := t.TempDir()
+	var extractions atomic.Int32
+	writePipelineProject(t, dir, newPipelineServer(t, func(req pipelineRequest) {
+		if strings.Contains(req.Prompt, "a source file") {
+			extractions.Add(1)
+		}
+	}))
+	analyze := func(args ...string) (string, error) {
+		t.Helper()
+		extractions.Store(0)
+		_, stderr, err := execute(t, dir, append([]string{"analyze", "--no-cache", "--save-analysis", "out.json"}, args...)...)
+		return stderr, err
+	}
+
+	if _, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--no-cache", "--save-analysis", "out.json", "--update"); err == nil || !strings.Contains(stderr, "does not exist") {
+		t.Errorf("Expected --update to fail without an analysis to refresh, got stderr:\n%s", stderr)
+	}
+	if stderr, err := analyze("--dir", "src"); err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+
+	// Only the new file is extracted, whatever the path naming the directory
+	if err := os.WriteFile(filepath.Join(dir, "src", "b.go"), []byte("package src\n"), 0644); err != nil {
+		t.Fatalf("Failed to write b.go: %v", err)
+	}
+	if stderr, err := analyze("--dir", "./src/", "--update"); err != nil {
+		t.Fatalf("analyze --update failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if got := extractions.Load(); got != 1 {
+		t.Errorf("Expected 1 extraction call for the new file, got %d", got)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "out.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the updated analysis: %v", err)
+	}
+	var updated struct {
+		Files []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &updated); err != nil || len(updated.Files) != 2 {
+		t.Errorf("Expected the updated analysis to hold 2 files, got %s (%v)", data, err)
+	}
+
+	// Another source is refused unless forced
+	if err := os.MkdirAll(filepath.Join(dir, "other"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other", "c.go"), []byte("package other\n"), 0644); err != nil {
+		t.Fatalf("Failed to write c.go: %v", err)
+	}
+	if stderr, err := analyze("--dir", "other", "--update"); err == nil || !strings.Contains(stderr, "pass --force") {
+		t.Errorf("Expected --update of another source to fail, got stderr:\n%s", stderr)
+	}
+	if stderr, err := analyze("--dir", "other", "--update", "--force"); err != nil {
+		t.Errorf("analyze --update --force failed: %v\nstderr:\n%s", err, stderr)
+	}
+
+	for _, args := range [][]string{{"--update", "--incremental", "--load-analysis", "out.json"}, {"--force"}} {
+		if stderr, err := analyze(append([]string{"--dir", "src"}, args...)...); err == nil {
+			t.Errorf("Expected %v to fail, got stderr:\n%s", args, stderr)
+		}
+	}
+}
+
+func TestAnalyzePrimaryLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	for _, name := range []string{"src/main.rs", "src/lib.rs", "src/target/debug/build.rs"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("fn main() {}\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// Rust's build output is skipped, unless the default excludes are off
+	tests := []struct {
+		args         []string
+		wantTarget   bool
+		wantDetected bool
+	}{
+		{nil, false, true},
+		{[]string{"--include", "target/**"}, true, true},
+		{[]string{"--no-default-excludes"}, true, false},
+	}
+	for _, tt := range tests {
+		args := append([]string{"analyze", "--dir", "src", "--list-only"}, tt.args...)
+		stdout, stderr, err := execute(t, dir, args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v\nstderr:\n%s", args, err, stderr)
+		}
+		if got := strings.Contains(stdout, "target/debug/build.rs"); got != tt.wantTarget {
+			t.Errorf("Expected target/ listed for %v: %v, got:\n%s", tt.args, tt.wantTarget, stdout)
+		}
+		if got := strings.Contains(stderr, `msg="Detected primary language" language=Rust excludes=target`); got != tt.wantDetected {
+			t.Errorf("Expected Rust to be detected for %v: %v, got stderr:\n%s", tt.args, tt.wantDetected, stderr)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command group
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the LLM response cache",
+	Long: `Commands for managing the cache of LLM responses and extracted file
+knowledge kept under the user cache directory (e.g., ~/.cache/code-decoder).
+Cached results let repeated runs over the same code skip LLM calls that were
+already paid for.`,
+	// Managing the cache does not need a valid LLM configuration.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:          "clear",
+	Short:        "Remove all cached LLM responses and extractions",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := llm.DefaultCacheDir()
+		if err != nil {
+			return err
+		}
+		removed, err := llm.ClearCache(dir)
+		if err != nil {
+			return err
+		}
+		extractions, err := analysis.ClearExtractionCache(dir)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), map[string]any{"dir": dir, "removed": removed, "extractions": extractions})
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached response(s) and %d cached extraction(s) from %s\n", removed, extractions, dir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
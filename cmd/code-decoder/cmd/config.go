@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and check the configuration",
+	Long: `Commands for working with the code-decoder configuration file,
+such as checking it for problems before running a real command.`,
+	// Config subcommands report configuration problems themselves, so they
+	// must not be stopped by the root command's validation.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the resolved configuration",
+	Long: `Loads the configuration (from --config, the default locations, and
+environment variables) and checks it for problems such as a missing API key,
+a missing endpoint, or an invalid audience. Every problem found is printed and
+the command exits with status 3; otherwise "configuration valid" is printed.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfgErr != nil {
+			problems := config.Problems(cfgErr)
+			for _, problem := range problems {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  - %s\n", problem)
+			}
+			err := &config.Error{Err: fmt.Errorf("configuration invalid: %d problem(s) found", len(problems))}
+			if profile != "" {
+				err.Err = fmt.Errorf("configuration invalid (profile %q): %d problem(s) found", profile, len(problems))
+			}
+			if jsonOutput {
+				messages := make([]string, len(problems))
+				for i, problem := range problems {
+					messages[i] = problem.Error()
+				}
+				if jsonErr := printJSON(cmd.OutOrStdout(), map[string]any{"valid": false, "problems": messages}); jsonErr != nil {
+					return jsonErr
+				}
+				return silentError{err}
+			}
+			return err
+		}
+
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), map[string]any{"valid": true})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "configuration valid")
+		return nil
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the resolved configuration and where each value came from",
+	Long: `Prints every setting of the resolved configuration, after merging the
+config file, environment variables and the flags given, with the source of
+each value: flag, env, file or default. API keys and tokens are redacted
+(e.g., "sk-...abcd"). Profiles are not listed; with --profile, the llm
+settings are those of the profile.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfgErr != nil {
+			return fmt.Errorf("%w (run config validate to list the problems)", cfgErr)
+		}
+		settings := cfg.Settings()
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), configShow{File: cfg.File, Profile: cfg.Profile, Settings: settings})
+		}
+
+		file := cfg.File
+		if file == "" {
+			file = "none (defaults and environment variables only)"
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Config file:", file)
+		if cfg.Profile != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), "Profile:", cfg.Profile)
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, setting := range settings {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, formatSetting(setting.Value), setting.Source)
+		}
+		return w.Flush()
+	},
+}
+
+// configShow is the --json output of config show.
+type configShow struct {
+	File     string           `json:"file"`              // Config file read; empty if none
+	Profile  string           `json:"profile,omitempty"` // Profile replacing the llm section, if any
+	Settings []config.Setting `json:"settings"`
+}
+
+// formatSetting returns value, a config.Setting value, as config show prints it.
+func formatSetting(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "(unset)"
+	case []string:
+		return strings.Join(v, ",")
+	case config.ByteSize:
+		return fmt.Sprintf("%d", v)
+	}
+	return fmt.Sprint(value)
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+}
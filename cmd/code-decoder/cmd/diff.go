@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/spf13/cobra"
+)
+
+// analyzeDiffCmd represents the analyze diff command
+var analyzeDiffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Compare two analysis files",
+	Long: `Loads two analyses of a codebase and reports the abstractions that were
+added or removed, the relationships that changed, and the files that entered or
+left the analyzed scope. Like diff, it exits with status 1 when the analyses
+differ, so it can gate CI.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	// Comparing analyses does not need a valid LLM configuration.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return usageErrorf("unsupported diff format: %q (supported: text, json)", format)
+		}
+
+		older, err := analysis.Load(args[0])
+		if err != nil {
+			return err
+		}
+		newer, err := analysis.Load(args[1])
+		if err != nil {
+			return err
+		}
+
+		diff := analysis.Compare(older, newer)
+		if format == "json" || jsonOutput {
+			if err := printJSON(cmd.OutOrStdout(), diff); err != nil {
+				return err
+			}
+		} else {
+			printDiff(cmd.OutOrStdout(), diff)
+		}
+		if !diff.Empty() {
+			return silentError{errors.New("the analyses differ")}
+		}
+		return nil
+	},
+}
+
+// printDiff writes a human-readable summary of d.
+func printDiff(w io.Writer, d analysis.Diff) {
+	if d.Empty() {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+	section := func(title string, added, removed []string) {
+		if len(added)+len(removed) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s: %d added, %d removed\n", title, len(added), len(removed))
+		for _, item := range added {
+			fmt.Fprintf(w, "  + %s\n", item)
+		}
+		for _, item := range removed {
+			fmt.Fprintf(w, "  - %s\n", item)
+		}
+	}
+	relationships := func(rels []analysis.Relationship) []string {
+		lines := make([]string, len(rels))
+		for i, rel := range rels {
+			lines[i] = fmt.Sprintf("%s -> %s (%s)", rel.From, rel.To, rel.Label)
+		}
+		return lines
+	}
+
+	section("Abstractions", d.AddedAbstractions, d.RemovedAbstractions)
+	section("Relationships", relationships(d.AddedRelationships), relationships(d.RemovedRelationships))
+	section("Files", d.AddedFiles, d.RemovedFiles)
+}
+
+func init() {
+	analyzeCmd.AddCommand(analyzeDiffCmd)
+
+	analyzeDiffCmd.Flags().String("format", "text", "Output format (text, json)")
+}
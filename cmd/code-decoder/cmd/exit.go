@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+// Exit statuses of code-decoder, documented in the README so that scripts
+// can tell why a run failed.
+const (
+	exitOK          = 0   // Success
+	exitError       = 1   // Any failure not covered below
+	exitUsage       = 2   // Invalid command line: unknown command or flag, bad or conflicting flag values
+	exitConfig      = 3   // The configuration is missing, unreadable or invalid
+	exitProvider    = 4   // The LLM provider failed: unreachable, error response, timeout or unknown model
+	exitInterrupted = 130 // Interrupted by Ctrl-C or SIGTERM: 128 plus the signal number, as in shells
+)
+
+// usageError is returned for an invalid command line, which exits with
+// status exitUsage.
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// usageErrorf formats a usageError.
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit status for err, the error a command returned.
+// canceled reports whether the run was interrupted.
+func exitCode(err error, canceled bool) int {
+	var (
+		usage  usageError
+		cfgErr *config.Error
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case canceled && errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &cfgErr):
+		return exitConfig
+	case llm.IsProviderError(err):
+		return exitProvider
+	}
+	return exitError
+}
+
+// running is set once the RunE of the command being run is called. Errors
+// returned before that, other than the configuration's, come from cobra's
+// checks of the command line: unknown commands and flags, wrong arguments,
+// and missing or conflicting flags.
+var running bool
+
+// trackRun makes the RunE of c and its subcommands set running.
+func trackRun(c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			running = true
+			return run(cmd, args)
+		}
+	}
+	for _, sub := range c.Commands() {
+		trackRun(sub)
+	}
+}
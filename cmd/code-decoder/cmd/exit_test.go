@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/llm"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		canceled bool
+		want     int
+	}{
+		{name: "success", err: nil, want: exitOK},
+		{name: "generic", err: errors.New("clone failed"), want: exitError},
+		{name: "usage", err: usageErrorf("--concurrency must be at least 1, got %d", 0), want: exitUsage},
+		{name: "silent usage", err: silentError{usageErrorf("bad flag")}, want: exitUsage},
+		{name: "config", err: fmt.Errorf("loading: %w", &config.Error{Err: errors.New("invalid configuration")}), want: exitConfig},
+		{name: "api", err: fmt.Errorf("identifying abstractions: %w", &llm.APIError{Provider: "openai", StatusCode: 401}), want: exitProvider},
+		{name: "connection", err: &llm.ConnectionError{Provider: "ollama", Err: errors.New("connection refused")}, want: exitProvider},
+		{name: "joined", err: fmt.Errorf("2 of 3 chapters could not be generated: %w", errors.Join(errors.New("x"), llm.ErrEmptyResponse)), want: exitProvider},
+		{name: "interrupted", err: fmt.Errorf("extracting a.go: %w", context.Canceled), canceled: true, want: exitInterrupted},
+		{name: "canceled without a signal", err: context.Canceled, want: exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err, tt.canceled); got != tt.want {
+				t.Errorf("Expected exit status %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	for name, content := range map[string]string{
+		"invalid.yaml":     "llm:\n  provider: ollama\n  endpoint: http://localhost:11434\n  model: llama3\n  temperature: 5\n",
+		"unreachable.yaml": "llm:\n  provider: ollama\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "unknown flag", args: []string{"analyze", "--bogus"}, want: exitUsage},
+		{name: "unknown command", args: []string{"bogus"}, want: exitUsage},
+		{name: "conflicting flags", args: []string{"analyze", "--dir", "src", "--list-only", "--save-analysis", "a.json"}, want: exitUsage},
+		{name: "bad flag value", args: []string{"analyze", "--dir", "src", "--save-analysis", "a.json", "--concurrency", "0"}, want: exitUsage},
+		{name: "invalid config", args: []string{"analyze", "--dir", "src", "--save-analysis", "a.json", "--config", "invalid.yaml"}, want: exitConfig},
+		{name: "config validate", args: []string{"config", "validate", "--config", "invalid.yaml"}, want: exitConfig},
+		{name: "missing config", args: []string{"test-llm", "--config", "missing.yaml"}, want: exitConfig},
+		{name: "unreachable provider", args: []string{"test-llm", "--config", "unreachable.yaml"}, want: exitProvider},
+		{name: "generic", args: []string{"analyze", "--dir", "missing", "--save-analysis", "a.json"}, want: exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, err := execute(t, dir, tt.args...)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != tt.want {
+				t.Errorf("Expected exit status %d, got %v; stderr:\n%s", tt.want, err, stderr)
+			}
+		})
+	}
+}
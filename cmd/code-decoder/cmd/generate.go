@@ -3,9 +3,23 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os" // Added for error handling in completion registration
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/generation"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+	"github.com/ksylvan/code-decoder/internal/render"
 	"github.com/spf13/cobra"
 )
 
@@ -16,40 +30,660 @@ var generateCmd = &cobra.Command{
 	Long: `Creates audience-targeted tutorials based on either a direct codebase analysis
 or a previously saved analysis file. Outputs can be customized by audience,
 language, and format.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("generate called")
-		// TODO: Implement generation logic here
-		// 1. Determine source: load analysis or analyze dir/repo
-		// 2. Get generation options (audience, language, format, output dir)
+	SilenceUsage: true,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// Generation needs a saved analysis or a codebase to analyze
+		for _, name := range []string{"load-analysis", "dir", "repo"} {
+			if value, _ := cmd.Flags().GetString(name); value != "" {
+				return nil
+			}
+		}
+		return usageErrorf("a source is required: use --load-analysis for a saved analysis, or --dir (local directory) or --repo (GitHub repository) to analyze a codebase")
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slog.Debug("generate called")
+		start := time.Now()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		preview := promptPreviewRequested(cmd)
+		if loadPath, _ := cmd.Flags().GetString("load-analysis"); loadPath == "" {
+			switch {
+			case dryRun:
+				return usageErrorf("--dry-run requires --load-analysis, since analyzing a codebase calls the LLM")
+			case preview:
+				return usageErrorf("--prompt-preview requires --load-analysis, since analyzing a codebase calls the LLM (preview its extraction prompts with analyze --prompt-preview)")
+			}
+		}
+
+		// Chapters may be written by another model than the one analyzing
+		llmCfg, err := llmConfig(cmd, "writing-model")
+		if err != nil {
+			return err
+		}
+		completion := completionOptions()
+		length, err := chapterLength(cmd)
+		if err != nil {
+			return err
+		}
+		// --max-tokens caps the chapters alone, not the analysis of --dir or
+		// --repo, whose JSON responses it could truncate
+		if cmd.Flags().Changed("max-tokens") {
+			maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+			if maxTokens <= 0 {
+				return usageErrorf("--max-tokens must be positive, got %d", maxTokens)
+			}
+			completion.MaxTokens = maxTokens
+		} else if length.MaxTokens > 0 {
+			completion.MaxTokens = length.MaxTokens
+		}
+		contextWindow, tokenizer, err := contextGuard(llmCfg, completion)
+		if err != nil {
+			return err
+		}
+		// Check the output format before doing any (possibly expensive) work
+		format, _ := cmd.Flags().GetString("format")
+		noDiagrams, _ := cmd.Flags().GetBool("no-diagrams")
+		noCrossLinks, _ := cmd.Flags().GetBool("no-cross-links")
+		mermaidURL, _ := cmd.Flags().GetString("mermaid-url")
+		singleFile, _ := cmd.Flags().GetBool("single-file")
+		outputName, _ := cmd.Flags().GetString("output-name-template")
+		frontMatter, _ := cmd.Flags().GetBool("front-matter")
+		chapterOrder, _ := cmd.Flags().GetString("chapter-order")
+		if !slices.Contains(generation.ChapterOrders, chapterOrder) {
+			return usageErrorf("invalid --chapter-order %q (use %s)", chapterOrder, strings.Join(generation.ChapterOrders, ", "))
+		}
+		audience, _ := cmd.Flags().GetString("audience")
+		if !cmd.Flags().Changed("audience") && cfg.Defaults.Audience != "" {
+			audience = cfg.Defaults.Audience
+		}
+		if !slices.Contains(prompts.Audiences, audience) {
+			return usageErrorf("invalid --audience %q (use %s)", audience, strings.Join(prompts.Audiences, ", "))
+		}
+		languageName, _ := cmd.Flags().GetString("language")
+		if !cmd.Flags().Changed("language") && cfg.Defaults.Language != "" {
+			languageName = cfg.Defaults.Language
+		}
+		language, known := generation.LookupLanguage(languageName)
+		if !known {
+			slog.Warn("Unknown language; asking the LLM to write in it anyway", "language", languageName)
+		}
+		// A saved analysis is loaded up front, since the default output
+		// directory is named after its project
+		var a *analysis.Analysis
+		loadPath, _ := cmd.Flags().GetString("load-analysis")
+		if loadPath != "" {
+			if a, err = loadAnalysis(cmd, loadPath); err != nil {
+				return err
+			}
+			slog.Info("Loaded analysis", "path", loadPath, "project", a.ProjectName, "files", len(a.Files))
+			if name, _ := cmd.Flags().GetString("name"); name != "" {
+				a.ProjectName = name
+			}
+		}
+		outputDir, _ := cmd.Flags().GetString("output")
+		if !cmd.Flags().Changed("output") && outputDir != stdoutOutput {
+			if cfg.Defaults.OutputDir != "" {
+				outputDir = cfg.Defaults.OutputDir
+			}
+			// The default output directory is shared by the tutorials of every project
+			project := projectName(cmd)
+			if a != nil {
+				project = a.ProjectName
+			}
+			outputDir = filepath.Join(outputDir, render.ProjectDir(project))
+		}
+		resume, _ := cmd.Flags().GetBool("resume")
+		toStdout := outputDir == stdoutOutput
+		if toStdout {
+			// Stream the tutorial as one Markdown document, with nothing else on stdout
+			switch {
+			case format != "markdown" && format != "md":
+				return usageErrorf("--output - streams Markdown to stdout, but the %s format writes files; give an output directory instead", format)
+			case jsonOutput:
+				return usageErrorf("--output - cannot be combined with --json, which also writes to stdout")
+			case resume:
+				return usageErrorf("--output - cannot be combined with --resume, since no progress is saved to resume from")
+			}
+			singleFile = true
+		}
+		if savePath, _ := cmd.Flags().GetString("save-analysis"); savePath == pipedAnalysis && (toStdout || jsonOutput) {
+			return usageErrorf("--save-analysis - cannot be combined with --output - or --json, which also write to stdout")
+		}
+		only, _ := cmd.Flags().GetStringSlice("chapters")
+		switch overwrite, _ := cmd.Flags().GetBool("overwrite"); {
+		case len(only) > 0 && toStdout:
+			return usageErrorf("--chapters keeps the other chapters in the output directory, so it cannot be combined with --output -")
+		case len(only) > 0 && (singleFile || format == "pdf" || format == "json"):
+			return usageErrorf("--chapters keeps the files of the other chapters, but this output writes them all to one file; generate the whole tutorial instead")
+		case len(only) > 0 && !dryRun && !preview:
+			// The tutorial is updated in place, so --overwrite is implied
+			if err := checkChaptersOutputDir(outputDir); err != nil {
+				return err
+			}
+		case !toStdout && !overwrite && !dryRun && !preview:
+			if err := checkOutputDir(outputDir, resume); err != nil {
+				return err
+			}
+		}
+		if !toStdout && !dryRun && !preview {
+			runOutput = outputDir
+		}
+		style, err := outputStyle(cmd, format, outputDir)
+		if err != nil {
+			return err
+		}
+		options := render.Options{Diagrams: !noDiagrams, CrossLinks: !noCrossLinks, MermaidURL: mermaidURL, SingleFile: singleFile, OutputName: outputName,
+			FrontMatter: frontMatter, FrontMatterTemplate: cfg.Generation.FrontMatterTemplate}
+		if style != nil {
+			options.Style = *style
+		}
+		renderer, err := render.New(format, options)
+		if err != nil {
+			return err
+		}
+		templates, err := loadPrompts(cmd)
+		if err != nil {
+			return err
+		}
+		completion.System = templates.System()
+		if cfg.Generation.SystemPrompt != "" {
+			if err := checkSystemPrompt(cfg.Generation.SystemPrompt, contextWindow, tokenizer, completion.MaxTokens); err != nil {
+				return err
+			}
+			completion.System = cfg.Generation.SystemPrompt
+		}
+
+		// 1. Determine source: the loaded analysis or analyze dir/repo
+		if a == nil {
+			if a, err = analyzeSource(cmd); err != nil {
+				return err
+			}
+			if savePath, _ := cmd.Flags().GetString("save-analysis"); savePath != "" {
+				if err := saveAnalysis(cmd, savePath, a); err != nil {
+					return err
+				}
+			}
+		}
+
+		generator := &generation.Generator{Options: completion, Prompts: templates, Audience: audience, Language: language.Name, Words: length.Words, ContextWindow: contextWindow, Tokenizer: tokenizer}
+		if dryRun {
+			if len(only) > 0 {
+				if a.Abstractions, err = generation.Order(a, chapterOrder); err != nil {
+					return err
+				}
+				if generator.Only, err = selectChapters(a.Abstractions, only); err != nil {
+					return err
+				}
+			}
+			return estimateGeneration(cmd, llmCfg, a, generator)
+		}
+		if preview {
+			if a.Abstractions, err = generation.Order(a, chapterOrder); err != nil {
+				return err
+			}
+			if len(only) > 0 {
+				if generator.Only, err = selectChapters(a.Abstractions, only); err != nil {
+					return err
+				}
+			}
+			return previewChapters(cmd, a, generator)
+		}
+
+		// 2. Get generation options (the format and output dir were checked up front)
+		provider, err := newProvider(cmd, llmCfg)
+		if err != nil {
+			return err
+		}
+		generator.Provider = provider
+		out := humanOut(cmd)
+		if toStdout {
+			out = cmd.ErrOrStderr()
+		}
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		verbose = verbose && !quiet
+		if verbose {
+			// Show each chapter as it is being written
+			generator.OnChunk = func(_ generation.Chapter, text string) {
+				fmt.Fprint(out, text)
+			}
+		}
+
+		// Record each completed chapter, so that an interrupted run can be resumed
+		if resume {
+			previous, err := resumableManifest(outputDir, a.ProjectName)
+			if err != nil {
+				return err
+			}
+			if previous != nil {
+				if previous.ChapterOrder != "" && previous.ChapterOrder != chapterOrder {
+					if cmd.Flags().Changed("chapter-order") {
+						return usageErrorf("cannot resume: the interrupted run ordered chapters %s, not %s (drop --chapter-order or --resume)", previous.ChapterOrder, chapterOrder)
+					}
+					chapterOrder = previous.ChapterOrder
+					slog.Info("Keeping the chapter order of the interrupted run", "order", chapterOrder)
+				}
+				if previous.Audience != "" && previous.Audience != generator.Audience {
+					if cmd.Flags().Changed("audience") {
+						return usageErrorf("cannot resume: the interrupted run was written for the %s audience, not %s (drop --audience or --resume)", previous.Audience, generator.Audience)
+					}
+					generator.Audience = previous.Audience
+					slog.Info("Keeping the audience of the interrupted run", "audience", generator.Audience)
+				}
+				if previous.Language.Name != "" && previous.Language != language {
+					if cmd.Flags().Changed("language") {
+						return usageErrorf("cannot resume: the interrupted run was written in %s, not %s (drop --language or --resume)", previous.Language.Name, language.Name)
+					}
+					language = previous.Language
+					generator.Language = language.Name
+					slog.Info("Keeping the language of the interrupted run", "language", language.Name)
+				}
+				generator.Completed = previous.Chapters
+			}
+		}
+		if a.Abstractions, err = generation.Order(a, chapterOrder); err != nil {
+			return err
+		}
+		slog.Debug("Ordered chapters", "order", chapterOrder)
+		if len(only) > 0 {
+			if generator.Only, err = selectChapters(a.Abstractions, only); err != nil {
+				return err
+			}
+			slog.Info("Regenerating chapters; the others are kept as they are", "chapters", generator.Only)
+		}
+		manifest := &generation.Manifest{ProjectName: a.ProjectName, ChapterOrder: chapterOrder, Audience: generator.Audience, Language: language}
+		tutorial := &render.Tutorial{ProjectName: a.ProjectName, Relationships: a.Relationships, Analysis: a, Language: language, Audience: generator.Audience}
+		// write renders the chapters completed so far to the output directory
+		write := func(chapters []generation.Chapter) ([]string, error) {
+			tutorial.Chapters = chapters
+			if len(generator.Only) > 0 {
+				tutorial.Chapters, tutorial.Keep = keptChapters(a, chapters)
+			}
+			paths, err := renderer.Render(tutorial, outputDir)
+			if err != nil {
+				return nil, err
+			}
+			removed, err := render.UpdateRecord(outputDir, a.ProjectName, style, paths)
+			for _, path := range removed {
+				slog.Info("Removed stale tutorial file", "path", path)
+			}
+			return paths, err
+		}
+		if !toStdout {
+			// Each chapter is written as it completes, so that a failed run
+			// leaves a readable tutorial of the chapters done; PDFs, made by
+			// an external converter, are only written at the end, and so are
+			// regenerated chapters, whose old files are kept until then
+			incremental := format != "pdf" && len(generator.Only) == 0
+			generator.OnChapter = func(chapter generation.Chapter) error {
+				completed, err := manifest.Add(outputDir, chapter)
+				if err != nil {
+					return err
+				}
+				if incremental {
+					_, err := write(completed)
+					return err
+				}
+				return nil
+			}
+		}
+
 		// 3. Generate content using LLM and analysis data
-		// 4. Render content using templates (Markdown/HTML)
-		// 5. Save output files
+		chapters, err := generator.Generate(cmd.Context(), a)
+		if err != nil {
+			if len(chapters) > 0 && !toStdout {
+				if _, werr := write(chapters); werr != nil {
+					slog.Warn("Failed to write the completed chapters", "error", werr)
+				} else {
+					slog.Info("Wrote the completed chapters; re-run with --resume to generate the rest", "chapters", len(chapters), "dir", outputDir)
+				}
+			}
+			return err
+		}
+
+		if verbose {
+			fmt.Fprintln(out)
+		}
+
+		glossary, _ := cmd.Flags().GetBool("glossary")
+		if !cmd.Flags().Changed("glossary") {
+			glossary = generation.GlossaryDefault(generator.Audience)
+		}
+		if glossary && len(generator.Only) > 0 {
+			// The glossary needs every chapter, so the one written is kept
+			slog.Info("Keeping the glossary as it is, since only some chapters are regenerated")
+		} else if glossary && format != "json" {
+			if tutorial.Glossary, err = generator.Glossary(cmd.Context(), a, chapters); err != nil {
+				return err
+			}
+		}
+
+		// 4. Render content using templates and 5. Save output files
+		tutorial.Chapters = chapters
+		written := len(chapters)
+		if toStdout {
+			if err := renderer.(*render.MarkdownRenderer).WriteDocument(tutorial, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			newRunSummary(a, &written, start).print(cmd.ErrOrStderr())
+			return nil
+		}
+		paths, err := write(chapters)
+		if err != nil {
+			return err
+		}
+		slog.Info("Tutorial written", "dir", outputDir, "files", len(paths))
+		if err := generation.RemoveManifest(outputDir); err != nil {
+			return err
+		}
+		run := newRunSummary(a, &written, start)
+		run.print(cmd.ErrOrStderr())
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), map[string]any{
+				"project":    a.ProjectName,
+				"output_dir": outputDir,
+				"chapters":   len(chapters),
+				"language":   language,
+				"files":      paths,
+				"run":        run,
+			})
+		}
+		return nil
 	},
 }
 
+// stdoutOutput is the --output value that streams the tutorial to stdout.
+const stdoutOutput = "-"
+
+// checkOutputDir refuses to write a tutorial to outputDir when it holds one
+// already, unless an interrupted run is being resumed there: --overwrite
+// must be passed to replace it.
+func checkOutputDir(outputDir string, resume bool) error {
+	if resume {
+		if _, err := generation.LoadManifest(outputDir); err == nil {
+			return nil
+		}
+	}
+	exists, err := render.HasTutorial(outputDir)
+	if err != nil || !exists {
+		return err
+	}
+	return usageErrorf("%s already holds a generated tutorial: pass --overwrite to replace it (files of your own in the directory are kept), or choose another --output", outputDir)
+}
+
+// checkChaptersOutputDir checks that outputDir holds a tutorial to
+// regenerate chapters of with --chapters.
+func checkChaptersOutputDir(outputDir string) error {
+	exists, err := render.HasTutorial(outputDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return usageErrorf("--chapters regenerates chapters of the tutorial in %s, but there is none there: generate the whole tutorial first", outputDir)
+	}
+	return nil
+}
+
+// selectChapters resolves the --chapters values, abstraction names or
+// 1-based chapter indexes, to the names of the abstractions they select
+// among abstractions, in chapter order.
+func selectChapters(abstractions []analysis.Abstraction, values []string) ([]string, error) {
+	names := make([]string, len(abstractions))
+	for i, abs := range abstractions {
+		names[i] = abs.Name
+	}
+	var selected []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		i := slices.Index(names, value)
+		if i < 0 {
+			i = slices.IndexFunc(names, func(name string) bool { return strings.EqualFold(name, value) })
+		}
+		if n, err := strconv.Atoi(value); i < 0 && err == nil {
+			if n < 1 || n > len(names) {
+				return nil, usageErrorf("--chapters: there is no chapter %d; the tutorial has %d", n, len(names))
+			}
+			i = n - 1
+		}
+		if i < 0 {
+			return nil, usageErrorf("--chapters: the analysis has no abstraction %q (its chapters: %s)", value, strings.Join(names, ", "))
+		}
+		if !slices.Contains(selected, names[i]) {
+			selected = append(selected, names[i])
+		}
+	}
+	return selected, nil
+}
+
+// keptChapters returns the chapters of the tutorial about a when only some
+// of them were regenerated: those of chapters, and in their place for every
+// other abstraction, a chapter without content whose file is kept as it is,
+// along with the indexes of the kept chapters (see render.Tutorial.Keep).
+func keptChapters(a *analysis.Analysis, chapters []generation.Chapter) ([]generation.Chapter, []int) {
+	all := make([]generation.Chapter, len(a.Abstractions))
+	var keep []int
+	for i, abs := range a.Abstractions {
+		all[i] = generation.Chapter{Index: i + 1, Title: abs.Name, Abstraction: abs.Name}
+		if j := slices.IndexFunc(chapters, func(c generation.Chapter) bool { return c.Index == i+1 }); j >= 0 {
+			all[i] = chapters[j]
+		} else {
+			keep = append(keep, i+1)
+		}
+	}
+	return all, keep
+}
+
+// outputStyle returns the look of the tutorial written to outputDir in
+// format, as the command's --theme and --css flags give it, or else as the
+// record of the tutorial already there keeps it, so that re-running writes
+// it the same way; the default theme otherwise. It returns nil for formats
+// without a look.
+func outputStyle(cmd *cobra.Command, format, outputDir string) (*render.Style, error) {
+	theme, _ := cmd.Flags().GetString("theme")
+	css, _ := cmd.Flags().GetString("css")
+	themeSet, cssSet := cmd.Flags().Changed("theme"), cmd.Flags().Changed("css")
+	switch {
+	case format != "html" && format != "pdf":
+		if themeSet || cssSet {
+			return nil, usageErrorf("--theme and --css style html and pdf output, not %s", format)
+		}
+		return nil, nil
+	case themeSet && cssSet:
+		return nil, usageErrorf("--theme and --css cannot be combined, since the --css stylesheet replaces the theme")
+	case themeSet:
+		if err := render.CheckTheme(theme); err != nil {
+			return nil, usageErrorf("invalid --theme: %v", err)
+		}
+		return &render.Style{Theme: theme}, nil
+	case cssSet:
+		// The path is recorded, so it must not depend on the working directory
+		abs, err := filepath.Abs(css)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --css path: %w", err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, usageErrorf("cannot use --css %s: %v", css, err)
+		}
+		return &render.Style{CSS: abs}, nil
+	}
+
+	record, err := render.LoadRecord(outputDir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case record.Style != nil:
+		slog.Info("Keeping the style of the tutorial in the output directory", "theme", record.Style.Theme, "css", record.Style.CSS)
+		return record.Style, nil
+	}
+	return &render.Style{Theme: theme}, nil
+}
+
+// chapterLength returns the target length of the chapters: that of the
+// --chapter-length level when it is given, else generation.target_words from
+// the config, else none, leaving the length to the model.
+func chapterLength(cmd *cobra.Command) (generation.Length, error) {
+	if name, _ := cmd.Flags().GetString("chapter-length"); name != "" {
+		length, err := generation.LookupLength(name)
+		if err != nil {
+			return generation.Length{}, usageErrorf("--chapter-length: %w", err)
+		}
+		return length, nil
+	}
+	if cfg.Generation.TargetWords > 0 {
+		return generation.WordsLength(cfg.Generation.TargetWords), nil
+	}
+	return generation.Length{}, nil
+}
+
+// checkSystemPrompt checks that a custom system prompt, which is sent with
+// every chapter request, leaves room for the chapter prompts in a context
+// window of window tokens (0 if unknown), and warns when it takes more than
+// a quarter of it.
+func checkSystemPrompt(system string, window int, tokenizer llm.Tokenizer, maxTokens int) error {
+	if window <= 0 {
+		return nil
+	}
+	tokens := tokenizer.CountTokens(system)
+	if tokens >= llm.PromptBudget(window, maxTokens) {
+		return fmt.Errorf("the system prompt (%d tokens) leaves no room for the chapter prompts in the %d-token context window", tokens, window)
+	}
+	if tokens > window/4 {
+		slog.Warn("The system prompt takes much of the context window, leaving less room for the code of each chapter", "tokens", tokens, "window", window)
+	}
+	return nil
+}
+
+// resumableManifest returns the manifest of an interrupted run in
+// outputDir, or nil if there is none.
+func resumableManifest(outputDir, project string) (*generation.Manifest, error) {
+	manifest, err := generation.LoadManifest(outputDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		slog.Info("Nothing to resume; generating all chapters", "dir", outputDir)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if manifest.ProjectName != project {
+		return nil, usageErrorf("cannot resume: %s was written for project %q, not %q (remove it or choose another --output)",
+			filepath.Join(outputDir, generation.ManifestFile), manifest.ProjectName, project)
+	}
+	slog.Info("Resuming generation", "dir", outputDir, "completed", len(manifest.Chapters))
+	return manifest, nil
+}
+
+// estimateGeneration prints the estimated input tokens and cost of
+// generating tutorials from a with generator and the model of llmCfg,
+// without making any API calls.
+func estimateGeneration(cmd *cobra.Command, llmCfg config.LLMConfig, a *analysis.Analysis, generator *generation.Generator) error {
+	chapterPrompts, err := generator.ChapterPrompts(a)
+	if err != nil {
+		return err
+	}
+
+	// The system instruction is sent with every prompt
+	tokenizer := llm.TokenizerFor(llmCfg.Model)
+	tokens := 0
+	for _, prompt := range chapterPrompts {
+		tokens += tokenizer.CountTokens(generator.Options.System) + tokenizer.CountTokens(prompt)
+	}
+
+	pricing, ok := llm.LookupPricing(llmCfg.Provider, llmCfg.Model)
+	if jsonOutput {
+		estimate := map[string]any{
+			"provider":     llmCfg.Provider,
+			"model":        llmCfg.Model,
+			"prompts":      len(chapterPrompts),
+			"input_tokens": tokens,
+			"input_cost":   nil, // Unknown without pricing data
+		}
+		if ok {
+			estimate["input_cost"] = pricing.InputCost(tokens)
+		}
+		return printJSON(cmd.OutOrStdout(), estimate)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Estimated input tokens: %d across %d prompts (%s, model %s)\n", tokens, len(chapterPrompts), llmCfg.Provider, llmCfg.Model)
+	switch {
+	case !ok:
+		fmt.Fprintf(out, "Estimated input cost: unknown (no pricing data for %s model %s)\n", llmCfg.Provider, llmCfg.Model)
+	case pricing.InputPerMillion == 0:
+		fmt.Fprintln(out, "Estimated input cost: $0.00 (local provider)")
+	default:
+		fmt.Fprintf(out, "Estimated input cost: $%.4f (at $%.2f per 1M input tokens)\n", pricing.InputCost(tokens), pricing.InputPerMillion)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
 	// Flags for generate command
-	generateCmd.Flags().String("load-analysis", "", "Path to a saved analysis file to use for generation")
+	generateCmd.Flags().String("load-analysis", "", "Path to a saved analysis file to use for generation, or - to read the analysis from stdin (e.g., piped from analyze --save-analysis -)")
 	generateCmd.Flags().String("dir", "", "Path to the local directory to analyze and generate from")
 	generateCmd.Flags().String("repo", "", "URL of the GitHub repository to analyze and generate from")
-	generateCmd.Flags().String("audience", "developer", "Target audience for the tutorial (beginner, developer, contributor)")
-	generateCmd.Flags().String("language", "English", "Language for the generated tutorial")
-	generateCmd.Flags().String("output", "./tutorials", "Directory to save generated tutorials")
-	generateCmd.Flags().String("format", "markdown", "Output format (markdown, html)")
-	generateCmd.Flags().String("save-analysis", "", "File path to save analysis results if analyzing a codebase directly")
+	generateCmd.Flags().String("name", "", "Project name, the title of the tutorial (defaults to the name in --load-analysis, or of the directory or repository)")
+	generateCmd.Flags().Bool("skip-token-check", false, "Clone without first validating the GitHub token (github.token) with the GitHub API")
+	generateCmd.Flags().String("audience", prompts.AudienceDeveloper, "Target audience for the tutorial: developer (APIs and usage), beginner (concepts and analogies) or contributor (internals and extension points); defaults to defaults.audience from the config")
+	generateCmd.Flags().Bool("include-tests", false, "Analyze test files when analyzing --dir or --repo (default true for the contributor audience)")
+	generateCmd.Flags().String("language", generation.DefaultLanguage.Name, "Language to write the tutorial in, as a name or code (e.g., Chinese, zh, pt-BR); code and identifiers are kept as is (defaults to defaults.language from the config)")
+	generateCmd.Flags().String("output", "./tutorials", "Directory to save the generated tutorial, or - to write a single Markdown document to stdout (defaults to a subdirectory named after the project in defaults.output_dir from the config)")
+	generateCmd.Flags().String("format", "markdown", "Output format (markdown, html, pdf, or json for the analysis and chapters in the public export schema)")
+	generateCmd.Flags().Bool("single-file", false, "Write the markdown tutorial as one document with a table of contents instead of a file per chapter")
+	generateCmd.Flags().Bool("front-matter", false, "Start each markdown chapter file with YAML front matter (title, weight, audience, language, date) for static site generators such as Hugo and Jekyll; the fields are set by generation.front_matter_template in the config")
+	generateCmd.Flags().String("output-name-template", render.DefaultOutputName, "Go template naming chapter files, with the fields Index, Slug and Title (the format's extension is added)")
+	generateCmd.Flags().String("chapter-order", generation.OrderTopological, "Order of the chapters: topological (foundations first), alphabetical, or as-analyzed")
+	generateCmd.Flags().StringSlice("chapters", nil, "Regenerate only these chapters, given by abstraction name or 1-based index (e.g., 2,\"Config Loader\"), keeping the files of the others in the output directory")
+	generateCmd.Flags().Bool("glossary", false, "Write a glossary of the tutorial's key terms, linking each to the chapters that introduce it (default true for the beginner audience)")
+	generateCmd.Flags().Bool("no-diagrams", false, "Do not include Mermaid diagrams of how the abstractions connect")
+	generateCmd.Flags().Bool("no-cross-links", false, "Do not link the first mention of another chapter's abstraction in each chapter to that chapter")
+	generateCmd.Flags().String("theme", render.DefaultTheme, "Look of html and pdf output: "+strings.Join(render.Themes, ", ")+"; defaults to the one the tutorial in the output directory was written with, if any")
+	generateCmd.Flags().String("css", "", "Stylesheet replacing the built-in theme of html and pdf output altogether")
+	generateCmd.Flags().String("mermaid-url", render.DefaultMermaidURL, "URL of the Mermaid JS module loaded by HTML output (e.g., a local copy for offline viewing)")
+	generateCmd.Flags().Int("max-files", config.DefaultMaxFiles, "Refuse to analyze more files than this without --yes when analyzing a codebase; 0 means no limit (defaults to defaults.max_files)")
+	generateCmd.Flags().BoolP("yes", "y", false, "Analyze the files even if there are more than --max-files")
+	generateCmd.Flags().Int("concurrency", config.DefaultConcurrency, "Number of files to analyze in parallel when analyzing a codebase (defaults to defaults.concurrency from the config)")
+	generateCmd.Flags().Int("scan-buffer", config.DefaultScanBuffer, "Number of files the scan may find ahead of the extraction when analyzing a codebase, bounding the memory it takes (defaults to defaults.scan_buffer from the config)")
+	generateCmd.Flags().String("save-analysis", "", "File path to save analysis results if analyzing a codebase directly, or - to write them to stdout")
 	generateCmd.Flags().String("provider", "", "Override the LLM provider specified in the config")
-	generateCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	generateCmd.Flags().String("model", "", "Override the LLM model specified in the config (llm.model)")
+	generateCmd.Flags().String("extraction-model", "", "Model analyzing the codebase with --dir or --repo, e.g., a cheaper one (defaults to --model)")
+	generateCmd.Flags().String("writing-model", "", "Model writing the chapters, e.g., a stronger one (defaults to --model)")
+	generateCmd.Flags().String("system-prompt", "", "System instruction sent with every chapter request, e.g., to set the tutorial's voice; replaces the built-in one (defaults to generation.system_prompt from the config)")
+	generateCmd.Flags().String("chapter-length", "", "Length of each chapter: short (about 600 words), medium (1500) or long (3000), which also sets the tokens each may take unless --max-tokens is given (defaults to generation.target_words from the config)")
+	generateCmd.Flags().Float64("temperature", 0, "Sampling temperature between 0 and 2 (overrides llm.temperature)")
+	generateCmd.Flags().Int("max-tokens", 0, "Maximum tokens to generate per chapter and for the glossary, leaving the analysis of --dir or --repo to llm.max_tokens (defaults to llm.max_tokens)")
+	generateCmd.Flags().Int("max-abstractions", analysis.DefaultMaxAbstractions, "Maximum number of core abstractions to identify when analyzing a codebase")
+	generateCmd.Flags().Float64("max-cost", 0, "Stop once the run's estimated cost reaches this many US dollars, writing the chapters done; 0 means no limit (overrides llm.max_cost)")
+	generateCmd.Flags().Int("max-tokens-total", 0, "Stop once the run's requests have used this many input and output tokens, writing the chapters done; 0 means no limit")
+	generateCmd.Flags().Bool("pull-model", false, "Download the configured model first if a local provider (ollama) does not have it")
+	generateCmd.Flags().String("prompts-dir", "", "Directory of .tmpl files overriding the built-in prompts (defaults to defaults.prompts_dir from the config)")
+	generateCmd.Flags().Bool("resume", false, "Reuse the chapters completed by an interrupted run in the output directory")
+	generateCmd.Flags().Bool("overwrite", false, "Replace the tutorial already in the output directory, removing its stale chapter files")
+	generateCmd.Flags().Bool("dry-run", false, "Estimate input tokens and cost without calling the LLM (requires --load-analysis)")
+	generateCmd.Flags().Bool("prompt-preview", false, "Print the chapter prompts that would be sent, rendered from the prompt templates, and exit without any LLM request (requires --load-analysis; combine with --chapters to preview some)")
+	generateCmd.Flags().String("prompt-preview-dir", "", "Write the previewed prompts to numbered files in this directory instead of stdout (implies --prompt-preview)")
+	generateCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output (same as --log-level debug)")
+	addNotifyFlags(generateCmd)
 
 	// Register custom completion for the --audience flag
-	err := generateCmd.RegisterFlagCompletionFunc("audience", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"beginner", "developer", "contributor"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err := generateCmd.RegisterFlagCompletionFunc("audience", cobra.FixedCompletions(prompts.Audiences, cobra.ShellCompDirectiveNoFileComp))
 	if err != nil {
 		// Handle error, e.g., log it or exit. Exiting is simple for init phase.
 		fmt.Fprintf(os.Stderr, "Error registering completion function for --audience: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+	err = generateCmd.RegisterFlagCompletionFunc("chapter-order", cobra.FixedCompletions(generation.ChapterOrders, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering completion function for --chapter-order: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	err = generateCmd.RegisterFlagCompletionFunc("chapter-length", cobra.FixedCompletions(generation.ChapterLengths, cobra.ShellCompDirectiveNoFileComp))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering completion function for --chapter-length: %v\n", err)
+		os.Exit(exitError)
 	}
 
 	// Ensure either load-analysis or one of (dir, repo) is provided
@@ -58,7 +692,23 @@ func init() {
 	// Note: dir and repo are already mutually exclusive via analyzeCmd logic if we reuse it,
 	// but explicit here is fine too. If generate directly analyzes, it needs this.
 	generateCmd.MarkFlagsMutuallyExclusive("dir", "repo")
+	// PreRunE ensures that at least one of --load-analysis, --dir and --repo is provided
+
+	// A run either estimates its cost or previews its prompts
+	generateCmd.MarkFlagsMutuallyExclusive("dry-run", "prompt-preview")
+	generateCmd.MarkFlagsMutuallyExclusive("dry-run", "prompt-preview-dir")
 
-	// TODO: Add logic to ensure that if --load-analysis is not used, then either --dir or --repo MUST be provided.
-	// This might require validation within the Run function or using PersistentPreRunE.
+	// Flags overriding the config
+	bindConfigFlag(generateCmd, "provider", "llm.provider")
+	bindConfigFlag(generateCmd, "model", "llm.model")
+	bindConfigFlag(generateCmd, "system-prompt", "generation.system_prompt")
+	bindConfigFlag(generateCmd, "temperature", "llm.temperature")
+	bindConfigFlag(generateCmd, "max-cost", "llm.max_cost")
+	bindConfigFlag(generateCmd, "audience", "defaults.audience")
+	bindConfigFlag(generateCmd, "language", "defaults.language")
+	bindConfigFlag(generateCmd, "output", "defaults.output_dir")
+	bindConfigFlag(generateCmd, "max-files", "defaults.max_files")
+	bindConfigFlag(generateCmd, "concurrency", "defaults.concurrency")
+	bindConfigFlag(generateCmd, "scan-buffer", "defaults.scan_buffer")
+	bindConfigFlag(generateCmd, "prompts-dir", "defaults.prompts_dir")
 }
@@ -0,0 +1,848 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+func TestGenerateRequiresSource(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := "llm:\n  provider: ollama\n  endpoint: http://localhost:11434\n  model: llama3\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, stderr, err := execute(t, dir, "generate", "--config", configPath)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 0 {
+		t.Fatalf("Expected a nonzero exit status, got %v", err)
+	}
+	for _, flag := range []string{"--load-analysis", "--dir", "--repo"} {
+		if !strings.Contains(stderr, flag) {
+			t.Errorf("Expected the error to suggest %s, got:\n%s", flag, stderr)
+		}
+	}
+}
+
+func TestGenerateToStdout(t *testing.T) {
+	// The fake Ollama server writes every chapter the same way
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		case "/api/generate":
+			w.Write([]byte(`{"response":"# Loader\n\nLoads the configuration.","done":true}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],` +
+		`"abstractions":[{"name":"Loader","description":"Loads the configuration"}],"relationships":[]}`
+	for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := execute(t, dir, "generate", "--load-analysis", "analysis.json", "--output", "-", "--verbose")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if !strings.HasPrefix(stdout, "---\n") || !strings.Contains(stdout, "# Tutorial: demo") || !strings.Contains(stdout, "Loads the configuration.") {
+		t.Errorf("Expected the single Markdown document on stdout, got:\n%s", stdout)
+	}
+	if strings.Count(stdout, "Loads the configuration.") != 1 {
+		t.Errorf("Expected the streamed chapter text on stderr only, got stdout:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tutorials")); !os.IsNotExist(err) {
+		t.Errorf("Expected no tutorial files to be written, got %v", err)
+	}
+
+	for _, args := range [][]string{{"--format", "html"}, {"--json"}, {"--resume"}} {
+		args = append([]string{"generate", "--load-analysis", "analysis.json", "--output", "-"}, args...)
+		_, stderr, err := execute(t, dir, args...)
+		if err == nil || !strings.Contains(stderr, "--output -") {
+			t.Errorf("Expected %v to be rejected with --output -, got stderr:\n%s", args, stderr)
+		}
+	}
+}
+
+func TestAnalysisPipeline(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+
+	// analyze --save-analysis - | generate --load-analysis - --output -
+	saved, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--no-cache", "--save-analysis", "-")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	a, err := analysis.Read(strings.NewReader(saved), "stdout")
+	if err != nil || len(a.Abstractions) == 0 {
+		t.Fatalf("Expected the analysis alone on stdout, got %v:\n%s", err, saved)
+	}
+	stdout, stderr, err := executeWithStdin(t, dir, saved, "generate", "--load-analysis", "-", "--output", "-", "--no-cache")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "# Tutorial: src") || !strings.Contains(stdout, "Loads the configuration.") {
+		t.Errorf("Expected the tutorial of the piped analysis on stdout, got:\n%s", stdout)
+	}
+
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{name: "truncated", stdin: saved[:len(saved)/2], args: []string{"generate", "--load-analysis", "-", "--output", "-"}, wantErr: "failed to parse the analysis on stdin: the JSON ends after"},
+		{name: "not an analysis", stdin: "Error: boom\n", args: []string{"generate", "--load-analysis", "-", "--output", "-"}, wantErr: "invalid JSON at byte 1"},
+		{name: "empty", stdin: "\n", args: []string{"generate", "--load-analysis", "-", "--output", "-"}, wantErr: "the analysis on stdin is empty"},
+		{name: "nothing piped", args: []string{"generate", "--load-analysis", "-", "--output", "-"}, wantErr: "nothing is piped into it"},
+		{name: "json output too", args: []string{"analyze", "--dir", "src", "--save-analysis", "-", "--json"}, wantErr: "--save-analysis - cannot be combined"},
+		{name: "update", args: []string{"analyze", "--dir", "src", "--save-analysis", "-", "--update"}, wantErr: "--update requires --save-analysis with the file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, err := executeWithStdin(t, dir, tt.stdin, tt.args...)
+			if err == nil || !strings.Contains(stderr, tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v, stderr:\n%s", tt.wantErr, err, stderr)
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		case "/api/generate":
+			w.Write([]byte(`{"response":"# Loader\n\nLoads the configuration.","done":true}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[{"path":"a.go","size":1}],` +
+		`"skipped_files":{"binary":2},"abstractions":[{"name":"Loader","description":"Loads the configuration"}],"relationships":[]}`
+	for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := execute(t, dir, "generate", "--load-analysis", "analysis.json", "--output", "out", "--no-cache", "--json")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var result struct {
+		Run runSummary `json:"run"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	run := result.Run
+	if run.FilesScanned != 1 || run.FilesSkipped != 2 || run.Abstractions != 1 || run.Chapters == nil || *run.Chapters != 1 {
+		t.Errorf("Unexpected run summary: %+v", run)
+	}
+	if run.LLMCalls != 1 || run.InputTokens == 0 || run.OutputTokens == 0 || run.EstimatedCost == nil || *run.EstimatedCost != 0 {
+		t.Errorf("Expected one free request with estimated tokens, got %+v", run)
+	}
+	if !strings.Contains(stderr, "Run summary:") || !strings.Contains(stderr, "LLM requests:  1") {
+		t.Errorf("Expected the summary on stderr, got:\n%s", stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "generate", "--load-analysis", "analysis.json", "--output", "out", "--no-cache", "--quiet", "--overwrite")
+	if err != nil || strings.Contains(stderr, "Run summary:") {
+		t.Errorf("Expected no summary with --quiet, got %v and stderr:\n%s", err, stderr)
+	}
+}
+
+func TestGenerateOverwrite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		case "/api/generate":
+			w.Write([]byte(`{"response":"# Chapter\n\nExplains it.","done":true}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	writeAnalysis := func(abstractions ...string) {
+		t.Helper()
+		var list []string
+		for _, name := range abstractions {
+			list = append(list, `{"name":"`+name+`","description":"Does things"}`)
+		}
+		analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],` +
+			`"abstractions":[` + strings.Join(list, ",") + `],"relationships":[]}`
+		for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+		}
+	}
+	generate := func(args ...string) (string, error) {
+		t.Helper()
+		args = append([]string{"generate", "--load-analysis", "analysis.json", "--output", "out", "--no-cache"}, args...)
+		_, stderr, err := execute(t, dir, args...)
+		return stderr, err
+	}
+	out := filepath.Join(dir, "out")
+
+	writeAnalysis("Loader", "Parser")
+	if stderr, err := generate(); err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if err := os.WriteFile(filepath.Join(out, "NOTES.md"), []byte("mine"), 0644); err != nil {
+		t.Fatalf("Failed to write user file: %v", err)
+	}
+
+	// Refused without --overwrite
+	if stderr, err := generate(); err == nil || !strings.Contains(stderr, "already holds a generated tutorial") || !strings.Contains(stderr, "--overwrite") {
+		t.Errorf("Expected generate to refuse the existing tutorial, got %v and stderr:\n%s", err, stderr)
+	}
+
+	// Overwritten, with the chapter of the dropped abstraction removed
+	writeAnalysis("Loader")
+	if stderr, err := generate("--overwrite"); err != nil {
+		t.Fatalf("generate --overwrite failed: %v\nstderr:\n%s", err, stderr)
+	}
+	for name, want := range map[string]bool{"index.md": true, "01_loader.md": true, "02_parser.md": false, "NOTES.md": true, ".code-decoder.json": true} {
+		if _, err := os.Stat(filepath.Join(out, name)); (err == nil) != want {
+			t.Errorf("Expected %s to exist: %v, got %v", name, want, err)
+		}
+	}
+
+	// Switching formats removes the Markdown files too
+	if stderr, err := generate("--overwrite", "--format", "html"); err != nil {
+		t.Fatalf("generate --format html failed: %v\nstderr:\n%s", err, stderr)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if got := strings.Join(names, ","); got != ".code-decoder.json,01_loader.html,NOTES.md,index.html,style.css" {
+		t.Errorf("Unexpected output files %s", got)
+	}
+}
+
+func TestGenerateTheme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		case "/api/generate":
+			w.Write([]byte(`{"response":"# Chapter\n\nExplains it.","done":true}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],` +
+		`"abstractions":[{"name":"Loader","description":"Loads things"}],"relationships":[]}`
+	for name, content := range map[string]string{
+		"config.yaml":   "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n",
+		"analysis.json": analysis,
+		"brand.css":     "body { color: teal; }\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	generate := func(args ...string) (string, error) {
+		t.Helper()
+		args = append([]string{"generate", "--load-analysis", "analysis.json", "--output", "out", "--no-cache", "--overwrite"}, args...)
+		_, stderr, err := execute(t, dir, args...)
+		return stderr, err
+	}
+
+	// A theme, once chosen, is kept by the runs that choose none
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--format", "html"}, "--bg: #ffffff"},
+		{[]string{"--format", "html", "--theme", "dark"}, "--bg: #0d1117"},
+		{[]string{"--format", "html"}, "--bg: #0d1117"},
+		{[]string{"--format", "html", "--css", "brand.css"}, "color: teal"},
+		{[]string{"--format", "html"}, "color: teal"},
+		{[]string{"--format", "html", "--theme", "minimal"}, "Georgia"},
+	}
+	for _, tt := range tests {
+		if stderr, err := generate(tt.args...); err != nil {
+			t.Fatalf("generate %v failed: %v\nstderr:\n%s", tt.args, err, stderr)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "out", "style.css"))
+		if err != nil {
+			t.Fatalf("Failed to read the stylesheet: %v", err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("Expected the stylesheet of %v to contain %q, got:\n%s", tt.args, tt.want, data)
+		}
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--format", "html", "--theme", "solarized"}, "available: light, dark, minimal"},
+		{[]string{"--format", "html", "--theme", "dark", "--css", "brand.css"}, "cannot be combined"},
+		{[]string{"--format", "html", "--css", "missing.css"}, "missing.css"},
+		{[]string{"--theme", "dark"}, "not markdown"},
+	} {
+		if stderr, err := generate(tt.args...); err == nil || !strings.Contains(stderr, tt.want) {
+			t.Errorf("Expected %v to fail with %q, got stderr:\n%s", tt.args, tt.want, stderr)
+		}
+	}
+}
+
+func TestGenerateChapters(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+		case "/api/generate":
+			mu.Lock()
+			calls++
+			fmt.Fprintf(w, `{"response":"# Chapter\n\nVersion %d.","done":true}`+"\n", calls)
+			mu.Unlock()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],` +
+		`"abstractions":[{"name":"Loader","description":"Loads"},{"name":"Parser","description":"Parses"},{"name":"Writer","description":"Writes"}],"relationships":[]}`
+	for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	generate := func(args ...string) (string, error) {
+		t.Helper()
+		args = append([]string{"generate", "--load-analysis", "analysis.json", "--output", "out", "--no-cache", "--chapter-order", "as-analyzed"}, args...)
+		_, stderr, err := execute(t, dir, args...)
+		return stderr, err
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "out", name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	// There is no tutorial to regenerate chapters of yet
+	var exitErr *exec.ExitError
+	if stderr, err := generate("--chapters", "Parser"); !errors.As(err, &exitErr) || exitErr.ExitCode() != exitUsage || !strings.Contains(stderr, "generate the whole tutorial first") {
+		t.Errorf("Expected a usage error without a tutorial, got %v and stderr:\n%s", err, stderr)
+	}
+	if stderr, err := generate(); err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	loader, writer := read("01_loader.md"), read("03_writer.md")
+
+	// By name, case aside, and by index; --overwrite is implied
+	for _, args := range [][]string{{"--chapters", "parser"}, {"--chapters", "2", "--overwrite"}} {
+		calls = 0
+		if stderr, err := generate(args...); err != nil {
+			t.Fatalf("generate %v failed: %v\nstderr:\n%s", args, err, stderr)
+		}
+		if calls != 1 {
+			t.Errorf("Expected a single request for %v, got %d", args, calls)
+		}
+		if got := read("02_parser.md"); !strings.Contains(got, "Version 1.") {
+			t.Errorf("Expected the chapter to be regenerated, got:\n%s", got)
+		}
+		if read("01_loader.md") != loader || read("03_writer.md") != writer {
+			t.Error("Expected the other chapters to be left as they were")
+		}
+		index := read("index.md")
+		for _, want := range []string{"01_loader.md", "02_parser.md", "03_writer.md"} {
+			if !strings.Contains(index, want) {
+				t.Errorf("Expected index.md to link to %s, got:\n%s", want, index)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--chapters", "Lexer"}, want: `no abstraction "Lexer" (its chapters: Loader, Parser, Writer)`},
+		{args: []string{"--chapters", "7"}, want: "there is no chapter 7"},
+		{args: []string{"--chapters", "Parser", "--single-file"}, want: "writes them all to one file"},
+	} {
+		if stderr, err := generate(tt.args...); !errors.As(err, &exitErr) || exitErr.ExitCode() != exitUsage || !strings.Contains(stderr, tt.want) {
+			t.Errorf("Expected a usage error containing %q for %v, got %v and stderr:\n%s", tt.want, tt.args, err, stderr)
+		}
+	}
+}
+
+// pipelineRequest is a request received by the server of newPipelineServer.
+type pipelineRequest struct {
+	Model   string `json:"model"`
+	Prompt  string `json:"prompt"`
+	System  string `json:"system"`
+	Options struct {
+		NumPredict int `json:"num_predict"`
+	} `json:"options"`
+}
+
+// newPipelineServer returns a fake Ollama server with the models llama3 and
+// mistral, answering the prompts of every step from analysis to chapters and
+// the glossary.
+// Each request is passed to record, if not nil.
+func newPipelineServer(t *testing.T, record func(req pipelineRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"mistral:latest"}]}`))
+			return
+		}
+		var req pipelineRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if record != nil {
+			record(req)
+		}
+		response := "# Loader\n\nLoads the configuration."
+		switch {
+		case strings.Contains(req.Prompt, "a source file"):
+			response = `{"summary": "Loads the configuration", "abstractions": [{"name": "Loader", "description": "Loads the configuration"}]}`
+		case strings.Contains(req.Prompt, "These are its files"):
+			response = `{"abstractions": [{"name": "Loader", "description": "Loads the configuration", "files": ["a.go"]}]}`
+		case strings.Contains(req.Prompt, "These are its core abstractions"):
+			response = `{"relationships": []}`
+		case strings.Contains(req.Prompt, "These are the tutorial's chapters"):
+			response = `{"terms": [{"term": "configuration", "definition": "The settings of a program.", "chapters": [1]}]}`
+		}
+		json.NewEncoder(w).Encode(map[string]any{"response": response, "done": true})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writePipelineProject writes a config using server and a source directory
+// with one file to dir.
+func writePipelineProject(t *testing.T, dir string, server *httptest.Server) {
+	t.Helper()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	for name, content := range map[string]string{"config.yaml": config, "src/a.go": "package src\n"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestGenerateProjectName(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+
+	// Without --output, the tutorial goes to a directory named after the project
+	_, stderr, err := execute(t, dir, "generate", "--dir", "src", "--name", "acme/widgets", "--save-analysis", "analysis.json", "--no-cache")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "analysis.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the saved analysis: %v", err)
+	}
+	if !strings.Contains(string(data), `"project_name": "acme/widgets"`) {
+		t.Errorf("Expected the name in the saved analysis, got:\n%s", data)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "tutorials", "acme-widgets", "index.md"))
+	if err != nil {
+		t.Fatalf("Expected the tutorial in tutorials/acme-widgets: %v\nstderr:\n%s", err, stderr)
+	}
+	if !strings.Contains(string(index), "# Tutorial: acme/widgets") {
+		t.Errorf("Expected the name in the index heading, got:\n%s", index)
+	}
+
+	// --name renames a loaded analysis, and an explicit --output is used as is
+	_, stderr, err = execute(t, dir, "generate", "--load-analysis", "analysis.json", "--name", "widgets", "--output", "out", "--no-cache")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if index, err = os.ReadFile(filepath.Join(dir, "out", "index.md")); err != nil || !strings.Contains(string(index), "# Tutorial: widgets") {
+		t.Errorf("Expected the renamed tutorial in out, got %v and:\n%s", err, index)
+	}
+}
+
+func TestGenerateModels(t *testing.T) {
+	var mu sync.Mutex
+	models := map[string]map[string]bool{} // Step -> models used
+	server := newPipelineServer(t, func(req pipelineRequest) {
+		step := "writing"
+		if strings.HasPrefix(req.Prompt, "You are analyzing") {
+			step = "extraction"
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if models[step] == nil {
+			models[step] = map[string]bool{}
+		}
+		models[step][req.Model] = true
+	})
+	dir := t.TempDir()
+	writePipelineProject(t, dir, server)
+
+	_, stderr, err := execute(t, dir, "generate", "--dir", "src", "--output", "out", "--no-cache", "--extraction-model", "mistral", "--writing-model", "llama3")
+	if err != nil {
+		t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if len(models["extraction"]) != 1 || !models["extraction"]["mistral"] || len(models["writing"]) != 1 || !models["writing"]["llama3"] {
+		t.Errorf("Expected mistral to analyze and llama3 to write, got %v", models)
+	}
+
+	// --model applies to both steps
+	clear(models)
+	_, stderr, err = execute(t, dir, "generate", "--dir", "src", "--output", "out2", "--no-cache", "--model", "mistral")
+	if err != nil {
+		t.Fatalf("generate --model failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if len(models["extraction"]) != 1 || !models["extraction"]["mistral"] || len(models["writing"]) != 1 || !models["writing"]["mistral"] {
+		t.Errorf("Expected mistral for every step, got %v", models)
+	}
+
+	for _, args := range [][]string{{"generate", "--dir", "src", "--writing-model="}, {"analyze", "--dir", "src", "--save-analysis", "analysis.json", "--model="}} {
+		_, stderr, err := execute(t, dir, args...)
+		if err == nil || !strings.Contains(stderr, "must not be empty") {
+			t.Errorf("Expected %v to be rejected, got stderr:\n%s", args, stderr)
+		}
+	}
+}
+
+func TestGenerateSystemPrompt(t *testing.T) {
+	var mu sync.Mutex
+	systems := map[string]string{} // Step -> system prompt
+	server := newPipelineServer(t, func(req pipelineRequest) {
+		step := "writing"
+		if strings.HasPrefix(req.Prompt, "You are analyzing") {
+			step = "extraction"
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		systems[step] = req.System
+	})
+	dir := t.TempDir()
+	writePipelineProject(t, dir, server)
+	configPath := filepath.Join(dir, "config.yaml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if err := os.WriteFile(configPath, append(data, "generation:\n  system_prompt: \"Be terse.\"\n"...), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--output", "out"}, want: "Be terse."},
+		{args: []string{"--output", "out2", "--system-prompt", "Friendly"}, want: "Friendly"},
+	}
+	for _, tt := range tests {
+		clear(systems)
+		args := append([]string{"generate", "--dir", "src", "--no-cache"}, tt.args...)
+		if _, stderr, err := execute(t, dir, args...); err != nil {
+			t.Fatalf("generate %v failed: %v\nstderr:\n%s", tt.args, err, stderr)
+		}
+		if systems["writing"] != tt.want {
+			t.Errorf("Expected the system prompt %q for chapters, got %q", tt.want, systems["writing"])
+		}
+		if !strings.Contains(systems["extraction"], "expert software engineer") {
+			t.Errorf("Expected the built-in system prompt for extraction, got %q", systems["extraction"])
+		}
+	}
+
+	// A system prompt that cannot fit the context window is rejected up front
+	if err := os.WriteFile(configPath, append(data, "  context_window: 8192\ngeneration:\n  system_prompt: \""+strings.Repeat("Be terse. ", 4000)+"\"\n"...), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	_, stderr, err := execute(t, dir, "generate", "--dir", "src", "--output", "out3", "--no-cache")
+	if err == nil || !strings.Contains(stderr, "leaves no room") {
+		t.Errorf("Expected an oversized system prompt to be rejected, got stderr:\n%s", stderr)
+	}
+}
+
+func TestGenerateChapterLength(t *testing.T) {
+	var mu sync.Mutex
+	var chapter pipelineRequest
+	var analysisTokens []int
+	server := newPipelineServer(t, func(req pipelineRequest) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(req.Prompt, "You are writing a chapter") {
+			chapter = req
+		} else if !strings.Contains(req.Prompt, "glossary") {
+			analysisTokens = append(analysisTokens, req.Options.NumPredict)
+		}
+	})
+	dir := t.TempDir()
+	writePipelineProject(t, dir, server)
+	configPath := filepath.Join(dir, "config.yaml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if err := os.WriteFile(configPath, append(data, "generation:\n  target_words: 800\n"...), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		args       []string
+		wantWords  string
+		wantTokens int
+	}{
+		{args: []string{"--output", "out"}, wantWords: "about 800 words", wantTokens: 2400},
+		{args: []string{"--output", "out2", "--chapter-length", "short"}, wantWords: "about 600 words", wantTokens: 1800},
+		{args: []string{"--output", "out3", "--chapter-length", "long", "--max-tokens", "5000"}, wantWords: "about 3000 words", wantTokens: 5000},
+	}
+	for _, tt := range tests {
+		chapter = pipelineRequest{}
+		args := append([]string{"generate", "--dir", "src", "--no-cache"}, tt.args...)
+		if _, stderr, err := execute(t, dir, args...); err != nil {
+			t.Fatalf("generate %v failed: %v\nstderr:\n%s", tt.args, err, stderr)
+		}
+		if !strings.Contains(chapter.Prompt, tt.wantWords) {
+			t.Errorf("Expected the chapter prompt to ask for %s, got:\n%s", tt.wantWords, chapter.Prompt)
+		}
+		if chapter.Options.NumPredict != tt.wantTokens {
+			t.Errorf("Expected chapters to take at most %d tokens, got %d", tt.wantTokens, chapter.Options.NumPredict)
+		}
+	}
+	// Neither --chapter-length nor --max-tokens caps the analysis requests
+	if len(analysisTokens) == 0 {
+		t.Error("Expected generate to analyze the codebase")
+	}
+	for _, tokens := range analysisTokens {
+		if tokens != 0 {
+			t.Errorf("Expected the analysis to keep llm.max_tokens (unset), got %d", tokens)
+		}
+	}
+
+	_, stderr, err := execute(t, dir, "generate", "--dir", "src", "--output", "out5", "--max-tokens", "0")
+	if err == nil || !strings.Contains(stderr, "--max-tokens must be positive") {
+		t.Errorf("Expected a non-positive --max-tokens to be rejected, got stderr:\n%s", stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "generate", "--dir", "src", "--output", "out4", "--chapter-length", "epic")
+	if err == nil || !strings.Contains(stderr, `invalid chapter length "epic" (use short, medium, long)`) {
+		t.Errorf("Expected an unknown chapter length to be rejected, got stderr:\n%s", stderr)
+	}
+}
+
+func TestGeneratePartialOutput(t *testing.T) {
+	var mu sync.Mutex
+	calls, failOn := 0, 3 // Fail the request of the third chapter
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+			return
+		}
+		mu.Lock()
+		calls++
+		fail := calls == failOn
+		mu.Unlock()
+		if fail {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"response":"# Chapter\n\nExplains it.","done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],"abstractions":[` +
+		`{"name":"Loader","description":"Loads"},{"name":"Parser","description":"Parses"},{"name":"Writer","description":"Writes"},{"name":"Cache","description":"Caches"}],"relationships":[]}`
+	for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	out := filepath.Join(dir, "out")
+	args := []string{"generate", "--load-analysis", "analysis.json", "--output", "out", "--chapter-order", "as-analyzed", "--no-cache"}
+
+	// The chapters done before the failure are written, and the failed one is named
+	_, stderr, err := execute(t, dir, args...)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 0 {
+		t.Fatalf("Expected a nonzero exit status, got %v\nstderr:\n%s", err, stderr)
+	}
+	if !strings.Contains(stderr, "chapter 3 (Writer)") {
+		t.Errorf("Expected the failed chapter in the error, got stderr:\n%s", stderr)
+	}
+	for name, want := range map[string]bool{"index.md": true, "01_loader.md": true, "02_parser.md": true, "03_writer.md": false, "04_cache.md": false, ".progress.json": true} {
+		if _, err := os.Stat(filepath.Join(out, name)); (err == nil) != want {
+			t.Errorf("Expected %s to exist: %v, got %v", name, want, err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(out, "index.md"))
+	if err != nil || !strings.Contains(string(index), "02_parser.md") || strings.Contains(string(index), "03_writer.md") {
+		t.Errorf("Expected the index to list the written chapters, got %v:\n%s", err, index)
+	}
+
+	// --resume generates only the rest
+	mu.Lock()
+	calls, failOn = 0, 0
+	mu.Unlock()
+	if _, stderr, err := execute(t, dir, append(args, "--resume")...); err != nil {
+		t.Fatalf("generate --resume failed: %v\nstderr:\n%s", err, stderr)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 chapters to be generated on resume, got %d", calls)
+	}
+	for name, want := range map[string]bool{"03_writer.md": true, "04_cache.md": true, ".progress.json": false} {
+		if _, err := os.Stat(filepath.Join(out, name)); (err == nil) != want {
+			t.Errorf("Expected %s to exist: %v, got %v", name, want, err)
+		}
+	}
+}
+
+func TestGenerateBudget(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+			return
+		}
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.Write([]byte(`{"response":"# Chapter\n\nExplains it.","done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	analysis := `{"schema_version":1,"project_name":"demo","source":{"type":"dir","location":"."},"files":[],"abstractions":[` +
+		`{"name":"Loader","description":"Loads"},{"name":"Parser","description":"Parses"},{"name":"Writer","description":"Writes"}],"relationships":[]}`
+	for name, content := range map[string]string{"config.yaml": config, "analysis.json": analysis} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	args := []string{"generate", "--load-analysis", "analysis.json", "--output", "out", "--chapter-order", "as-analyzed", "--no-cache"}
+
+	// The first chapter spends the budget, and is written before the run stops
+	_, stderr, err := execute(t, dir, append(args, "--max-tokens-total", "1")...)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitError {
+		t.Fatalf("Expected exit status %d, got %v\nstderr:\n%s", exitError, err, stderr)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 request before the budget was spent, got %d", calls)
+	}
+	if !strings.Contains(stderr, "budget exceeded after 1 request") {
+		t.Errorf("Expected the error to tell how far the run got, got stderr:\n%s", stderr)
+	}
+	for name, want := range map[string]bool{"01_loader.md": true, "02_parser.md": false, ".progress.json": true} {
+		if _, err := os.Stat(filepath.Join(dir, "out", name)); (err == nil) != want {
+			t.Errorf("Expected %s to exist: %v, got %v", name, want, err)
+		}
+	}
+
+	// A local model is free, so a cost ceiling never stops it
+	if _, stderr, err := execute(t, dir, append(args, "--output", "out2", "--max-cost", "0.01")...); err != nil {
+		t.Errorf("Expected a free model to stay within --max-cost, got %v\nstderr:\n%s", err, stderr)
+	}
+
+	_, stderr, err = execute(t, dir, append(args, "--output", "out3", "--max-tokens-total", "-1")...)
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitUsage {
+		t.Errorf("Expected a negative --max-tokens-total to exit with status %d, got %v\nstderr:\n%s", exitUsage, err, stderr)
+	}
+}
+
+func TestGenerateGlossary(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "beginner", args: []string{"--audience", "beginner"}, want: true},
+		{name: "developer", args: nil, want: false},
+		{name: "developer with --glossary", args: []string{"--glossary", "--format", "html"}, want: true},
+		{name: "contributor", args: []string{"--audience", "contributor"}, want: false},
+		{name: "beginner without glossary", args: []string{"--audience", "beginner", "--glossary=false"}, want: false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := fmt.Sprintf("out%d", i)
+			args := append([]string{"generate", "--dir", "src", "--output", out, "--no-cache"}, tt.args...)
+			if _, stderr, err := execute(t, dir, args...); err != nil {
+				t.Fatalf("generate failed: %v\nstderr:\n%s", err, stderr)
+			}
+			glossary, index := "glossary.md", "index.md"
+			if slices.Contains(tt.args, "html") {
+				glossary, index = "glossary.html", "index.html"
+			}
+			content, err := os.ReadFile(filepath.Join(dir, out, glossary))
+			if (err == nil) != tt.want {
+				t.Fatalf("Expected %s to exist: %v, got %v", glossary, tt.want, err)
+			}
+			if !tt.want {
+				return
+			}
+			for _, want := range []string{"configuration", "The settings of a program.", "Loader", "01_loader."} {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Expected the glossary to contain %q, got:\n%s", want, content)
+				}
+			}
+			if data, err := os.ReadFile(filepath.Join(dir, out, index)); err != nil || !strings.Contains(string(data), glossary) {
+				t.Errorf("Expected %s to link to the glossary, got %v:\n%s", index, err, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksylvan/code-decoder/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// defaultNotifyTimeout is the default of --notify-timeout.
+const defaultNotifyTimeout = 10 * time.Second
+
+// maxNotifyError caps the length of the error quoted by a notification, since
+// chat services limit the length of messages (Discord to 2000 characters).
+const maxNotifyError = 500
+
+// Statuses of the runs reported by --notify-url.
+const (
+	notifySuccess     = "success"
+	notifyFailure     = "failure"
+	notifyInterrupted = "interrupted"
+)
+
+// runOutput is where the command being run writes its result (the analysis
+// file or the tutorial's directory), set by analyze and generate once they
+// know it, for the --notify-url notification.
+var runOutput string
+
+// notification is the JSON body posted to --notify-url when a run ends. Text
+// and Content repeat the summary as a sentence, the message shown by Slack
+// and Discord incoming webhooks respectively, which ignore the other fields.
+type notification struct {
+	Text            string   `json:"text"`
+	Content         string   `json:"content"`
+	Command         string   `json:"command"`                  // analyze or generate
+	Status          string   `json:"status"`                   // success, failure or interrupted
+	DurationSeconds float64  `json:"duration_seconds"`         // Wall-clock time of the run
+	Output          string   `json:"output,omitempty"`         // Analysis file or tutorial directory, if known
+	EstimatedCost   *float64 `json:"estimated_cost,omitempty"` // US dollars; omitted without pricing data for a model used
+	Error           string   `json:"error,omitempty"`          // Why the run failed
+}
+
+// addNotifyFlags adds --notify-url and --notify-timeout to c, and makes its
+// RunE check them before the run starts. The notification itself is sent by
+// Execute through notifyRun, so that runs failing before their RunE, such as
+// on a configuration error, are reported too.
+func addNotifyFlags(c *cobra.Command) {
+	c.Flags().String("notify-url", "", "URL to POST a JSON summary of the run to when it ends, successfully or not (e.g., a Slack or Discord incoming webhook)")
+	c.Flags().Duration("notify-timeout", defaultNotifyTimeout, "How long to wait for --notify-url to respond")
+
+	run := c.RunE
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		target, _ := cmd.Flags().GetString("notify-url")
+		if target == "" {
+			return run(cmd, args)
+		}
+		timeout, _ := cmd.Flags().GetDuration("notify-timeout")
+		if err := checkNotifyURL(target, timeout); err != nil {
+			return err
+		}
+		// The URL of a webhook is all it takes to post to it
+		logging.AddSecret(target)
+		return run(cmd, args)
+	}
+}
+
+// notifyRun posts the notification of the run of cmd that took elapsed and
+// ended with err to its --notify-url, if cmd has one. Sending it cannot fail
+// the run: a failure is logged, and the run's own error and exit status are
+// kept. Runs that never started, such as for --help, and command lines whose
+// --notify-url could not be parsed are not reported.
+func notifyRun(cmd *cobra.Command, err error, elapsed time.Duration) {
+	if cmd == nil || cmd.Flags().Lookup("notify-url") == nil || (err == nil && !running) {
+		return
+	}
+	target, _ := cmd.Flags().GetString("notify-url")
+	if target == "" {
+		return
+	}
+	timeout, _ := cmd.Flags().GetDuration("notify-timeout")
+	if checkErr := checkNotifyURL(target, timeout); checkErr != nil {
+		// Reported as the run's error if it got as far as checking it
+		slog.Debug("Not sending the run notification", "error", checkErr)
+		return
+	}
+	logging.AddSecret(target)
+
+	n := newNotification(cmd, err, elapsed)
+	if notifyErr := notify(cmd.Context(), target, timeout, n); notifyErr != nil {
+		slog.Warn("Failed to send the run notification", "error", notifyErr)
+	} else {
+		slog.Debug("Sent the run notification", "status", n.Status)
+	}
+}
+
+// checkNotifyURL checks the values of --notify-url and --notify-timeout
+// before the run, rather than finding them unusable once it is over.
+func checkNotifyURL(target string, timeout time.Duration) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return usageErrorf("invalid --notify-url %q: expected an http or https URL", logging.Redact(target))
+	}
+	if timeout <= 0 {
+		return usageErrorf("--notify-timeout must be positive, got %s", timeout)
+	}
+	return nil
+}
+
+// newNotification reports the run of cmd that took elapsed and ended with
+// err, with the secrets of the error and output path redacted.
+func newNotification(cmd *cobra.Command, err error, elapsed time.Duration) notification {
+	n := notification{
+		Command:         cmd.Name(),
+		Status:          notifySuccess,
+		DurationSeconds: elapsed.Round(time.Millisecond).Seconds(),
+		Output:          logging.Redact(runOutput),
+	}
+	// Without a configuration, the run did not get as far as any request
+	if cfg != nil {
+		if _, cost, unpriced := meteredUsage(); len(unpriced) == 0 {
+			n.EstimatedCost = &cost
+		}
+	}
+	switch {
+	case err == nil:
+	case cmd.Context().Err() != nil && errors.Is(err, context.Canceled):
+		n.Status = notifyInterrupted
+	default:
+		n.Status = notifyFailure
+		n.Error = logging.Redact(err.Error())
+		n.Error = truncate(n.Error, maxNotifyError)
+	}
+
+	elapsedText := elapsed.Round(time.Second).String()
+	switch n.Status {
+	case notifySuccess:
+		n.Text = fmt.Sprintf("code-decoder %s succeeded in %s", n.Command, elapsedText)
+		if n.Output != "" {
+			n.Text += ", writing " + n.Output
+		}
+	case notifyInterrupted:
+		n.Text = fmt.Sprintf("code-decoder %s was interrupted after %s", n.Command, elapsedText)
+	default:
+		n.Text = fmt.Sprintf("code-decoder %s failed after %s: %s", n.Command, elapsedText, n.Error)
+	}
+	if n.EstimatedCost != nil {
+		n.Text += fmt.Sprintf(" (estimated cost $%.4f)", *n.EstimatedCost)
+	}
+	n.Content = n.Text
+	return n
+}
+
+// notify posts n to target, giving up after timeout. The run may have been
+// interrupted, so it is sent even once ctx is canceled.
+func notify(ctx context.Context, target string, timeout time.Duration, n notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification refused with status %s", resp.Status)
+	}
+	return nil
+}
+
+// truncate cuts s to at most limit bytes, followed by "..." if it was cut,
+// without splitting a multi-byte character.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit] + "..."
+}
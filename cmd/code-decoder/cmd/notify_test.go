@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNotifyURL(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	var (
+		mu            sync.Mutex
+		notifications []map[string]any
+		status        = http.StatusOK
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("Expected a JSON notification: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		notifications = append(notifications, n)
+		w.WriteHeader(status)
+	}))
+	defer hook.Close()
+	last := func() map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(notifications) == 0 {
+			return nil
+		}
+		return notifications[len(notifications)-1]
+	}
+
+	// A successful run reports where it wrote its result, with any secret redacted
+	secret := "sk-abcdefghijklmnopqrstuvwxyz"
+	_, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--no-cache", "--save-analysis", secret+".json", "--notify-url", hook.URL+"/hook")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	n := last()
+	if n["status"] != "success" || n["command"] != "analyze" || n["output"] != "[REDACTED].json" {
+		t.Errorf("Expected the successful analysis to be reported, got %v", n)
+	}
+	if text, _ := n["text"].(string); !strings.HasPrefix(text, "code-decoder analyze succeeded in ") || n["content"] != text {
+		t.Errorf("Expected a message for Slack and Discord, got %v", n)
+	}
+	if _, ok := n["estimated_cost"]; !ok {
+		t.Errorf("Expected the cost of the run, got %v", n)
+	}
+
+	// A failed run is reported, and keeps its exit status
+	_, _, err = execute(t, dir, "analyze", "--dir", "src", "--format", "yaml", "--notify-url", hook.URL)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitUsage {
+		t.Errorf("Expected the usage error's exit status, got %v", err)
+	}
+	n = last()
+	if errText, _ := n["error"].(string); n["status"] != "failure" || !strings.Contains(errText, "unsupported analysis format") {
+		t.Errorf("Expected the failure to be reported, got %v", n)
+	}
+
+	// So is a run that fails before it starts, on its configuration
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("llm:\n  provider: ollama\n  model: llama3\n  temperature: 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "out.json", "--config", invalid, "--notify-url", hook.URL)
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitConfig {
+		t.Errorf("Expected the configuration error's exit status, got %v\nstderr:\n%s", err, stderr)
+	}
+	n = last()
+	if errText, _ := n["error"].(string); n["status"] != "failure" || !strings.Contains(errText, "temperature") {
+		t.Errorf("Expected the configuration error to be reported, got %v", n)
+	}
+
+	// A notification that fails does not fail the run
+	mu.Lock()
+	status = http.StatusInternalServerError
+	mu.Unlock()
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "out.json", "--notify-url", hook.URL+"/?key=secret123")
+	if err != nil {
+		t.Errorf("Expected the run to succeed despite the notification, got %v\nstderr:\n%s", err, stderr)
+	}
+	if !strings.Contains(stderr, "Failed to send the run notification") || strings.Contains(stderr, "secret123") {
+		t.Errorf("Expected the failed notification to be logged without the URL's key, got stderr:\n%s", stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--save-analysis", "out.json", "--notify-url", "hooks.example.com")
+	if err == nil || !strings.Contains(stderr, "expected an http or https URL") {
+		t.Errorf("Expected an invalid URL to be refused, got %v, stderr:\n%s", err, stderr)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		want  string
+	}{
+		{name: "short", s: "failed", limit: 10, want: "failed"},
+		{name: "exact", s: "failed", limit: 6, want: "failed"},
+		{name: "ascii", s: "failed badly", limit: 6, want: "failed..."},
+		{name: "rune boundary", s: "héllo", limit: 3, want: "hé..."},
+		{name: "inside a rune", s: "héllo", limit: 2, want: "h..."},
+		{name: "inside the first rune", s: "日本", limit: 2, want: "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.limit)
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Expected valid UTF-8, got %q", got)
+			}
+		})
+	}
+}
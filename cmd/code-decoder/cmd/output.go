@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// silentError is returned by a command that has already reported its error
+// (e.g., as part of its JSON output), so that Execute only sets the exit status.
+type silentError struct{ error }
+
+func (e silentError) Unwrap() error { return e.error }
+
+// humanOut returns where a command writes human-readable output: stdout, or
+// stderr with --json so that stdout carries nothing but JSON.
+func humanOut(cmd *cobra.Command) io.Writer {
+	if jsonOutput {
+		return cmd.ErrOrStderr()
+	}
+	return cmd.OutOrStdout()
+}
+
+// printJSON writes v to stdout as an indented JSON document.
+func printJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
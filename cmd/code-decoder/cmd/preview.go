@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/generation"
+	"github.com/spf13/cobra"
+)
+
+// promptPreview is a prompt rendered by --prompt-preview, and its --json output.
+type promptPreview struct {
+	Name   string `json:"name"`   // What the prompt is for (e.g., "extract main.go")
+	Prompt string `json:"prompt"` // The prompt as it would be sent
+}
+
+// unsafeFileChars are the runs of characters replaced in the names of the
+// files --prompt-preview-dir writes.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// promptPreviewRequested reports whether cmd should preview its prompts:
+// --prompt-preview or --prompt-preview-dir is set.
+func promptPreviewRequested(cmd *cobra.Command) bool {
+	preview, _ := cmd.Flags().GetBool("prompt-preview")
+	dir, _ := cmd.Flags().GetString("prompt-preview-dir")
+	return preview || dir != ""
+}
+
+// previewAnalysis renders the extraction prompts analyze would send for the
+// files it would analyze, after the system instruction sent with each, and
+// prints them without making any LLM request. The prompts identifying the
+// core abstractions and their relationships are built from the responses to
+// these, so they cannot be previewed.
+func previewAnalysis(cmd *cobra.Command) error {
+	a, dir, cleanup, err := scanSource(cmd, true)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	llmCfg, err := llmConfig(cmd, "extraction-model")
+	if err != nil {
+		return err
+	}
+	completion := completionOptions()
+	contextWindow, tokenizer, err := contextGuard(llmCfg, completion)
+	if err != nil {
+		return err
+	}
+	templates, err := loadPrompts(cmd)
+	if err != nil {
+		return err
+	}
+	completion.System = templates.System()
+	extractor := &analysis.Extractor{Options: completion, Root: dir, Prompts: templates, ContextWindow: contextWindow, Tokenizer: tokenizer}
+	files, err := extractor.ExtractionPrompts(a)
+	if err != nil {
+		return err
+	}
+
+	previews := []promptPreview{{Name: "system", Prompt: completion.System}}
+	for i, parts := range files {
+		for j, prompt := range parts {
+			name := "extract " + a.Files[i].Path
+			if len(parts) > 1 {
+				name += fmt.Sprintf(" (part %d of %d)", j+1, len(parts))
+			}
+			previews = append(previews, promptPreview{Name: name, Prompt: prompt})
+		}
+	}
+	return printPreviews(cmd, previews)
+}
+
+// previewChapters renders the chapter prompts generate would send for a, in
+// chapter order, after the system instruction sent with each, and prints
+// them without making any LLM request. The glossary prompt quotes the
+// chapters written, so it cannot be previewed.
+func previewChapters(cmd *cobra.Command, a *analysis.Analysis, generator *generation.Generator) error {
+	chapters, err := generator.ChapterPrompts(a)
+	if err != nil {
+		return err
+	}
+	previews := []promptPreview{{Name: "system", Prompt: generator.Options.System}}
+	for i, abs := range a.Abstractions {
+		if len(generator.Only) > 0 && !slices.Contains(generator.Only, abs.Name) {
+			continue
+		}
+		previews = append(previews, promptPreview{Name: fmt.Sprintf("chapter %d %s", i+1, abs.Name), Prompt: chapters[0]})
+		chapters = chapters[1:]
+	}
+	return printPreviews(cmd, previews)
+}
+
+// printPreviews prints previews to stdout, as a JSON array with --json, or
+// writes each to a numbered file of --prompt-preview-dir.
+func printPreviews(cmd *cobra.Command, previews []promptPreview) error {
+	dir, _ := cmd.Flags().GetString("prompt-preview-dir")
+	if dir == "" {
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), previews)
+		}
+		out := cmd.OutOrStdout()
+		for i, preview := range previews {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "===== %s =====\n%s\n", preview.Name, strings.TrimRight(preview.Prompt, "\n"))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompt preview directory: %w", err)
+	}
+	paths := make([]string, 0, len(previews))
+	for i, preview := range previews {
+		name := strings.Trim(unsafeFileChars.ReplaceAllString(preview.Name, "-"), "-")
+		path := filepath.Join(dir, fmt.Sprintf("%03d-%s.txt", i+1, name))
+		if err := os.WriteFile(path, []byte(preview.Prompt), 0644); err != nil {
+			return fmt.Errorf("failed to write prompt preview: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	slog.Info("Wrote prompt previews", "dir", dir, "prompts", len(previews))
+	if jsonOutput {
+		return printJSON(cmd.OutOrStdout(), map[string]any{"dir": dir, "files": paths})
+	}
+	return nil
+}
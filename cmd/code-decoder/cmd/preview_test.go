@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+func TestPromptPreview(t *testing.T) {
+	dir := t.TempDir()
+	var requests atomic.Int32
+	writePipelineProject(t, dir, newPipelineServer(t, func(pipelineRequest) { requests.Add(1) }))
+	a := &analysis.Analysis{
+		ProjectName: "demo",
+		Files:       []analysis.File{{Path: "a.go", Summary: "Loads the configuration"}},
+		Abstractions: []analysis.Abstraction{
+			{Name: "Loader", Description: "Loads the configuration", Files: []string{"a.go"}},
+			{Name: "Validator", Description: "Checks the configuration", Files: []string{"a.go"}},
+		},
+	}
+	if err := analysis.Save(filepath.Join(dir, "analysis.json"), a); err != nil {
+		t.Fatalf("Failed to save the analysis: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "extraction",
+			args: []string{"analyze", "--dir", "src", "--prompt-preview"},
+			want: []string{"===== system =====", "===== extract a.go =====", "package src"},
+		},
+		{
+			name: "chapters",
+			args: []string{"generate", "--load-analysis", "analysis.json", "--prompt-preview"},
+			want: []string{"===== system =====", "===== chapter 1 ", "===== chapter 2 ", "Checks the configuration"},
+		},
+		{
+			name:     "selected chapter",
+			args:     []string{"generate", "--load-analysis", "analysis.json", "--prompt-preview", "--chapters", "Validator"},
+			want:     []string{"===== chapter 2 Validator ====="},
+			unwanted: []string{"Loader ====="},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := execute(t, dir, tt.args...)
+			if err != nil {
+				t.Fatalf("%v failed: %v\nstderr:\n%s", tt.args, err, stderr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected %q in the preview, got:\n%s", want, stdout)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(stdout, unwanted) {
+					t.Errorf("Expected no %q in the preview, got:\n%s", unwanted, stdout)
+				}
+			}
+		})
+	}
+
+	// The prompts can be written to files instead
+	if _, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--prompt-preview-dir", "preview"); err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "preview", "002-extract-a.go.txt"))
+	if err != nil || !strings.Contains(string(content), "package src") {
+		t.Errorf("Expected the extraction prompt of a.go in its file, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "preview", "001-system.txt")); err != nil {
+		t.Errorf("Expected the system prompt in its file: %v", err)
+	}
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no LLM request, got %d", n)
+	}
+	_, stderr, err := execute(t, dir, "generate", "--dir", "src", "--prompt-preview")
+	if err == nil || !strings.Contains(stderr, "--prompt-preview requires --load-analysis") {
+		t.Errorf("Expected the preview to require a saved analysis, got %v, stderr:\n%s", err, stderr)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+// completionOptions returns the completion options from the llm section of
+// the config, where a command's --temperature flag, when it has one, is
+// already applied (see bindConfigFlag).
+func completionOptions() llm.CompletionOptions {
+	return llm.CompletionOptions{
+		Temperature: cfg.LLM.Temperature,
+		MaxTokens:   cfg.LLM.MaxTokens,
+		TopP:        cfg.LLM.TopP,
+	}
+}
+
+// llmConfig returns the llm section of the config for a phase of cmd, with
+// the model replaced by the phase's model flag (e.g., --writing-model) when
+// cmd has that flag and it is set. The --model flag, which applies to every
+// phase, is already applied (see bindConfigFlag), but neither may be empty.
+func llmConfig(cmd *cobra.Command, modelFlag string) (config.LLMConfig, error) {
+	llmCfg := cfg.LLM
+	for _, name := range []string{"model", modelFlag} {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if strings.TrimSpace(flag.Value.String()) == "" {
+			return llmCfg, usageErrorf("--%s must not be empty", name)
+		}
+		llmCfg.Model = flag.Value.String()
+	}
+	return llmCfg, nil
+}
+
+// contextGuard returns the context window of the model of llmCfg, in
+// tokens, and the tokenizer that prompts are checked against it with. The
+// window is llm.context_window when set, and looked up by model name
+// otherwise; it is 0, which disables the guard, for models that are not known.
+func contextGuard(llmCfg config.LLMConfig, opts llm.CompletionOptions) (int, llm.Tokenizer, error) {
+	tokenizer := llm.TokenizerFor(llmCfg.Model)
+	window := llmCfg.ContextWindow
+	if window == 0 {
+		var ok bool
+		if window, ok = llm.LookupContextWindow(llmCfg.Model); !ok {
+			slog.Debug("Unknown context window; prompts are sent whole (set llm.context_window to check them)", "model", llmCfg.Model)
+			return 0, tokenizer, nil
+		}
+	}
+	if llm.PromptBudget(window, opts.MaxTokens) <= 0 {
+		return 0, nil, fmt.Errorf("max tokens (%d) leave no room for the prompt in the %d-token context window of %s", opts.MaxTokens, window, llmCfg.Model)
+	}
+	slog.Debug("Guarding the context window", "model", llmCfg.Model, "tokens", window)
+	return window, tokenizer, nil
+}
+
+// newProvider creates the LLM provider of llmCfg for a command. Responses are
+// cached on disk unless --no-cache is set (and replaced with --refresh), and
+// the requests sent to the provider are counted for the run summary, charged
+// to the run's budget (see withBudget) and traced to --debug-dir when it is
+// set. Local providers are checked for the configured model first, which is
+// pulled when --pull-model is set.
+func newProvider(cmd *cobra.Command, llmCfg config.LLMConfig) (llm.Provider, error) {
+	provider, err := llm.NewProvider(llmCfg)
+	if err != nil {
+		return nil, err
+	}
+	pull, _ := cmd.Flags().GetBool("pull-model")
+	if err := ensureModel(cmd.Context(), provider, llmCfg.Model, pull); err != nil {
+		return nil, err
+	}
+	provider = llm.NewMeteringProvider(provider, meterFor(llmCfg.Model), llm.TokenizerFor(llmCfg.Model))
+	if provider, err = withBudget(cmd, provider, llmCfg); err != nil {
+		return nil, err
+	}
+	if debugDir != "" {
+		// Beneath the cache, so that only the requests actually sent are traced
+		if provider, err = llm.NewTracingProvider(provider, llmCfg.Model, debugDir, llm.APIKey(llmCfg), cfg.GitHub.Token); err != nil {
+			return nil, err
+		}
+		slog.Warn("Tracing LLM requests; the files may contain sensitive source code", "dir", debugDir)
+	}
+
+	if noCache {
+		return provider, nil
+	}
+	if cacheTTL < 0 {
+		return nil, usageErrorf("--cache-ttl must not be negative")
+	}
+	dir, err := llm.DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Caching LLM responses", "dir", dir, "ttl", cacheTTL)
+	caching := llm.NewCachingProvider(provider, llmCfg.Model, dir, cacheTTL)
+	caching.Refresh = refresh
+	return caching, nil
+}
+
+// budget caps the estimated cost and tokens of the run, across the
+// providers that newProvider creates; nil until withBudget sets one.
+var budget *llm.Budget
+
+// withBudget wraps provider to charge its requests to the run's budget,
+// which the first call creates from llm.max_cost (or --max-cost) and the
+// command's --max-tokens-total. The provider is returned as is without
+// either ceiling. A cost ceiling needs pricing data for the model of llmCfg.
+func withBudget(cmd *cobra.Command, provider llm.Provider, llmCfg config.LLMConfig) (llm.Provider, error) {
+	maxTokens, _ := cmd.Flags().GetInt("max-tokens-total")
+	if maxTokens < 0 {
+		return nil, usageErrorf("--max-tokens-total must not be negative, got %d", maxTokens)
+	}
+	if llmCfg.MaxCost == 0 && maxTokens == 0 {
+		return provider, nil
+	}
+	pricing, ok := llm.LookupPricing(llmCfg.Provider, llmCfg.Model)
+	if !ok && llmCfg.MaxCost > 0 {
+		return nil, usageErrorf("cannot enforce a maximum cost: no pricing data for %s model %s (cap the tokens with --max-tokens-total instead)", llmCfg.Provider, llmCfg.Model)
+	}
+	if budget == nil {
+		budget = &llm.Budget{MaxCost: llmCfg.MaxCost, MaxTokens: maxTokens}
+		slog.Debug("Capping the run", "max_cost", llmCfg.MaxCost, "max_tokens_total", maxTokens)
+	}
+	return llm.NewBudgetProvider(provider, budget, pricing, llm.TokenizerFor(llmCfg.Model)), nil
+}
+
+// ensureModel checks that a local provider has model, pulling it if pull is
+// set. Cloud providers are not checked.
+func ensureModel(ctx context.Context, provider llm.Provider, model string, pull bool) error {
+	manager, ok := llm.AsModelManager(provider)
+	if !ok {
+		return nil
+	}
+	available, err := manager.HasModel(ctx, model)
+	if err != nil {
+		return err
+	}
+	if available {
+		return nil
+	}
+	if !pull {
+		return modelNotFound(provider, model)
+	}
+
+	slog.Info("Pulling model", "provider", provider.Name(), "model", model)
+	reporter := newProgress()
+	err = manager.PullModel(ctx, model, func(p llm.PullProgress) {
+		// Reported in megabytes, since model layers are often gigabytes in size
+		reporter.Update("pulling", int(p.Completed>>20), int(p.Total>>20), model+": "+p.Status)
+	})
+	reporter.Finish()
+	if err != nil {
+		return err
+	}
+	slog.Info("Model pulled", "provider", provider.Name(), "model", model)
+	return nil
+}
+
+// modelNotFound returns the error for a model missing from a local
+// provider, explaining how to download it.
+func modelNotFound(provider llm.Provider, model string) error {
+	return fmt.Errorf("%w: run `%s pull %s` or pass --pull-model", &llm.ModelNotFoundError{Provider: provider.Name(), Model: model}, provider.Name(), model)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+// providersCmd represents the providers command group
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Show the supported LLM providers",
+	Long: `Commands for inspecting the LLM providers this build supports and the
+llm configuration fields each of them requires.`,
+	// Listing providers does not need a valid LLM configuration.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+// providersListCmd represents the providers list command
+var providersListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the supported LLM providers and their required config fields",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var summaries []providerSummary
+		for _, info := range llm.Providers() {
+			kind := "cloud"
+			if info.Local {
+				kind = "local"
+			}
+			summaries = append(summaries, providerSummary{Name: info.Name, Type: kind, Description: info.Description, Fields: info.Fields})
+		}
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), summaries)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tTYPE\tREQUIRED FIELDS\tDESCRIPTION")
+		for _, s := range summaries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Type, strings.Join(s.Fields, ", "), s.Description)
+		}
+		return w.Flush()
+	},
+}
+
+// providerSummary is the description of a provider printed by providers list.
+type providerSummary struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // "cloud" or "local"
+	Description string   `json:"description"`
+	Fields      []string `json:"required_fields"`
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+	providersCmd.AddCommand(providersListCmd)
+}
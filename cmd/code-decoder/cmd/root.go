@@ -3,19 +3,42 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ksylvan/code-decoder/internal/config"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/logging"
+	"github.com/ksylvan/code-decoder/internal/progress"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var (
 	cfgFile string
+	profile string
 	cfg     *config.Config
+	// Error from loading/validating the configuration, reported by commands that need it
+	cfgErr error
 	// Root command flags
 	versionFlag bool
+	logLevel    string
+	quiet       bool
+	noCache     bool
+	refresh     bool
+	debugDir    string
+	cacheTTL    time.Duration
+	jsonOutput  bool
 	// App version set by main
 	appVersion string
 )
@@ -28,6 +51,12 @@ var rootCmd = &cobra.Command{
 It analyzes GitHub repositories or local directories, identifies core abstractions,
 and generates comprehensive, visualized documentation.`,
 	// Run: func(cmd *cobra.Command, args []string) { }, // Keep commented out unless root command needs direct action
+	SilenceErrors: true, // Reported by Execute
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		applyVerbose(cmd)
+		// Commands need a valid configuration; report why it could not be loaded
+		return cfgErr
+	},
 }
 
 // SetVersion allows main to set the version string
@@ -37,10 +66,50 @@ func SetVersion(version string) {
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+//
+// Commands run with a context that is canceled on SIGINT (Ctrl-C) or
+// SIGTERM, which aborts in-flight LLM requests and git clones. Work already
+// done is kept, since responses are cached and completed chapters recorded
+// as they arrive, and the process exits with status 130. A second signal
+// kills the process right away. Other failures exit with the status for
+// their kind of error (see exitCode). Either way, a run given --notify-url
+// is reported to it first (see notifyRun).
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop() // Restore the default behavior, so that a second Ctrl-C exits at once
+	}()
+
+	trackRun(rootCmd)
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if err != nil && !running && exitCode(err, false) == exitError {
+		err = usageError{err}
+	}
+	notifyRun(cmd, err, time.Since(start))
+	code := exitCode(err, ctx.Err() != nil)
+	if code == exitInterrupted {
+		slog.Debug("Command canceled", "error", err)
+		fmt.Fprintln(rootCmd.ErrOrStderr(), "Interrupted")
+		if jsonOutput {
+			printJSON(rootCmd.OutOrStdout(), map[string]string{"error": "interrupted"})
+		}
+		os.Exit(code)
+	}
 	if err != nil {
-		os.Exit(1)
+		// Errors are reported here rather than by cobra, so that commands can
+		// fail without a message once they have reported the problem themselves
+		var silent silentError
+		if !errors.As(err, &silent) {
+			message := logging.Redact(err.Error())
+			fmt.Fprintln(rootCmd.ErrOrStderr(), "Error:", message)
+			if jsonOutput {
+				printJSON(rootCmd.OutOrStdout(), map[string]string{"error": message})
+			}
+		}
+		os.Exit(code)
 	}
 }
 
@@ -48,48 +117,97 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Persistent flags (global for application)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/code-decoder/config.yaml or ./config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file in YAML, JSON or TOML, by its extension (default is ./config.yaml or $HOME/.config/code-decoder/config.yaml; config.json and config.toml are found too)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "LLM profile from the profiles section of the config to use instead of the llm section")
 	rootCmd.PersistentFlags().BoolVarP(&versionFlag, "version", "V", false, "Print version information and exit")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level ("+strings.Join(logging.Levels, ", ")+")")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print errors and the requested output: no logs or progress (overrides --log-level)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print machine-readable JSON to stdout; human-readable output goes to stderr")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the LLM response cache")
+	rootCmd.PersistentFlags().BoolVar(&refresh, "refresh", false, "Extract every file and ask the LLM again instead of reusing cached results, which the new ones replace")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 0, "Ignore cached LLM responses older than this (e.g., 72h; 0 means never expire)")
+	rootCmd.PersistentFlags().StringVar(&debugDir, "debug-dir", "", "Write each LLM prompt and raw response to a file in this directory (may contain sensitive source code)")
+
+	err := rootCmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return logging.Levels, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error registering completion function for --log-level: %v\n", err)
+		os.Exit(exitError)
+	}
 
 	// Add the completion command
 	rootCmd.AddCommand(completionCmd)
 }
 
+// applyVerbose raises the log level to debug for commands run with
+// -v/--verbose, unless --quiet was given too.
+func applyVerbose(cmd *cobra.Command) {
+	if quiet {
+		return
+	}
+	if flag := cmd.Flags().Lookup("verbose"); flag != nil && flag.Changed && flag.Value.String() == "true" {
+		logging.SetLevel(slog.LevelDebug)
+	}
+}
+
+// configNotFound explains how to provide the configuration file, when none
+// was found in the default locations.
+const configNotFound = `Error: Configuration file not found.
+Please create a config.yaml in the current directory (./config.yaml)
+or in your home config directory (~/.config/code-decoder/config.yaml).
+An example configuration can be found at 'example/config.yaml'; the same
+settings may be written as config.json or config.toml instead.
+Alternatively, specify a config file using the --config flag, or run
+code-decoder in a terminal to be guided through creating one.
+`
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	// Set up logging first so the rest of initialization can use it
+	if err := logging.Setup(os.Stderr, logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if quiet {
+		// Quiet wins over --log-level: only errors are logged
+		logging.SetLevel(slog.LevelError)
+	}
+	if versionCmd.CalledAs() != "" {
+		return // Printing the version needs no configuration
+	}
+
 	configLoaded := false // Flag to track if any config file was loaded
 
 	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-		if err := viper.ReadInConfig(); err == nil {
-			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+		// Use config file from the flag, in the format its extension names
+		err := config.SetConfigFile(viper.GetViper(), cfgFile)
+		if err == nil {
+			err = viper.ReadInConfig()
+		}
+		if err == nil {
+			slog.Info("Using config file", "path", viper.ConfigFileUsed())
 			configLoaded = true
 		} else {
 			// If the specified config file has an error (e.g., not found, permission denied)
-			fmt.Fprintf(os.Stderr, "Error reading specified config file (%s): %s\n", cfgFile, err)
-			os.Exit(1) // Exit if the explicitly provided config file fails
+			slog.Error("Error reading specified config file", "path", cfgFile, "error", err)
+			os.Exit(exitConfig) // Exit if the explicitly provided config file fails
 		}
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
+		// Search config in the home directory, then the current directory,
+		// for config.yaml, config.json or config.toml
+		path, err := config.FindConfigFile()
 		cobra.CheckErr(err) // Should not happen normally
 
-		// Search config in home directory and current directory
-		viper.AddConfigPath(home + "/.config/code-decoder")
-		viper.AddConfigPath(".") // Also look in the current directory
-		viper.SetConfigName("config")
-		viper.SetConfigType("yaml") // Explicitly set config type
-
-		// Attempt to read the config file from default locations
-		if err := viper.ReadInConfig(); err == nil {
-			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
-			configLoaded = true
-		} else {
-			// Only treat ConfigFileNotFoundError as non-fatal for default locations
-			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+		// Attempt to read the config file found
+		if path != "" {
+			cobra.CheckErr(config.SetConfigFile(viper.GetViper(), path)) // Found files always have a supported format
+			if err := viper.ReadInConfig(); err == nil {
+				slog.Info("Using config file", "path", viper.ConfigFileUsed())
+				configLoaded = true
+			} else {
 				// Config file was found but another error was produced
-				fmt.Fprintf(os.Stderr, "Error reading config file (%s): %s\n", viper.ConfigFileUsed(), err)
+				slog.Error("Error reading config file", "path", viper.ConfigFileUsed(), "error", err)
 			}
 		}
 	}
@@ -98,15 +216,94 @@ func initConfig() {
 	// Environment variables can override config file settings or provide defaults
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// Check if a config file was loaded. If not, print message and exit.
+	// Check if a config file was loaded. If not, offer to create one when
+	// run interactively, and print message and exit otherwise.
 	if !configLoaded && cfgFile == "" { // Only exit if no default config found AND no --config flag used
-		fmt.Fprintln(os.Stderr, "Error: Configuration file not found.")
-		fmt.Fprintln(os.Stderr, "Please create a config.yaml in the current directory (./config.yaml)")
-		fmt.Fprintln(os.Stderr, "or in your home config directory (~/.config/code-decoder/config.yaml).")
-		fmt.Fprintln(os.Stderr, "An example configuration can be found at 'example/config.yaml'.")
-		fmt.Fprintln(os.Stderr, "Alternatively, specify a config file using the --config flag.")
-		os.Exit(1)
+		if !progress.IsTerminal(os.Stdin) || !progress.IsTerminal(os.Stderr) {
+			// This is an error, so it is shown even with --quiet
+			fmt.Fprint(rootCmd.ErrOrStderr(), configNotFound)
+			os.Exit(exitConfig)
+		}
+		path, err := defaultConfigPath()
+		if err == nil {
+			err = runSetup(os.Stdin, rootCmd.ErrOrStderr(), path)
+		}
+		if err != nil {
+			fmt.Fprintln(rootCmd.ErrOrStderr(), "Error:", err)
+			os.Exit(exitConfig)
+		}
+		cfgFile = path
+	}
+
+	// Load and validate the resolved configuration. Errors are returned by
+	// rootCmd.PersistentPreRunE so commands like "config validate" can report them.
+	cfg, cfgErr = config.LoadConfig(cfgFile, profile, configFlags(runningCommand())...)
+	if cfgErr == nil {
+		registerSecrets(cfg)
+	}
+	if cfgErr == nil && cfg.Profile != "" {
+		slog.Info("Using LLM profile", "profile", cfg.Profile, "provider", cfg.LLM.Provider, "model", cfg.LLM.Model)
+	}
+}
+
+// registerSecrets makes the logger, and the errors Execute prints, redact
+// the API keys and GitHub token of cfg, and the values of the extra headers
+// sent to the provider, which often hold keys too, including those of its
+// profiles.
+func registerSecrets(cfg *config.Config) {
+	logging.AddSecret(llm.APIKey(cfg.LLM), cfg.GitHub.Token)
+	logging.AddSecret(slices.Collect(maps.Values(cfg.LLM.ExtraHeaders))...)
+	for _, profile := range cfg.Profiles {
+		logging.AddSecret(profile.APIKey)
+		logging.AddSecret(slices.Collect(maps.Values(profile.ExtraHeaders))...)
+	}
+}
+
+// configKeyAnnotation is the flag annotation naming the config key a flag
+// overrides; see bindConfigFlag.
+const configKeyAnnotation = "code-decoder_config_key"
+
+// bindConfigFlag binds the flag name of cmd to the config key it overrides,
+// so that setting the flag takes precedence over the environment and the
+// config file when the configuration is loaded (and validated).
+func bindConfigFlag(cmd *cobra.Command, name, key string) {
+	if err := cmd.Flags().SetAnnotation(name, configKeyAnnotation, []string{key}); err != nil {
+		panic(err) // A programming error: the flag is not defined
+	}
+}
+
+// configFlags returns the flags of cmd bound to config keys with
+// bindConfigFlag.
+func configFlags(cmd *cobra.Command) []config.FlagBinding {
+	if cmd == nil {
+		return nil
+	}
+	var bindings []config.FlagBinding
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if keys := flag.Annotations[configKeyAnnotation]; len(keys) > 0 {
+			bindings = append(bindings, config.FlagBinding{Key: keys[0], Flag: flag})
+		}
+	})
+	return bindings
+}
+
+// runningCommand returns the command being executed, or nil before the
+// command line is parsed. Cobra runs initConfig once it has found the
+// command, but does not pass it along.
+func runningCommand() *cobra.Command {
+	var find func(cmd *cobra.Command) *cobra.Command
+	find = func(cmd *cobra.Command) *cobra.Command {
+		if cmd.CalledAs() != "" {
+			return cmd
+		}
+		for _, sub := range cmd.Commands() {
+			if found := find(sub); found != nil {
+				return found
+			}
+		}
+		return nil
 	}
+	return find(rootCmd)
 }
 
 // completionCmd represents the completion command
@@ -166,7 +363,7 @@ PowerShell:
 			gen(cmd.Root(), nil)
 		} else {
 			fmt.Fprintf(os.Stderr, "Unknown shell: %s\n", args[0])
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 	},
 }
@@ -0,0 +1,255 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExecuteHelper runs the command line in CODEDECODER_TEST_ARGS when
+// started by execute, and does nothing otherwise.
+func TestExecuteHelper(t *testing.T) {
+	if os.Getenv("CODEDECODER_TEST_EXECUTE") != "1" {
+		return
+	}
+	rootCmd.SetArgs(strings.Fields(os.Getenv("CODEDECODER_TEST_ARGS")))
+	Execute()
+	os.Exit(0)
+}
+
+// execute runs code-decoder with args in a subprocess, since Execute exits
+// on errors, and returns what it wrote to stdout and stderr.
+func execute(t *testing.T, dir string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	return executeWithStdin(t, dir, "", args...)
+}
+
+// executeWithStdin is execute with stdin piped into code-decoder.
+func executeWithStdin(t *testing.T, dir, stdin string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExecuteHelper$")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HOME="+dir, "CODEDECODER_TEST_EXECUTE=1", "CODEDECODER_TEST_ARGS="+strings.Join(args, " "))
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err = cmd.Run()
+	return out.String(), errOut.String(), err
+}
+
+func TestQuiet(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := "llm:\n  provider: ollama\n  endpoint: http://localhost:11434\n  model: llama3\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+	}{
+		{name: "quiet", args: []string{"config", "validate", "--quiet"}, wantStdout: "configuration valid\n"},
+		{name: "quiet wins over the log level", args: []string{"config", "validate", "-q", "--log-level", "debug"}, wantStdout: "configuration valid\n"},
+		{name: "quiet with json", args: []string{"config", "validate", "-q", "--json"}, wantStdout: "{\n  \"valid\": true\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := execute(t, dir, append(tt.args, "--config", configPath)...)
+			if err != nil {
+				t.Fatalf("Expected success, got %v (stderr: %s)", err, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("Expected stdout %q, got %q", tt.wantStdout, stdout)
+			}
+			if stderr != "" {
+				t.Errorf("Expected nothing on stderr, got %q", stderr)
+			}
+		})
+	}
+
+	// Errors are still reported, including the missing configuration
+	_, stderr, err := execute(t, t.TempDir(), "config", "validate", "--quiet")
+	if err == nil || !strings.Contains(stderr, "Configuration file not found") {
+		t.Errorf("Expected the missing configuration to be reported, got %v (stderr: %q)", err, stderr)
+	}
+}
+
+func TestJSONKeepsStdoutClean(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := "llm:\n  provider: ollama\n  endpoint: http://localhost:11434\n  model: llama3\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	stdout, stderr, err := execute(t, dir, "config", "validate", "--json", "--config", configPath)
+	if err != nil {
+		t.Fatalf("Expected success, got %v (stderr: %s)", err, stderr)
+	}
+	if want := "{\n  \"valid\": true\n}\n"; stdout != want {
+		t.Errorf("Expected stdout %q, got %q", want, stdout)
+	}
+	if !strings.Contains(stderr, "Using config file") {
+		t.Errorf("Expected the config file to be logged on stderr, got %q", stderr)
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupting a process with a signal is not supported on Windows")
+	}
+
+	// The fake Ollama server never answers, so the command waits until it is interrupted
+	received := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: ollama\n  endpoint: " + server.URL + "\n  model: llama3\n  max_retries: 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExecuteHelper$")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HOME="+dir, "CODEDECODER_TEST_EXECUTE=1", "CODEDECODER_TEST_ARGS=test-llm")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start the command: %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		t.Fatalf("The command never called the LLM; stderr:\n%s", stderr.String())
+	}
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatalf("Failed to interrupt the command: %v", err)
+	}
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitInterrupted {
+		t.Fatalf("Expected exit status %d, got %v; stderr:\n%s", exitInterrupted, err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Interrupted") || strings.Contains(stderr.String(), "Error:") {
+		t.Errorf("Expected only an interruption notice, got stderr:\n%s", stderr.String())
+	}
+}
+
+func TestVersion(t *testing.T) {
+	// No configuration is needed to print the version
+	dir := t.TempDir()
+	stdout, stderr, err := execute(t, dir, "version")
+	if err != nil {
+		t.Fatalf("Expected success, got %v (stderr: %s)", err, stderr)
+	}
+	for _, want := range []string{"code-decoder version", "commit:   unknown", "built:    unknown", "go:       " + runtime.Version()} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	stdout, stderr, err = execute(t, dir, "version", "--json")
+	if err != nil {
+		t.Fatalf("Expected success, got %v (stderr: %s)", err, stderr)
+	}
+	var info map[string]string
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", stdout, err)
+	}
+	for _, key := range []string{"version", "commit", "date", "go_version", "platform"} {
+		if _, ok := info[key]; !ok {
+			t.Errorf("Expected %q in the JSON output, got %v", key, info)
+		}
+	}
+	if info["go_version"] != runtime.Version() || info["platform"] != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Unexpected build information: %v", info)
+	}
+}
+
+func TestFlagOverridesConfig(t *testing.T) {
+	dir := t.TempDir()
+	// Invalid as configured: openai needs an API key
+	config := "llm:\n  provider: openai\n  endpoint: http://127.0.0.1:1\n  model: llama3\n  max_retries: 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, stderr, err := execute(t, dir, "test-llm")
+	if err == nil || !strings.Contains(stderr, "llm.api_key is required") {
+		t.Errorf("Expected the configured provider to be invalid, got %v (stderr: %s)", err, stderr)
+	}
+
+	// The flag is applied before the configuration is validated; nothing
+	// listens on the endpoint, so only the connection fails
+	_, stderr, err = execute(t, dir, "test-llm", "--provider", "ollama")
+	if err == nil || !strings.Contains(stderr, "connection to ollama failed") {
+		t.Errorf("Expected --provider to override the config, got %v (stderr: %s)", err, stderr)
+	}
+
+	t.Setenv("CODEDECODER_LLM_PROVIDER", "ollama")
+	_, stderr, err = execute(t, dir, "test-llm", "--provider", "openai")
+	if err == nil || !strings.Contains(stderr, "llm.api_key is required") || !strings.Contains(stderr, "with flags --provider") {
+		t.Errorf("Expected --provider to override the environment, got %v (stderr: %s)", err, stderr)
+	}
+}
+
+func TestConfigShow(t *testing.T) {
+	dir := t.TempDir()
+	config := "llm:\n  provider: openai\n  api_key: sk-proj-abcdefgh1234\n  model: gpt-4o\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CODEDECODER_DEFAULTS_CONCURRENCY", "8")
+
+	stdout, stderr, err := execute(t, dir, "config", "show", "-q")
+	if err != nil {
+		t.Fatalf("config show failed: %v (stderr: %s)", err, stderr)
+	}
+	for _, want := range []string{"Config file:", "config.yaml", "KEY", "sk-...1234", "gpt-4o", "defaults.concurrency", "env", "defaults.max_files"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "abcdefgh") {
+		t.Errorf("Expected the API key to be redacted, got:\n%s", stdout)
+	}
+
+	stdout, stderr, err = execute(t, dir, "--json", "config", "show")
+	if err != nil {
+		t.Fatalf("config show --json failed: %v (stderr: %s)", err, stderr)
+	}
+	var shown configShow
+	if err := json.Unmarshal([]byte(stdout), &shown); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	for _, setting := range shown.Settings {
+		if setting.Key == "llm.model" && (setting.Value != "gpt-4o" || setting.Source != "file") {
+			t.Errorf("Expected llm.model from the file, got %+v", setting)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+)
+
+// setupDefaults are the values suggested by the setup wizard for each
+// provider, accepted by pressing Enter.
+var setupDefaults = map[string]map[string]string{
+	"openai":    {"model": "gpt-4o"},
+	"anthropic": {"model": "claude-3-5-sonnet-latest"},
+	"gemini":    {"model": "gemini-1.5-pro"},
+	"ollama":    {"endpoint": "http://localhost:11434", "model": "llama3"},
+	"lmstudio":  {"endpoint": "http://localhost:1234/v1"},
+}
+
+// setupPrompts are the questions the setup wizard asks for the llm fields.
+var setupPrompts = map[string]string{
+	"endpoint":   "Endpoint (base URL of the API)",
+	"deployment": "Deployment name",
+	"model":      "Model",
+}
+
+// errSetupAborted is returned by runSetup when the input ends before the
+// configuration is complete.
+var errSetupAborted = errors.New("setup aborted before the configuration was complete")
+
+// defaultConfigPath returns the path of the configuration file in the home
+// config directory, which the setup wizard writes.
+func defaultConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "code-decoder", "config.yaml"), nil
+}
+
+// runSetup walks the user through choosing an LLM provider and entering the
+// llm fields it requires, reading the answers from in and asking on out,
+// and writes the resulting configuration to path. The API key may be left
+// empty to use the CODEDECODER_LLM_APIKEY environment variable instead.
+func runSetup(in io.Reader, out io.Writer, path string) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(question, fallback string) (string, error) {
+		if fallback != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, fallback)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", errSetupAborted
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, nil
+		}
+		return fallback, nil
+	}
+
+	fmt.Fprintln(out, "No configuration file found; answer a few questions to create one.")
+	fmt.Fprintln(out)
+	providers := llm.Providers()
+	for i, info := range providers {
+		fmt.Fprintf(out, "  %d. %-10s %s\n", i+1, info.Name, info.Description)
+	}
+	var info llm.ProviderInfo
+	for info.Name == "" {
+		answer, err := ask(fmt.Sprintf("LLM provider (1-%d or name)", len(providers)), "")
+		if err != nil {
+			return err
+		}
+		for i, p := range providers {
+			if answer == p.Name || answer == strconv.Itoa(i+1) {
+				info = p
+			}
+		}
+		if info.Name == "" {
+			fmt.Fprintf(out, "Unknown provider %q\n", answer)
+		}
+	}
+
+	values := map[string]string{"provider": info.Name}
+	for _, field := range []string{"endpoint", "deployment", "model"} {
+		required := slices.Contains(info.Fields, field)
+		question := setupPrompts[field]
+		if field == "endpoint" && info.Name == "openai" {
+			question = "Endpoint (leave empty for the OpenAI API, or the base URL of a compatible gateway)"
+		} else if !required {
+			continue
+		}
+		for {
+			answer, err := ask(question, setupDefaults[info.Name][field])
+			if err != nil {
+				return err
+			}
+			values[field] = answer
+			if answer != "" || !required {
+				break
+			}
+		}
+	}
+	if slices.Contains(info.Fields, "api_key") {
+		answer, err := ask("API key (shown as typed; leave empty to use $CODEDECODER_LLM_APIKEY)", "")
+		if err != nil {
+			return err
+		}
+		values["api_key"] = answer
+	}
+
+	if err := writeSetupConfig(path, values); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Configuration written to %s\n\n", path)
+	return nil
+}
+
+// writeSetupConfig writes the llm section made of values to path. The file
+// is only readable by the user, since it may hold an API key.
+func writeSetupConfig(path string, values map[string]string) error {
+	var sb strings.Builder
+	sb.WriteString("# Written by code-decoder's setup; see example/config.yaml for every setting\n")
+	sb.WriteString("llm:\n")
+	for _, key := range []string{"provider", "endpoint", "deployment", "model", "api_key"} {
+		if values[key] != "" {
+			fmt.Fprintf(&sb, "  %s: %s\n", key, strconv.Quote(values[key]))
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+func TestRunSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     config.LLMConfig
+		wantText string
+	}{
+		{
+			name:     "local provider with the suggested endpoint",
+			input:    "bedrock\n5\n\nmistral\n",
+			want:     config.LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", Model: "mistral"},
+			wantText: `Unknown provider "bedrock"`,
+		},
+		{
+			name:  "cloud provider with an API key",
+			input: "anthropic\n\nsk-ant-test\n",
+			want:  config.LLMConfig{Provider: "anthropic", Model: "claude-3-5-sonnet-latest", APIKey: "sk-ant-test"},
+		},
+		{
+			name:     "required field asked again",
+			input:    "azure\nhttps://example.openai.azure.com\n\ngpt4o\ngpt-4o\nkey\n",
+			want:     config.LLMConfig{Provider: "azure", Endpoint: "https://example.openai.azure.com", Deployment: "gpt4o", Model: "gpt-4o", APIKey: "key"},
+			wantText: "Deployment name: Deployment name: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "code-decoder", "config.yaml")
+			var out strings.Builder
+			if err := runSetup(strings.NewReader(tt.input), &out, path); err != nil {
+				t.Fatalf("runSetup() error = %v\noutput:\n%s", err, out.String())
+			}
+			if !strings.Contains(out.String(), tt.wantText) {
+				t.Errorf("Expected the output to contain %q, got:\n%s", tt.wantText, out.String())
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Expected the config file to be written: %v", err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("Expected the config file to be private, got mode %v", info.Mode().Perm())
+			}
+
+			loaded, err := config.LoadConfig(path, "")
+			if err != nil {
+				t.Fatalf("Expected a valid config, got %v", err)
+			}
+			got := config.LLMConfig{Provider: loaded.LLM.Provider, Endpoint: loaded.LLM.Endpoint, Deployment: loaded.LLM.Deployment, Model: loaded.LLM.Model, APIKey: loaded.LLM.APIKey}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Input ending early writes nothing
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := runSetup(strings.NewReader("ollama\n"), &strings.Builder{}, path); !errors.Is(err, errSetupAborted) {
+		t.Errorf("Expected errSetupAborted, got %v", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("Expected no config file after an aborted setup")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// statsLargestFiles is the number of largest files analyze --stats lists.
+const statsLargestFiles = 10
+
+// sourceStats is what analyze --stats reports of the files it would
+// analyze, and its --json output.
+type sourceStats struct {
+	Files       int             `json:"files"`
+	Lines       int             `json:"lines"`        // Non-blank lines of all the files
+	TotalSize   int64           `json:"total_size"`   // In bytes
+	AverageSize int64           `json:"average_size"` // In bytes
+	Languages   []languageStats `json:"languages"`    // Most files first
+	Largest     []statsFile     `json:"largest_files"`
+	Skipped     map[string]int  `json:"skipped_files,omitempty"` // Files left out as binary or generated, by reason
+}
+
+type languageStats struct {
+	Language string `json:"language"` // Detected language, or "unknown"
+	Files    int    `json:"files"`
+	Lines    int    `json:"lines"`
+	Size     int64  `json:"size"`
+}
+
+type statsFile struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Size     int64  `json:"size"`
+	Lines    int    `json:"lines"`
+}
+
+// printStats prints statistics of the files analyze would analyze (their
+// count, size and lines by language, and the largest of them) without
+// making any LLM request.
+func printStats(cmd *cobra.Command) error {
+	a, dir, cleanup, err := scanSource(cmd, true)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	stats := sourceStats{Files: len(a.Files), Languages: []languageStats{}, Largest: []statsFile{}, Skipped: a.Skipped}
+	byLanguage := make(map[string]*languageStats)
+	files := make([]statsFile, 0, len(a.Files))
+	for _, f := range a.Files {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+		file := statsFile{Path: f.Path, Language: f.Language, Size: f.Size, Lines: countLines(content)}
+		if file.Language == "" {
+			file.Language = "unknown"
+		}
+		files = append(files, file)
+		stats.Lines += file.Lines
+		stats.TotalSize += file.Size
+
+		lang := byLanguage[file.Language]
+		if lang == nil {
+			lang = &languageStats{Language: file.Language}
+			byLanguage[file.Language] = lang
+		}
+		lang.Files++
+		lang.Lines += file.Lines
+		lang.Size += file.Size
+	}
+	if stats.Files > 0 {
+		stats.AverageSize = stats.TotalSize / int64(stats.Files)
+	}
+	for _, lang := range byLanguage {
+		stats.Languages = append(stats.Languages, *lang)
+	}
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		if stats.Languages[i].Files != stats.Languages[j].Files {
+			return stats.Languages[i].Files > stats.Languages[j].Files
+		}
+		return stats.Languages[i].Language < stats.Languages[j].Language
+	})
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	stats.Largest = append(stats.Largest, files[:min(len(files), statsLargestFiles)]...)
+
+	if jsonOutput {
+		return printJSON(cmd.OutOrStdout(), stats)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LANGUAGE\tFILES\tLINES\tSIZE")
+	for _, lang := range stats.Languages {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", lang.Language, lang.Files, lang.Lines, lang.Size)
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t%d\n", stats.Files, stats.Lines, stats.TotalSize)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\nAverage file size: %d bytes\n", stats.AverageSize)
+	if len(a.Skipped) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Skipped: %s\n", formatSkipped(a.Skipped))
+	}
+	if len(stats.Largest) == 0 {
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "\nLargest files:\n")
+	w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SIZE\tLINES\tLANGUAGE\tPATH")
+	for _, f := range stats.Largest {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", f.Size, f.Lines, f.Language, f.Path)
+	}
+	return w.Flush()
+}
+
+// countLines returns the number of non-blank lines of content.
+func countLines(content []byte) int {
+	n := 0
+	for line := range bytes.Lines(content) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			n++
+		}
+	}
+	return n
+}
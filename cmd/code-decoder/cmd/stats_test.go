@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAnalyzeStats(t *testing.T) {
+	dir := t.TempDir()
+	var calls atomic.Int32
+	writePipelineProject(t, dir, newPipelineServer(t, func(pipelineRequest) { calls.Add(1) }))
+	for name, content := range map[string]string{
+		"src/b.go":     "package src\n\nfunc B() {}\n\n// B does nothing\n",
+		"src/c.md":     "# C\n",
+		"src/logo.png": "\x89PNG\x00\x00",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	stdout, stderr, err := execute(t, dir, "--json", "analyze", "--dir", "src", "--stats", "--no-cache")
+	if err != nil {
+		t.Fatalf("analyze --stats failed: %v\nstderr:\n%s", err, stderr)
+	}
+	var stats sourceStats
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+	if stats.Files != 3 || len(stats.Languages) != 2 || stats.Languages[0].Language != "Go" || stats.Languages[0].Files != 2 {
+		t.Errorf("Expected 2 Go files and 1 Markdown file, got %+v", stats)
+	}
+	if stats.AverageSize != stats.TotalSize/3 || len(stats.Largest) != 3 || stats.Largest[0].Path != "b.go" || stats.Largest[0].Lines != 3 {
+		t.Errorf("Expected b.go, with 3 non-blank lines, to be the largest file, got %+v", stats)
+	}
+	if stats.Skipped["binary"] != 1 {
+		t.Errorf("Expected the skipped binary file to be counted, got %v", stats.Skipped)
+	}
+
+	stdout, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--stats")
+	if err != nil {
+		t.Fatalf("analyze --stats failed: %v\nstderr:\n%s", err, stderr)
+	}
+	for _, want := range []string{"LANGUAGE", "Markdown", "total", "Average file size", "Largest files", "b.go"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected the stats to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected no LLM requests, got %d", n)
+	}
+
+	if _, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--stats", "--save-analysis", "out.json"); err == nil || !strings.Contains(stderr, "stats") {
+		t.Errorf("Expected --stats and --save-analysis to conflict, got stderr:\n%s", stderr)
+	}
+}
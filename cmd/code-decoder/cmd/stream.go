@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// fileStream is the scan of a codebase feeding its extraction: paths flow
+// from the scanner through a channel of --scan-buffer capacity to be
+// described (hashed and their language detected), then through another into
+// the extraction workers, so that the LLM, the slowest stage, holds back the
+// walk rather than the whole tree being listed up front.
+type fileStream struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc // Called by the first stage to fail, with its error
+	files   chan analysis.File
+	opts    scanner.ScanOptions
+	scanned *scanner.Result
+	wg      sync.WaitGroup
+}
+
+// streamFiles starts scanning the files of dir selected by opts. Unless
+// --yes is set, up to --max-files files are held back until the walk shows
+// there are no more, and it fails if there are, so that too many files are
+// refused before the provider is even set up; the scan runs ahead of the
+// extraction otherwise. The caller must call stop when done with the stream.
+func streamFiles(cmd *cobra.Command, dir string, opts scanner.ScanOptions) (*fileStream, error) {
+	buffer, err := scanBuffer(cmd)
+	if err != nil {
+		return nil, err
+	}
+	maxFiles, err := maxFilesLimit(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		maxFiles = 0
+	}
+
+	ctx, cancel := context.WithCancelCause(cmd.Context())
+	s := &fileStream{ctx: ctx, cancel: cancel, files: make(chan analysis.File, buffer), opts: opts}
+	paths := make(chan string, buffer)
+	counted := make(chan struct{}) // Closed once the files are known to be within maxFiles
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		var err error
+		if s.scanned, err = scanner.Stream(ctx, dir, opts, paths); err != nil {
+			cancel(err)
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		defer close(s.files)
+		if err := s.describe(cmd, dir, paths, maxFiles, counted); err != nil {
+			cancel(err)
+		}
+	}()
+
+	select {
+	case <-counted:
+		return s, nil
+	case <-ctx.Done():
+		s.stop()
+		return nil, context.Cause(ctx)
+	}
+}
+
+// describe describes the files at the paths received from paths, sending
+// them on to s.files until paths is closed or the stream is canceled. If
+// maxFiles is not 0, the files are held back until paths is closed, and
+// refused by checkMaxFiles, with their full count, if there are more;
+// counted is closed once they are sent on.
+func (s *fileStream) describe(cmd *cobra.Command, dir string, paths <-chan string, maxFiles int, counted chan<- struct{}) error {
+	if maxFiles == 0 {
+		close(counted)
+	}
+	var held []analysis.File
+	for p := range paths {
+		if maxFiles > 0 && len(held) == maxFiles {
+			count := len(held) + 1
+			for range paths {
+				count++
+			}
+			return checkMaxFiles(cmd, count)
+		}
+		file, err := describeFile(dir, p)
+		if err != nil {
+			return err
+		}
+		slog.Debug("Selected file", "path", p, "size", file.Size, "language", file.Language)
+		if maxFiles > 0 {
+			held = append(held, file)
+			continue
+		}
+		if !s.send(file) {
+			return nil
+		}
+	}
+	if maxFiles > 0 {
+		close(counted)
+	}
+	for _, file := range held {
+		if !s.send(file) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// send sends file on to the extraction, reporting false if the stream was
+// canceled first.
+func (s *fileStream) send(file analysis.File) bool {
+	select {
+	case s.files <- file:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// extract extracts knowledge from the files of the stream as they come,
+// adding them to a in the order ListFiles would return them.
+func (s *fileStream) extract(cmd *cobra.Command, extractor *analysis.Extractor, a *analysis.Analysis) error {
+	if err := extractor.ExtractStream(s.ctx, a, s.files); err != nil {
+		s.cancel(err)
+	}
+	s.wg.Wait()
+	if err := context.Cause(s.ctx); err != nil {
+		return err
+	}
+
+	reportScan(a, s.opts, s.scanned, len(a.Files))
+	if err := checkMaxFiles(cmd, len(a.Files)); err != nil {
+		return err
+	}
+	if len(a.Files) > 0 {
+		slog.Info("Languages", "breakdown", analysis.FormatLanguages(a.Languages()))
+	}
+	return nil
+}
+
+// stop cancels what is left of the stream and waits for its stages to end.
+func (s *fileStream) stop() {
+	s.cancel(nil)
+	s.wg.Wait()
+}
+
+// scanBuffer returns the capacity of the channels between the stages of a
+// fileStream: --scan-buffer or, without the flag, defaults.scan_buffer.
+func scanBuffer(cmd *cobra.Command) (int, error) {
+	buffer, _ := cmd.Flags().GetInt("scan-buffer")
+	if !cmd.Flags().Changed("scan-buffer") && cfg.Defaults.ScanBuffer > 0 {
+		buffer = cfg.Defaults.ScanBuffer
+	}
+	if buffer < 1 {
+		return 0, usageErrorf("--scan-buffer must be at least 1, got %d", buffer)
+	}
+	return buffer, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+func TestAnalyzeStream(t *testing.T) {
+	dir := t.TempDir()
+	writePipelineProject(t, dir, newPipelineServer(t, nil))
+	for i := range 20 {
+		name := filepath.Join(dir, "src", fmt.Sprintf("pkg%d", i%3), fmt.Sprintf("f%02d.go", i))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(fmt.Sprintf("package pkg // %d\n", i)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// The files stream through the smallest buffer, but are recorded sorted
+	_, stderr, err := execute(t, dir, "analyze", "--dir", "src", "--scan-buffer", "1", "--concurrency", "4", "--no-cache", "--save-analysis", "out.json")
+	if err != nil {
+		t.Fatalf("analyze failed: %v\nstderr:\n%s", err, stderr)
+	}
+	a, err := analysis.Load(filepath.Join(dir, "out.json"))
+	if err != nil {
+		t.Fatalf("Failed to load the analysis: %v", err)
+	}
+	var paths []string
+	for _, f := range a.Files {
+		paths = append(paths, f.Path)
+		if f.Summary == "" || f.Hash == "" {
+			t.Errorf("Expected %s to be described and summarized, got %+v", f.Path, f)
+		}
+	}
+	if len(paths) != 21 || !slices.IsSorted(paths) {
+		t.Errorf("Expected the 21 files sorted, got %v", paths)
+	}
+	if !strings.Contains(stderr, "Found files to analyze") || !strings.Contains(stderr, "count=21") {
+		t.Errorf("Expected the count of files found to be logged, got stderr:\n%s", stderr)
+	}
+
+	// The file limit is checked against the whole tree before any request
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--max-files", "5", "--no-cache", "--save-analysis", "out.json")
+	if err == nil || !strings.Contains(stderr, "found 21 files to analyze, more than the limit of 5") {
+		t.Errorf("Expected the file limit error, got %v, stderr:\n%s", err, stderr)
+	}
+
+	_, stderr, err = execute(t, dir, "analyze", "--dir", "src", "--scan-buffer", "0", "--save-analysis", "out.json")
+	if err == nil || !strings.Contains(stderr, "--scan-buffer must be at least 1") {
+		t.Errorf("Expected --scan-buffer 0 to be refused, got %v, stderr:\n%s", err, stderr)
+	}
+}
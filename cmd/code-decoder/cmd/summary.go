@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/llm"
+)
+
+// meters count the requests sent by the providers that newProvider creates,
+// by model, for the run summary.
+var meters = map[string]*llm.Meter{}
+
+// meterFor returns the meter of the requests sent to model.
+func meterFor(model string) *llm.Meter {
+	if meters[model] == nil {
+		meters[model] = &llm.Meter{}
+	}
+	return meters[model]
+}
+
+// runSummary is the report of an analyze or generate run, printed to stderr
+// when the run completes and included in the --json output, so that
+// anomalies such as no abstractions found stand out.
+type runSummary struct {
+	FilesScanned   int      `json:"files_scanned"`            // Files selected for analysis
+	FilesSkipped   int      `json:"files_skipped"`            // Files left out as binary, generated or tests
+	FilesFailed    int      `json:"files_failed"`             // Files the LLM could not analyze
+	Languages      []string `json:"languages"`                // Languages of the analyzed files, most common first
+	Abstractions   int      `json:"abstractions"`             // Core abstractions identified
+	Chapters       *int     `json:"chapters,omitempty"`       // Chapters written; omitted by analyze
+	LLMCalls       int      `json:"llm_calls"`                // Requests sent to the provider, not answered from the cache
+	InputTokens    int      `json:"input_tokens"`             // Estimated
+	OutputTokens   int      `json:"output_tokens"`            // Estimated
+	ElapsedSeconds float64  `json:"elapsed_seconds"`          // Wall-clock time of the run
+	EstimatedCost  *float64 `json:"estimated_cost,omitempty"` // US dollars; omitted without pricing data for a model used
+
+	unpriced []string // Models used without pricing data
+}
+
+// newRunSummary reports a run that started at start and produced a; the
+// number of chapters written is given by generate only.
+func newRunSummary(a *analysis.Analysis, chapters *int, start time.Time) runSummary {
+	usage, cost, unpriced := meteredUsage()
+	summary := runSummary{
+		FilesScanned:   len(a.Files),
+		FilesFailed:    len(a.Failed),
+		Languages:      []string{},
+		Abstractions:   len(a.Abstractions),
+		Chapters:       chapters,
+		LLMCalls:       usage.Calls,
+		InputTokens:    usage.InputTokens,
+		OutputTokens:   usage.OutputTokens,
+		ElapsedSeconds: time.Since(start).Round(time.Millisecond).Seconds(),
+		unpriced:       unpriced,
+	}
+	for _, share := range a.Languages() {
+		summary.Languages = append(summary.Languages, share.Language)
+	}
+	for _, n := range a.Skipped {
+		summary.FilesSkipped += n
+	}
+	if len(unpriced) == 0 {
+		summary.EstimatedCost = &cost
+	}
+	return summary
+}
+
+// meteredUsage returns the usage of the requests the meters counted, and
+// their cost, which only adds up the models other than those unpriced, the
+// ones used without pricing data.
+func meteredUsage() (usage llm.Usage, cost float64, unpriced []string) {
+	for model, meter := range meters {
+		used := meter.Usage()
+		usage.Calls += used.Calls
+		usage.InputTokens += used.InputTokens
+		usage.OutputTokens += used.OutputTokens
+		if pricing, ok := llm.LookupPricing(cfg.LLM.Provider, model); ok {
+			cost += pricing.Cost(used)
+		} else {
+			unpriced = append(unpriced, model)
+		}
+	}
+	if len(meters) == 0 {
+		// Nothing was sent, which costs nothing if the model is priced at all
+		if _, ok := llm.LookupPricing(cfg.LLM.Provider, cfg.LLM.Model); !ok {
+			unpriced = append(unpriced, cfg.LLM.Model)
+		}
+	}
+	return usage, cost, unpriced
+}
+
+// print writes the summary for humans to w, unless --quiet is set.
+func (s runSummary) print(w io.Writer) {
+	if s.Abstractions == 0 {
+		slog.Warn("No abstractions were found; check the selected files and the LLM responses (e.g., with --debug-dir)")
+	}
+	if quiet {
+		return
+	}
+	fmt.Fprintln(w, "Run summary:")
+	fmt.Fprintf(w, "  Files:         %d analyzed, %d skipped, %d failed\n", s.FilesScanned, s.FilesSkipped, s.FilesFailed)
+	if len(s.Languages) > 0 {
+		fmt.Fprintf(w, "  Languages:     %s\n", strings.Join(s.Languages, ", "))
+	}
+	fmt.Fprintf(w, "  Abstractions:  %d\n", s.Abstractions)
+	if s.Chapters != nil {
+		fmt.Fprintf(w, "  Chapters:      %d\n", *s.Chapters)
+	}
+	fmt.Fprintf(w, "  LLM requests:  %d (responses from the cache are not counted)\n", s.LLMCalls)
+	fmt.Fprintf(w, "  Tokens:        ~%d input, ~%d output (estimated)\n", s.InputTokens, s.OutputTokens)
+	fmt.Fprintf(w, "  Elapsed:       %s\n", time.Duration(s.ElapsedSeconds*float64(time.Second)).Round(time.Millisecond))
+	if s.EstimatedCost != nil {
+		fmt.Fprintf(w, "  Cost:          $%.4f (estimated)\n", *s.EstimatedCost)
+	} else {
+		sort.Strings(s.unpriced)
+		fmt.Fprintf(w, "  Cost:          unknown (no pricing data for %s model %s)\n", cfg.LLM.Provider, strings.Join(s.unpriced, ", "))
+	}
+}
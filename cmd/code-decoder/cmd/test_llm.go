@@ -4,7 +4,12 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ksylvan/code-decoder/internal/llm"
 	"github.com/spf13/cobra"
 )
 
@@ -15,26 +20,105 @@ var testLlmCmd = &cobra.Command{
 	Long: `Verifies that the application can successfully connect to the
 Large Language Model (LLM) provider specified in the configuration
 (or overridden via flags) and perform a basic interaction.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("test-llm called")
-		providerOverride, _ := cmd.Flags().GetString("provider")
-		fmt.Printf("Testing LLM connection (Provider from config: %s, Override: %s)", cfg.LLM.Provider, providerOverride)
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slog.Debug("test-llm called")
 
-		// TODO: Implement LLM connection test logic
-		// 1. Determine the provider to use (config or override)
+		// 1. Determine the provider to use (config or override) and
 		// 2. Get provider configuration (API key, endpoint, model)
+		llmCfg := cfg.LLM
+
 		// 3. Initialize the LLM client/provider
-		// 4. Call the provider's TestConnection method or perform a simple API call
-		// 5. Report success or failure
+		provider, err := llm.NewProvider(llmCfg)
+		if err != nil {
+			return err
+		}
+		baseURL := llm.BaseURL(provider)
+		slog.Info("Testing LLM connection", "provider", llmCfg.Provider, "model", llmCfg.Model, "base_url", baseURL)
+
+		// 4. Call the provider's TestConnection method
+		start := time.Now()
+		err = provider.TestConnection(cmd.Context())
+		latency := time.Since(start)
+		if err != nil {
+			err = fmt.Errorf("connection to %s failed: %w", provider.Name(), err)
+		}
+
+		// Local providers must also have the model downloaded
+		var modelAvailable *bool
+		if manager, ok := llm.AsModelManager(provider); ok && err == nil {
+			var available bool
+			if available, err = manager.HasModel(cmd.Context(), llmCfg.Model); err == nil {
+				modelAvailable = &available
+				if !available {
+					err = modelNotFound(provider, llmCfg.Model)
+				}
+			}
+		} else if lister, ok := llm.AsModelLister(provider); ok && err == nil && isLocalProvider(llmCfg.Provider) {
+			// Servers such as LM Studio may load the model on demand, so a
+			// model missing from the list is only a warning
+			var models []string
+			if models, err = lister.ListModels(cmd.Context()); err == nil {
+				available := slices.Contains(models, llmCfg.Model)
+				modelAvailable = &available
+				if !available {
+					slog.Warn("The configured model is not loaded on the server", "provider", provider.Name(), "model", llmCfg.Model, "loaded", strings.Join(models, ", "))
+				}
+			}
+		}
+
+		// 5. Report the result
+		if jsonOutput {
+			result := testLLMResult{Provider: provider.Name(), Model: llmCfg.Model, BaseURL: baseURL, LatencyMS: latency.Milliseconds(), ModelAvailable: modelAvailable, OK: err == nil}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			if jsonErr := printJSON(cmd.OutOrStdout(), result); jsonErr != nil {
+				return jsonErr
+			}
+			if err != nil {
+				return silentError{err}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Successfully connected to %s (model: %s) in %s\n", provider.Name(), llmCfg.Model, latency.Round(time.Millisecond))
+		if baseURL != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Base URL: %s\n", baseURL)
+		}
+		if modelAvailable != nil && *modelAvailable {
+			fmt.Fprintf(cmd.OutOrStdout(), "Model %s is available locally\n", llmCfg.Model)
+		}
+		return nil
 	},
 }
 
+// isLocalProvider reports whether the named provider runs models on the
+// user's machine.
+func isLocalProvider(name string) bool {
+	info, ok := llm.LookupProvider(name)
+	return ok && info.Local
+}
+
+// testLLMResult is the --json output of test-llm.
+type testLLMResult struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	BaseURL   string `json:"base_url,omitempty"` // URL the requests were sent under
+	LatencyMS int64  `json:"latency_ms"`
+	// ModelAvailable reports whether a local provider has the model; it is
+	// omitted for cloud providers and when the connection failed
+	ModelAvailable *bool  `json:"model_available,omitempty"`
+	OK             bool   `json:"ok"`
+	Error          string `json:"error,omitempty"`
+}
+
 func init() {
 	rootCmd.AddCommand(testLlmCmd)
 
 	// Flags for test-llm command
 	testLlmCmd.Flags().String("provider", "", "Override the LLM provider specified in the config for this test")
-
-	// TODO: Bind flags if needed, though provider override is handled directly in Run for now
-	// viper.BindPFlag("testllm.provider", testLlmCmd.Flags().Lookup("provider"))
+	bindConfigFlag(testLlmCmd, "provider", "llm.provider")
 }
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTestLLMLMStudioModels(t *testing.T) {
+	// The fake LM Studio server has a single model loaded
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-coder-7b-instruct","object":"model"}]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tests := []struct {
+		model         string
+		wantAvailable bool
+	}{
+		{model: "qwen2.5-coder-7b-instruct", wantAvailable: true},
+		{model: "llama-3.2-3b", wantAvailable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			config := "llm:\n  provider: lmstudio\n  endpoint: " + server.URL + "\n  model: " + tt.model + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			stdout, stderr, err := execute(t, dir, "test-llm", "--json")
+			if err != nil {
+				t.Fatalf("Expected a missing model to be only a warning, got %v; stderr:\n%s", err, stderr)
+			}
+			var result testLLMResult
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatalf("Failed to decode output %q: %v", stdout, err)
+			}
+			if !result.OK || result.ModelAvailable == nil || *result.ModelAvailable != tt.wantAvailable {
+				t.Errorf("Expected ok with model_available %v, got %+v", tt.wantAvailable, result)
+			}
+			if got := strings.Contains(stderr, "The configured model is not loaded"); got == tt.wantAvailable {
+				t.Errorf("Expected a warning: %v, got stderr:\n%s", !tt.wantAvailable, stderr)
+			}
+		})
+	}
+}
+
+func TestTestLLMGateway(t *testing.T) {
+	// The fake gateway serves the OpenAI API under /api/v1 and requires a key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gateway-key" {
+			http.Error(w, `{"error":{"message":"invalid key"}}`, http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"mistral-7b"}]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := "llm:\n  provider: openai\n  api_key: gateway-key\n  endpoint: " + server.URL + "/api/v1/\n  model: mistral-7b\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	stdout, stderr, err := execute(t, dir, "test-llm")
+	if err != nil {
+		t.Fatalf("test-llm error = %v; stderr:\n%s", err, stderr)
+	}
+	if want := "Base URL: " + server.URL + "/api/v1\n"; !strings.Contains(stdout, want) {
+		t.Errorf("Expected output to contain %q, got %q", want, stdout)
+	}
+
+	stdout, _, err = execute(t, dir, "test-llm", "--json")
+	if err != nil {
+		t.Fatalf("test-llm --json error = %v", err)
+	}
+	var result testLLMResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Failed to decode output %q: %v", stdout, err)
+	}
+	if !result.OK || result.Provider != "openai" || result.BaseURL != server.URL+"/api/v1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
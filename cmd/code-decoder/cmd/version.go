@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// unknownBuildInfo is shown for build information that was not recorded.
+const unknownBuildInfo = "unknown"
+
+// Build information set by main; see SetBuildInfo.
+var (
+	buildCommit = unknownBuildInfo
+	buildDate   = unknownBuildInfo
+)
+
+// SetBuildInfo allows main to set the commit and date the binary was built
+// from, as injected with -ldflags. Values that are empty or "unknown" fall
+// back to the version control information Go records in the binary, if any.
+func SetBuildInfo(commit, date string) {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				buildCommit = setting.Value
+			case "vcs.time":
+				buildDate = setting.Value
+			}
+		}
+	}
+	if commit != "" && commit != unknownBuildInfo {
+		buildCommit = commit
+	}
+	if date != "" && date != unknownBuildInfo {
+		buildDate = date
+	}
+}
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Long: `Prints the version of code-decoder (the same as --version), with the
+commit and date it was built from and the Go version it was built with.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	// Printing the version does not need a valid LLM configuration.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		platform := runtime.GOOS + "/" + runtime.GOARCH
+		if jsonOutput {
+			return printJSON(cmd.OutOrStdout(), map[string]string{
+				"version":    appVersion,
+				"commit":     buildCommit,
+				"date":       buildDate,
+				"go_version": runtime.Version(),
+				"platform":   platform,
+			})
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "code-decoder version %s\n", appVersion)
+		fmt.Fprintf(out, "  commit:   %s\n", buildCommit)
+		fmt.Fprintf(out, "  built:    %s\n", buildDate)
+		fmt.Fprintf(out, "  go:       %s\n", runtime.Version())
+		fmt.Fprintf(out, "  platform: %s\n", platform)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
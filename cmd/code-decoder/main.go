@@ -9,8 +9,12 @@ import (
 	"github.com/ksylvan/code-decoder/cmd/code-decoder/cmd"
 )
 
-// version is set during build time using ldflags
-var version = "dev" // Default value
+// Build information, set during build time using ldflags
+var (
+	version = "dev"     // Default value
+	commit  = "unknown" // Git commit the binary was built from
+	date    = "unknown" // Build date
+)
 
 func main() {
 	// Check for version flag before doing anything else
@@ -23,5 +27,6 @@ func main() {
 
 	// Pass the version to the command package
 	cmd.SetVersion(version)
+	cmd.SetBuildInfo(commit, date)
 	cmd.Execute()
 }
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+// DefaultMaxAbstractions is the number of core abstractions identified when
+// Extractor.MaxAbstractions is not set.
+const DefaultMaxAbstractions = 10
+
+// abstractionsResponse is the structured response expected when
+// consolidating the abstractions of a codebase.
+type abstractionsResponse struct {
+	Abstractions []struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Files       []string `json:"files"`
+	} `json:"abstractions"`
+}
+
+// IdentifyAbstractions replaces the candidate abstractions that Extract
+// found file by file with the core abstractions of the codebase: a single
+// LLM request merges overlapping candidates, drops trivial ones, and ranks
+// the rest, most important first, keeping at most MaxAbstractions.
+//
+// Only files of a are kept in the abstractions' file lists. If the request
+// fails with an error that is not fatal (see Extract), or its response
+// cannot be used, the candidates are ranked by how many files implement them
+// instead, so that the work of extracting every file is not lost.
+func (e *Extractor) IdentifyAbstractions(ctx context.Context, a *Analysis) error {
+	if len(a.Abstractions) == 0 {
+		return nil
+	}
+	limit := e.MaxAbstractions
+	if limit < 1 {
+		limit = DefaultMaxAbstractions
+	}
+	slog.Info("Identifying core abstractions", "candidates", len(a.Abstractions), "max", limit)
+
+	identified, err := e.identify(ctx, a, limit)
+	switch {
+	case err != nil && llm.IsFatal(err):
+		return fmt.Errorf("identifying abstractions: %w", err)
+	case err != nil:
+		slog.Warn("Could not identify the core abstractions; ranking the candidates by file count", "error", err)
+		identified = rankCandidates(a.Abstractions, limit)
+	}
+	a.Abstractions = identified
+	return nil
+}
+
+// identify asks the LLM to consolidate the candidate abstractions of a.
+func (e *Extractor) identify(ctx context.Context, a *Analysis, limit int) ([]Abstraction, error) {
+	data := prompts.AbstractionsData{Project: a.ProjectName, MaxAbstractions: limit}
+	for _, f := range a.Files {
+		data.Files = append(data.Files, prompts.FileSummary{Path: f.Path, Summary: f.Summary})
+	}
+	for _, abs := range a.Abstractions {
+		data.Candidates = append(data.Candidates, prompts.AbstractionSummary{Name: abs.Name, Description: abs.Description, Files: abs.Files})
+	}
+	if e.DocsContext {
+		data.Docs = e.docExcerpts(a)
+	}
+	templates := e.Prompts
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	prompt, err := templates.Abstractions(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := completeJSON[abstractionsResponse](ctx, e, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(a.Files))
+	for _, f := range a.Files {
+		known[f.Path] = true
+	}
+	candidates := make(map[string]Abstraction, len(a.Abstractions))
+	for _, abs := range a.Abstractions {
+		candidates[strings.ToLower(abs.Name)] = abs
+	}
+
+	var identified []Abstraction
+	seen := make(map[string]bool)
+	for _, found := range parsed.Abstractions {
+		name := strings.TrimSpace(found.Name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		var files []string
+		for _, path := range found.Files {
+			if known[path] && !slices.Contains(files, path) {
+				files = append(files, path)
+			}
+		}
+		if len(files) == 0 {
+			// Fall back to the files of the candidate it kept, if any
+			files = candidates[key].Files
+		}
+		if len(files) == 0 {
+			slog.Debug("Dropping abstraction without known files", "name", name)
+			continue
+		}
+		seen[key] = true
+		identified = append(identified, Abstraction{Name: name, Description: strings.TrimSpace(found.Description), Files: files})
+		if len(identified) == limit {
+			break
+		}
+	}
+	if len(identified) == 0 {
+		return nil, fmt.Errorf("response listed no abstraction implemented by the analyzed files")
+	}
+	return identified, nil
+}
+
+// rankCandidates returns at most limit candidates, those implemented by the
+// most files first; ties keep their order of first appearance.
+func rankCandidates(candidates []Abstraction, limit int) []Abstraction {
+	ranked := append([]Abstraction(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return len(ranked[i].Files) > len(ranked[j].Files)
+	})
+	return ranked[:min(limit, len(ranked))]
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+// candidateAnalysis returns an analysis with the candidate abstractions
+// Extract would find in it.
+func candidateAnalysis() *Analysis {
+	return &Analysis{
+		ProjectName: "demo",
+		Files: []File{
+			{Path: "config.go", Summary: "Defines the config."},
+			{Path: "loader.go", Summary: "Loads the config."},
+			{Path: "cli.go", Summary: "Parses flags."},
+		},
+		Abstractions: []Abstraction{
+			{Name: "Loader", Description: "Reads files", Files: []string{"loader.go"}},
+			{Name: "Config", Description: "Settings", Files: []string{"config.go", "loader.go"}},
+			{Name: "Settings", Description: "Also settings", Files: []string{"config.go"}},
+			{Name: "CLI", Description: "Flags", Files: []string{"cli.go"}},
+		},
+	}
+}
+
+func TestIdentifyAbstractions(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(string) (string, error) {
+		return `{"abstractions": [
+			{"name": "Configuration", "description": "Settings and how they load", "files": ["config.go", "loader.go", "config.go", "missing.go"]},
+			{"name": "configuration", "description": "Duplicate", "files": ["config.go"]},
+			{"name": "CLI", "description": "Command line", "files": []},
+			{"name": "Ghost", "description": "Not in the codebase", "files": ["ghost.go"]},
+			{"name": "Loader", "description": "Over the limit", "files": ["loader.go"]}
+		]}`, nil
+	}}
+
+	a := candidateAnalysis()
+	e := &Extractor{Provider: provider, MaxAbstractions: 2}
+	if err := e.IdentifyAbstractions(context.Background(), a); err != nil {
+		t.Fatalf("IdentifyAbstractions() error = %v", err)
+	}
+
+	want := []Abstraction{
+		{Name: "Configuration", Description: "Settings and how they load", Files: []string{"config.go", "loader.go"}},
+		// Without valid files, the files of the candidate of the same name are used
+		{Name: "CLI", Description: "Command line", Files: []string{"cli.go"}},
+	}
+	if !reflect.DeepEqual(a.Abstractions, want) {
+		t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, want)
+	}
+
+	prompts := provider.Prompts()
+	if len(prompts) != 1 {
+		t.Fatalf("Expected one consolidation request, got %d", len(prompts))
+	}
+	for _, s := range []string{"- loader.go: Loads the config.", "- Config: Settings (files: config.go, loader.go)", "at most 2 abstractions"} {
+		if !strings.Contains(prompts[0], s) {
+			t.Errorf("Expected the prompt to contain %q, got:\n%s", s, prompts[0])
+		}
+	}
+}
+
+func TestIdentifyAbstractionsFallback(t *testing.T) {
+	// Ranked by file count, ties in their original order
+	ranked := []Abstraction{
+		{Name: "Config", Description: "Settings", Files: []string{"config.go", "loader.go"}},
+		{Name: "Loader", Description: "Reads files", Files: []string{"loader.go"}},
+		{Name: "Settings", Description: "Also settings", Files: []string{"config.go"}},
+	}
+
+	tests := []struct {
+		name    string
+		respond func(string) (string, error)
+		wantErr bool
+	}{
+		{name: "malformed response", respond: func(string) (string, error) { return "Sorry, no.", nil }},
+		{name: "no usable abstraction", respond: func(string) (string, error) {
+			return `{"abstractions": [{"name": "Ghost", "files": ["ghost.go"]}]}`, nil
+		}},
+		{name: "server error", respond: func(string) (string, error) {
+			return "", &llm.APIError{Provider: "fake", StatusCode: http.StatusInternalServerError, Message: "boom"}
+		}},
+		{name: "fatal error", wantErr: true, respond: func(string) (string, error) {
+			return "", &llm.APIError{Provider: "fake", StatusCode: http.StatusUnauthorized, Message: "bad key"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := candidateAnalysis()
+			e := &Extractor{Provider: &llmtest.Provider{Respond: tt.respond}, MaxAbstractions: 3}
+			err := e.IdentifyAbstractions(context.Background(), a)
+			if tt.wantErr {
+				var apiErr *llm.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Expected the API error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IdentifyAbstractions() error = %v", err)
+			}
+			if !reflect.DeepEqual(a.Abstractions, ranked) {
+				t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, ranked)
+			}
+		})
+	}
+}
+
+func TestIdentifyAbstractionsWithoutCandidates(t *testing.T) {
+	provider := &llmtest.Provider{}
+	a := &Analysis{Files: []File{{Path: "main.go"}}}
+	if err := (&Extractor{Provider: provider}).IdentifyAbstractions(context.Background(), a); err != nil {
+		t.Fatalf("IdentifyAbstractions() error = %v", err)
+	}
+	if len(provider.Prompts()) != 0 || len(a.Abstractions) != 0 {
+		t.Errorf("Expected no request and no abstractions, got %d prompts and %+v", len(provider.Prompts()), a.Abstractions)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package analysis defines the on-disk analysis format shared by the analyze
+// and generate commands.
+package analysis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the analysis file format written by Save.
+// Bump it whenever a change would make older readers misinterpret the file.
+const SchemaVersion = 1
+
+// Source types recorded in Source.Type
+const (
+	SourceDir     = "dir"     // A local directory
+	SourceRepo    = "repo"    // A remote (GitHub) repository
+	SourceArchive = "archive" // A local .tar.gz, .tar or .zip archive
+)
+
+// Analysis is the result of analyzing a codebase.
+type Analysis struct {
+	SchemaVersion int               `json:"schema_version"`
+	ProjectName   string            `json:"project_name"`
+	Source        Source            `json:"source"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Files         []File            `json:"files"`
+	Skipped       map[string]int    `json:"skipped_files,omitempty"` // Files left out as binary or generated, by reason
+	Failed        map[string]string `json:"failed_files,omitempty"`  // Files the LLM could not analyze, with the reason
+	Abstractions  []Abstraction     `json:"abstractions"`
+	Relationships []Relationship    `json:"relationships"`
+}
+
+// Source describes where the analyzed codebase came from.
+type Source struct {
+	Type     string   `json:"type"`            // SourceDir, SourceRepo or SourceArchive
+	Location string   `json:"location"`        // Directory path, repository URL or archive path
+	Paths    []string `json:"paths,omitempty"` // Subdirectories the analysis was restricted to, if any
+	Since    string   `json:"since,omitempty"` // Git revision the analysis was restricted to the changes since, if any
+}
+
+// File is a single analyzed file.
+type File struct {
+	Path     string `json:"path"`               // Slash-separated path relative to the source root
+	Size     int64  `json:"size"`               // Size in bytes
+	Language string `json:"language,omitempty"` // Programming language (e.g., "Go"), or "unknown"
+	Hash     string `json:"hash,omitempty"`     // Hex-encoded SHA-256 of the content, to detect changes
+	Summary  string `json:"summary,omitempty"`  // What the file does, as extracted by the LLM
+	Docs     bool   `json:"docs,omitempty"`     // Whether the file is one of the project's own documents (see IsDoc)
+}
+
+// Abstraction is a core concept of the codebase (a component, module, or pattern).
+type Abstraction struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Files       []string `json:"files,omitempty"` // Paths of the files implementing it
+}
+
+// Relationship is a directed edge between two abstractions (e.g., "A uses B").
+type Relationship struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+// LanguageShare is the number of files of the analysis written in a language.
+type LanguageShare struct {
+	Language string  `json:"language"`
+	Files    int     `json:"files"`
+	Percent  float64 `json:"percent"` // Share of all the analyzed files
+}
+
+// Languages returns the breakdown of a's files by language, most common
+// first. Files without a detected language count as "unknown".
+func (a *Analysis) Languages() []LanguageShare {
+	counts := make(map[string]int)
+	for _, f := range a.Files {
+		lang := f.Language
+		if lang == "" {
+			lang = "unknown"
+		}
+		counts[lang]++
+	}
+
+	shares := make([]LanguageShare, 0, len(counts))
+	for lang, n := range counts {
+		shares = append(shares, LanguageShare{Language: lang, Files: n, Percent: 100 * float64(n) / float64(len(a.Files))})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Files != shares[j].Files {
+			return shares[i].Files > shares[j].Files
+		}
+		return shares[i].Language < shares[j].Language
+	})
+	return shares
+}
+
+// FormatLanguages formats a language breakdown for display (e.g., "62% Go, 20% YAML").
+func FormatLanguages(shares []LanguageShare) string {
+	parts := make([]string, len(shares))
+	for i, share := range shares {
+		parts[i] = fmt.Sprintf("%.0f%% %s", share.Percent, share.Language)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// gzipSuffix is the extension of the analysis files Save compresses.
+const gzipSuffix = ".gz"
+
+// gzipMagic starts every gzip stream, whatever the name of its file.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Save writes a to path as versioned JSON, creating parent directories as
+// needed. A path ending in .gz (e.g., analysis.json.gz) gets gzip-compressed
+// JSON, which keeps the analyses of large codebases small.
+func Save(path string, a *Analysis) error {
+	data, err := encode(a)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(strings.ToLower(path), gzipSuffix) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return fmt.Errorf("failed to compress analysis: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress analysis: %w", err)
+		}
+		data = buf.Bytes()
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for analysis file: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write analysis file: %w", err)
+	}
+	return nil
+}
+
+// Write writes a to w as the versioned JSON Save writes to a file, so that
+// it can be piped into another command, which reads it with Read.
+func Write(w io.Writer, a *Analysis) error {
+	data, err := encode(a)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write analysis: %w", err)
+	}
+	return nil
+}
+
+// encode returns a as versioned JSON, setting its schema version.
+func encode(a *Analysis) ([]byte, error) {
+	if a == nil {
+		return nil, errors.New("cannot save a nil analysis")
+	}
+	a.SchemaVersion = SchemaVersion
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analysis: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Load reads an analysis file written by Save, decompressing it if it is
+// gzipped, whatever its extension. Files written with a different schema
+// version are rejected with a descriptive error.
+func Load(path string) (*Analysis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analysis file: %w", err)
+	}
+	defer f.Close()
+	return Read(f, "analysis file "+path)
+}
+
+// Read reads an analysis written by Save or Write from r, as Load does;
+// name tells where it comes from in the errors (e.g., "analysis file
+// out.json"). Besides the schema version, it checks that the files,
+// abstractions and relationships have the fields every reader relies on,
+// and tells a truncated analysis, such as the output of a command that
+// failed while writing it, from one that is not JSON at all.
+func Read(r io.Reader, name string) (*Analysis, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("%s is empty: expected a code-decoder analysis", name)
+	}
+
+	var a Analysis
+	if err := json.Unmarshal(data, &a); err != nil {
+		var syntax *json.SyntaxError
+		switch {
+		case errors.As(err, &syntax) && syntax.Offset >= int64(len(data)):
+			return nil, fmt.Errorf("failed to parse %s: the JSON ends after %d bytes, so the analysis is truncated (did the command writing it fail?)", name, len(data))
+		case errors.As(err, &syntax):
+			return nil, fmt.Errorf("failed to parse %s: invalid JSON at byte %d: %w", name, syntax.Offset, err)
+		}
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	switch {
+	case a.SchemaVersion == 0:
+		return nil, fmt.Errorf("%s is not a code-decoder analysis (missing schema_version)", name)
+	case a.SchemaVersion > SchemaVersion:
+		return nil, fmt.Errorf("%s uses schema version %d, but this version of code-decoder only supports version %d; please upgrade code-decoder",
+			name, a.SchemaVersion, SchemaVersion)
+	case a.SchemaVersion < SchemaVersion:
+		return nil, fmt.Errorf("%s uses outdated schema version %d (expected %d); re-run analyze to regenerate it",
+			name, a.SchemaVersion, SchemaVersion)
+	}
+	if err := a.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return &a, nil
+}
+
+// validate checks the fields of a that must be set: the paths of its files,
+// the names of its abstractions, and both ends of its relationships.
+func (a *Analysis) validate() error {
+	for i, f := range a.Files {
+		if f.Path == "" {
+			return fmt.Errorf("file %d has no path", i+1)
+		}
+	}
+	for i, abs := range a.Abstractions {
+		if abs.Name == "" {
+			return fmt.Errorf("abstraction %d has no name", i+1)
+		}
+	}
+	for i, rel := range a.Relationships {
+		if rel.From == "" || rel.To == "" {
+			return fmt.Errorf("relationship %d lacks the abstraction it goes from or to", i+1)
+		}
+	}
+	return nil
+}
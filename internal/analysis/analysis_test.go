@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		gzipped bool
+	}{
+		{name: "plain", file: "analysis.json"},
+		{name: "gzipped", file: "analysis.json.gz", gzipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", tt.file)
+			want := &Analysis{
+				ProjectName: "code-decoder",
+				Source:      Source{Type: SourceRepo, Location: "https://github.com/ksylvan/code-decoder"},
+				CreatedAt:   time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+				Files: []File{
+					{Path: "cmd/code-decoder/main.go", Size: 512, Summary: "CLI entry point"},
+					{Path: "internal/config/config.go", Size: 4096},
+				},
+				Abstractions: []Abstraction{
+					{Name: "Config", Description: "Loads and validates configuration", Files: []string{"internal/config/config.go"}},
+				},
+				Relationships: []Relationship{
+					{From: "CLI", To: "Config", Label: "uses"},
+				},
+			}
+
+			if err := Save(path, want); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if want.SchemaVersion != SchemaVersion {
+				t.Errorf("Expected Save to set schema version %d, got %d", SchemaVersion, want.SchemaVersion)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read the saved analysis: %v", err)
+			}
+			if got := bytes.HasPrefix(data, gzipMagic); got != tt.gzipped {
+				t.Errorf("Expected gzipped = %v for %s, got %v", tt.gzipped, tt.file, got)
+			}
+
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+
+			// The content is detected, not the extension
+			renamed := filepath.Join(filepath.Dir(path), "renamed")
+			if err := os.Rename(path, renamed); err != nil {
+				t.Fatalf("Failed to rename the analysis: %v", err)
+			}
+			if got, err := Load(renamed); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() of the renamed file = %+v, %v, want %+v", got, err, want)
+			}
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	want := &Analysis{
+		ProjectName:  "code-decoder",
+		Files:        []File{{Path: "main.go", Size: 512}},
+		Abstractions: []Abstraction{{Name: "CLI", Description: "Parses the command line"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, want); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := Read(&buf, "the analysis on stdin")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+
+	_, err = Read(strings.NewReader(`{"schema_version": 1, "proj`), "the analysis on stdin")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse the analysis on stdin: ") {
+		t.Errorf("Expected the error to name the input, got %v", err)
+	}
+}
+
+func TestLoadRejectsIncompatibleFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		wantMsg string
+	}{
+		{name: "newer schema", content: `{"schema_version": 99}`, wantMsg: "please upgrade"},
+		{name: "older schema", content: `{"schema_version": -1}`, wantMsg: "outdated schema version"},
+		{name: "missing schema", content: `{"project_name": "x"}`, wantMsg: "missing schema_version"},
+		{name: "invalid json", content: `{"schema_version": 1`, wantMsg: "failed to parse"},
+		{name: "truncated", content: `{"schema_version": 1, "files": [{"path": "a.go"}`, wantMsg: "the analysis is truncated"},
+		{name: "not json", content: "Error: no such repository\n", wantMsg: "invalid JSON at byte 1"},
+		{name: "empty", content: "\n", wantMsg: "is empty"},
+		{name: "abstraction without name", content: `{"schema_version": 1, "abstractions": [{"description": "x"}]}`, wantMsg: "abstraction 1 has no name"},
+		{name: "relationship without end", content: `{"schema_version": 1, "relationships": [{"from": "A"}]}`, wantMsg: "relationship 1 lacks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantMsg, err)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Load() expected an error for a missing file")
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	a := &Analysis{Files: []File{
+		{Path: "a.go", Language: "Go"},
+		{Path: "b.go", Language: "Go"},
+		{Path: "c.yaml", Language: "YAML"},
+		{Path: "LICENSE"},
+	}}
+
+	shares := a.Languages()
+	want := []LanguageShare{
+		{Language: "Go", Files: 2, Percent: 50},
+		{Language: "YAML", Files: 1, Percent: 25},
+		{Language: "unknown", Files: 1, Percent: 25},
+	}
+	if !reflect.DeepEqual(shares, want) {
+		t.Errorf("Languages() = %+v, want %+v", shares, want)
+	}
+	if got := FormatLanguages(shares); got != "50% Go, 25% YAML, 25% unknown" {
+		t.Errorf("FormatLanguages() = %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// extractionsDir is the subdirectory of the cache directory holding the
+// knowledge extracted from files.
+const extractionsDir = "extractions"
+
+// extractionEntry is a cached extraction as stored on disk.
+type extractionEntry struct {
+	Model     string         `json:"model"`
+	Hash      string         `json:"hash"` // Content hash of the file
+	CreatedAt time.Time      `json:"created_at"`
+	Knowledge *fileKnowledge `json:"knowledge"`
+}
+
+// ExtractionCache keeps the knowledge extracted from each file on disk,
+// keyed by the file's content hash and the model, so that any later analysis
+// with the same model reuses it for files whose content has not changed,
+// whatever analysis file it is saved to. Unlike the LLM response cache, an
+// entry survives changes to the prompt, such as a new project name or a
+// file that moved.
+type ExtractionCache struct {
+	dir     string
+	model   string
+	refresh bool
+}
+
+// NewExtractionCache returns the cache of the extractions made with model,
+// stored under dir. With refresh set, cached extractions are not used, and
+// are replaced by the new ones.
+func NewExtractionCache(dir, model string, refresh bool) *ExtractionCache {
+	return &ExtractionCache{dir: filepath.Join(dir, extractionsDir), model: model, refresh: refresh}
+}
+
+// lookup returns the cached knowledge of a file with the content hash. A nil
+// cache has none.
+func (c *ExtractionCache) lookup(hash string) (*fileKnowledge, bool) {
+	if c == nil || c.refresh || hash == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return nil, false
+	}
+	var entry extractionEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Knowledge == nil {
+		slog.Warn("Ignoring corrupt extraction cache entry", "path", c.path(hash), "error", err)
+		return nil, false
+	}
+	return entry.Knowledge, true
+}
+
+// store records the knowledge of a file with the content hash. Failing to
+// write the cache only costs a later run, so it is logged rather than
+// returned.
+func (c *ExtractionCache) store(hash string, knowledge *fileKnowledge) {
+	if c == nil || hash == "" {
+		return
+	}
+	entry := extractionEntry{Model: c.model, Hash: hash, CreatedAt: time.Now().UTC(), Knowledge: knowledge}
+	if err := writeEntry(c.path(hash), entry); err != nil {
+		slog.Warn("Failed to write extraction cache entry", "error", err)
+	}
+}
+
+func (c *ExtractionCache) path(hash string) string {
+	sum := sha256.Sum256([]byte(c.model + "\x00" + hash))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// writeEntry writes entry as JSON to path via a temporary file and rename,
+// since files with the same content may be extracted at the same time.
+func writeEntry(path string, entry extractionEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ClearExtractionCache removes all cached extractions under dir and returns
+// how many entries were removed.
+func ClearExtractionCache(dir string) (int, error) {
+	extractions := filepath.Join(dir, extractionsDir)
+	entries, err := os.ReadDir(extractions)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read extraction cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(extractions, e.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove extraction cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+func TestExtractionCache(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package a"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	cacheDir := t.TempDir()
+	newProvider := func() *llmtest.Provider {
+		return &llmtest.Provider{Respond: func(prompt string) (string, error) {
+			return `{"summary": "Declares package a.", "abstractions": [{"name": "Package A", "description": "The package"}]}`, nil
+		}}
+	}
+	extract := func(cache *ExtractionCache, files ...File) (*Analysis, int) {
+		t.Helper()
+		provider := newProvider()
+		a := &Analysis{ProjectName: "demo", Files: files}
+		e := &Extractor{Provider: provider, Root: root, Cache: cache}
+		if err := e.Extract(context.Background(), a); err != nil {
+			t.Fatalf("Extract() error = %v", err)
+		}
+		return a, len(provider.Prompts())
+	}
+
+	if _, calls := extract(NewExtractionCache(cacheDir, "model-a", false), File{Path: "a.go", Hash: "h1"}); calls != 1 {
+		t.Fatalf("Expected the first extraction to call the LLM once, got %d calls", calls)
+	}
+
+	// The same content is reused under any path, by any later analysis
+	a, calls := extract(NewExtractionCache(cacheDir, "model-a", false), File{Path: "b.go", Hash: "h1"}, File{Path: "a.go", Hash: "h2"})
+	if calls != 1 {
+		t.Errorf("Expected only the changed file to be extracted, got %d calls", calls)
+	}
+	if a.Files[0].Summary != "Declares package a." || len(a.Abstractions) != 1 || len(a.Abstractions[0].Files) != 2 {
+		t.Errorf("Expected the cached knowledge to be merged like extracted knowledge, got %+v and %+v", a.Files, a.Abstractions)
+	}
+
+	// Another model, a refresh or no cache extract the file again
+	for name, cache := range map[string]*ExtractionCache{
+		"other model": NewExtractionCache(cacheDir, "model-b", false),
+		"refresh":     NewExtractionCache(cacheDir, "model-a", true),
+		"no cache":    nil,
+	} {
+		if _, calls := extract(cache, File{Path: "a.go", Hash: "h1"}); calls != 1 {
+			t.Errorf("%s: expected the file to be extracted, got %d calls", name, calls)
+		}
+	}
+
+	removed, err := ClearExtractionCache(cacheDir)
+	if err != nil || removed != 3 {
+		t.Errorf("ClearExtractionCache() = %d, %v; want 3, nil", removed, err)
+	}
+	if removed, err := ClearExtractionCache(filepath.Join(cacheDir, "missing")); err != nil || removed != 0 {
+		t.Errorf("ClearExtractionCache() on a missing directory = %d, %v", removed, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import "strings"
+
+// Diff lists how an analysis differs from an older one of the same codebase.
+// Abstractions are matched by name (ignoring case), relationships by their
+// endpoints and label (so a relabeled relationship is removed and added),
+// and files by path.
+type Diff struct {
+	AddedAbstractions    []string       `json:"added_abstractions"`
+	RemovedAbstractions  []string       `json:"removed_abstractions"`
+	AddedRelationships   []Relationship `json:"added_relationships"`
+	RemovedRelationships []Relationship `json:"removed_relationships"`
+	AddedFiles           []string       `json:"added_files"`   // Files that entered the scope
+	RemovedFiles         []string       `json:"removed_files"` // Files that left the scope
+}
+
+// Compare returns the differences from older to newer. Each list keeps the
+// order of the analysis the entries come from.
+func Compare(older, newer *Analysis) Diff {
+	abstractionKey := func(abs Abstraction) string { return strings.ToLower(abs.Name) }
+	relationshipKey := func(rel Relationship) string {
+		return strings.ToLower(rel.From) + "\x00" + strings.ToLower(rel.To) + "\x00" + rel.Label
+	}
+	fileKey := func(f File) string { return f.Path }
+
+	var d Diff
+	d.AddedAbstractions = names(missing(newer.Abstractions, older.Abstractions, abstractionKey))
+	d.RemovedAbstractions = names(missing(older.Abstractions, newer.Abstractions, abstractionKey))
+	d.AddedRelationships = missing(newer.Relationships, older.Relationships, relationshipKey)
+	d.RemovedRelationships = missing(older.Relationships, newer.Relationships, relationshipKey)
+	d.AddedFiles = paths(missing(newer.Files, older.Files, fileKey))
+	d.RemovedFiles = paths(missing(older.Files, newer.Files, fileKey))
+	return d
+}
+
+// Empty reports whether there are no differences.
+func (d Diff) Empty() bool {
+	return len(d.AddedAbstractions)+len(d.RemovedAbstractions)+len(d.AddedRelationships)+
+		len(d.RemovedRelationships)+len(d.AddedFiles)+len(d.RemovedFiles) == 0
+}
+
+// missing returns the items of from whose key is not in other. The result is
+// never nil, so that empty lists are encoded as [] rather than null.
+func missing[T any](from, other []T, key func(T) string) []T {
+	seen := make(map[string]bool, len(other))
+	for _, item := range other {
+		seen[key(item)] = true
+	}
+	result := []T{}
+	for _, item := range from {
+		if !seen[key(item)] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func names(abstractions []Abstraction) []string {
+	result := make([]string, len(abstractions))
+	for i, abs := range abstractions {
+		result[i] = abs.Name
+	}
+	return result
+}
+
+func paths(files []File) []string {
+	result := make([]string, len(files))
+	for i, f := range files {
+		result[i] = f.Path
+	}
+	return result
+}
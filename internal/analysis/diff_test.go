@@ -0,0 +1,41 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	older := &Analysis{
+		Files:         []File{{Path: "a.go"}, {Path: "b.go"}},
+		Abstractions:  []Abstraction{{Name: "Config"}, {Name: "Legacy"}},
+		Relationships: []Relationship{{From: "Config", To: "Legacy", Label: "wraps"}},
+	}
+	newer := &Analysis{
+		Files:         []File{{Path: "a.go"}, {Path: "c.go"}},
+		Abstractions:  []Abstraction{{Name: "config"}, {Name: "Plugin"}},
+		Relationships: []Relationship{{From: "Config", To: "Plugin", Label: "loads"}},
+	}
+
+	got := Compare(older, newer)
+	want := Diff{
+		AddedAbstractions:    []string{"Plugin"},
+		RemovedAbstractions:  []string{"Legacy"},
+		AddedRelationships:   []Relationship{{From: "Config", To: "Plugin", Label: "loads"}},
+		RemovedRelationships: []Relationship{{From: "Config", To: "Legacy", Label: "wraps"}},
+		AddedFiles:           []string{"c.go"},
+		RemovedFiles:         []string{"b.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %+v, want %+v", got, want)
+	}
+	if got.Empty() {
+		t.Error("Expected differences")
+	}
+
+	if same := Compare(newer, newer); !same.Empty() {
+		t.Errorf("Expected no differences comparing an analysis with itself, got %+v", same)
+	}
+}
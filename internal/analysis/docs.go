@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+// maxDocsContext is the most bytes of documentation quoted in the prompt
+// identifying the core abstractions, so that a long manual does not crowd
+// out the file summaries.
+const maxDocsContext = 12 * 1024
+
+// docExtensions are the extensions of the files under docs/ that IsDoc
+// recognizes as documentation.
+var docExtensions = map[string]bool{".md": true, ".markdown": true, ".rst": true, ".txt": true, ".adoc": true}
+
+// IsDoc reports whether the slash-separated path p is one of the project's
+// own documents: a README or CONTRIBUTING file at the top level, or a text
+// document under the top-level docs/ directory.
+func IsDoc(p string) bool {
+	if dir, rest, ok := strings.Cut(p, "/"); ok {
+		return strings.EqualFold(dir, "docs") && docExtensions[strings.ToLower(path.Ext(rest))]
+	}
+	return docRank(p) < 2
+}
+
+// docRank orders documents by how much they tell about the project as a
+// whole: READMEs, then CONTRIBUTING files, then the rest.
+func docRank(p string) int {
+	base := strings.ToLower(p)
+	switch {
+	case strings.HasPrefix(base, "readme"):
+		return 0
+	case strings.HasPrefix(base, "contributing"):
+		return 1
+	}
+	return 2
+}
+
+// Docs returns the files of a marked as documents (see IsDoc), READMEs
+// first, then CONTRIBUTING files, then those under docs/, by path.
+func (a *Analysis) Docs() []File {
+	var docs []File
+	for _, f := range a.Files {
+		if f.Docs {
+			docs = append(docs, f)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if ri, rj := docRank(docs[i].Path), docRank(docs[j].Path); ri != rj {
+			return ri < rj
+		}
+		return docs[i].Path < docs[j].Path
+	})
+	return docs
+}
+
+// docExcerpts returns the start of the documents of a, read from e.Root, to
+// ground the identification of its core abstractions. Together they hold at
+// most maxDocsContext bytes, cut at a line boundary; documents that cannot
+// be read are left out.
+func (e *Extractor) docExcerpts(a *Analysis) []prompts.DocExcerpt {
+	var excerpts []prompts.DocExcerpt
+	budget := maxDocsContext
+	for _, doc := range a.Docs() {
+		if budget <= 0 {
+			break
+		}
+		content, err := os.ReadFile(filepath.Join(e.Root, filepath.FromSlash(doc.Path)))
+		if err != nil {
+			slog.Warn("Could not read documentation; leaving it out of the prompt", "path", doc.Path, "error", err)
+			continue
+		}
+		text := strings.TrimSpace(string(content))
+		if text == "" {
+			continue
+		}
+		if len(text) > budget {
+			text = text[:budget]
+			if i := strings.LastIndexByte(text, '\n'); i > 0 {
+				text = text[:i]
+			}
+			text += "\n[...]"
+		}
+		budget -= len(text)
+		excerpts = append(excerpts, prompts.DocExcerpt{Path: doc.Path, Content: text})
+	}
+	return excerpts
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+func TestIsDoc(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"README.md", true},
+		{"readme", true},
+		{"README.rst", true},
+		{"CONTRIBUTING.md", true},
+		{"docs/guide.md", true},
+		{"docs/api/index.rst", true},
+		{"Docs/intro.adoc", true},
+		{"docs/diagram.png", false},
+		{"docs/gen.go", false},
+		{"cmd/README.md", false},
+		{"main.go", false},
+		{"LICENSE", false},
+	}
+	for _, tt := range tests {
+		if got := IsDoc(tt.path); got != tt.want {
+			t.Errorf("IsDoc(%q) = %v, expected %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDocs(t *testing.T) {
+	a := &Analysis{Files: []File{
+		{Path: "docs/b.md", Docs: true},
+		{Path: "CONTRIBUTING.md", Docs: true},
+		{Path: "main.go"},
+		{Path: "docs/a.md", Docs: true},
+		{Path: "README.md", Docs: true},
+	}}
+	var got []string
+	for _, doc := range a.Docs() {
+		got = append(got, doc.Path)
+	}
+	want := "README.md CONTRIBUTING.md docs/a.md docs/b.md"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected docs %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestIdentifyAbstractionsDocsContext(t *testing.T) {
+	root := t.TempDir()
+	long := strings.Repeat("Lorem ipsum dolor sit amet.\n", maxDocsContext/20)
+	for name, content := range map[string]string{
+		"README.md":    "# Demo\n\nDemo is built around its Loader.",
+		"docs/long.md": long,
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	response := `{"abstractions": [{"name": "Loader", "description": "d", "files": ["loader.go"]}]}`
+
+	for _, enabled := range []bool{true, false} {
+		a := candidateAnalysis()
+		a.Files = append(a.Files,
+			File{Path: "docs/long.md", Docs: true},
+			File{Path: "docs/missing.md", Docs: true},
+			File{Path: "README.md", Docs: true},
+		)
+		provider := &llmtest.Provider{Respond: func(string) (string, error) { return response, nil }}
+		e := &Extractor{Provider: provider, Root: root, DocsContext: enabled}
+		if err := e.IdentifyAbstractions(context.Background(), a); err != nil {
+			t.Fatalf("IdentifyAbstractions() error = %v", err)
+		}
+		prompt := provider.Prompts()[0]
+		readme := strings.Index(prompt, "Demo is built around its Loader.")
+		if !enabled {
+			if readme >= 0 || strings.Contains(prompt, "--- docs/long.md ---") {
+				t.Errorf("Expected no documentation in the prompt without DocsContext, got:\n%s", prompt)
+			}
+			continue
+		}
+		if readme < 0 || readme > strings.Index(prompt, "--- docs/long.md ---") {
+			t.Errorf("Expected the README before the other docs in the prompt, got:\n%s", prompt)
+		}
+		if !strings.Contains(prompt, "amet.\n[...]") {
+			t.Errorf("Expected the long document to be cut at a line, got:\n%s", prompt)
+		}
+		if strings.Count(prompt, "Lorem") >= strings.Count(long, "Lorem") {
+			t.Errorf("Expected at most %d bytes of documentation in the prompt", maxDocsContext)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/progress"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+const (
+	// maxAbstractionsPerFile caps how many abstractions are requested per file.
+	maxAbstractionsPerFile = 3
+	// maxFileParts caps how many parts a file too large for the context
+	// window is extracted in; the middle of larger files (e.g., data dumps)
+	// is left out rather than costing dozens of requests.
+	maxFileParts = 8
+	// maxFailurePercent is the share of files that may fail to be analyzed
+	// before the extraction is aborted.
+	maxFailurePercent = 25
+)
+
+// Extractor uses an LLM to extract knowledge from the files of a codebase.
+type Extractor struct {
+	Provider llm.Provider
+	Options  llm.CompletionOptions // Sent with every extraction request
+	Root     string                // Local directory that the analysis file paths are relative to
+	Progress progress.Reporter     // Receives per-file progress; nil reports nothing
+	Prompts  *prompts.Set          // Prompt templates; nil uses prompts.Default()
+
+	// Concurrency is the number of files analyzed in parallel; values below 1
+	// analyze one file at a time. Rate limited requests are retried by the
+	// provider (see llm.RetryingProvider), so a high value slows down rather
+	// than fails when the provider pushes back.
+	Concurrency int
+
+	// MaxAbstractions caps how many core abstractions IdentifyAbstractions
+	// keeps; values below 1 use DefaultMaxAbstractions.
+	MaxAbstractions int
+
+	// ContextWindow is the model's context window in tokens. A file whose
+	// extraction prompt would not fit, leaving room for the response (see
+	// llm.PromptBudget), is split into parts that are extracted separately,
+	// leaving out its middle if it needs more than maxFileParts of them; 0
+	// sends every file whole.
+	ContextWindow int
+
+	// Tokenizer counts the tokens a prompt occupies for the context window
+	// guard; nil uses llm.HeuristicTokenizer.
+	Tokenizer llm.Tokenizer
+
+	// Cache reuses the knowledge extracted from files with the same content
+	// by earlier runs; nil extracts every file.
+	Cache *ExtractionCache
+
+	// DocsContext quotes the start of the project's documents (see
+	// Analysis.Docs) in the prompt identifying its core abstractions, so
+	// that they match how the project describes itself.
+	DocsContext bool
+}
+
+// fileKnowledge is the structured response expected for each file.
+type fileKnowledge struct {
+	Summary      string `json:"summary"`
+	Abstractions []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"abstractions"`
+}
+
+// Extract summarizes each file of a and derives the codebase's abstractions
+// from the per-file results. Up to Concurrency files are sent to the LLM at
+// once, but the results are merged in file order, so abstractions with the
+// same name (ignoring case) are merged in order of first appearance no matter
+// which request finishes first.
+//
+// A file that cannot be analyzed, even after asking again for a response
+// that was empty or held no valid JSON, is logged, left without a summary,
+// and recorded in a.Failed with the reason. The whole extraction fails if
+// the context is canceled, if an error shows the provider cannot serve any
+// request (e.g., a rejected API key), if more than a quarter of the files
+// fail, or if no file could be analyzed at all.
+func (e *Extractor) Extract(ctx context.Context, a *Analysis) error {
+	pending := make([]int, len(a.Files))
+	for i := range pending {
+		pending[i] = i
+	}
+	return e.extract(ctx, a, pending)
+}
+
+// ExtractIncremental is like Extract, but reuses the results of baseline (a
+// previous analysis of the same codebase) for the files whose content hash
+// is unchanged, so that only new and changed files are sent to the LLM.
+// Abstractions are kept only for the files that remain unchanged before the
+// other files are merged in.
+func (e *Extractor) ExtractIncremental(ctx context.Context, a, baseline *Analysis) (Changes, error) {
+	changes, pending := carryOver(a, baseline)
+	return changes, e.extract(ctx, a, pending)
+}
+
+// ExtractStream is like Extract, but takes the files to analyze from files
+// as they arrive (e.g., as a scanner finds them) until it is closed,
+// appending them to a.Files in the order received. The capacity of files
+// bounds how far the sender runs ahead of the extraction, so a codebase need
+// not be listed before its first file is sent to the LLM. As the number of
+// files is only known at the end, so is whether more than a quarter of them
+// failed. ExtractStream stops receiving once ctx is canceled or the
+// extraction fails, so the sender should give up once ctx is done, which the
+// caller cancels after ExtractStream returns.
+func (e *Extractor) ExtractStream(ctx context.Context, a *Analysis, files <-chan File) error {
+	base := len(a.Files)
+	ext, err := e.extractFiles(ctx, a.ProjectName, files, -1)
+	if err != nil {
+		return err
+	}
+	a.Files = append(a.Files, ext.files...)
+	return ext.merge(a, func(j int) *File { return &a.Files[base+j] })
+}
+
+// extract extracts knowledge from the files of a at the pending indexes,
+// merging their abstractions into a.Abstractions.
+func (e *Extractor) extract(ctx context.Context, a *Analysis, pending []int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	files := make(chan File)
+	go func() {
+		defer close(files)
+		for _, i := range pending {
+			select {
+			case files <- a.Files[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	ext, err := e.extractFiles(ctx, a.ProjectName, files, len(pending))
+	if err != nil {
+		return err
+	}
+	return ext.merge(a, func(j int) *File { return &a.Files[pending[j]] })
+}
+
+// extraction is the knowledge extracted from files, each at the index it
+// was received at.
+type extraction struct {
+	files    []File
+	results  []*fileKnowledge // nil for the files that failed
+	failures []error
+}
+
+// extractFiles extracts knowledge from the files received from files, up to
+// Concurrency at once, until it is closed. total is the number of files to
+// expect, which the progress is reported against and the share of failures
+// checked against as they happen, or -1 if unknown, in which case the share
+// is only checked at the end.
+func (e *Extractor) extractFiles(ctx context.Context, project string, files <-chan File, total int) (*extraction, error) {
+	reporter := e.Progress
+	if reporter == nil {
+		reporter = progress.Nop{}
+	}
+	defer reporter.Finish()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ext := &extraction{}
+	workers := max(1, e.Concurrency)
+	if total >= 0 {
+		workers = max(1, min(e.Concurrency, total))
+	}
+	// expected is the number of files progress is reported against: those
+	// received so far if the total is unknown
+	expected := func() int {
+		if total >= 0 {
+			return total
+		}
+		return len(ext.files)
+	}
+
+	type job struct {
+		j    int // Index of the file in ext
+		file File
+	}
+	var (
+		mu      sync.Mutex
+		done    int
+		cached  int
+		failed  int
+		fatal   error
+		lastErr error
+		wg      sync.WaitGroup
+	)
+	jobs := make(chan job)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				path := job.file.Path
+				slog.Debug("Extracting knowledge", "path", path)
+				mu.Lock()
+				reporter.Update("extracting", done, expected(), path)
+				mu.Unlock()
+
+				knowledge, hit := e.Cache.lookup(job.file.Hash)
+				var err error
+				if !hit {
+					if knowledge, err = e.extractFile(ctx, project, job.file); err == nil {
+						e.Cache.store(job.file.Hash, knowledge)
+					}
+				}
+
+				mu.Lock()
+				done++
+				if hit {
+					cached++
+				}
+				if err != nil && fatal == nil {
+					ext.failures[job.j] = err
+					failed++
+					err = fmt.Errorf("extracting %s: %w", path, err)
+					switch {
+					case llm.IsFatal(err):
+						fatal = err
+					case total >= 0 && failed > maxFailures(total):
+						fatal = fmt.Errorf("aborting after %d of %d files failed: %w", failed, total, err)
+					default:
+						lastErr = err
+						slog.Warn("Skipping file that could not be analyzed", "path", path, "error", err)
+					}
+					if fatal != nil {
+						cancel()
+					}
+				}
+				ext.results[job.j] = knowledge
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for {
+		select {
+		case file, ok := <-files:
+			if !ok {
+				break feed
+			}
+			mu.Lock()
+			j := len(ext.files)
+			ext.files = append(ext.files, file)
+			ext.results = append(ext.results, nil)
+			ext.failures = append(ext.failures, nil)
+			mu.Unlock()
+			select {
+			case jobs <- job{j: j, file: file}:
+			case <-ctx.Done():
+				break feed
+			}
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	n := len(ext.files)
+	if fatal == nil && total < 0 && failed < n && failed > maxFailures(n) {
+		fatal = fmt.Errorf("aborting after %d of %d files failed: %w", failed, n, lastErr)
+	}
+	if fatal != nil {
+		return nil, fatal
+	}
+	if cached > 0 {
+		slog.Info("Reused cached extractions of unchanged files", "files", cached, "hint", "pass --refresh to extract them again")
+	}
+	reporter.Update("extracted", n, n, "")
+	return ext, nil
+}
+
+// maxFailures returns how many of total files may fail to be analyzed
+// before the extraction is aborted.
+func maxFailures(total int) int {
+	return max(1, total*maxFailurePercent/100)
+}
+
+// merge records ext in a: the summaries of the files fileAt returns by
+// index, the files that failed, and the abstractions found, merged into
+// a.Abstractions in file order. It fails if no file could be analyzed.
+func (ext *extraction) merge(a *Analysis, fileAt func(j int) *File) error {
+	failed := 0
+	for _, err := range ext.failures {
+		if err != nil {
+			failed++
+		}
+	}
+	total := len(ext.files)
+	if failed == total && total > 0 {
+		failures := make([]error, total)
+		for j, err := range ext.failures {
+			failures[j] = fmt.Errorf("extracting %s: %w", ext.files[j].Path, err)
+		}
+		return fmt.Errorf("no file could be analyzed: %w", errors.Join(failures...))
+	}
+	if failed > 0 {
+		a.Failed = make(map[string]string, failed)
+		var paths []string
+		for j, err := range ext.failures {
+			if err != nil {
+				path := ext.files[j].Path
+				a.Failed[path] = err.Error()
+				paths = append(paths, path)
+			}
+		}
+		slog.Warn("Some files could not be analyzed; re-run with --incremental to retry them", "failed", failed, "files", total, "paths", strings.Join(paths, ", "))
+	}
+
+	index := make(map[string]int) // Lowercase abstraction name -> position in a.Abstractions
+	for i, abs := range a.Abstractions {
+		index[strings.ToLower(abs.Name)] = i
+	}
+	for j, knowledge := range ext.results {
+		if knowledge == nil {
+			continue
+		}
+		file := fileAt(j)
+		file.Summary = strings.TrimSpace(knowledge.Summary)
+
+		for _, found := range knowledge.Abstractions {
+			name := strings.TrimSpace(found.Name)
+			if name == "" {
+				continue
+			}
+			key := strings.ToLower(name)
+			if pos, ok := index[key]; ok {
+				a.Abstractions[pos].Files = append(a.Abstractions[pos].Files, file.Path)
+				continue
+			}
+			index[key] = len(a.Abstractions)
+			a.Abstractions = append(a.Abstractions, Abstraction{
+				Name:        name,
+				Description: strings.TrimSpace(found.Description),
+				Files:       []string{file.Path},
+			})
+		}
+	}
+	return nil
+}
+
+// ExtractionPrompts returns the prompts Extract would send for the files of
+// a, without calling the provider: for each file, in order, its prompt, or
+// those of its parts if it is too large for the context window.
+func (e *Extractor) ExtractionPrompts(a *Analysis) ([][]string, error) {
+	all := make([][]string, 0, len(a.Files))
+	for _, file := range a.Files {
+		requests, err := e.fileRequests(a.ProjectName, file)
+		if err != nil {
+			return nil, fmt.Errorf("rendering the extraction prompt of %s: %w", file.Path, err)
+		}
+		all = append(all, requests)
+	}
+	return all, nil
+}
+
+// extractFile asks the LLM for the summary and abstractions of a single file.
+func (e *Extractor) extractFile(ctx context.Context, project string, file File) (*fileKnowledge, error) {
+	requests, err := e.fileRequests(project, file)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*fileKnowledge, 0, len(requests))
+	for _, prompt := range requests {
+		knowledge, err := completeJSON(ctx, e, prompt, checkKnowledge)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, knowledge)
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return mergeKnowledge(parts), nil
+}
+
+// fileRequests reads file and renders its extraction prompts (see filePrompts).
+func (e *Extractor) fileRequests(project string, file File) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(e.Root, filepath.FromSlash(file.Path)))
+	if err != nil {
+		return nil, err
+	}
+
+	templates := e.Prompts
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	language := file.Language
+	if language == "unknown" {
+		language = ""
+	}
+	return e.filePrompts(templates, prompts.ExtractData{
+		Project:         project,
+		Path:            file.Path,
+		Language:        language,
+		Content:         string(content),
+		MaxAbstractions: maxAbstractionsPerFile,
+	})
+}
+
+// checkKnowledge rejects a response without a summary, which the file
+// would otherwise be left without.
+func checkKnowledge(k *fileKnowledge) error {
+	if strings.TrimSpace(k.Summary) == "" {
+		return errors.New("response has no summary")
+	}
+	return nil
+}
+
+// filePrompts renders the extraction prompts for a file: a single prompt
+// holding the whole file, unless it does not fit the context window, in
+// which case the file is split at line boundaries into parts that do, after
+// trimming its middle if it takes more than maxFileParts of them.
+func (e *Extractor) filePrompts(templates *prompts.Set, data prompts.ExtractData) ([]string, error) {
+	prompt, err := templates.Extract(data)
+	if err != nil {
+		return nil, err
+	}
+	if e.ContextWindow <= 0 {
+		return []string{prompt}, nil
+	}
+	tokenizer := e.Tokenizer
+	if tokenizer == nil {
+		tokenizer = llm.HeuristicTokenizer
+	}
+	count := tokenizer.CountTokens
+	budget := llm.PromptBudget(e.ContextWindow, e.Options.MaxTokens) - count(e.Options.System)
+	tokens := count(prompt)
+	if tokens <= budget {
+		return []string{prompt}, nil
+	}
+
+	// The room left for content is what the prompt needs without it, with
+	// part numbers as wide as they can get
+	content := data.Content
+	data.Content, data.Part, data.Parts = "", len(content), len(content)
+	overhead, err := templates.Extract(data)
+	if err != nil {
+		return nil, err
+	}
+	room := budget - count(overhead)
+	if room <= 0 {
+		return nil, fmt.Errorf("the extraction prompt leaves no room for the file in the %d-token context window", e.ContextWindow)
+	}
+
+	if limit := room * maxFileParts; count(content) > limit {
+		trimmed := llm.TrimToTokens(content, limit, tokenizer)
+		slog.Warn("File is too large to extract whole; leaving out its middle", "path", data.Path,
+			"tokens", count(content), "kept", count(trimmed), "elided", count(content)-count(trimmed))
+		content = trimmed
+	}
+	chunks := splitContent(content, room, count)
+	slog.Warn("File exceeds the model's context window; extracting it in parts", "path", data.Path, "tokens", tokens, "budget", budget, "parts", len(chunks))
+	requests := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		data.Content, data.Part, data.Parts = chunk, i+1, len(chunks)
+		prompt, err := templates.Extract(data)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, prompt)
+	}
+	return requests, nil
+}
+
+// splitContent splits content into chunks of at most room tokens, as
+// estimated by count, cutting between lines. A line too long for a chunk of
+// its own is cut between characters.
+func splitContent(content string, room int, count func(string) int) []string {
+	var chunks []string
+	var chunk strings.Builder
+	used := 0
+	flush := func() {
+		if chunk.Len() > 0 {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+			used = 0
+		}
+	}
+	for _, line := range strings.SplitAfter(content, "\n") {
+		n := count(line)
+		if used+n > room {
+			flush()
+		}
+		for n > room {
+			// Cut the line where the share of its tokens that fits ends, backing
+			// off until the head fits in case the tokens are unevenly spread
+			runes := []rune(line)
+			cut := max(1, len(runes)*room/n)
+			for cut > 1 && count(string(runes[:cut])) > room {
+				cut = cut * 3 / 4
+			}
+			chunks = append(chunks, string(runes[:cut]))
+			line = string(runes[cut:])
+			n = count(line)
+		}
+		chunk.WriteString(line)
+		used += n
+	}
+	flush()
+	return chunks
+}
+
+// mergeKnowledge combines the knowledge extracted from the parts of a file:
+// their summaries in order, and their abstractions merged by name (ignoring
+// case) up to maxAbstractionsPerFile.
+func mergeKnowledge(parts []*fileKnowledge) *fileKnowledge {
+	merged := &fileKnowledge{}
+	var summaries []string
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		if summary := strings.TrimSpace(part.Summary); summary != "" && !slices.Contains(summaries, summary) {
+			summaries = append(summaries, summary)
+		}
+		for _, abs := range part.Abstractions {
+			key := strings.ToLower(strings.TrimSpace(abs.Name))
+			if key == "" || seen[key] || len(merged.Abstractions) == maxAbstractionsPerFile {
+				continue
+			}
+			seen[key] = true
+			merged.Abstractions = append(merged.Abstractions, abs)
+		}
+	}
+	merged.Summary = strings.Join(summaries, " ")
+	return merged
+}
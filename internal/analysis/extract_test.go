@@ -0,0 +1,498 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+// recordingReporter is a progress.Reporter that records the updates it receives.
+type recordingReporter struct {
+	mu       sync.Mutex
+	updates  []string
+	finished bool
+}
+
+func (r *recordingReporter) Update(phase string, done, total int, item string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, fmt.Sprintf("%s %d/%d %s", phase, done, total, item))
+}
+
+func (r *recordingReporter) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = true
+}
+
+func TestExtract(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"config.go": "package config // CONFIG",
+		"loader.go": "package config // LOADER",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "CONFIG") {
+			return "```json\n{\"summary\": \"Defines the config.\", \"abstractions\": [{\"name\": \"Config\", \"description\": \"Settings\"}]}\n```", nil
+		}
+		return `Here you go: {"summary": "Loads the config.", "abstractions": [{"name": "config", "description": "dup"}, {"name": "Loader", "description": "Reads files"}]}`, nil
+	}}
+
+	a := &Analysis{
+		ProjectName: "demo",
+		Files:       []File{{Path: "config.go", Language: "Go"}, {Path: "loader.go"}},
+	}
+	reporter := &recordingReporter{}
+	e := &Extractor{Provider: provider, Root: root, Progress: reporter}
+	if err := e.Extract(context.Background(), a); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	wantUpdates := []string{"extracting 0/2 config.go", "extracting 1/2 loader.go", "extracted 2/2 "}
+	if !reflect.DeepEqual(reporter.updates, wantUpdates) || !reporter.finished {
+		t.Errorf("Progress updates = %q (finished %v), want %q", reporter.updates, reporter.finished, wantUpdates)
+	}
+
+	if a.Files[0].Summary != "Defines the config." || a.Files[1].Summary != "Loads the config." {
+		t.Errorf("Unexpected summaries: %+v", a.Files)
+	}
+	want := []Abstraction{
+		{Name: "Config", Description: "Settings", Files: []string{"config.go", "loader.go"}},
+		{Name: "Loader", Description: "Reads files", Files: []string{"loader.go"}},
+	}
+	if !reflect.DeepEqual(a.Abstractions, want) {
+		t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, want)
+	}
+	prompts := provider.Prompts()
+	if len(prompts) != 2 || !strings.Contains(prompts[0], `"demo"`) {
+		t.Fatalf("Unexpected prompts: %v", prompts)
+	}
+	if !strings.Contains(prompts[0], "File: config.go\nLanguage: Go\n") || strings.Contains(prompts[1], "Language:") {
+		t.Errorf("Expected only the first prompt to name the language, got %q", prompts)
+	}
+}
+
+func TestExtractInvalidResponse(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	provider := &llmtest.Provider{Respond: func(string) (string, error) { return "I cannot help with that.", nil }}
+	a := &Analysis{Files: []File{{Path: "a.go"}}}
+	e := &Extractor{Provider: provider, Root: root}
+	if err := e.Extract(context.Background(), a); err == nil {
+		t.Error("Extract() expected an error for a response without JSON")
+	}
+}
+
+func TestExtractUnusableResponses(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"empty.go", "malformed.go", "broken.go", "fine.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("// "+strings.ToUpper(name)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// EMPTY.GO and MALFORMED.GO answer properly when asked again; BROKEN.GO never does
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		retried := strings.Contains(prompt, "Your previous answer could not be used")
+		switch {
+		case strings.Contains(prompt, "BROKEN.GO"):
+			return `{"summary": "Truncated",}`, nil
+		case strings.Contains(prompt, "EMPTY.GO") && !retried:
+			return "  \n", nil
+		case strings.Contains(prompt, "MALFORMED.GO") && !retried:
+			return `{"abstractions": []}`, nil
+		}
+		return `{"summary": "Works.", "abstractions": []}`, nil
+	}}
+	a := &Analysis{Files: []File{{Path: "empty.go"}, {Path: "malformed.go"}, {Path: "broken.go"}, {Path: "fine.go"}}}
+	e := &Extractor{Provider: provider, Root: root}
+	if err := e.Extract(context.Background(), a); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	for _, f := range a.Files {
+		if want := f.Path != "broken.go"; (f.Summary == "Works.") != want {
+			t.Errorf("Unexpected summary for %s: %q", f.Path, f.Summary)
+		}
+	}
+	if len(a.Failed) != 1 || !strings.Contains(a.Failed["broken.go"], "invalid JSON") {
+		t.Errorf("Expected broken.go to be recorded as failed, got %v", a.Failed)
+	}
+	if n := len(provider.Prompts()); n != 7 {
+		t.Errorf("Expected 7 requests (one retry for each of 3 files), got %d", n)
+	}
+
+	baseline := &Analysis{Files: []File{{Path: "fine.go", Hash: "h1", Summary: "Works."}, {Path: "broken.go", Hash: "h2"}}, Failed: map[string]string{"broken.go": "invalid JSON"}}
+	next := &Analysis{Files: []File{{Path: "fine.go", Hash: "h1"}, {Path: "broken.go", Hash: "h2"}}}
+	changes, pending := carryOver(next, baseline)
+	if want := (Changes{Changed: 1, Unchanged: 1}); changes != want || !reflect.DeepEqual(pending, []int{1}) {
+		t.Errorf("Expected the failed file to be extracted again, got %+v and pending %v", changes, pending)
+	}
+}
+
+func TestExtractSplitsOversizedFiles(t *testing.T) {
+	root := t.TempDir()
+	var big strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&big, "LINE %d\n", i)
+	}
+	for name, content := range map[string]string{"big.go": big.String(), "small.go": "LINE small"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		switch {
+		case strings.Contains(prompt, "(part 1 of 3"):
+			return `{"summary": "Starts big.", "abstractions": [{"name": "Big", "description": "First"}]}`, nil
+		case strings.Contains(prompt, "(part 2 of 3"):
+			return `{"summary": "Starts big.", "abstractions": [{"name": "big", "description": "Again"}, {"name": "Part", "description": "Middle"}]}`, nil
+		case strings.Contains(prompt, "(part 3 of 3"):
+			return `{"summary": "Ends big.", "abstractions": []}`, nil
+		}
+		return `{"summary": "Small.", "abstractions": []}`, nil
+	}}
+
+	// The fake counter only counts content lines, so that 4 fit in the 14 - 10 tokens left for the prompt
+	a := &Analysis{Files: []File{{Path: "big.go"}, {Path: "small.go"}}}
+	e := &Extractor{
+		Provider:      provider,
+		Root:          root,
+		Options:       llm.CompletionOptions{MaxTokens: 10},
+		ContextWindow: 14,
+		Tokenizer:     llm.TokenizerFunc(func(text string) int { return strings.Count(text, "LINE") }),
+	}
+	if err := e.Extract(context.Background(), a); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if got := len(provider.Prompts()); got != 4 {
+		t.Errorf("Expected 3 requests for big.go and 1 for small.go, got %d", got)
+	}
+	for _, prompt := range provider.Prompts() {
+		if strings.Contains(prompt, "(part 2 of 3") && !strings.Contains(prompt, "LINE 5\nLINE 6\nLINE 7\nLINE 8\n") {
+			t.Errorf("Expected part 2 to hold lines 5 to 8, got:\n%s", prompt)
+		}
+	}
+	if a.Files[0].Summary != "Starts big. Ends big." || a.Files[1].Summary != "Small." {
+		t.Errorf("Unexpected summaries: %+v", a.Files)
+	}
+	want := []Abstraction{
+		{Name: "Big", Description: "First", Files: []string{"big.go"}},
+		{Name: "Part", Description: "Middle", Files: []string{"big.go"}},
+	}
+	if !reflect.DeepEqual(a.Abstractions, want) {
+		t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, want)
+	}
+
+	// The preview renders the same prompts without a provider
+	e.Provider = nil
+	previews, err := e.ExtractionPrompts(a)
+	if err != nil {
+		t.Fatalf("ExtractionPrompts() error = %v", err)
+	}
+	var all []string
+	for _, file := range previews {
+		all = append(all, file...)
+	}
+	if len(previews) != 2 || len(previews[0]) != 3 || !reflect.DeepEqual(all, provider.Prompts()) {
+		t.Errorf("Expected the 3 prompts of big.go and the one of small.go, got %q", previews)
+	}
+}
+
+func TestExtractTrimsHugeFiles(t *testing.T) {
+	root := t.TempDir()
+	var huge strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&huge, "LINE %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(root, "huge.sql"), []byte(huge.String()), 0644); err != nil {
+		t.Fatalf("Failed to write huge.sql: %v", err)
+	}
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		return `{"summary": "Data.", "abstractions": []}`, nil
+	}}
+
+	// 4 lines fit in a part, so maxFileParts parts hold the first and last 16
+	a := &Analysis{Files: []File{{Path: "huge.sql"}}}
+	e := &Extractor{
+		Provider:      provider,
+		Root:          root,
+		Options:       llm.CompletionOptions{MaxTokens: 10},
+		ContextWindow: 14,
+		Tokenizer:     llm.TokenizerFunc(func(text string) int { return strings.Count(text, "LINE") }),
+	}
+	if err := e.Extract(context.Background(), a); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	prompts := provider.Prompts()
+	if len(prompts) != maxFileParts {
+		t.Fatalf("Expected %d requests, got %d", maxFileParts, len(prompts))
+	}
+	all := strings.Join(prompts, "\n")
+	for _, s := range []string{"LINE 16\n", "[... 68 tokens elided ...]", "LINE 85\n", "LINE 100\n"} {
+		if !strings.Contains(all, s) {
+			t.Errorf("Expected the prompts to contain %q", s)
+		}
+	}
+	if strings.Contains(all, "LINE 17\n") || strings.Contains(all, "LINE 84\n") {
+		t.Errorf("Expected the middle of the file to be left out")
+	}
+}
+
+func TestSplitContent(t *testing.T) {
+	count := func(text string) int { return len(text) }
+	tests := []struct {
+		content string
+		room    int
+		want    []string
+	}{
+		{"ab\ncd\n", 10, []string{"ab\ncd\n"}},
+		{"ab\ncd\nef\n", 6, []string{"ab\ncd\n", "ef\n"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"ab\ncd\nefghij", 4, []string{"ab\n", "cd\n", "efgh", "ij"}},
+		{"", 4, nil},
+	}
+	for _, tt := range tests {
+		if got := splitContent(tt.content, tt.room, count); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitContent(%q, %d) = %q, want %q", tt.content, tt.room, got, tt.want)
+		}
+	}
+}
+
+func TestExtractConcurrent(t *testing.T) {
+	root := t.TempDir()
+	var files []File
+	for i := range 8 {
+		name := fmt.Sprintf("f%d.go", i)
+		if err := os.WriteFile(filepath.Join(root, name), []byte(fmt.Sprintf("package f // FILE%d", i)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		files = append(files, File{Path: name})
+	}
+
+	// Every file reports the shared abstraction, and earlier files answer
+	// last, so the merge order must not depend on completion order.
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		var n int
+		fmt.Sscanf(prompt[strings.Index(prompt, "// FILE")+len("// FILE"):], "%d", &n)
+		time.Sleep(time.Duration(8-n) * time.Millisecond)
+		if n == 3 {
+			return "not JSON", nil
+		}
+		return fmt.Sprintf(`{"summary": "File %d.", "abstractions": [{"name": "Shared", "description": "from %d"}]}`, n, n), nil
+	}}
+
+	a := &Analysis{Files: files}
+	e := &Extractor{Provider: provider, Root: root, Concurrency: 4}
+	if err := e.Extract(context.Background(), a); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if a.Files[0].Summary != "File 0." || a.Files[3].Summary != "" || a.Files[7].Summary != "File 7." {
+		t.Errorf("Unexpected summaries: %+v", a.Files)
+	}
+	want := []Abstraction{{
+		Name:        "Shared",
+		Description: "from 0",
+		Files:       []string{"f0.go", "f1.go", "f2.go", "f4.go", "f5.go", "f6.go", "f7.go"},
+	}}
+	if !reflect.DeepEqual(a.Abstractions, want) {
+		t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, want)
+	}
+}
+
+func TestExtractStream(t *testing.T) {
+	root := t.TempDir()
+	var files []File
+	for i := range 8 {
+		name := fmt.Sprintf("f%d.go", i)
+		if err := os.WriteFile(filepath.Join(root, name), []byte(fmt.Sprintf("package f // FILE%d", i)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		files = append(files, File{Path: name})
+	}
+	stream := func() <-chan File {
+		ch := make(chan File, 2)
+		go func() {
+			defer close(ch)
+			for _, f := range files {
+				ch <- f
+			}
+		}()
+		return ch
+	}
+	fileNumber := func(prompt string) int {
+		var n int
+		fmt.Sscanf(prompt[strings.Index(prompt, "// FILE")+len("// FILE"):], "%d", &n)
+		return n
+	}
+
+	// Earlier files answer last, but the files are added in the order sent
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		n := fileNumber(prompt)
+		time.Sleep(time.Duration(8-n) * time.Millisecond)
+		return fmt.Sprintf(`{"summary": "File %d.", "abstractions": [{"name": "Shared", "description": "from %d"}]}`, n, n), nil
+	}}
+	reporter := &recordingReporter{}
+	a := &Analysis{}
+	e := &Extractor{Provider: provider, Root: root, Concurrency: 4, Progress: reporter}
+	if err := e.ExtractStream(context.Background(), a, stream()); err != nil {
+		t.Fatalf("ExtractStream() error = %v", err)
+	}
+	if len(a.Files) != len(files) {
+		t.Fatalf("Expected %d files, got %+v", len(files), a.Files)
+	}
+	for i, f := range a.Files {
+		if want := fmt.Sprintf("File %d.", i); f.Path != files[i].Path || f.Summary != want {
+			t.Errorf("Expected file %d to be %s with summary %q, got %+v", i, files[i].Path, want, f)
+		}
+	}
+	if len(a.Abstractions) != 1 || a.Abstractions[0].Description != "from 0" || len(a.Abstractions[0].Files) != len(files) {
+		t.Errorf("Expected the shared abstraction of file 0 with every file, got %+v", a.Abstractions)
+	}
+	if !reporter.finished || reporter.updates[len(reporter.updates)-1] != "extracted 8/8 " {
+		t.Errorf("Expected the progress to end with every file, got %v", reporter.updates)
+	}
+
+	// The share of failures is checked once the stream ends
+	provider = &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		if fileNumber(prompt) < 3 {
+			return "", errors.New("overloaded")
+		}
+		return `{"summary": "Fine."}`, nil
+	}}
+	e = &Extractor{Provider: provider, Root: root, Concurrency: 4}
+	err := e.ExtractStream(context.Background(), &Analysis{}, stream())
+	if err == nil || !strings.Contains(err.Error(), "aborting after 3 of 8 files failed") {
+		t.Errorf("Expected the extraction to abort, got %v", err)
+	}
+}
+
+func TestExtractAborts(t *testing.T) {
+	root := t.TempDir()
+	var files []File
+	for i := range 8 {
+		name := fmt.Sprintf("f%d.go", i)
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package f"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		files = append(files, File{Path: name})
+	}
+
+	tests := []struct {
+		name    string
+		respond func(string) (string, error)
+		wantErr string
+	}{
+		{
+			name: "rejected API key",
+			respond: func(string) (string, error) {
+				return "", &llm.APIError{Provider: "fake", StatusCode: http.StatusUnauthorized, Message: "bad key"}
+			},
+			wantErr: "status 401",
+		},
+		{
+			name:    "too many failures",
+			respond: func(string) (string, error) { return "", errors.New("overloaded") },
+			wantErr: "aborting after 3 of 8 files failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &llmtest.Provider{Respond: tt.respond}
+			a := &Analysis{Files: append([]File(nil), files...)}
+			e := &Extractor{Provider: provider, Root: root}
+			err := e.Extract(context.Background(), a)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if n := len(provider.Prompts()); n == len(files) {
+				t.Errorf("Expected the extraction to stop early, got %d requests", n)
+			}
+		})
+	}
+}
+
+func TestExtractIncremental(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{"same.go": "package a", "changed.go": "package b // NEW", "added.go": "package c // NEW"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	baseline := &Analysis{
+		Files: []File{
+			{Path: "same.go", Hash: "h1", Summary: "Kept."},
+			{Path: "changed.go", Hash: "h2", Summary: "Outdated."},
+			{Path: "removed.go", Hash: "h3", Summary: "Gone."},
+		},
+		Abstractions: []Abstraction{
+			{Name: "Core", Description: "Kept", Files: []string{"same.go", "changed.go"}},
+			{Name: "Legacy", Description: "Gone", Files: []string{"removed.go"}},
+		},
+		Relationships: []Relationship{{From: "Core", To: "Legacy", Label: "wraps"}},
+	}
+	a := &Analysis{Files: []File{
+		{Path: "added.go", Hash: "h4"},
+		{Path: "changed.go", Hash: "h2-new"},
+		{Path: "same.go", Hash: "h1"},
+	}}
+
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		if !strings.Contains(prompt, "// NEW") {
+			return "", errors.New("unchanged file sent to the LLM")
+		}
+		return `{"summary": "Fresh.", "abstractions": [{"name": "core", "description": "dup"}, {"name": "Plugin", "description": "New"}]}`, nil
+	}}
+	e := &Extractor{Provider: provider, Root: root}
+	changes, err := e.ExtractIncremental(context.Background(), a, baseline)
+	if err != nil {
+		t.Fatalf("ExtractIncremental() error = %v", err)
+	}
+
+	if want := (Changes{Added: 1, Changed: 1, Removed: 1, Unchanged: 1}); changes != want {
+		t.Errorf("Changes = %+v, want %+v", changes, want)
+	}
+	if n := len(provider.Prompts()); n != 2 {
+		t.Errorf("Expected 2 requests, got %d", n)
+	}
+	if a.Files[0].Summary != "Fresh." || a.Files[1].Summary != "Fresh." || a.Files[2].Summary != "Kept." {
+		t.Errorf("Unexpected summaries: %+v", a.Files)
+	}
+	want := []Abstraction{
+		{Name: "Core", Description: "Kept", Files: []string{"same.go", "added.go", "changed.go"}},
+		{Name: "Plugin", Description: "New", Files: []string{"added.go", "changed.go"}},
+	}
+	if !reflect.DeepEqual(a.Abstractions, want) {
+		t.Errorf("Abstractions = %+v, want %+v", a.Abstractions, want)
+	}
+	if len(a.Relationships) != 0 {
+		t.Errorf("Expected the relationship to the dropped abstraction to be removed, got %+v", a.Relationships)
+	}
+}
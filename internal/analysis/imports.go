@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	goImportBlockRe  = regexp.MustCompile(`(?s)\bimport\s*\((.*?)\)`)
+	goImportRe       = regexp.MustCompile(`(?m)^\s*import\s+(?:[\w.]+\s+)?"([^"]+)"`)
+	quotedRe         = regexp.MustCompile(`"([^"]+)"`)
+	pythonImportRe   = regexp.MustCompile(`(?m)^[ \t]*(?:from[ \t]+([.\w]+)[ \t]+import|import[ \t]+([\w.]+))`)
+	jsImportRe       = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*\(?\s*|\brequire\s*\(\s*)['"]([^'"]+)['"]`)
+	jvmImportRe      = regexp.MustCompile(`(?m)^\s*import\s+(?:static\s+)?([\w.]+)`)
+	rustUseRe        = regexp.MustCompile(`(?m)^\s*(?:pub(?:\([^)]*\))?\s+)?(?:use\s+crate::([\w:]+)|mod\s+(\w+)\s*;)`)
+	includeRe        = regexp.MustCompile(`(?m)^\s*#\s*include\s*"([^"]+)"`)
+	scriptExtensions = regexp.MustCompile(`\.(?:[cm]?js|jsx|ts|tsx|vue|svelte)$`)
+)
+
+// imports returns the imports of a file written in language, each as the
+// paths it may refer to (without file extensions), most likely first. Paths
+// are relative to the source root when the import is relative to the file,
+// and as written (with separators turned into slashes) otherwise.
+func imports(language, file string, content []byte) [][]string {
+	text := string(content)
+	dir := path.Dir(file)
+	var found [][]string
+	add := func(candidates ...string) {
+		found = append(found, candidates)
+	}
+
+	switch language {
+	case "Go":
+		for _, block := range goImportBlockRe.FindAllStringSubmatch(text, -1) {
+			for _, m := range quotedRe.FindAllStringSubmatch(block[1], -1) {
+				add(m[1])
+			}
+		}
+		for _, m := range goImportRe.FindAllStringSubmatch(text, -1) {
+			add(m[1])
+		}
+	case "Python":
+		for _, m := range pythonImportRe.FindAllStringSubmatch(text, -1) {
+			module := m[1] + m[2]
+			dots := len(module) - len(strings.TrimLeft(module, "."))
+			name := strings.ReplaceAll(module[dots:], ".", "/")
+			if dots == 0 {
+				add(name)
+				continue
+			}
+			// Relative imports start from the file's package, one level up per extra dot
+			base := dir
+			for range dots - 1 {
+				base = path.Dir(base)
+			}
+			add(path.Join(base, name))
+		}
+	case "JavaScript", "TypeScript", "Vue", "Svelte":
+		for _, m := range jsImportRe.FindAllStringSubmatch(text, -1) {
+			spec := scriptExtensions.ReplaceAllString(m[1], "")
+			if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") {
+				spec = path.Join(dir, spec)
+			}
+			add(spec)
+		}
+	case "Java", "Kotlin", "Scala":
+		for _, m := range jvmImportRe.FindAllStringSubmatch(text, -1) {
+			// Static imports name a member of the class: fall back to the class
+			name := strings.ReplaceAll(strings.TrimSuffix(m[1], "."), ".", "/")
+			add(name, path.Dir(name))
+		}
+	case "Rust":
+		for _, m := range rustUseRe.FindAllStringSubmatch(text, -1) {
+			if m[2] != "" {
+				add(path.Join(dir, m[2]))
+				continue
+			}
+			// crate::a::b::Item may name an item of module a::b, or a itself
+			var candidates []string
+			for name := strings.ReplaceAll(m[1], "::", "/"); name != "."; name = path.Dir(name) {
+				candidates = append(candidates, "src/"+name)
+			}
+			add(candidates...)
+		}
+	case "C", "C++", "Objective-C":
+		for _, m := range includeRe.FindAllStringSubmatch(text, -1) {
+			name := strings.TrimSuffix(m[1], path.Ext(m[1]))
+			add(path.Join(dir, name), name)
+		}
+	}
+	return found
+}
+
+// importIndex resolves imports to the files of an analysis.
+type importIndex struct {
+	files []string
+	keys  map[string][]string // Paths a file can be imported as: without extension, and its directory
+}
+
+func newImportIndex(files []File) *importIndex {
+	idx := &importIndex{keys: make(map[string][]string, len(files))}
+	for _, f := range files {
+		idx.files = append(idx.files, f.Path)
+		keys := []string{strings.TrimSuffix(f.Path, path.Ext(f.Path))}
+		if dir := path.Dir(f.Path); dir != "." {
+			keys = append(keys, dir)
+		}
+		idx.keys[f.Path] = keys
+	}
+	return idx
+}
+
+// resolve returns the files an import may refer to, trying its candidate
+// paths in order. A candidate matches a file imported by the same path, by
+// a longer path ending with it (e.g., a Go module path), or by the path of
+// the file within a source directory (e.g., src/pkg/mod for pkg/mod).
+func (idx *importIndex) resolve(candidates []string) []string {
+	for _, spec := range candidates {
+		var matches []string
+		for _, file := range idx.files {
+			for _, key := range idx.keys[file] {
+				if spec == key || strings.HasSuffix(spec, "/"+key) ||
+					(strings.Contains(spec, "/") && strings.HasSuffix(key, "/"+spec)) {
+					matches = append(matches, file)
+					break
+				}
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
+// inferRelationships derives the relationships between the abstractions of
+// a from the import statements of their files, read from root: A uses B
+// when a file of A imports a file of B. The result only depends on a and
+// the file contents, so it is the same on every run.
+func inferRelationships(root string, a *Analysis) []Relationship {
+	owners := make(map[string][]string)
+	for _, abs := range a.Abstractions {
+		for _, file := range abs.Files {
+			owners[file] = append(owners[file], abs.Name)
+		}
+	}
+	languages := make(map[string]string, len(a.Files))
+	for _, f := range a.Files {
+		languages[f.Path] = f.Language
+	}
+	idx := newImportIndex(a.Files)
+
+	var relationships []Relationship
+	seen := make(map[[2]string]bool)
+	for _, abs := range a.Abstractions {
+		for _, file := range abs.Files {
+			content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+			if err != nil {
+				slog.Debug("Skipping the imports of an unreadable file", "path", file, "error", err)
+				continue
+			}
+			for _, candidates := range imports(languages[file], file, content) {
+				for _, target := range idx.resolve(candidates) {
+					for _, to := range owners[target] {
+						if to == abs.Name || seen[[2]string{abs.Name, to}] {
+							continue
+						}
+						seen[[2]string{abs.Name, to}] = true
+						relationships = append(relationships, Relationship{From: abs.Name, To: to, Label: defaultRelationshipLabel})
+					}
+				}
+			}
+		}
+	}
+	return relationships
+}
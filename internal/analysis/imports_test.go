@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	tests := []struct {
+		language string
+		file     string
+		content  string
+		want     [][]string
+	}{
+		{
+			language: "Go", file: "cmd/main.go",
+			content: "package main\n\nimport (\n\t\"fmt\"\n\tcfg \"example.com/demo/config\"\n)\n\nimport _ \"embed\"\n",
+			want:    [][]string{{"fmt"}, {"example.com/demo/config"}, {"embed"}},
+		},
+		{
+			language: "Python", file: "pkg/sub/mod.py",
+			content: "import os\nfrom pkg.config import Settings\nfrom . import helpers\nfrom ..util import tools\n",
+			want:    [][]string{{"os"}, {"pkg/config"}, {"pkg/sub"}, {"pkg/util"}},
+		},
+		{
+			language: "TypeScript", file: "src/app/main.ts",
+			content: "import React from 'react'\nimport { load } from \"../config/loader.js\"\nconst x = require('./x')\nimport('./lazy')\n",
+			want:    [][]string{{"react"}, {"src/config/loader"}, {"src/app/x"}, {"src/app/lazy"}},
+		},
+		{
+			language: "Java", file: "src/main/java/com/demo/App.java",
+			content: "package com.demo;\n\nimport com.demo.config.Settings;\nimport static com.demo.util.Strings.join;\n",
+			want:    [][]string{{"com/demo/config/Settings", "com/demo/config"}, {"com/demo/util/Strings/join", "com/demo/util/Strings"}},
+		},
+		{
+			language: "Rust", file: "src/main.rs",
+			content: "mod config;\nuse crate::loader::Loader;\nuse std::fs;\n",
+			want:    [][]string{{"src/config"}, {"src/loader/Loader", "src/loader"}},
+		},
+		{
+			language: "C", file: "src/main.c",
+			content: "#include <stdio.h>\n#include \"config.h\"\n",
+			want:    [][]string{{"src/config", "config"}},
+		},
+		{language: "Markdown", file: "README.md", content: "import this", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			got := imports(tt.language, tt.file, []byte(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportIndexResolve(t *testing.T) {
+	idx := newImportIndex([]File{
+		{Path: "config/config.go"},
+		{Path: "config/load.go"},
+		{Path: "src/pkg/util.py"},
+		{Path: "src/main/java/com/demo/config/Settings.java"},
+		{Path: "main.go"},
+	})
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       []string
+	}{
+		{name: "go package", candidates: []string{"example.com/demo/config"}, want: []string{"config/config.go", "config/load.go"}},
+		{name: "module under a source directory", candidates: []string{"pkg/util"}, want: []string{"src/pkg/util.py"}},
+		{name: "first matching candidate", candidates: []string{"com/demo/config/Settings/load", "com/demo/config/Settings"}, want: []string{"src/main/java/com/demo/config/Settings.java"}},
+		{name: "external package", candidates: []string{"fmt"}, want: nil},
+		{name: "bare name only matches at the root", candidates: []string{"util"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idx.resolve(tt.candidates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve(%q) = %q, want %q", tt.candidates, got, tt.want)
+			}
+		})
+	}
+}
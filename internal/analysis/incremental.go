@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+// Changes counts how the files of an analysis differ from its baseline.
+type Changes struct {
+	Added     int `json:"added"`     // Files missing from the baseline
+	Changed   int `json:"changed"`   // Files whose content hash differs (or is unknown), or that failed to be analyzed
+	Removed   int `json:"removed"`   // Baseline files no longer in scope
+	Unchanged int `json:"unchanged"` // Files whose results were reused
+}
+
+// carryOver copies the results of baseline into a for the files whose hash
+// is unchanged (and that did not fail to be analyzed), and returns the
+// changes along with the indexes of the files of a that still need to be
+// extracted. The baseline's abstractions are kept, in order, with only their
+// unchanged files; abstractions left with no files are dropped, as are
+// relationships between dropped abstractions.
+func carryOver(a, baseline *Analysis) (Changes, []int) {
+	previous := make(map[string]File, len(baseline.Files))
+	for _, f := range baseline.Files {
+		previous[f.Path] = f
+	}
+
+	var changes Changes
+	var pending []int
+	unchanged := make(map[string]bool)
+	present := make(map[string]bool, len(a.Files))
+	for i := range a.Files {
+		file := &a.Files[i]
+		present[file.Path] = true
+		old, ok := previous[file.Path]
+		switch {
+		case !ok:
+			changes.Added++
+			pending = append(pending, i)
+		case old.Hash == "" || old.Hash != file.Hash || baseline.Failed[file.Path] != "":
+			changes.Changed++
+			pending = append(pending, i)
+		default:
+			changes.Unchanged++
+			unchanged[file.Path] = true
+			file.Summary = old.Summary
+		}
+	}
+	for path := range previous {
+		if !present[path] {
+			changes.Removed++
+		}
+	}
+
+	a.Abstractions = nil
+	kept := make(map[string]bool)
+	for _, abs := range baseline.Abstractions {
+		var files []string
+		for _, path := range abs.Files {
+			if unchanged[path] {
+				files = append(files, path)
+			}
+		}
+		if len(files) > 0 {
+			abs.Files = files
+			a.Abstractions = append(a.Abstractions, abs)
+			kept[abs.Name] = true
+		}
+	}
+	a.Relationships = nil
+	for _, rel := range baseline.Relationships {
+		if kept[rel.From] && kept[rel.To] {
+			a.Relationships = append(a.Relationships, rel)
+		}
+	}
+	return changes, pending
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+// defaultRelationshipLabel labels relationships without a label of their own.
+const defaultRelationshipLabel = "uses"
+
+// relationshipsResponse is the structured response expected when asking how
+// the abstractions of a codebase depend on each other.
+type relationshipsResponse struct {
+	Relationships []struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Label string `json:"label"`
+	} `json:"relationships"`
+}
+
+// ExtractRelationships sets a.Relationships to the directed edges between
+// the abstractions of a ("A uses B"), which the tutorial's diagrams and
+// chapter order are built from. A single LLM request is made; relationships
+// naming unknown abstractions, or an abstraction and itself, are dropped.
+//
+// If the request fails with an error that is not fatal (see Extract), or
+// its response yields no relationship, they are inferred from the import
+// statements of the files under Root instead: A uses B when a file of A
+// imports a file of B.
+func (e *Extractor) ExtractRelationships(ctx context.Context, a *Analysis) error {
+	a.Relationships = nil
+	if len(a.Abstractions) < 2 {
+		return nil
+	}
+	slog.Info("Extracting relationships", "abstractions", len(a.Abstractions))
+
+	relationships, err := e.relationships(ctx, a)
+	switch {
+	case err != nil && llm.IsFatal(err):
+		return fmt.Errorf("extracting relationships: %w", err)
+	case err != nil:
+		slog.Warn("Could not extract the relationships; inferring them from imports", "error", err)
+		relationships = inferRelationships(e.Root, a)
+	}
+	a.Relationships = relationships
+	return nil
+}
+
+// relationships asks the LLM how the abstractions of a depend on each other.
+func (e *Extractor) relationships(ctx context.Context, a *Analysis) ([]Relationship, error) {
+	data := prompts.RelationshipsData{Project: a.ProjectName}
+	names := make(map[string]string, len(a.Abstractions))
+	for _, abs := range a.Abstractions {
+		data.Abstractions = append(data.Abstractions, prompts.AbstractionSummary{Name: abs.Name, Description: abs.Description, Files: abs.Files})
+		names[strings.ToLower(abs.Name)] = abs.Name
+	}
+	templates := e.Prompts
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	prompt, err := templates.Relationships(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := completeJSON[relationshipsResponse](ctx, e, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var relationships []Relationship
+	seen := make(map[[2]string]bool)
+	for _, found := range parsed.Relationships {
+		from, to := names[strings.ToLower(strings.TrimSpace(found.From))], names[strings.ToLower(strings.TrimSpace(found.To))]
+		if from == "" || to == "" || from == to || seen[[2]string{from, to}] {
+			slog.Debug("Dropping relationship", "from", found.From, "to", found.To)
+			continue
+		}
+		seen[[2]string{from, to}] = true
+		label := strings.TrimSpace(found.Label)
+		if label == "" {
+			label = defaultRelationshipLabel
+		}
+		relationships = append(relationships, Relationship{From: from, To: to, Label: label})
+	}
+	if len(relationships) == 0 {
+		return nil, errors.New("response listed no relationship between the abstractions")
+	}
+	return relationships, nil
+}
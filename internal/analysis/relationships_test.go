@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+// layeredAnalysis returns an analysis of a small Go module whose abstractions
+// import each other: CLI imports Config and Loader, and Loader imports Config.
+func layeredAnalysis(t *testing.T) *Analysis {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":          "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/demo/config\"\n\tld \"example.com/demo/loader\"\n)\n",
+		"config/config.go": "package config\n\nimport \"os\"\n",
+		"loader/loader.go": "package loader\n\nimport \"example.com/demo/config\"\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Analysis{
+		ProjectName: "demo",
+		Source:      Source{Type: SourceDir, Location: root},
+		Files: []File{
+			{Path: "config/config.go", Language: "Go"},
+			{Path: "loader/loader.go", Language: "Go"},
+			{Path: "main.go", Language: "Go"},
+		},
+		Abstractions: []Abstraction{
+			{Name: "CLI", Description: "Entry point", Files: []string{"main.go"}},
+			{Name: "Config", Description: "Settings", Files: []string{"config/config.go"}},
+			{Name: "Loader", Description: "Reads the settings", Files: []string{"loader/loader.go"}},
+		},
+	}
+}
+
+func TestExtractRelationships(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(string) (string, error) {
+		return "```json\n" + `{"relationships": [
+			{"from": "cli", "to": "Loader", "label": "runs"},
+			{"from": "CLI", "to": "loader", "label": "duplicate"},
+			{"from": "Loader", "to": "Config", "label": ""},
+			{"from": "Config", "to": "Config", "label": "self"},
+			{"from": "Ghost", "to": "Config", "label": "unknown"}
+		]}` + "\n```", nil
+	}}
+
+	a := layeredAnalysis(t)
+	e := &Extractor{Provider: provider, Root: a.Source.Location}
+	if err := e.ExtractRelationships(context.Background(), a); err != nil {
+		t.Fatalf("ExtractRelationships() error = %v", err)
+	}
+
+	want := []Relationship{
+		{From: "CLI", To: "Loader", Label: "runs"},
+		{From: "Loader", To: "Config", Label: "uses"},
+	}
+	if !reflect.DeepEqual(a.Relationships, want) {
+		t.Errorf("Relationships = %+v, want %+v", a.Relationships, want)
+	}
+
+	prompts := provider.Prompts()
+	if len(prompts) != 1 {
+		t.Fatalf("Expected one relationships request, got %d", len(prompts))
+	}
+	if want := "- Loader: Reads the settings (files: loader/loader.go)"; !strings.Contains(prompts[0], want) {
+		t.Errorf("Expected the prompt to contain %q, got:\n%s", want, prompts[0])
+	}
+}
+
+func TestExtractRelationshipsFallback(t *testing.T) {
+	// Inferred from the imports, in the order of the abstractions and their imports
+	inferred := []Relationship{
+		{From: "CLI", To: "Config", Label: "uses"},
+		{From: "CLI", To: "Loader", Label: "uses"},
+		{From: "Loader", To: "Config", Label: "uses"},
+	}
+
+	tests := []struct {
+		name    string
+		respond func(string) (string, error)
+		wantErr bool
+	}{
+		{name: "malformed response", respond: func(string) (string, error) { return "They are all related.", nil }},
+		{name: "empty response", respond: func(string) (string, error) { return `{"relationships": []}`, nil }},
+		{name: "only unknown abstractions", respond: func(string) (string, error) {
+			return `{"relationships": [{"from": "Ghost", "to": "Config"}]}`, nil
+		}},
+		{name: "server error", respond: func(string) (string, error) {
+			return "", &llm.APIError{Provider: "fake", StatusCode: http.StatusBadGateway, Message: "boom"}
+		}},
+		{name: "fatal error", wantErr: true, respond: func(string) (string, error) {
+			return "", &llm.APIError{Provider: "fake", StatusCode: http.StatusForbidden, Message: "denied"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := layeredAnalysis(t)
+			e := &Extractor{Provider: &llmtest.Provider{Respond: tt.respond}, Root: a.Source.Location}
+			err := e.ExtractRelationships(context.Background(), a)
+			if tt.wantErr {
+				var apiErr *llm.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Expected the API error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractRelationships() error = %v", err)
+			}
+			if !reflect.DeepEqual(a.Relationships, inferred) {
+				t.Errorf("Relationships = %+v, want %+v", a.Relationships, inferred)
+			}
+		})
+	}
+}
+
+func TestExtractRelationshipsSingleAbstraction(t *testing.T) {
+	provider := &llmtest.Provider{}
+	a := &Analysis{
+		Abstractions:  []Abstraction{{Name: "CLI", Files: []string{"main.go"}}},
+		Relationships: []Relationship{{From: "CLI", To: "Old"}},
+	}
+	if err := (&Extractor{Provider: provider}).ExtractRelationships(context.Background(), a); err != nil {
+		t.Fatalf("ExtractRelationships() error = %v", err)
+	}
+	if len(provider.Prompts()) != 0 || len(a.Relationships) != 0 {
+		t.Errorf("Expected no request and no relationships, got %d prompts and %+v", len(provider.Prompts()), a.Relationships)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+)
+
+// retryInstruction is appended to a prompt whose response could not be
+// used, to ask for the JSON object again. %v is the reason.
+const retryInstruction = "\n\nYour previous answer could not be used: %v. " +
+	"Answer again with only the JSON object described above: no prose, no Markdown code fence."
+
+// completeJSON sends prompt to the provider and decodes the JSON object of
+// the response into a new T, which check (if not nil) validates. A response
+// that is empty, holds no valid JSON object or fails the check is asked for
+// once more, with the reason appended to the prompt; errors of the provider
+// itself are returned as is, since the provider retries those it can.
+func completeJSON[T any](ctx context.Context, e *Extractor, prompt string, check func(*T) error) (*T, error) {
+	response, err := e.Provider.Complete(ctx, prompt, e.Options)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeChecked(response, check)
+	if err == nil {
+		return v, nil
+	}
+
+	slog.Warn("Unusable LLM response; asking again", "error", err)
+	response, err = e.Provider.Complete(ctx, prompt+fmt.Sprintf(retryInstruction, err), e.Options)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = decodeChecked(response, check); err != nil {
+		return nil, fmt.Errorf("unusable response, even when asked again: %w", err)
+	}
+	return v, nil
+}
+
+// decodeChecked decodes response into a new T and validates it with check,
+// if not nil.
+func decodeChecked[T any](response string, check func(*T) error) (*T, error) {
+	v := new(T)
+	if err := ParseJSONResponse(response, v); err != nil {
+		return nil, err
+	}
+	if check != nil {
+		if err := check(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// ParseJSONResponse decodes a JSON object from an LLM response into v,
+// tolerating surrounding prose and Markdown code fences. A blank response
+// fails with llm.ErrEmptyResponse.
+func ParseJSONResponse(response string, v any) error {
+	if strings.TrimSpace(response) == "" {
+		return llm.ErrEmptyResponse
+	}
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return errors.New("response did not contain a JSON object")
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), v); err != nil {
+		return fmt.Errorf("response contained invalid JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// ByteSize is a size in bytes. In the config file it can be written as a
+// plain number of bytes or with a unit, such as "512KB", "10MB" or "1.5GB".
+// Units are binary: 1KB is 1024 bytes (KiB, MiB, ... are accepted too).
+type ByteSize int64
+
+// Byte size units.
+const (
+	KB ByteSize = 1 << (10 * (iota + 1))
+	MB
+	GB
+	TB
+)
+
+var (
+	byteSizeRe    = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmgt]?)(?:i?b)?$`)
+	byteSizeUnits = map[string]ByteSize{"": 1, "k": KB, "m": MB, "g": GB, "t": TB}
+)
+
+// ParseByteSize parses a size such as "1048576", "512KB", "10 MB" or
+// "1.5GiB" (case insensitive). Negative sizes are rejected.
+func ParseByteSize(s string) (ByteSize, error) {
+	m := byteSizeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		if strings.HasPrefix(strings.TrimSpace(s), "-") {
+			return 0, fmt.Errorf("invalid size %q: must not be negative", s)
+		}
+		return 0, fmt.Errorf("invalid size %q: use a number of bytes or a number with a unit (e.g., 512KB, 10MB, 1.5GB)", s)
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	bytes := value * float64(byteSizeUnits[m[2]])
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+	return ByteSize(math.Round(bytes)), nil
+}
+
+// String formats the size with the largest unit that divides it exactly
+// (e.g., "10MB"), or as a number of bytes.
+func (b ByteSize) String() string {
+	for _, unit := range []struct {
+		size ByteSize
+		name string
+	}{{TB, "TB"}, {GB, "GB"}, {MB, "MB"}, {KB, "KB"}} {
+		if b != 0 && b%unit.size == 0 {
+			return fmt.Sprintf("%d%s", b/unit.size, unit.name)
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// byteSizeHook is a mapstructure decode hook that converts strings with
+// units, and plain numbers, to ByteSize values.
+func byteSizeHook(from, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeOf(ByteSize(0)) {
+		return data, nil
+	}
+	switch v := reflect.ValueOf(data); v.Kind() {
+	case reflect.String:
+		return ParseByteSize(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return nil, fmt.Errorf("invalid size %d: must not be negative", v.Int())
+		}
+		return ByteSize(v.Int()), nil
+	case reflect.Float32, reflect.Float64:
+		if v.Float() < 0 {
+			return nil, fmt.Errorf("invalid size %g: must not be negative", v.Float())
+		}
+		return ByteSize(math.Round(v.Float())), nil
+	}
+	return data, nil
+}
+
+// decodeHook extends Viper's default decode hooks (durations and
+// comma-separated lists) with byteSizeHook.
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	byteSizeHook,
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+)
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ByteSize
+		wantErr bool
+	}{
+		{input: "0", want: 0},
+		{input: "1000000", want: 1000000},
+		{input: "512KB", want: 512 * 1024},
+		{input: "512k", want: 512 * 1024},
+		{input: "10MB", want: 10 * 1024 * 1024},
+		{input: "10 mb", want: 10 * 1024 * 1024},
+		{input: "1.5GB", want: 1536 * 1024 * 1024},
+		{input: "2GiB", want: 2 * 1024 * 1024 * 1024},
+		{input: "1TB", want: 1 << 40},
+		{input: "100B", want: 100},
+		{input: "", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "-5MB", wantErr: true},
+		{input: "ten", wantErr: true},
+		{input: "10XB", wantErr: true},
+		{input: "99999999TB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseByteSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	tests := []struct {
+		size ByteSize
+		want string
+	}{
+		{0, "0"},
+		{1000000, "1000000"},
+		{512 * KB, "512KB"},
+		{10 * MB, "10MB"},
+		{1536 * MB, "1536MB"},
+		{2 * GB, "2GB"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.size.String(); got != tt.want {
+			t.Errorf("Expected '%s', got '%s'", tt.want, got)
+		}
+	}
+}
+
+func TestLoadConfig_MaxSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    ByteSize
+		wantErr bool
+	}{
+		{name: "plain integer", value: "1000000", want: 1000000},
+		{name: "human readable", value: "10MB", want: 10 * MB},
+		{name: "quoted with space", value: `"1.5 KB"`, want: 1536},
+		{name: "negative", value: "-1", wantErr: true},
+		{name: "unparseable", value: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			configContent := "llm:\n  provider: ollama\n  endpoint: http://localhost:11434\n  model: llama3\ndefaults:\n  max_size: " + tt.value + "\n"
+			if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := LoadConfig(configPath, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.Defaults.MaxSize != tt.want {
+				t.Errorf("Expected max_size %d, got %d", tt.want, cfg.Defaults.MaxSize)
+			}
+		})
+	}
+}
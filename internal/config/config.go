@@ -3,37 +3,109 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
-	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	LLM      LLMConfig      `mapstructure:"llm"`
-	Defaults DefaultsConfig `mapstructure:"defaults"`
-	GitHub   GitHubConfig   `mapstructure:"github"`
+	LLM        LLMConfig            `mapstructure:"llm"`
+	Profiles   map[string]LLMConfig `mapstructure:"profiles"` // Named alternatives to the llm section
+	Defaults   DefaultsConfig       `mapstructure:"defaults"`
+	Generation GenerationConfig     `mapstructure:"generation"`
+	GitHub     GitHubConfig         `mapstructure:"github"`
+
+	// Profile is the name of the profile that replaced the llm section, if any
+	Profile string `mapstructure:"-"`
+	// File is the path of the configuration file that was read, or empty if
+	// none was found and only defaults and environment variables apply
+	File string `mapstructure:"-"`
+
+	sources map[string]string // Where LoadConfig found each setting; see Settings
 }
 
 // LLMConfig holds configuration for the LLM provider
 type LLMConfig struct {
-	Provider string `mapstructure:"provider"` // e.g., "openai", "anthropic", "ollama", "lmstudio"
-	APIKey   string `mapstructure:"api_key"`  // API key for cloud providers
-	Model    string `mapstructure:"model"`    // Specific model to use (e.g., "gpt-4", "claude-3-opus")
-	Endpoint string `mapstructure:"endpoint"` // Endpoint URL for local providers (Ollama, LM Studio)
+	Provider   string `mapstructure:"provider"`     // e.g., "openai", "azure", "anthropic", "gemini", "ollama", "lmstudio"
+	APIKey     string `mapstructure:"api_key"`      // API key for cloud providers
+	APIKeyFile string `mapstructure:"api_key_file"` // Path to a file holding the API key (e.g., a Docker secret)
+	Model      string `mapstructure:"model"`        // Specific model to use (e.g., "gpt-4", "claude-3-opus")
+	Endpoint   string `mapstructure:"endpoint"`     // Endpoint URL for local providers (Ollama, LM Studio), the Azure resource, or an OpenAI-compatible gateway
+	Deployment string `mapstructure:"deployment"`   // Azure OpenAI deployment name
+
+	Temperature *float64 `mapstructure:"temperature"` // Sampling temperature (0-2); unset uses the provider default
+	MaxTokens   int      `mapstructure:"max_tokens"`  // Maximum tokens to generate per request; 0 uses the provider default
+	TopP        *float64 `mapstructure:"top_p"`       // Nucleus sampling probability mass (0-1); unset uses the provider default
+
+	MaxRetries     int           `mapstructure:"max_retries"`      // Retries for transient errors (429, 5xx, timeouts)
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"` // Delay before the first retry, doubled on each retry
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`  // Time each request may take, streamed response included; 0 means no limit
+
+	RequestsPerMinute int `mapstructure:"requests_per_minute"` // Client-side rate limit; 0 means unlimited
+
+	ContextWindow int `mapstructure:"context_window"` // Tokens the model accepts per request; 0 looks it up by model name
+
+	MaxCost float64 `mapstructure:"max_cost"` // Estimated US dollars a run may spend before it stops; 0 means no limit
+
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // Skip TLS certificate verification (e.g., a self-signed local endpoint)
+	CACertFile         string `mapstructure:"ca_cert_file"`         // PEM file of extra root certificates to trust (e.g., a corporate CA)
+
+	Organization string            `mapstructure:"organization"`  // OpenAI organization ID, sent as the OpenAI-Organization header
+	ExtraHeaders map[string]string `mapstructure:"extra_headers"` // Headers added to every request (e.g., for a gateway), unless the provider sets them
 }
 
+// Defaults applied when the configuration does not set a value.
+const (
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = time.Second
+	DefaultRequestTimeout = 10 * time.Minute
+	DefaultConcurrency    = 4
+	DefaultMaxFiles       = 500
+	DefaultScanBuffer     = 64
+)
+
 // DefaultsConfig holds default settings for operations
 type DefaultsConfig struct {
-	OutputDir string   `mapstructure:"output_dir"` // Default directory for generated tutorials
-	Language  string   `mapstructure:"language"`   // Default tutorial language
-	Audience  string   `mapstructure:"audience"`   // Default target audience
-	Include   []string `mapstructure:"include"`    // Default include patterns
-	Exclude   []string `mapstructure:"exclude"`    // Default exclude patterns
-	MaxSize   int64    `mapstructure:"max_size"`   // Default max file size
+	OutputDir   string   `mapstructure:"output_dir"`  // Default directory for generated tutorials
+	Language    string   `mapstructure:"language"`    // Default tutorial language
+	Audience    string   `mapstructure:"audience"`    // Default target audience
+	Include     []string `mapstructure:"include"`     // Default include patterns
+	Exclude     []string `mapstructure:"exclude"`     // Default exclude patterns
+	Paths       []string `mapstructure:"paths"`       // Subdirectories of the source to analyze; empty means all of it
+	MaxSize     ByteSize `mapstructure:"max_size"`    // Default max file size (e.g., 1000000 or "10MB")
+	Concurrency int      `mapstructure:"concurrency"` // Files analyzed in parallel
+	MaxFiles    int      `mapstructure:"max_files"`   // Most files analyzed without confirmation (--yes); 0 means no limit
+	ScanBuffer  int      `mapstructure:"scan_buffer"` // Files the scan may find ahead of the extraction
+	PromptsDir  string   `mapstructure:"prompts_dir"` // Directory of .tmpl files overriding the built-in prompts
+
+	// TestPatterns match the test files skipped unless tests are included;
+	// empty uses the built-in patterns for common languages
+	TestPatterns []string `mapstructure:"test_patterns"`
+}
+
+// GenerationConfig holds settings for writing the chapters
+type GenerationConfig struct {
+	// SystemPrompt replaces the built-in system instruction of every chapter
+	// request, e.g., to give the tutorial a terse or a friendly voice
+	SystemPrompt string `mapstructure:"system_prompt"`
+	// TargetWords is the approximate length of each chapter in words, which
+	// also sets the response budget of chapter requests; 0 leaves it to the model
+	TargetWords int `mapstructure:"target_words"`
+	// FrontMatterTemplate is the text/template of the YAML front matter
+	// --front-matter writes to each chapter file; empty uses the built-in one
+	FrontMatterTemplate string `mapstructure:"front_matter_template"`
 }
 
 // GitHubConfig holds configuration related to GitHub access
@@ -41,76 +113,239 @@ type GitHubConfig struct {
 	Token string `mapstructure:"token"` // GitHub personal access token for private repos
 }
 
+// FlagBinding binds a command-line flag to the config key it overrides.
+type FlagBinding struct {
+	Key  string // Config key, e.g., "llm.provider"
+	Flag *pflag.Flag
+}
+
 // LoadConfig reads configuration from file, environment variables, and flags.
 // Precedence: Flags > Env > Config File (current dir) > Config File (home dir)
-func LoadConfig(cfgFile string) (*Config, error) {
+// Only the flags that were set on the command line override the
+// configuration; the default value of a flag never masks a configured one.
+// When profile is not empty, the LLM profile of that name replaces the llm
+// section before the configuration is validated, and flags bound to llm keys
+// override the profile instead. Its errors are of type *Error.
+func LoadConfig(cfgFile, profile string, flags ...FlagBinding) (*Config, error) {
+	cfg, err := load(cfgFile, profile, flags)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	return cfg, nil
+}
+
+// Error is returned when the configuration cannot be read or is invalid, so
+// that callers can tell a problem with the configuration from other failures.
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// load implements LoadConfig.
+func load(cfgFile, profile string, flags []FlagBinding) (*Config, error) {
 	v := viper.New()
 
 	// 1. Set defaults (optional, if you have hardcoded defaults)
 	// v.SetDefault("defaults.output_dir", "./tutorials")
 	// v.SetDefault("llm.provider", "openai")
+	v.SetDefault("llm.max_retries", DefaultMaxRetries)
+	v.SetDefault("llm.retry_base_delay", DefaultRetryBaseDelay)
+	v.SetDefault("llm.request_timeout", DefaultRequestTimeout)
+	v.SetDefault("defaults.concurrency", DefaultConcurrency)
+	v.SetDefault("defaults.max_files", DefaultMaxFiles)
+	v.SetDefault("defaults.scan_buffer", DefaultScanBuffer)
 
-	// 2. Set config file paths
-	if cfgFile != "" {
-		// Use config file from the flag.
-		v.SetConfigFile(cfgFile)
-	} else {
-		// Search config in home directory and current directory.
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	// 2. Set the config file: the one given, or the first found in
+	// ~/.config/code-decoder/, then the current directory
+	path := cfgFile
+	if path == "" {
+		var err error
+		if path, err = FindConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+	if path != "" {
+		if err := SetConfigFile(v, path); err != nil {
+			return nil, err
 		}
-		v.AddConfigPath(filepath.Join(home, ".config", "code-decoder")) // ~/.config/code-decoder/
-		v.AddConfigPath(".")                                            // Current directory
-		v.SetConfigName("config")                                       // Name of config file (without extension)
-		v.SetConfigType("yaml")                                         // REQUIRED if the config file does not have the extension in the name
 	}
 
 	// 3. Set environment variable handling
 	v.SetEnvPrefix("CODEDECODER") // e.g., CODEDECODER_LLM_PROVIDER
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv() // Read in environment variables that match
+	// AutomaticEnv only applies to keys Viper already knows about, so bind
+	// every key, for variables to set the keys the config file leaves out too.
+	if err := bindEnv(v, reflect.TypeFor[Config](), ""); err != nil {
+		return nil, fmt.Errorf("failed to bind environment variable: %w", err)
+	}
 
 	// 4. Read the configuration file
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; ignore error if not explicitly specified via flag
-			if cfgFile != "" {
-				return nil, fmt.Errorf("config file specified but not found: %s", cfgFile)
-			}
-			fmt.Println("Config file not found, using defaults and environment variables.")
-		} else {
-			// Config file was found but another error was produced
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	if path == "" {
+		slog.Debug("Config file not found, using defaults and environment variables")
+	} else if err := v.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("config file specified but not found: %s", path)
 		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	} else {
-		fmt.Println("Using config file:", v.ConfigFileUsed())
+		slog.Debug("Reading config file", "path", v.ConfigFileUsed())
+		if err := expandFileEnv(v); err != nil {
+			return nil, err
+		}
+	}
+	// Profiles get the same defaults as the llm section
+	for name := range v.GetStringMap("profiles") {
+		v.SetDefault("profiles."+name+".max_retries", DefaultMaxRetries)
+		v.SetDefault("profiles."+name+".retry_base_delay", DefaultRetryBaseDelay)
+		v.SetDefault("profiles."+name+".request_timeout", DefaultRequestTimeout)
+	}
+
+	// 5. Bind the flags that were set, which take precedence over the rest
+	var set []string // Named in errors, since the problem may come from them
+	flagKeys := make(map[string]bool)
+	for _, b := range flags {
+		if b.Flag == nil || !b.Flag.Changed {
+			continue
+		}
+		set = append(set, "--"+b.Flag.Name)
+		flagKeys[b.Key] = true
+		keys := []string{b.Key}
+		// Viper lowercases map keys, so profile names are case-insensitive
+		if field, ok := strings.CutPrefix(b.Key, "llm."); ok && profile != "" && v.IsSet("profiles."+strings.ToLower(profile)) {
+			keys = append(keys, "profiles."+strings.ToLower(profile)+"."+field)
+		}
+		for _, key := range keys {
+			if err := v.BindPFlag(key, b.Flag); err != nil {
+				return nil, fmt.Errorf("failed to bind flag --%s: %w", b.Flag.Name, err)
+			}
+		}
 	}
 
-	// 5. Unmarshal the config into the struct
+	// 6. Unmarshal the config into the struct
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(decodeHook)); err != nil {
+		if len(set) > 0 {
+			return nil, fmt.Errorf("failed to unmarshal config (with flags %s): %w", strings.Join(set, ", "), err)
+		}
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
-
-	// 6. Bind flags (This should happen in the cmd package where flags are defined)
-	// Example: v.BindPFlag("llm.provider", rootCmd.PersistentFlags().Lookup("provider"))
-	// Since LoadConfig is called before flags are parsed by Cobra initially,
-	// flag values need to be manually checked or Viper needs to be updated after flag parsing.
-	// Cobra's OnInitialize handles this timing. Viper instance used in initConfig
-	// will have access to parsed flags if BindPFlag was called correctly in cmd's init().
-	// For simplicity here, we assume Viper handles precedence correctly if flags are bound.
+	cfg.File = v.ConfigFileUsed()
+	if profile != "" {
+		if err := cfg.selectProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+	cfg.sources = settingSources(v, &cfg, flagKeys)
 
 	// 7. Validate the configuration
 	if err := cfg.Validate(); err != nil {
+		var qualifiers []string
+		if cfg.Profile != "" {
+			qualifiers = append(qualifiers, fmt.Sprintf("profile %q", cfg.Profile))
+		}
+		if len(set) > 0 {
+			qualifiers = append(qualifiers, "with flags "+strings.Join(set, ", "))
+		}
+		if len(qualifiers) > 0 {
+			return nil, fmt.Errorf("invalid configuration (%s): %w", strings.Join(qualifiers, ", "), err)
+		}
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// 8. Resolve the API key from api_key_file (Validate ensured it is readable)
+	if cfg.LLM.APIKeyFile != "" {
+		key, err := readAPIKeyFile(cfg.LLM.APIKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.LLM.APIKey = key
+	}
+
 	return &cfg, nil
 }
 
+// bindEnv binds the environment variable of every key of the config struct
+// t, whose keys are prefixed with prefix. Maps (the profiles) are skipped,
+// since their keys are not known up front.
+func bindEnv(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Map:
+		case reflect.Struct:
+			if err := bindEnv(v, field.Type, prefix+name+"."); err != nil {
+				return err
+			}
+		default:
+			if err := v.BindEnv(prefix + name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectProfile replaces the llm section with the named profile.
+func (c *Config) selectProfile(name string) error {
+	// Viper lowercases map keys, so profile names are case-insensitive
+	llmCfg, ok := c.Profiles[strings.ToLower(name)]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config defines no profiles", name)
+		}
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	c.LLM = llmCfg
+	c.Profile = name
+	return nil
+}
+
+// validHeaderName reports whether name may name an HTTP header: a non-empty
+// token of RFC 9110, such as X-Request-ID.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// readAPIKeyFile reads an API key from path, trimming trailing whitespace and newlines.
+func readAPIKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read llm.api_key_file: %w", err)
+	}
+	key := strings.TrimRightFunc(string(data), unicode.IsSpace)
+	if key == "" {
+		return "", fmt.Errorf("llm.api_key_file '%s' is empty", path)
+	}
+	return key, nil
+}
+
 // Validate checks if the loaded configuration is valid.
+// Every problem found is reported; the returned error joins them (see errors.Join).
 func (c *Config) Validate() error {
+	var problems []error
+
 	// Basic validation example
 	if c.LLM.Provider == "" {
 		// Depending on commands, this might be acceptable, or it might be an error.
@@ -119,14 +354,29 @@ func (c *Config) Validate() error {
 		// return errors.New("llm.provider is required")
 	}
 
+	// The API key may come from the config or from a file, but not both
+	if c.LLM.APIKeyFile != "" {
+		if c.LLM.APIKey != "" {
+			problems = append(problems, errors.New("llm.api_key and llm.api_key_file are mutually exclusive; set only one"))
+		} else if _, err := readAPIKeyFile(c.LLM.APIKeyFile); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
 	// Add more validation rules as needed
 	// e.g., check if API key is present for cloud providers
-	isCloudProvider := c.LLM.Provider == "openai" || c.LLM.Provider == "anthropic"
-	if isCloudProvider && c.LLM.APIKey == "" {
+	isCloudProvider := c.LLM.Provider == "openai" || c.LLM.Provider == "azure" || c.LLM.Provider == "anthropic" || c.LLM.Provider == "gemini"
+	if isCloudProvider && c.LLM.APIKey == "" && c.LLM.APIKeyFile == "" {
 		// Check environment variable as a fallback before erroring
 		envVarName := "CODEDECODER_LLM_APIKEY" // Or specific ones like CODEDECODER_OPENAI_API_KEY
-		if os.Getenv(envVarName) == "" {
-			return fmt.Errorf("llm.api_key is required for provider '%s' and %s env var is not set", c.LLM.Provider, envVarName)
+		envKeySet := os.Getenv(envVarName) != ""
+		if c.LLM.Provider == "gemini" {
+			// Gemini also accepts its own key variable
+			envKeySet = envKeySet || os.Getenv("CODEDECODER_GEMINI_API_KEY") != ""
+			envVarName += " or CODEDECODER_GEMINI_API_KEY"
+		}
+		if !envKeySet {
+			problems = append(problems, fmt.Errorf("llm.api_key is required for provider '%s' and %s env var is not set", c.LLM.Provider, envVarName))
 		}
 		// Optionally load from env var directly here if Viper didn't pick it up
 		// c.LLM.APIKey = os.Getenv(envVarName)
@@ -134,14 +384,123 @@ func (c *Config) Validate() error {
 
 	isLocalProvider := c.LLM.Provider == "ollama" || c.LLM.Provider == "lmstudio"
 	if isLocalProvider && c.LLM.Endpoint == "" {
-		return fmt.Errorf("llm.endpoint is required for local provider '%s'", c.LLM.Provider)
+		problems = append(problems, fmt.Errorf("llm.endpoint is required for local provider '%s'", c.LLM.Provider))
+	}
+
+	if c.LLM.Endpoint != "" {
+		if err := validateEndpoint(c.LLM.Endpoint); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if c.LLM.Provider == "azure" {
+		if c.LLM.Endpoint == "" {
+			problems = append(problems, errors.New("llm.endpoint (the Azure OpenAI resource URL) is required for provider 'azure'"))
+		}
+		if c.LLM.Deployment == "" {
+			problems = append(problems, errors.New("llm.deployment is required for provider 'azure'"))
+		}
+	}
+
+	if c.LLM.Temperature != nil && (*c.LLM.Temperature < 0 || *c.LLM.Temperature > 2) {
+		problems = append(problems, fmt.Errorf("llm.temperature must be between 0 and 2, got %g", *c.LLM.Temperature))
+	}
+	if c.LLM.MaxTokens < 0 {
+		problems = append(problems, fmt.Errorf("llm.max_tokens must be positive, got %d", c.LLM.MaxTokens))
+	}
+	if c.LLM.TopP != nil && (*c.LLM.TopP < 0 || *c.LLM.TopP > 1) {
+		problems = append(problems, fmt.Errorf("llm.top_p must be between 0 and 1, got %g", *c.LLM.TopP))
+	}
+
+	if c.LLM.MaxRetries < 0 {
+		problems = append(problems, fmt.Errorf("llm.max_retries must not be negative, got %d", c.LLM.MaxRetries))
+	}
+	if c.LLM.RetryBaseDelay < 0 {
+		problems = append(problems, fmt.Errorf("llm.retry_base_delay must not be negative, got %s", c.LLM.RetryBaseDelay))
+	}
+	if c.LLM.RequestTimeout < 0 {
+		problems = append(problems, fmt.Errorf("llm.request_timeout must not be negative, got %s", c.LLM.RequestTimeout))
+	}
+	if c.LLM.RequestsPerMinute < 0 {
+		problems = append(problems, fmt.Errorf("llm.requests_per_minute must not be negative, got %d", c.LLM.RequestsPerMinute))
+	}
+	if c.LLM.ContextWindow < 0 {
+		problems = append(problems, fmt.Errorf("llm.context_window must not be negative, got %d", c.LLM.ContextWindow))
+	}
+	if c.LLM.MaxCost < 0 {
+		problems = append(problems, fmt.Errorf("llm.max_cost must not be negative, got %g", c.LLM.MaxCost))
+	}
+	for name, value := range c.LLM.ExtraHeaders {
+		switch {
+		case !validHeaderName(name):
+			problems = append(problems, fmt.Errorf("llm.extra_headers: invalid header name %q", name))
+		case strings.ContainsAny(value, "\r\n"):
+			problems = append(problems, fmt.Errorf("llm.extra_headers: the value of %s must be on one line", name))
+		}
+	}
+	if c.LLM.ContextWindow > 0 && c.LLM.MaxTokens >= c.LLM.ContextWindow {
+		problems = append(problems, fmt.Errorf("llm.max_tokens (%d) must be less than llm.context_window (%d)", c.LLM.MaxTokens, c.LLM.ContextWindow))
+	}
+
+	if c.Defaults.MaxSize < 0 {
+		problems = append(problems, fmt.Errorf("defaults.max_size must not be negative, got %d", c.Defaults.MaxSize))
+	}
+	if c.Defaults.Concurrency < 0 {
+		problems = append(problems, fmt.Errorf("defaults.concurrency must not be negative, got %d", c.Defaults.Concurrency))
+	}
+	if c.Defaults.MaxFiles < 0 {
+		problems = append(problems, fmt.Errorf("defaults.max_files must not be negative, got %d", c.Defaults.MaxFiles))
+	}
+	if c.Defaults.ScanBuffer < 0 {
+		problems = append(problems, fmt.Errorf("defaults.scan_buffer must not be negative, got %d", c.Defaults.ScanBuffer))
+	}
+	if c.Generation.TargetWords < 0 {
+		problems = append(problems, fmt.Errorf("generation.target_words must not be negative, got %d", c.Generation.TargetWords))
 	}
 
 	// Validate audience values if necessary
 	validAudiences := map[string]bool{"beginner": true, "developer": true, "contributor": true}
 	if c.Defaults.Audience != "" && !validAudiences[c.Defaults.Audience] {
-		return fmt.Errorf("invalid default audience: '%s'. Must be one of beginner, developer, contributor", c.Defaults.Audience)
+		problems = append(problems, fmt.Errorf("invalid default audience: '%s'. Must be one of beginner, developer, contributor", c.Defaults.Audience))
 	}
 
+	return errors.Join(problems...)
+}
+
+// validateEndpoint checks that endpoint is an absolute http or https URL
+// with a host, so that a typo such as a missing scheme is reported up front
+// rather than as a confusing connection error.
+func validateEndpoint(endpoint string) error {
+	if strings.TrimSpace(endpoint) != endpoint {
+		return fmt.Errorf("llm.endpoint %q must not have leading or trailing spaces", endpoint)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("llm.endpoint %q is not a valid URL: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("llm.endpoint %q must start with http:// or https:// (e.g., http://localhost:11434)", endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("llm.endpoint %q has no host", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("llm.endpoint %q must not have a query or fragment, since API paths are appended to it", endpoint)
+	}
 	return nil
 }
+
+// Problems splits an error returned by Validate or LoadConfig into the
+// individual validation problems it contains. Errors that do not carry
+// multiple problems are returned as a single-element slice.
+func Problems(err error) []error {
+	if err == nil {
+		return nil
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			return joined.Unwrap()
+		}
+	}
+	return []error{err}
+}
@@ -3,12 +3,18 @@
 package config
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 func TestConfig_Validate(t *testing.T) {
+	temperature, tooHot, topP := 0.2, 2.5, 1.5
 	tests := []struct {
 		name    string
 		cfg     Config
@@ -94,6 +100,202 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid azure config",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:   "azure",
+					APIKey:     "test-key",
+					Endpoint:   "https://my-resource.openai.azure.com",
+					Deployment: "gpt-4o",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "azure without endpoint or deployment",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider: "azure",
+					APIKey:   "test-key",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid gemini config",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider: "gemini",
+					APIKey:   "test-key",
+					Model:    "gemini-2.0-flash",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid sampling settings",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:    "ollama",
+					Endpoint:    "http://localhost:11434",
+					Temperature: &temperature,
+					MaxTokens:   1024,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "temperature out of range",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:    "ollama",
+					Endpoint:    "http://localhost:11434",
+					Temperature: &tooHot,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max tokens and top p",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:  "ollama",
+					Endpoint:  "http://localhost:11434",
+					MaxTokens: -5,
+					TopP:      &topP,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max retries",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:   "ollama",
+					Endpoint:   "http://localhost:11434",
+					MaxRetries: -1,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative requests per minute",
+			cfg: Config{
+				LLM: LLMConfig{
+					Provider:          "ollama",
+					Endpoint:          "http://localhost:11434",
+					RequestsPerMinute: -60,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative context window",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ContextWindow: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max cost",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", MaxCost: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max tokens filling the context window",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ContextWindow: 4096, MaxTokens: 4096},
+			},
+			wantErr: true,
+		},
+		{
+			name: "context window with room for the prompt",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ContextWindow: 8192, MaxTokens: 1024},
+			},
+			wantErr: false,
+		},
+		{
+			name: "extra headers",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ExtraHeaders: map[string]string{"x-route-key": "blue"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "extra header with an invalid name",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ExtraHeaders: map[string]string{"x route": "blue"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra header spanning lines",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434", ExtraHeaders: map[string]string{"x-route-key": "blue\r\nHost: evil"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "endpoint without scheme",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "localhost:11434"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "endpoint with trailing spaces",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "lmstudio", Endpoint: "http://localhost:1234 "},
+			},
+			wantErr: true,
+		},
+		{
+			name: "endpoint with non-http scheme",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "ftp://localhost:11434"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "endpoint without host",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "azure", APIKey: "k", Endpoint: "https://", Deployment: "gpt-4o"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid https endpoint with path",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "ollama", Endpoint: "https://llm.example.com/ollama"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "endpoint with a query",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "openai", APIKey: "k", Endpoint: "https://gateway.example.com/v1?team=a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "openai gateway",
+			cfg: Config{
+				LLM: LLMConfig{Provider: "openai", APIKey: "k", Endpoint: "http://localhost:8000/v1", Model: "mistral"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative concurrency",
+			cfg: Config{
+				LLM:      LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434"},
+				Defaults: DefaultsConfig{Concurrency: -2},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,7 +359,7 @@ defaults:
 
 	// Test loading from explicit config file
 	t.Run("load from explicit config file", func(t *testing.T) {
-		cfg, err := LoadConfig(testConfigPath)
+		cfg, err := LoadConfig(testConfigPath, "")
 		if err != nil {
 			t.Fatalf("LoadConfig() error = %v", err)
 		}
@@ -171,11 +373,57 @@ defaults:
 		if cfg.Defaults.Audience != "developer" {
 			t.Errorf("Expected audience 'developer', got '%s'", cfg.Defaults.Audience)
 		}
+		if cfg.LLM.MaxRetries != DefaultMaxRetries || cfg.LLM.RetryBaseDelay != DefaultRetryBaseDelay {
+			t.Errorf("Expected default retry settings, got %d retries with %s base delay", cfg.LLM.MaxRetries, cfg.LLM.RetryBaseDelay)
+		}
+		if cfg.LLM.RequestTimeout != DefaultRequestTimeout {
+			t.Errorf("Expected default request timeout %s, got %s", DefaultRequestTimeout, cfg.LLM.RequestTimeout)
+		}
+		if cfg.Defaults.Concurrency != DefaultConcurrency {
+			t.Errorf("Expected default concurrency %d, got %d", DefaultConcurrency, cfg.Defaults.Concurrency)
+		}
+	})
+
+	// Test that loading reports the file used without printing it
+	t.Run("does not write to stdout", func(t *testing.T) {
+		var cfg *Config
+		stdout := captureStdout(t, func() {
+			cfg, err = LoadConfig(testConfigPath, "")
+		})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if stdout != "" {
+			t.Errorf("Expected nothing on stdout, got %q", stdout)
+		}
+		if cfg.File != testConfigPath {
+			t.Errorf("Expected File %q, got %q", testConfigPath, cfg.File)
+		}
+	})
+
+	// Test loading without a config file in the default locations
+	t.Run("no config file", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		t.Setenv("HOME", t.TempDir())
+
+		var cfg *Config
+		stdout := captureStdout(t, func() {
+			cfg, err = LoadConfig("", "")
+		})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if stdout != "" {
+			t.Errorf("Expected nothing on stdout, got %q", stdout)
+		}
+		if cfg.File != "" {
+			t.Errorf("Expected no File, got %q", cfg.File)
+		}
 	})
 
 	// Test loading with invalid config file path
 	t.Run("load with invalid config path", func(t *testing.T) {
-		_, err := LoadConfig(filepath.Join(tmpDir, "nonexistent.yaml"))
+		_, err := LoadConfig(filepath.Join(tmpDir, "nonexistent.yaml"), "")
 		if err == nil {
 			t.Error("LoadConfig() expected error with nonexistent file")
 		}
@@ -187,7 +435,7 @@ defaults:
 		os.Setenv("CODEDECODER_LLM_PROVIDER", "anthropic")
 		defer os.Unsetenv("CODEDECODER_LLM_PROVIDER")
 
-		cfg, err := LoadConfig(testConfigPath)
+		cfg, err := LoadConfig(testConfigPath, "")
 		if err != nil {
 			t.Fatalf("LoadConfig() error = %v", err)
 		}
@@ -198,3 +446,323 @@ defaults:
 		}
 	})
 }
+
+// captureStdout returns what f writes to os.Stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	f()
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read stdout: %v", err)
+	}
+	return string(data)
+}
+
+func TestConfig_ValidateAPIKeyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	keyPath := filepath.Join(tmpDir, "api_key")
+	if err := os.WriteFile(keyPath, []byte("key-from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	emptyPath := filepath.Join(tmpDir, "empty_key")
+	if err := os.WriteFile(emptyPath, []byte(" \n\t\n"), 0600); err != nil {
+		t.Fatalf("Failed to write empty key file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		llm     LLMConfig
+		wantErr bool
+	}{
+		{
+			name:    "key from readable file",
+			llm:     LLMConfig{Provider: "openai", APIKeyFile: keyPath, Model: "gpt-4"},
+			wantErr: false,
+		},
+		{
+			name:    "both api_key and api_key_file set",
+			llm:     LLMConfig{Provider: "openai", APIKey: "test-key", APIKeyFile: keyPath, Model: "gpt-4"},
+			wantErr: true,
+		},
+		{
+			name:    "unreadable key file",
+			llm:     LLMConfig{Provider: "openai", APIKeyFile: filepath.Join(tmpDir, "missing"), Model: "gpt-4"},
+			wantErr: true,
+		},
+		{
+			name:    "empty key file",
+			llm:     LLMConfig{Provider: "anthropic", APIKeyFile: emptyPath, Model: "claude-3-opus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{LLM: tt.llm}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_APIKeyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	keyPath := filepath.Join(tmpDir, "api_key")
+	if err := os.WriteFile(keyPath, []byte("secret-from-file\r\n"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `llm:
+  provider: openai
+  model: gpt-4
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	// The key file is supplied only through the environment
+	t.Setenv("CODEDECODER_LLM_API_KEY_FILE", keyPath)
+
+	cfg, err := LoadConfig(configPath, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.LLM.APIKeyFile != keyPath {
+		t.Errorf("Expected api_key_file '%s', got '%s'", keyPath, cfg.LLM.APIKeyFile)
+	}
+	if cfg.LLM.APIKey != "secret-from-file" {
+		t.Errorf("Expected API key 'secret-from-file', got '%s'", cfg.LLM.APIKey)
+	}
+}
+
+func TestConfig_ValidateReportsAllProblems(t *testing.T) {
+	os.Unsetenv("CODEDECODER_LLM_APIKEY")
+
+	cfg := Config{
+		LLM: LLMConfig{
+			Provider: "openai",
+			Model:    "gpt-4",
+		},
+		Defaults: DefaultsConfig{
+			Audience: "invalid-audience",
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Config.Validate() expected an error")
+	}
+
+	problems := Problems(fmt.Errorf("invalid configuration: %w", err))
+	if len(problems) != 2 {
+		t.Errorf("Expected 2 problems (missing key, invalid audience), got %d: %v", len(problems), problems)
+	}
+
+	if got := Problems(nil); got != nil {
+		t.Errorf("Expected no problems for a nil error, got %v", got)
+	}
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `llm:
+  provider: openai
+  api_key: test-key
+  model: gpt-4o
+profiles:
+  Draft:
+    provider: ollama
+    endpoint: http://localhost:11434
+    model: llama3
+    max_retries: 1
+  final:
+    provider: anthropic
+    api_key: other-key
+    model: claude-3-opus
+  broken:
+    provider: ollama
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		profile      string
+		wantProvider string
+		wantRetries  int
+		wantErr      string
+	}{
+		{name: "no profile uses the llm section", profile: "", wantProvider: "openai", wantRetries: DefaultMaxRetries},
+		{name: "profile names are case-insensitive", profile: "draft", wantProvider: "ollama", wantRetries: 1},
+		{name: "profile gets the default retries", profile: "final", wantProvider: "anthropic", wantRetries: DefaultMaxRetries},
+		{name: "selected profile is validated", profile: "broken", wantErr: "llm.endpoint is required"},
+		{name: "unknown profile", profile: "nope", wantErr: "available: broken, draft, final"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(configPath, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.LLM.Provider != tt.wantProvider {
+				t.Errorf("Expected provider '%s', got '%s'", tt.wantProvider, cfg.LLM.Provider)
+			}
+			if cfg.LLM.MaxRetries != tt.wantRetries {
+				t.Errorf("Expected %d retries, got %d", tt.wantRetries, cfg.LLM.MaxRetries)
+			}
+			if cfg.Profile != tt.profile {
+				t.Errorf("Expected profile '%s', got '%s'", tt.profile, cfg.Profile)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `llm:
+  provider: openai
+  api_key: test-key
+  model: gpt-4o
+  temperature: 5
+profiles:
+  local:
+    provider: ollama
+    endpoint: http://localhost:11434
+    model: llama3
+defaults:
+  concurrency: 2
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	// flags returns the bindings of a flag set parsed from args
+	flags := func(t *testing.T, args ...string) []FlagBinding {
+		t.Helper()
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.String("provider", "", "")
+		fs.Float64("temperature", 0, "")
+		fs.Int("concurrency", DefaultConcurrency, "")
+		if err := fs.Parse(args); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		return []FlagBinding{
+			{Key: "llm.provider", Flag: fs.Lookup("provider")},
+			{Key: "llm.temperature", Flag: fs.Lookup("temperature")},
+			{Key: "defaults.concurrency", Flag: fs.Lookup("concurrency")},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		env             map[string]string
+		args            []string
+		profile         string
+		wantProvider    string
+		wantConcurrency int
+		wantErr         string
+	}{
+		{
+			name:    "file",
+			wantErr: "llm.temperature must be between 0 and 2",
+		},
+		{
+			name:         "flag fixes an invalid file value",
+			args:         []string{"--temperature", "0.5"},
+			wantProvider: "openai", wantConcurrency: 2,
+		},
+		{
+			name:         "env overrides the file",
+			env:          map[string]string{"CODEDECODER_LLM_TEMPERATURE": "1", "CODEDECODER_DEFAULTS_CONCURRENCY": "3"},
+			wantProvider: "openai", wantConcurrency: 3,
+		},
+		{
+			name:         "flag overrides env",
+			env:          map[string]string{"CODEDECODER_LLM_TEMPERATURE": "1", "CODEDECODER_LLM_PROVIDER": "anthropic", "CODEDECODER_DEFAULTS_CONCURRENCY": "3"},
+			args:         []string{"--provider", "gemini", "--concurrency", "8"},
+			wantProvider: "gemini", wantConcurrency: 8,
+		},
+		{
+			name:         "unset flag does not mask the file",
+			env:          map[string]string{"CODEDECODER_LLM_TEMPERATURE": "1"},
+			args:         []string{},
+			wantProvider: "openai", wantConcurrency: 2,
+		},
+		{
+			name:         "flag overrides the profile",
+			args:         []string{"--provider", "lmstudio"},
+			profile:      "local",
+			wantProvider: "lmstudio", wantConcurrency: 2,
+		},
+		{
+			name:    "flags are named in errors",
+			args:    []string{"--temperature", "3"},
+			wantErr: "with flags --temperature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg, err := LoadConfig(configPath, tt.profile, flags(t, tt.args...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.LLM.Provider != tt.wantProvider {
+				t.Errorf("Expected provider '%s', got '%s'", tt.wantProvider, cfg.LLM.Provider)
+			}
+			if cfg.Defaults.Concurrency != tt.wantConcurrency {
+				t.Errorf("Expected concurrency %d, got %d", tt.wantConcurrency, cfg.Defaults.Concurrency)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_EnvWithoutFileKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("llm:\n  provider: openai\n  api_key: test-key\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	t.Setenv("CODEDECODER_LLM_MODEL", "gpt-4o")
+	t.Setenv("CODEDECODER_GITHUB_TOKEN", "env-token")
+	t.Setenv("CODEDECODER_DEFAULTS_MAX_SIZE", "1MB")
+
+	cfg, err := LoadConfig(configPath, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.LLM.Model != "gpt-4o" || cfg.GitHub.Token != "env-token" || cfg.Defaults.MaxSize != 1<<20 {
+		t.Errorf("Expected the environment to set keys missing from the file, got model %q, token %q, max size %d",
+			cfg.LLM.Model, cfg.GitHub.Token, cfg.Defaults.MaxSize)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// expandFileEnv expands the environment variables referenced by the string
+// values of the config file v read (see expandEnv), and merges the result
+// back, so that environment variables and flags still take precedence over
+// the file's values.
+func expandFileEnv(v *viper.Viper) error {
+	file := viper.New()
+	if err := SetConfigFile(file, v.ConfigFileUsed()); err != nil {
+		return err
+	}
+	if err := file.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	settings := file.AllSettings()
+	changed, err := expandValues(settings, "")
+	if err != nil {
+		return fmt.Errorf("failed to expand environment variables in the config file: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// expandValues expands the strings of a config map in place, including
+// those of nested maps and lists, and reports whether any changed. key is
+// the dotted key of m, named in warnings and errors.
+func expandValues(m map[string]any, key string) (bool, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report problems in a stable order
+
+	changed := false
+	for _, name := range names {
+		value, c, err := expandValue(m[name], key+name)
+		if err != nil {
+			return false, err
+		}
+		if c {
+			m[name], changed = value, true
+		}
+	}
+	return changed, nil
+}
+
+func expandValue(value any, key string) (any, bool, error) {
+	switch value := value.(type) {
+	case string:
+		expanded, err := expandEnv(value, os.LookupEnv, func(name string) {
+			slog.Warn("Config refers to an unset environment variable; using an empty value", "key", key, "variable", name)
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", key, err)
+		}
+		return expanded, expanded != value, nil
+	case map[string]any:
+		changed, err := expandValues(value, key+".")
+		return value, changed, err
+	case []any:
+		changed := false
+		for i, item := range value {
+			expanded, c, err := expandValue(item, fmt.Sprintf("%s[%d]", key, i))
+			if err != nil {
+				return nil, false, err
+			}
+			if c {
+				value[i], changed = expanded, true
+			}
+		}
+		return value, changed, nil
+	}
+	return value, false, nil
+}
+
+// expandEnv replaces references to environment variables in s with their
+// values, looked up with lookup:
+//
+//   - $NAME and ${NAME} are replaced with the value of NAME, or with an
+//     empty string, after calling unset with the name, if it is not set
+//   - ${NAME:-default} uses default, which may itself refer to variables,
+//     when NAME is unset or empty
+//   - ${NAME:?message} is an error when NAME is unset or empty, for values
+//     that must not silently be empty
+//   - $$ is a literal $, as is a $ not followed by a name or brace
+func expandEnv(s string, lookup func(string) (string, bool), unset func(name string)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			sb.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in %q", s)
+			}
+			value, err := expandBraced(s[i+2:end], lookup, unset)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+			i = end
+		case isNameStart(next):
+			end := i + 2
+			for end < len(s) && isNameChar(s[end]) {
+				end++
+			}
+			value, ok := lookup(s[i+1 : end])
+			if !ok && unset != nil {
+				unset(s[i+1 : end])
+			}
+			sb.WriteString(value)
+			i = end - 1
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+// expandBraced expands the inside of a ${...} reference.
+func expandBraced(ref string, lookup func(string) (string, bool), unset func(name string)) (string, error) {
+	name, op, arg := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 {
+		name, op, arg = ref[:i], ref[i:min(i+2, len(ref))], ref[min(i+2, len(ref)):]
+	}
+	if !isName(name) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", ref)
+	}
+	value, ok := lookup(name)
+	switch op {
+	case "":
+		if !ok && unset != nil {
+			unset(name)
+		}
+		return value, nil
+	case ":-":
+		if value != "" {
+			return value, nil
+		}
+		return expandEnv(arg, lookup, unset)
+	case ":?":
+		if value != "" {
+			return value, nil
+		}
+		if arg == "" {
+			arg = "not set"
+		}
+		return "", fmt.Errorf("environment variable %s: %s", name, arg)
+	}
+	return "", fmt.Errorf("unsupported expansion ${%s} (use ${NAME}, ${NAME:-default} or ${NAME:?message})", ref)
+}
+
+// closingBrace returns the index of the } closing the reference whose
+// contents start at start, skipping nested references, or -1.
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isName(s string) bool {
+	if s == "" || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
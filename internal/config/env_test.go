@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"KEY": "sk-123", "HOST": "localhost", "PORT": "11434", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		in        string
+		want      string
+		wantUnset []string
+		wantErr   string
+	}{
+		{in: "no variables", want: "no variables"},
+		{in: "${KEY}", want: "sk-123"},
+		{in: "$KEY", want: "sk-123"},
+		{in: "http://$HOST:${PORT}/api", want: "http://localhost:11434/api"},
+		{in: "pa$$word", want: "pa$word"},
+		{in: "$$KEY", want: "$KEY"},
+		{in: "costs $5 or $", want: "costs $5 or $"},
+		{in: "${MISSING}-$ALSO_MISSING", want: "-", wantUnset: []string{"MISSING", "ALSO_MISSING"}},
+		{in: "${EMPTY}", want: ""},
+		{in: "${MISSING:-fallback}", want: "fallback"},
+		{in: "${EMPTY:-fallback}", want: "fallback"},
+		{in: "${KEY:-fallback}", want: "sk-123"},
+		// Defaults may refer to other variables, themselves with defaults
+		{in: "${MISSING:-http://${HOST}:${NOPE:-${PORT}}}", want: "http://localhost:11434"},
+		{in: "${MISSING:-$$literal}", want: "$literal"},
+		{in: "${KEY:?set KEY}", want: "sk-123"},
+		{in: "${MISSING:?set it to your API key}", wantErr: "MISSING: set it to your API key"},
+		{in: "${EMPTY:?}", wantErr: "EMPTY: not set"},
+		{in: "${KEY", wantErr: "unterminated"},
+		{in: "${1KEY}", wantErr: "invalid variable name"},
+		{in: "${KEY:+x}", wantErr: "unsupported expansion"},
+	}
+
+	for _, tt := range tests {
+		var unset []string
+		got, err := expandEnv(tt.in, lookup, func(name string) { unset = append(unset, name) })
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expandEnv(%q) expected error containing %q, got %v", tt.in, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandEnv(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if !slices.Equal(unset, tt.wantUnset) {
+			t.Errorf("expandEnv(%q) reported unset %v, want %v", tt.in, unset, tt.wantUnset)
+		}
+	}
+}
+
+func TestLoadConfig_EnvInterpolation(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `llm:
+  provider: openai
+  api_key: ${TEST_OPENAI_KEY}
+  model: "${TEST_MODEL:-gpt-4o}"
+profiles:
+  local:
+    provider: ollama
+    endpoint: http://${TEST_OLLAMA_HOST}:11434
+    model: llama3
+defaults:
+  exclude: ["$TEST_EXCLUDE", "cost$$"]
+github:
+  token: $TEST_UNSET_TOKEN
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	t.Setenv("TEST_OPENAI_KEY", "sk-from-env")
+	t.Setenv("TEST_OLLAMA_HOST", "gpu-box")
+	t.Setenv("TEST_EXCLUDE", "vendor/*")
+
+	cfg, err := LoadConfig(configPath, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.LLM.APIKey != "sk-from-env" || cfg.LLM.Model != "gpt-4o" {
+		t.Errorf("Expected the key from the environment and the default model, got %q and %q", cfg.LLM.APIKey, cfg.LLM.Model)
+	}
+	if got := cfg.Profiles["local"].Endpoint; got != "http://gpu-box:11434" {
+		t.Errorf("Expected the profile endpoint to be expanded, got %q", got)
+	}
+	if !slices.Equal(cfg.Defaults.Exclude, []string{"vendor/*", "cost$"}) {
+		t.Errorf("Expected the list items to be expanded, got %v", cfg.Defaults.Exclude)
+	}
+	if cfg.GitHub.Token != "" {
+		t.Errorf("Expected an unset variable to expand to nothing, got %q", cfg.GitHub.Token)
+	}
+
+	// Environment variables of the keys still override the expanded file
+	t.Setenv("CODEDECODER_LLM_MODEL", "gpt-4.1")
+	if cfg, err = LoadConfig(configPath, ""); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.LLM.Model != "gpt-4.1" {
+		t.Errorf("Expected CODEDECODER_LLM_MODEL to take precedence, got %q", cfg.LLM.Model)
+	}
+
+	// A required variable that is not set is an error
+	required := strings.Replace(configContent, "${TEST_OPENAI_KEY}", "${TEST_UNSET_KEY:?export your OpenAI key}", 1)
+	if err := os.WriteFile(configPath, []byte(required), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	if _, err := LoadConfig(configPath, ""); err == nil || !strings.Contains(err.Error(), "llm.api_key: environment variable TEST_UNSET_KEY: export your OpenAI key") {
+		t.Errorf("Expected an error naming the key and variable, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// configName is the name of the config file searched for, without its
+// extension.
+const configName = "config"
+
+// configFormats are the formats of config files, named by their extension,
+// in the order they are searched for within a directory: config.yaml wins
+// over config.json, which wins over config.toml.
+var configFormats = []string{"yaml", "yml", "json", "toml"}
+
+// configDirs returns the directories searched for a config file, in order:
+// ~/.config/code-decoder, then the current directory.
+func configDirs() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return []string{filepath.Join(home, ".config", "code-decoder"), "."}, nil
+}
+
+// FindConfigFile returns the path of the config file in the first of
+// configDirs holding one: config.yaml, config.yml, config.json or
+// config.toml. It returns "" if there is none.
+func FindConfigFile() (string, error) {
+	dirs, err := configDirs()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		for _, format := range configFormats {
+			path := filepath.Join(dir, configName+"."+format)
+			info, err := os.Stat(path)
+			switch {
+			case err == nil && !info.IsDir():
+				return path, nil
+			case err != nil && !errors.Is(err, fs.ErrNotExist):
+				return "", fmt.Errorf("failed to inspect config file: %w", err)
+			}
+		}
+	}
+	return "", nil
+}
+
+// SetConfigFile makes v read the config file at path, in the format its
+// extension names (see configFormats), or as YAML when it has none. It fails
+// for an extension naming another format.
+func SetConfigFile(v *viper.Viper, path string) error {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch {
+	case format == "":
+		format = "yaml"
+	case !slices.Contains(configFormats, format):
+		return fmt.Errorf("unsupported config file format %q in %s (use %s)", format, path, strings.Join(configFormats, ", "))
+	}
+	v.SetConfigFile(path)
+	v.SetConfigType(format)
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// formatConfigs hold the same settings in each config file format.
+var formatConfigs = map[string]string{
+	"yaml": "llm:\n  provider: openai\n  api_key: test-key\n  model: MODEL\ndefaults:\n  max_size: 2MB\n",
+	"json": `{"llm": {"provider": "openai", "api_key": "test-key", "model": "MODEL"}, "defaults": {"max_size": "2MB"}}`,
+	"toml": "[llm]\nprovider = \"openai\"\napi_key = \"test-key\"\nmodel = \"MODEL\"\n\n[defaults]\nmax_size = \"2MB\"\n",
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		format string
+	}{
+		{name: "yaml", file: "config.yaml", format: "yaml"},
+		{name: "yml", file: "settings.yml", format: "yaml"},
+		{name: "json", file: "config.json", format: "json"},
+		{name: "toml", file: "code-decoder.toml", format: "toml"},
+		{name: "uppercase extension", file: "CONFIG.JSON", format: "json"},
+		{name: "no extension is yaml", file: "config", format: "yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			content := strings.ReplaceAll(formatConfigs[tt.format], "MODEL", tt.name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			cfg, err := LoadConfig(path, "")
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.LLM.Provider != "openai" || cfg.LLM.Model != tt.name || cfg.Defaults.MaxSize != 2*1024*1024 || cfg.File != path {
+				t.Errorf("Unexpected config loaded from %s: %+v", tt.file, cfg)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte("[llm]\nprovider=openai\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	if _, err := LoadConfig(path, ""); err == nil || !strings.Contains(err.Error(), `unsupported config file format "ini"`) {
+		t.Errorf("Expected an error for an unsupported format, got %v", err)
+	}
+}
+
+func TestLoadConfig_SearchFormats(t *testing.T) {
+	home, cwd := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(cwd)
+	homeDir := filepath.Join(home, ".config", "code-decoder")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	write := func(dir, format, model string) {
+		t.Helper()
+		content := strings.ReplaceAll(formatConfigs[format], "MODEL", model)
+		if err := os.WriteFile(filepath.Join(dir, "config."+format), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+	}
+	load := func() string {
+		t.Helper()
+		cfg, err := LoadConfig("", "")
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		return cfg.LLM.Model
+	}
+
+	// A TOML file in the home directory is found
+	write(homeDir, "toml", "home-toml")
+	if got := load(); got != "home-toml" {
+		t.Errorf("Expected the TOML config of the home directory, got model %q", got)
+	}
+	// The home directory comes first, whatever the format
+	write(cwd, "yaml", "cwd-yaml")
+	if got := load(); got != "home-toml" {
+		t.Errorf("Expected the TOML config of the home directory, got model %q", got)
+	}
+	// Within a directory, JSON wins over TOML, and YAML over both
+	write(homeDir, "json", "home-json")
+	if got := load(); got != "home-json" {
+		t.Errorf("Expected the JSON config to win over TOML, got model %q", got)
+	}
+	write(homeDir, "yaml", "home-yaml")
+	if got := load(); got != "home-yaml" {
+		t.Errorf("Expected the YAML config to win over JSON, got model %q", got)
+	}
+	// A file named config without an extension is not a config file
+	if err := os.WriteFile(filepath.Join(cwd, "config"), []byte("not: [yaml"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if path, err := FindConfigFile(); err != nil || path != filepath.Join(homeDir, "config.yaml") {
+		t.Errorf("Expected the extensionless config to be ignored, got %q (%v)", path, err)
+	}
+}
+
+func TestLoadConfig_SearchOrder(t *testing.T) {
+	home, cwd := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(cwd)
+	homeDir := filepath.Join(home, ".config", "code-decoder")
+
+	dirs, err := configDirs()
+	if err != nil {
+		t.Fatalf("configDirs() error = %v", err)
+	}
+	if want := []string{homeDir, "."}; !slices.Equal(dirs, want) {
+		t.Errorf("Expected the search order %v, got %v", want, dirs)
+	}
+
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	for dir, model := range map[string]string{homeDir: "home", cwd: "cwd"} {
+		content := strings.ReplaceAll(formatConfigs["yaml"], "MODEL", model)
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+	}
+	cfg, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.LLM.Model != "home" || cfg.File != filepath.Join(homeDir, "config.yaml") {
+		t.Errorf("Expected the config.yaml of the home directory to win over the current directory's, got model %q from %s", cfg.LLM.Model, cfg.File)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Sources of the settings, as reported in Setting.Source.
+const (
+	SourceFlag    = "flag"    // A command-line flag
+	SourceEnv     = "env"     // A CODEDECODER_* environment variable
+	SourceFile    = "file"    // The config file (or, for llm.api_key, llm.api_key_file)
+	SourceDefault = "default" // Neither: the built-in default
+)
+
+// secretKeys are the keys whose values Settings redacts.
+var secretKeys = map[string]bool{"llm.api_key": true, "github.token": true}
+
+// Setting is one resolved configuration value.
+type Setting struct {
+	Key    string `json:"key"`    // Dotted key, e.g., "llm.model"
+	Value  any    `json:"value"`  // Resolved value; secrets are redacted and durations written as strings
+	Source string `json:"source"` // Where the value came from: SourceFlag, SourceEnv, SourceFile or SourceDefault
+}
+
+// Settings returns every setting of c except the profiles, in the order of
+// the Config struct, with secrets redacted (see Redact). The source of each
+// is known when c was returned by LoadConfig; otherwise it is reported as
+// SourceDefault.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	eachKey(reflect.ValueOf(*c), "", func(key string, value reflect.Value) {
+		setting := Setting{Key: key, Value: value.Interface(), Source: c.sources[key]}
+		switch v := setting.Value.(type) {
+		case string:
+			if secretKeys[key] {
+				setting.Value = Redact(v)
+			}
+		case time.Duration:
+			setting.Value = v.String()
+		case *float64:
+			if v == nil {
+				setting.Value = nil
+			} else {
+				setting.Value = *v
+			}
+		}
+		if setting.Source == "" {
+			setting.Source = SourceDefault
+		}
+		settings = append(settings, setting)
+	})
+	return settings
+}
+
+// Redact hides all of secret but its first three and last four characters
+// (e.g., "sk-...abcd"), or all of it if it is too short to show any.
+func Redact(secret string) string {
+	switch {
+	case secret == "":
+		return ""
+	case len(secret) < 12:
+		return "****"
+	}
+	return secret[:3] + "..." + secret[len(secret)-4:]
+}
+
+// eachKey calls fn with the dotted key and value of every setting of the
+// config struct v, whose keys are prefixed with prefix. Maps (the profiles)
+// are skipped, as in bindEnv.
+func eachKey(v reflect.Value, prefix string, fn func(key string, value reflect.Value)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Map:
+		case reflect.Struct:
+			eachKey(v.Field(i), prefix+name+".", fn)
+		default:
+			fn(prefix+name, v.Field(i))
+		}
+	}
+}
+
+// settingSources returns where v found the value of each setting of c: the
+// keys bound to flags that were set, the environment, the config file, or
+// none of them. With a profile, the llm settings come from its section of
+// the file.
+func settingSources(v *viper.Viper, c *Config, flagKeys map[string]bool) map[string]string {
+	sources := make(map[string]string)
+	eachKey(reflect.ValueOf(*c), "", func(key string, _ reflect.Value) {
+		fileKey, inEnv := key, false
+		if field, ok := strings.CutPrefix(key, "llm."); ok && c.Profile != "" {
+			// The profile replaced the llm section, environment included
+			fileKey = "profiles." + strings.ToLower(c.Profile) + "." + field
+		} else {
+			_, inEnv = os.LookupEnv("CODEDECODER_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_")))
+		}
+		switch {
+		case flagKeys[key]:
+			sources[key] = SourceFlag
+		case inEnv:
+			sources[key] = SourceEnv
+		case v.InConfig(fileKey), key == "llm.api_key" && c.LLM.APIKeyFile != "":
+			sources[key] = SourceFile
+		default:
+			sources[key] = SourceDefault
+		}
+	})
+	return sources
+}
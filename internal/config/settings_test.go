@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{"", ""},
+		{"short", "****"},
+		{"sk-proj-abcdefgh1234", "sk-...1234"},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.secret); got != tt.want {
+			t.Errorf("Redact(%q) = %q, expected %q", tt.secret, got, tt.want)
+		}
+	}
+}
+
+func TestSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+llm:
+  provider: openai
+  api_key: sk-proj-abcdefgh1234
+  model: gpt-4o
+profiles:
+  local:
+    provider: ollama
+    endpoint: http://localhost:11434
+    model: llama3
+defaults:
+  include: ["*.go"]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	t.Setenv("CODEDECODER_DEFAULTS_CONCURRENCY", "8")
+	t.Setenv("CODEDECODER_LLM_MAX_RETRIES", "5")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("model", "", "")
+	if err := fs.Parse([]string{"--model", "gpt-4.1"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	flags := []FlagBinding{{Key: "llm.model", Flag: fs.Lookup("model")}}
+
+	tests := []struct {
+		name    string
+		profile string
+		want    map[string]Setting
+	}{
+		{
+			name: "llm section",
+			want: map[string]Setting{
+				"llm.provider":         {Value: "openai", Source: SourceFile},
+				"llm.api_key":          {Value: "sk-...1234", Source: SourceFile},
+				"llm.model":            {Value: "gpt-4.1", Source: SourceFlag},
+				"llm.max_retries":      {Value: 5, Source: SourceEnv},
+				"llm.retry_base_delay": {Value: "1s", Source: SourceDefault},
+				"llm.temperature":      {Value: nil, Source: SourceDefault},
+				"defaults.concurrency": {Value: 8, Source: SourceEnv},
+				"defaults.include":     {Value: []string{"*.go"}, Source: SourceFile},
+			},
+		},
+		{
+			// The profile replaces the llm section, environment included
+			name:    "profile",
+			profile: "local",
+			want: map[string]Setting{
+				"llm.provider":    {Value: "ollama", Source: SourceFile},
+				"llm.model":       {Value: "gpt-4.1", Source: SourceFlag},
+				"llm.max_retries": {Value: DefaultMaxRetries, Source: SourceDefault},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(configPath, tt.profile, flags...)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			got := make(map[string]Setting)
+			for _, setting := range cfg.Settings() {
+				got[setting.Key] = setting
+			}
+			for key, want := range tt.want {
+				setting, ok := got[key]
+				if !ok {
+					t.Errorf("Expected a setting for %s", key)
+					continue
+				}
+				if setting.Source != want.Source || fmt.Sprint(setting.Value) != fmt.Sprint(want.Value) {
+					t.Errorf("%s: expected %v from %s, got %v from %s", key, want.Value, want.Source, setting.Value, setting.Source)
+				}
+			}
+			if _, ok := got["profiles"]; ok {
+				t.Error("Expected the profiles to be left out")
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package generation writes tutorial chapters from a codebase analysis using an LLM.
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+// Chapter is a generated tutorial chapter covering one abstraction.
+type Chapter struct {
+	Index       int    `json:"index"`       // 1-based position in the tutorial
+	Title       string `json:"title"`       // Chapter title
+	Abstraction string `json:"abstraction"` // Name of the abstraction the chapter covers
+	Content     string `json:"content"`     // Markdown content
+}
+
+// Generator writes tutorial chapters with an LLM.
+type Generator struct {
+	Provider llm.Provider
+	Options  llm.CompletionOptions // Sent with every chapter request
+	Prompts  *prompts.Set          // Prompt templates; nil uses prompts.Default()
+	Audience string                // Who the chapters are written for (see prompts.Audiences); empty means prompts.AudienceDeveloper
+	Language string                // Name of the language the chapters are written in (see LookupLanguage); empty means DefaultLanguage
+	Words    int                   // Approximate length of each chapter in words, asked for in its prompt (see Length); 0 leaves it to the model
+
+	// OnChunk, when set, receives each chapter's text as it is streamed from
+	// the provider (e.g., to show progress in verbose mode).
+	OnChunk func(chapter Chapter, text string)
+
+	// Only, when not empty, holds the names of the abstractions to write
+	// chapters about, such as to regenerate some chapters of a tutorial; the
+	// others are skipped. Chapters keep their positions among all the
+	// abstractions.
+	Only []string
+
+	// Completed holds chapters written by an earlier, interrupted run (see
+	// Manifest). A chapter covering the same abstraction at the same position
+	// is reused instead of being generated again.
+	Completed []Chapter
+
+	// OnChapter, when set, is called with each chapter once it is complete,
+	// whether generated or reused. Generation stops if it returns an error.
+	OnChapter func(chapter Chapter) error
+
+	// ContextWindow is the model's context window in tokens. Chapter prompts
+	// that would not fit, leaving room for the response (see
+	// llm.PromptBudget), are trimmed of context until they do; 0 sends them
+	// whole.
+	ContextWindow int
+
+	// Tokenizer counts the tokens a prompt occupies for the context window
+	// guard; nil uses llm.HeuristicTokenizer.
+	Tokenizer llm.Tokenizer
+}
+
+// emptyChapterInstruction is appended to a chapter prompt whose response was
+// empty, to ask for the chapter again.
+const emptyChapterInstruction = "\n\nYour previous answer was empty. Write the chapter described above, in Markdown."
+
+// Generate writes one chapter for each abstraction of a, or of Only.
+//
+// A chapter whose response is empty is asked for once more. A chapter that
+// still cannot be written is logged and left out, and the others are
+// written; Generate then returns the chapters written along with an error
+// naming the chapters that failed. Errors showing that no request can
+// succeed (see llm.IsFatal) stop the generation at once.
+func (g *Generator) Generate(ctx context.Context, a *analysis.Analysis) ([]Chapter, error) {
+	if len(a.Abstractions) == 0 {
+		return nil, errors.New("the analysis contains no abstractions to write chapters about; re-run analyze")
+	}
+
+	chapters := make([]Chapter, 0, len(a.Abstractions))
+	var failures []error
+	selected := 0
+	for i, abs := range a.Abstractions {
+		if !g.selected(abs) {
+			continue
+		}
+		selected++
+		chapter := Chapter{Index: i + 1, Title: abs.Name, Abstraction: abs.Name}
+		if done, ok := g.completed(chapter); ok {
+			slog.Info("Reusing chapter", "index", chapter.Index, "title", chapter.Title)
+			chapter = done
+		} else {
+			slog.Info("Generating chapter", "index", chapter.Index, "title", chapter.Title)
+			prompt, err := g.chapterPrompt(a, abs)
+			if err != nil {
+				return chapters, err
+			}
+			content, err := g.write(ctx, chapter, prompt)
+			if err != nil {
+				err = fmt.Errorf("generating chapter %d (%s): %w", chapter.Index, chapter.Title, err)
+				if llm.IsFatal(err) {
+					return chapters, err
+				}
+				slog.Warn("Skipping chapter that could not be generated", "index", chapter.Index, "title", chapter.Title, "error", err)
+				failures = append(failures, err)
+				continue
+			}
+			chapter.Content = content
+		}
+		chapters = append(chapters, chapter)
+
+		if g.OnChapter != nil {
+			if err := g.OnChapter(chapter); err != nil {
+				return chapters, err
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return chapters, fmt.Errorf("%d of %d chapters could not be generated: %w", len(failures), selected, errors.Join(failures...))
+	}
+	return chapters, nil
+}
+
+// ChapterPrompts returns the prompts Generate would send for a, one per chapter,
+// without calling the provider.
+func (g *Generator) ChapterPrompts(a *analysis.Analysis) ([]string, error) {
+	prompts := make([]string, 0, len(a.Abstractions))
+	for _, abs := range a.Abstractions {
+		if !g.selected(abs) {
+			continue
+		}
+		prompt, err := g.chapterPrompt(a, abs)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+// selected reports whether Generate writes the chapter about abs.
+func (g *Generator) selected(abs analysis.Abstraction) bool {
+	return len(g.Only) == 0 || slices.Contains(g.Only, abs.Name)
+}
+
+// completed returns the chapter from Completed matching chapter's position
+// and abstraction, if there is one with content.
+func (g *Generator) completed(chapter Chapter) (Chapter, bool) {
+	for _, done := range g.Completed {
+		if done.Index == chapter.Index && done.Abstraction == chapter.Abstraction && done.Content != "" {
+			return done, true
+		}
+	}
+	return Chapter{}, false
+}
+
+// write runs the prompt of chapter and returns its trimmed content, asking
+// once more if the response is empty.
+func (g *Generator) write(ctx context.Context, chapter Chapter, prompt string) (string, error) {
+	content, err := g.complete(ctx, chapter, prompt)
+	if err != nil {
+		return "", err
+	}
+	if content = strings.TrimSpace(content); content != "" {
+		return content, nil
+	}
+
+	slog.Warn("Empty chapter response; asking again", "index", chapter.Index, "title", chapter.Title)
+	content, err = g.complete(ctx, chapter, prompt+emptyChapterInstruction)
+	if err != nil {
+		return "", err
+	}
+	if content = strings.TrimSpace(content); content == "" {
+		return "", llm.ErrEmptyResponse
+	}
+	return content, nil
+}
+
+// complete runs a prompt, streaming the response to OnChunk when it is set.
+func (g *Generator) complete(ctx context.Context, chapter Chapter, prompt string) (string, error) {
+	if g.OnChunk == nil {
+		return g.Provider.Complete(ctx, prompt, g.Options)
+	}
+
+	ch, err := g.Provider.CompleteStream(ctx, prompt, g.Options)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return "", chunk.Err
+		}
+		g.OnChunk(chapter, chunk.Text)
+		sb.WriteString(chunk.Text)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// chapterPrompt builds the prompt used to write the chapter about abs.
+func (g *Generator) chapterPrompt(a *analysis.Analysis, abs analysis.Abstraction) (string, error) {
+	summaries := make(map[string]string, len(a.Files))
+	for _, f := range a.Files {
+		summaries[f.Path] = f.Summary
+	}
+
+	audience := g.Audience
+	if audience == "" {
+		audience = prompts.AudienceDeveloper
+	}
+	language := g.Language
+	if language == "" {
+		language = DefaultLanguage.Name
+	}
+	data := prompts.ChapterData{Project: a.ProjectName, Name: abs.Name, Description: abs.Description, Audience: audience, Language: language, Words: g.Words}
+	for _, path := range abs.Files {
+		data.Files = append(data.Files, prompts.FileSummary{Path: path, Summary: summaries[path]})
+	}
+	for _, other := range a.Abstractions {
+		if other.Name != abs.Name {
+			data.Others = append(data.Others, other.Name)
+		}
+	}
+
+	templates := g.Prompts
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	return g.fit(templates, data)
+}
+
+// fit renders the chapter prompt for data, trimming its context until it
+// fits the context window: first the summaries of the abstraction's files,
+// then the other abstractions, then the files themselves, starting from the
+// last (and least relevant) of each. It fails if the instructions alone do
+// not fit.
+func (g *Generator) fit(templates *prompts.Set, data prompts.ChapterData) (string, error) {
+	prompt, err := templates.Chapter(data)
+	if err != nil || g.ContextWindow <= 0 {
+		return prompt, err
+	}
+	tokenizer := g.Tokenizer
+	if tokenizer == nil {
+		tokenizer = llm.HeuristicTokenizer
+	}
+	count := tokenizer.CountTokens
+	budget := llm.PromptBudget(g.ContextWindow, g.Options.MaxTokens) - count(g.Options.System)
+	tokens := count(prompt)
+	if tokens <= budget {
+		return prompt, nil
+	}
+
+	files, others := len(data.Files), len(data.Others)
+	data.Files = slices.Clone(data.Files)
+	summarized := len(data.Files)
+	for count(prompt) > budget {
+		for summarized > 0 && data.Files[summarized-1].Summary == "" {
+			summarized--
+		}
+		switch {
+		case summarized > 0:
+			data.Files[summarized-1].Summary = ""
+		case len(data.Others) > 0:
+			data.Others = data.Others[:len(data.Others)-1]
+		case len(data.Files) > 0:
+			data.Files = data.Files[:len(data.Files)-1]
+		default:
+			return "", fmt.Errorf("the chapter prompt for %s does not fit the model's %d-token context window, even without context", data.Name, g.ContextWindow)
+		}
+		if prompt, err = templates.Chapter(data); err != nil {
+			return "", err
+		}
+	}
+	slog.Warn("Chapter prompt exceeds the model's context window; trimmed its context to fit",
+		"abstraction", data.Name, "tokens", tokens, "budget", budget,
+		"files", fmt.Sprintf("%d of %d", len(data.Files), files), "others", fmt.Sprintf("%d of %d", len(data.Others), others))
+	return prompt, nil
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+func testAnalysis() *analysis.Analysis {
+	return &analysis.Analysis{
+		ProjectName: "demo",
+		Files: []analysis.File{
+			{Path: "config.go", Summary: "Defines the config."},
+			{Path: "cli.go"},
+		},
+		Abstractions: []analysis.Abstraction{
+			{Name: "Config", Description: "Settings", Files: []string{"config.go"}},
+			{Name: "CLI", Description: "Commands", Files: []string{"cli.go"}},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, `abstraction "Config"`) {
+			return "# Config\n\nAll about config.\n", nil
+		}
+		return "# CLI\n\nAll about the CLI.", nil
+	}}
+
+	g := &Generator{Provider: provider}
+	chapters, err := g.Generate(context.Background(), testAnalysis())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Index != 1 || chapters[0].Title != "Config" || chapters[0].Content != "# Config\n\nAll about config." {
+		t.Errorf("Unexpected first chapter: %+v", chapters[0])
+	}
+	if chapters[1].Index != 2 || chapters[1].Abstraction != "CLI" {
+		t.Errorf("Unexpected second chapter: %+v", chapters[1])
+	}
+
+	prompt := provider.Prompts()[0]
+	for _, want := range []string{`"demo"`, "config.go: Defines the config.", "Other abstractions in the project: CLI"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestChapterPrompts(t *testing.T) {
+	provider := &llmtest.Provider{}
+	g := &Generator{Provider: provider}
+
+	prompts, err := g.ChapterPrompts(testAnalysis())
+	if err != nil {
+		t.Fatalf("ChapterPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 prompts, got %d", len(prompts))
+	}
+	if !strings.Contains(prompts[1], `abstraction "CLI"`) {
+		t.Errorf("Expected second prompt to cover CLI, got:\n%s", prompts[1])
+	}
+	if len(provider.Prompts()) != 0 {
+		t.Error("ChapterPrompts() must not call the provider")
+	}
+}
+
+func TestChapterPromptsByAudience(t *testing.T) {
+	tests := []struct {
+		audience string
+		want     string
+	}{
+		{audience: "", want: "developers who want to use the project"},
+		{audience: "developer", want: "developers who want to use the project"},
+		{audience: "beginner", want: "analogies"},
+		{audience: "contributor", want: "extension points"},
+	}
+
+	seen := map[string]string{}
+	for _, tt := range tests {
+		g := &Generator{Audience: tt.audience}
+		prompts, err := g.ChapterPrompts(testAnalysis())
+		if err != nil {
+			t.Fatalf("ChapterPrompts() error = %v", err)
+		}
+		if !strings.Contains(prompts[0], tt.want) {
+			t.Errorf("Expected the %q prompt to contain %q, got:\n%s", tt.audience, tt.want, prompts[0])
+		}
+		seen[prompts[0]] = tt.audience
+	}
+	// The empty audience is the developer one; the others all differ
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 distinct prompts for the audiences, got %d", len(seen))
+	}
+}
+
+func TestChapterPromptsLanguage(t *testing.T) {
+	g := &Generator{}
+	prompts, err := g.ChapterPrompts(testAnalysis())
+	if err != nil {
+		t.Fatalf("ChapterPrompts() error = %v", err)
+	}
+	if !strings.Contains(prompts[0], "Write the chapter in English") || strings.Contains(prompts[0], "translate") {
+		t.Errorf("Expected the default prompt to ask for English, got:\n%s", prompts[0])
+	}
+
+	g = &Generator{Language: "French"}
+	if prompts, err = g.ChapterPrompts(testAnalysis()); err != nil {
+		t.Fatalf("ChapterPrompts() error = %v", err)
+	}
+	for _, want := range []string{"Write the chapter in French", "Keep code, identifiers"} {
+		if !strings.Contains(prompts[0], want) {
+			t.Errorf("Expected the French prompt to contain %q, got:\n%s", want, prompts[0])
+		}
+	}
+}
+
+func TestChapterPromptsLength(t *testing.T) {
+	g := &Generator{}
+	prompts, err := g.ChapterPrompts(testAnalysis())
+	if err != nil {
+		t.Fatalf("ChapterPrompts() error = %v", err)
+	}
+	if strings.Contains(prompts[0], "Aim for about") {
+		t.Errorf("Expected no length instruction by default, got:\n%s", prompts[0])
+	}
+
+	length, err := LookupLength("Long")
+	if err != nil {
+		t.Fatalf("LookupLength() error = %v", err)
+	}
+	g = &Generator{Words: length.Words}
+	if prompts, err = g.ChapterPrompts(testAnalysis()); err != nil {
+		t.Fatalf("ChapterPrompts() error = %v", err)
+	}
+	if !strings.Contains(prompts[0], "Aim for about 3000 words") {
+		t.Errorf("Expected the prompt to ask for 3000 words, got:\n%s", prompts[0])
+	}
+	if _, err := LookupLength("epic"); err == nil {
+		t.Error("Expected an unknown chapter length to be rejected")
+	}
+}
+
+func TestChapterPromptFitsContextWindow(t *testing.T) {
+	a := &analysis.Analysis{
+		ProjectName: "demo",
+		Files: []analysis.File{
+			{Path: "FILE1.go", Summary: "SUM SUM"},
+			{Path: "FILE2.go", Summary: "SUM"},
+		},
+		Abstractions: []analysis.Abstraction{
+			{Name: "A", Description: "Core", Files: []string{"FILE1.go", "FILE2.go"}},
+			{Name: "OTHERB"},
+			{Name: "OTHERC"},
+		},
+	}
+	// The fake counter only counts the context, 7 tokens in all; 10 tokens are kept for the response
+	count := func(text string) int {
+		return strings.Count(text, "SUM") + strings.Count(text, "OTHER") + strings.Count(text, "FILE")
+	}
+
+	tests := []struct {
+		window  int
+		want    []string
+		notWant []string
+	}{
+		{0, []string{"FILE1.go: SUM SUM", "FILE2.go: SUM", "OTHERB, OTHERC"}, nil},
+		{17, []string{"FILE1.go: SUM SUM", "FILE2.go: SUM", "OTHERB, OTHERC"}, nil},
+		{16, []string{"FILE1.go: SUM SUM", "- FILE2.go\n", "OTHERB, OTHERC"}, []string{"FILE2.go:"}},
+		{13, []string{"- FILE1.go\n", "- FILE2.go\n", "OTHERB"}, []string{"SUM", "OTHERC"}},
+		{11, []string{"- FILE1.go"}, []string{"SUM", "OTHER", "FILE2"}},
+	}
+	for _, tt := range tests {
+		g := &Generator{ContextWindow: tt.window, Tokenizer: llm.TokenizerFunc(count), Options: llm.CompletionOptions{MaxTokens: 10}}
+		prompts, err := g.ChapterPrompts(a)
+		if err != nil {
+			t.Fatalf("ChapterPrompts() with a %d-token window error = %v", tt.window, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(prompts[0], want) {
+				t.Errorf("Expected the prompt for a %d-token window to contain %q, got:\n%s", tt.window, want, prompts[0])
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(prompts[0], notWant) {
+				t.Errorf("Expected the prompt for a %d-token window not to contain %q, got:\n%s", tt.window, notWant, prompts[0])
+			}
+		}
+	}
+	if a.Files[1].Summary != "SUM" {
+		t.Errorf("Expected trimming to leave the analysis unchanged, got %+v", a.Files)
+	}
+
+	g := &Generator{ContextWindow: 10, Tokenizer: llm.TokenizerFunc(func(string) int { return 1 }), Options: llm.CompletionOptions{MaxTokens: 10}}
+	if _, err := g.ChapterPrompts(a); err == nil {
+		t.Error("Expected an error when the instructions alone exceed the context window")
+	}
+}
+
+func TestGenerateStreaming(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(string) (string, error) { return "streamed chapter text", nil }}
+
+	var streamed strings.Builder
+	g := &Generator{
+		Provider: provider,
+		OnChunk:  func(_ Chapter, text string) { streamed.WriteString(text) },
+	}
+	chapters, err := g.Generate(context.Background(), testAnalysis())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := streamed.String(); got != "streamed chapter textstreamed chapter text" {
+		t.Errorf("Unexpected streamed text %q", got)
+	}
+	if chapters[0].Content != "streamed chapter text" {
+		t.Errorf("Unexpected chapter content %q", chapters[0].Content)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	g := &Generator{Provider: &llmtest.Provider{}}
+	if _, err := g.Generate(context.Background(), &analysis.Analysis{}); err == nil {
+		t.Error("Generate() expected an error for an analysis without abstractions")
+	}
+
+	failing := &llmtest.Provider{Respond: func(string) (string, error) { return "", errors.New("boom") }}
+	g = &Generator{Provider: failing}
+	if _, err := g.Generate(context.Background(), testAnalysis()); err == nil {
+		t.Error("Generate() expected the provider error")
+	}
+}
+
+func TestGenerateUnusableResponses(t *testing.T) {
+	// Config answers when asked again; CLI never does
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) {
+		if strings.Contains(prompt, `abstraction "Config"`) && strings.Contains(prompt, "Your previous answer was empty") {
+			return "# Config", nil
+		}
+		return "\n", nil
+	}}
+	var recorded []string
+	g := &Generator{Provider: provider, OnChapter: func(ch Chapter) error {
+		recorded = append(recorded, ch.Title)
+		return nil
+	}}
+	chapters, err := g.Generate(context.Background(), testAnalysis())
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 chapters could not be generated") || !strings.Contains(err.Error(), "chapter 2 (CLI)") {
+		t.Fatalf("Expected an error naming the CLI chapter, got %v", err)
+	}
+	if !errors.Is(err, llm.ErrEmptyResponse) {
+		t.Errorf("Expected the error to wrap llm.ErrEmptyResponse, got %v", err)
+	}
+	if len(chapters) != 1 || chapters[0].Content != "# Config" || strings.Join(recorded, ",") != "Config" {
+		t.Errorf("Expected only the Config chapter to be written, got %+v (recorded %q)", chapters, recorded)
+	}
+	if n := len(provider.Prompts()); n != 4 {
+		t.Errorf("Expected 4 requests, got %d", n)
+	}
+
+	rejected := &llmtest.Provider{Respond: func(string) (string, error) {
+		return "", &llm.APIError{Provider: "fake", StatusCode: 401, Message: "bad key"}
+	}}
+	g = &Generator{Provider: rejected}
+	if _, err := g.Generate(context.Background(), testAnalysis()); err == nil || len(rejected.Prompts()) != 1 {
+		t.Errorf("Expected a rejected API key to stop the generation, got %v after %d requests", err, len(rejected.Prompts()))
+	}
+}
+
+func TestGenerateResume(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) { return "# New", nil }}
+
+	var recorded []string
+	g := &Generator{
+		Provider: provider,
+		Completed: []Chapter{
+			{Index: 1, Title: "Config", Abstraction: "Config", Content: "# Saved"},
+			{Index: 2, Title: "Other", Abstraction: "Other", Content: "# Stale"},
+		},
+		OnChapter: func(ch Chapter) error {
+			recorded = append(recorded, ch.Content)
+			return nil
+		},
+	}
+	chapters, err := g.Generate(context.Background(), testAnalysis())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if chapters[0].Content != "# Saved" || chapters[1].Content != "# New" {
+		t.Errorf("Expected the first chapter to be reused and the second generated, got %+v", chapters)
+	}
+	if n := len(provider.Prompts()); n != 1 {
+		t.Errorf("Expected 1 request, got %d", n)
+	}
+	if strings.Join(recorded, ",") != "# Saved,# New" {
+		t.Errorf("Expected OnChapter for both chapters, got %q", recorded)
+	}
+}
+
+func TestGenerateOnly(t *testing.T) {
+	provider := &llmtest.Provider{Respond: func(prompt string) (string, error) { return "# CLI", nil }}
+
+	g := &Generator{Provider: provider, Only: []string{"CLI"}}
+	chapters, err := g.Generate(context.Background(), testAnalysis())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// The chapter keeps its position among all the abstractions
+	if len(chapters) != 1 || chapters[0].Index != 2 || chapters[0].Abstraction != "CLI" {
+		t.Errorf("Expected chapter 2 only, got %+v", chapters)
+	}
+	if prompts := provider.Prompts(); len(prompts) != 1 || !strings.Contains(prompts[0], `abstraction "CLI"`) {
+		t.Errorf("Expected a single request for CLI, got %q", prompts)
+	}
+	if prompts, err := g.ChapterPrompts(testAnalysis()); err != nil || len(prompts) != 1 {
+		t.Errorf("Expected the prompt of the selected chapter only, got %d (%v)", len(prompts), err)
+	}
+}
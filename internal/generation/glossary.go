@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/prompts"
+)
+
+const (
+	// maxGlossaryExcerpt is the most bytes of each chapter quoted in the
+	// glossary prompt, since chapters introduce their terms early on.
+	maxGlossaryExcerpt = 2048
+	// maxGlossaryTerms is the most terms the glossary prompt asks for.
+	maxGlossaryTerms = 40
+)
+
+// GlossaryEntry is a key term of a tutorial.
+type GlossaryEntry struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+	Chapters   []int  `json:"chapters"` // Indexes of the chapters introducing the term, in order
+}
+
+// GlossaryDefault reports whether tutorials written for audience include a
+// glossary unless asked otherwise: beginners need the terminology most,
+// while contributors know it already.
+func GlossaryDefault(audience string) bool {
+	return audience == prompts.AudienceBeginner
+}
+
+// glossaryResponse is the structured response expected when asking for the
+// key terms of a tutorial.
+type glossaryResponse struct {
+	Terms []struct {
+		Term       string `json:"term"`
+		Definition string `json:"definition"`
+		Chapters   []int  `json:"chapters"`
+	} `json:"terms"`
+}
+
+// Glossary returns the key terms of the chapters written from a, sorted
+// alphabetically regardless of case. The abstraction each chapter covers is
+// a term, defined by its description; a single LLM request finds the other
+// terms the chapters introduce. Terms that do not name a chapter of
+// chapters are dropped, so every entry links to at least one.
+//
+// If the request fails with an error that is not fatal (see llm.IsFatal),
+// or its response cannot be used, the glossary holds the abstractions only.
+func (g *Generator) Glossary(ctx context.Context, a *analysis.Analysis, chapters []Chapter) ([]GlossaryEntry, error) {
+	descriptions := make(map[string]string, len(a.Abstractions))
+	for _, abs := range a.Abstractions {
+		descriptions[abs.Name] = abs.Description
+	}
+	entries := make(map[string]*GlossaryEntry)
+	for _, chapter := range chapters {
+		key := strings.ToLower(chapter.Abstraction)
+		if entry, ok := entries[key]; ok {
+			entry.Chapters = append(entry.Chapters, chapter.Index)
+			continue
+		}
+		entries[key] = &GlossaryEntry{Term: chapter.Abstraction, Definition: descriptions[chapter.Abstraction], Chapters: []int{chapter.Index}}
+	}
+
+	slog.Info("Writing the glossary", "chapters", len(chapters))
+	terms, err := g.glossaryTerms(ctx, a, chapters)
+	switch {
+	case err != nil && llm.IsFatal(err):
+		return nil, fmt.Errorf("writing the glossary: %w", err)
+	case err != nil:
+		slog.Warn("Could not find the key terms of the chapters; the glossary lists the abstractions only", "error", err)
+	}
+	for _, term := range terms {
+		if entry, ok := entries[strings.ToLower(term.Term)]; ok {
+			entry.Chapters = append(entry.Chapters, term.Chapters...)
+			continue
+		}
+		entries[strings.ToLower(term.Term)] = &term
+	}
+
+	glossary := make([]GlossaryEntry, 0, len(entries))
+	for _, entry := range entries {
+		slices.Sort(entry.Chapters)
+		entry.Chapters = slices.Compact(entry.Chapters)
+		glossary = append(glossary, *entry)
+	}
+	slices.SortFunc(glossary, func(x, y GlossaryEntry) int {
+		if c := strings.Compare(strings.ToLower(x.Term), strings.ToLower(y.Term)); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Term, y.Term)
+	})
+	return glossary, nil
+}
+
+// glossaryTerms asks the LLM for the key terms of chapters, keeping those
+// with a definition and the chapters of chapters that introduce them.
+func (g *Generator) glossaryTerms(ctx context.Context, a *analysis.Analysis, chapters []Chapter) ([]GlossaryEntry, error) {
+	prompt, err := g.glossaryPrompt(a, chapters)
+	if err != nil {
+		return nil, err
+	}
+	response, err := g.Provider.Complete(ctx, prompt, g.Options)
+	if err != nil {
+		return nil, err
+	}
+	var parsed glossaryResponse
+	if err := analysis.ParseJSONResponse(response, &parsed); err != nil {
+		return nil, err
+	}
+
+	written := make(map[int]bool, len(chapters))
+	for _, chapter := range chapters {
+		written[chapter.Index] = true
+	}
+	var terms []GlossaryEntry
+	for _, found := range parsed.Terms {
+		term := GlossaryEntry{Term: strings.TrimSpace(found.Term), Definition: strings.TrimSpace(found.Definition)}
+		for _, index := range found.Chapters {
+			if written[index] {
+				term.Chapters = append(term.Chapters, index)
+			}
+		}
+		if term.Term == "" || term.Definition == "" || len(term.Chapters) == 0 {
+			slog.Debug("Dropping glossary term", "term", found.Term, "chapters", found.Chapters)
+			continue
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, errors.New("response listed no term of the chapters")
+	}
+	return terms, nil
+}
+
+// glossaryPrompt builds the prompt asking for the key terms of chapters,
+// quoting the start of each. The quotes are shortened until the prompt fits
+// the context window, and left out if they must be.
+func (g *Generator) glossaryPrompt(a *analysis.Analysis, chapters []Chapter) (string, error) {
+	descriptions := make(map[string]string, len(a.Abstractions))
+	for _, abs := range a.Abstractions {
+		descriptions[abs.Name] = abs.Description
+	}
+	audience := g.Audience
+	if audience == "" {
+		audience = prompts.AudienceDeveloper
+	}
+	language := g.Language
+	if language == "" {
+		language = DefaultLanguage.Name
+	}
+	templates := g.Prompts
+	if templates == nil {
+		templates = prompts.Default()
+	}
+	tokenizer := g.Tokenizer
+	if tokenizer == nil {
+		tokenizer = llm.HeuristicTokenizer
+	}
+	budget := llm.PromptBudget(g.ContextWindow, g.Options.MaxTokens) - tokenizer.CountTokens(g.Options.System)
+
+	for size := maxGlossaryExcerpt; ; size /= 2 {
+		data := prompts.GlossaryData{Project: a.ProjectName, Audience: audience, Language: language, MaxTerms: maxGlossaryTerms}
+		for _, chapter := range chapters {
+			data.Chapters = append(data.Chapters, prompts.GlossaryChapter{
+				Index:       chapter.Index,
+				Title:       chapter.Title,
+				Description: descriptions[chapter.Abstraction],
+				Excerpt:     excerpt(chapter.Content, size),
+			})
+		}
+		prompt, err := templates.Glossary(data)
+		if err != nil || g.ContextWindow <= 0 || tokenizer.CountTokens(prompt) <= budget {
+			return prompt, err
+		}
+		if size == 0 {
+			return "", fmt.Errorf("the glossary prompt does not fit the model's %d-token context window, even without quoting the chapters", g.ContextWindow)
+		}
+	}
+}
+
+// excerpt returns the start of text, at most size bytes of it cut at a line
+// boundary, or "" if size leaves no room for a line.
+func excerpt(text string, size int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= size {
+		return text
+	}
+	text = text[:size]
+	if i := strings.LastIndexByte(text, '\n'); i > 0 {
+		return text[:i]
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+	"github.com/ksylvan/code-decoder/internal/llm/llmtest"
+)
+
+func TestGlossary(t *testing.T) {
+	chapters := []Chapter{
+		{Index: 1, Title: "Config", Abstraction: "Config", Content: "# Config\n\nA profile is a named LLM setup."},
+		{Index: 2, Title: "CLI", Abstraction: "CLI", Content: "# CLI\n\nFlags override the config."},
+	}
+	tests := []struct {
+		name     string
+		response string
+		err      error
+		want     []GlossaryEntry
+		wantErr  bool
+	}{
+		{
+			name: "terms found",
+			// Unknown chapters are dropped, and so are terms left without one
+			response: `{"terms": [{"term": "profile", "definition": "A named LLM setup.", "chapters": [1, 7]},` +
+				`{"term": "flag", "definition": "A command-line option.", "chapters": [2]},` +
+				`{"term": "cli", "definition": "Duplicate of the abstraction.", "chapters": [1]},` +
+				`{"term": "orphan", "definition": "In no chapter.", "chapters": [9]}]}`,
+			want: []GlossaryEntry{
+				{Term: "CLI", Definition: "Commands", Chapters: []int{1, 2}},
+				{Term: "Config", Definition: "Settings", Chapters: []int{1}},
+				{Term: "flag", Definition: "A command-line option.", Chapters: []int{2}},
+				{Term: "profile", Definition: "A named LLM setup.", Chapters: []int{1}},
+			},
+		},
+		{
+			name:     "unusable response",
+			response: "I cannot help with that.",
+			want: []GlossaryEntry{
+				{Term: "CLI", Definition: "Commands", Chapters: []int{2}},
+				{Term: "Config", Definition: "Settings", Chapters: []int{1}},
+			},
+		},
+		{
+			name:    "fatal error",
+			err:     &llm.APIError{StatusCode: http.StatusUnauthorized, Message: "bad key"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &llmtest.Provider{Respond: func(prompt string) (string, error) { return tt.response, tt.err }}
+			g := &Generator{Provider: provider, Audience: "beginner"}
+			got, err := g.Glossary(context.Background(), testAnalysis(), chapters)
+			if tt.wantErr {
+				var apiErr *llm.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Expected the API error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Glossary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected glossary %+v, got %+v", tt.want, got)
+			}
+			prompt := provider.Prompts()[0]
+			for _, want := range []string{"Chapter 1: Config\nAbstraction: Settings", "A profile is a named LLM setup.", "in plain words"} {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestGlossaryPromptFit(t *testing.T) {
+	long := "# Config\n\n" + strings.Repeat("A line about the configuration.\n", 200)
+	chapters := []Chapter{{Index: 1, Title: "Config", Abstraction: "Config", Content: long}}
+	words := llm.TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+
+	// The excerpt is shortened to fit the window, at a line boundary
+	g := &Generator{ContextWindow: 2400, Tokenizer: words}
+	prompt, err := g.glossaryPrompt(testAnalysis(), chapters)
+	if err != nil {
+		t.Fatalf("glossaryPrompt() error = %v", err)
+	}
+	if words(prompt) > llm.PromptBudget(2400, 0) || !strings.Contains(prompt, "A line about the configuration.\n") || strings.Count(prompt, "A line") == 200 {
+		t.Errorf("Expected a shortened excerpt fitting the window, got %d words:\n%s", words(prompt), prompt)
+	}
+
+	g.ContextWindow = 10
+	if _, err := g.glossaryPrompt(testAnalysis(), chapters); err == nil {
+		t.Error("Expected an error when the instructions alone do not fit")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import "strings"
+
+// DefaultLanguage is the language tutorials are written in when none is given.
+var DefaultLanguage = Language{Name: "English", Code: "en"}
+
+// Language is a language tutorials can be written in.
+type Language struct {
+	Name string `json:"name"`           // English name, used in prompts (e.g., "Chinese")
+	Code string `json:"code,omitempty"` // BCP 47 tag (e.g., "zh" or "pt-BR"); empty if unknown
+}
+
+// languages are the languages LookupLanguage knows, with their ISO 639-1
+// codes and native names, which are accepted too.
+var languages = []struct {
+	name, code, native string
+}{
+	{"Arabic", "ar", "العربية"},
+	{"Bengali", "bn", "বাংলা"},
+	{"Bulgarian", "bg", "български"},
+	{"Catalan", "ca", "català"},
+	{"Chinese", "zh", "中文"},
+	{"Croatian", "hr", "hrvatski"},
+	{"Czech", "cs", "čeština"},
+	{"Danish", "da", "dansk"},
+	{"Dutch", "nl", "Nederlands"},
+	{"English", "en", "English"},
+	{"Estonian", "et", "eesti"},
+	{"Finnish", "fi", "suomi"},
+	{"French", "fr", "français"},
+	{"German", "de", "Deutsch"},
+	{"Greek", "el", "Ελληνικά"},
+	{"Hebrew", "he", "עברית"},
+	{"Hindi", "hi", "हिन्दी"},
+	{"Hungarian", "hu", "magyar"},
+	{"Indonesian", "id", "Bahasa Indonesia"},
+	{"Italian", "it", "italiano"},
+	{"Japanese", "ja", "日本語"},
+	{"Korean", "ko", "한국어"},
+	{"Latvian", "lv", "latviešu"},
+	{"Lithuanian", "lt", "lietuvių"},
+	{"Malay", "ms", "Bahasa Melayu"},
+	{"Norwegian", "no", "norsk"},
+	{"Persian", "fa", "فارسی"},
+	{"Polish", "pl", "polski"},
+	{"Portuguese", "pt", "português"},
+	{"Romanian", "ro", "română"},
+	{"Russian", "ru", "русский"},
+	{"Serbian", "sr", "српски"},
+	{"Slovak", "sk", "slovenčina"},
+	{"Slovenian", "sl", "slovenščina"},
+	{"Spanish", "es", "español"},
+	{"Swahili", "sw", "Kiswahili"},
+	{"Swedish", "sv", "svenska"},
+	{"Tamil", "ta", "தமிழ்"},
+	{"Thai", "th", "ไทย"},
+	{"Turkish", "tr", "Türkçe"},
+	{"Ukrainian", "uk", "українська"},
+	{"Urdu", "ur", "اردو"},
+	{"Vietnamese", "vi", "Tiếng Việt"},
+}
+
+// LookupLanguage resolves value, an English or native language name or an
+// ISO 639-1 code in any case, to a Language. A code may carry a region
+// (e.g., "pt-BR" or "zh_TW"), which is kept in the code and named in the
+// name. The boolean is false for an unknown language, which is returned
+// with value as its name and no code, so that the LLM can still be asked to
+// write in it.
+func LookupLanguage(value string) (Language, bool) {
+	value = strings.TrimSpace(value)
+	base, region, _ := strings.Cut(strings.ReplaceAll(value, "_", "-"), "-")
+	for _, l := range languages {
+		switch {
+		case strings.EqualFold(value, l.name) || strings.EqualFold(value, l.native):
+			return Language{Name: l.name, Code: l.code}, true
+		case strings.EqualFold(base, l.code) && region == "":
+			return Language{Name: l.name, Code: l.code}, true
+		case strings.EqualFold(base, l.code):
+			if len(region) == 4 {
+				region = strings.ToUpper(region[:1]) + strings.ToLower(region[1:]) // A script, e.g., "Hant"
+			} else {
+				region = strings.ToUpper(region)
+			}
+			return Language{Name: l.name + " (" + region + ")", Code: l.code + "-" + region}, true
+		}
+	}
+	return Language{Name: value}, false
+}
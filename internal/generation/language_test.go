@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import "testing"
+
+func TestLookupLanguage(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      Language
+		wantKnown bool
+	}{
+		{value: "English", want: Language{Name: "English", Code: "en"}, wantKnown: true},
+		{value: "chinese", want: Language{Name: "Chinese", Code: "zh"}, wantKnown: true},
+		{value: "ZH", want: Language{Name: "Chinese", Code: "zh"}, wantKnown: true},
+		{value: " 日本語 ", want: Language{Name: "Japanese", Code: "ja"}, wantKnown: true},
+		{value: "Español", want: Language{Name: "Spanish", Code: "es"}, wantKnown: true},
+		{value: "pt-br", want: Language{Name: "Portuguese (BR)", Code: "pt-BR"}, wantKnown: true},
+		{value: "zh_hant", want: Language{Name: "Chinese (Hant)", Code: "zh-Hant"}, wantKnown: true},
+		{value: "Klingon", want: Language{Name: "Klingon"}, wantKnown: false},
+		{value: "xx-YY", want: Language{Name: "xx-YY"}, wantKnown: false},
+	}
+
+	for _, tt := range tests {
+		got, known := LookupLanguage(tt.value)
+		if got != tt.want || known != tt.wantKnown {
+			t.Errorf("LookupLanguage(%q) = %+v, %v, want %+v, %v", tt.value, got, known, tt.want, tt.wantKnown)
+		}
+	}
+}
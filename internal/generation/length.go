@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chapter lengths accepted by LookupLength.
+const (
+	LengthShort  = "short"  // A quick overview of each abstraction
+	LengthMedium = "medium" // The usual walkthrough
+	LengthLong   = "long"   // An exhaustive guide
+)
+
+// ChapterLengths lists the chapter lengths, from the shortest.
+var ChapterLengths = []string{LengthShort, LengthMedium, LengthLong}
+
+// Length is the target length of each chapter.
+type Length struct {
+	Words     int // Approximate number of words the chapter is asked for
+	MaxTokens int // Most tokens the chapter's response may take
+}
+
+// tokensPerWord is the response budget per target word, which leaves room
+// for code examples, Markdown and languages taking more tokens per word
+// than English.
+const tokensPerWord = 3
+
+// lengths maps each chapter length to its target.
+var lengths = map[string]Length{
+	LengthShort:  {Words: 600, MaxTokens: 600 * tokensPerWord},
+	LengthMedium: {Words: 1500, MaxTokens: 1500 * tokensPerWord},
+	LengthLong:   {Words: 3000, MaxTokens: 3000 * tokensPerWord},
+}
+
+// LookupLength returns the target of the chapter length name (see
+// ChapterLengths), ignoring case.
+func LookupLength(name string) (Length, error) {
+	length, ok := lengths[strings.ToLower(name)]
+	if !ok {
+		return Length{}, fmt.Errorf("invalid chapter length %q (use %s)", name, strings.Join(ChapterLengths, ", "))
+	}
+	return length, nil
+}
+
+// WordsLength returns the target of chapters of about words words.
+func WordsLength(words int) Length {
+	return Length{Words: words, MaxTokens: words * tokensPerWord}
+}
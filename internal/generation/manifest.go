@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ManifestFile is the name of the manifest kept in the output directory while
+// a tutorial is being generated.
+const ManifestFile = ".progress.json"
+
+// Manifest records the chapters completed so far, with their content, so
+// that an interrupted run can be resumed without regenerating them. Since it
+// holds the content itself, resuming does not depend on how the rendered
+// chapter files are named.
+//
+// Each write replaces the file atomically, so that a crash never leaves a
+// half-written manifest behind. Generate completes chapters one at a time,
+// so this is for crash safety only; Add and Save also hold a lock, which
+// merely makes them safe for concurrent use.
+type Manifest struct {
+	ProjectName  string    `json:"project_name"`
+	ChapterOrder string    `json:"chapter_order,omitempty"` // See Order; resuming keeps the order of the interrupted run
+	Audience     string    `json:"audience,omitempty"`      // See Generator; resuming keeps the audience of the interrupted run
+	Language     Language  `json:"language,omitzero"`       // See LookupLanguage; resuming keeps the language of the interrupted run
+	Chapters     []Chapter `json:"chapters"`
+
+	mu sync.Mutex // Guards Chapters and the manifest file during Add and Save
+}
+
+// LoadManifest reads the manifest from the output directory dir. The error
+// matches fs.ErrNotExist when there is nothing to resume.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	return &m, nil
+}
+
+// Add records chapter as complete, keeping the chapters ordered by index,
+// and saves the manifest to the output directory dir. It returns a copy of
+// the chapters recorded so far.
+func (m *Manifest) Add(dir string, chapter Chapter) ([]Chapter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, _ := slices.BinarySearchFunc(m.Chapters, chapter.Index, func(c Chapter, index int) int { return c.Index - index })
+	m.Chapters = slices.Insert(m.Chapters, i, chapter)
+	if err := m.save(dir); err != nil {
+		return nil, err
+	}
+	return slices.Clone(m.Chapters), nil
+}
+
+// Save writes the manifest to the output directory dir. The file is replaced
+// atomically, so an interruption never leaves a truncated manifest behind.
+func (m *Manifest) Save(dir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.save(dir)
+}
+
+// save implements Save; the caller holds m.mu.
+func (m *Manifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ManifestFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, ManifestFile)); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// RemoveManifest deletes the manifest from the output directory dir, once
+// the tutorial is complete. A missing manifest is not an error.
+func RemoveManifest(dir string) error {
+	if err := os.Remove(filepath.Join(dir, ManifestFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	return nil
+}
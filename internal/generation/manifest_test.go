@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+
+	if _, err := LoadManifest(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist before saving, got %v", err)
+	}
+
+	m := &Manifest{
+		ProjectName:  "demo",
+		ChapterOrder: OrderTopological,
+		Audience:     "beginner",
+		Language:     Language{Name: "Chinese", Code: "zh"},
+		Chapters:     []Chapter{{Index: 1, Title: "Config", Abstraction: "Config", Content: "# Config"}},
+	}
+	if err := m.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("LoadManifest() = %+v, want %+v", got, m)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the manifest in the output directory, got %d entries", len(entries))
+	}
+
+	if err := RemoveManifest(dir); err != nil {
+		t.Fatalf("RemoveManifest() error = %v", err)
+	}
+	if err := RemoveManifest(dir); err != nil {
+		t.Errorf("RemoveManifest() of a missing manifest error = %v", err)
+	}
+	if _, err := LoadManifest(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist after removing, got %v", err)
+	}
+}
+
+func TestManifestConcurrentAdd(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{ProjectName: "demo"}
+	if err := m.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A reader checks that the file on disk is always a complete manifest
+	// while the workers add chapters
+	done := make(chan struct{})
+	readerErr := make(chan error, 1)
+	go func() {
+		defer close(readerErr)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+			if err != nil {
+				readerErr <- err
+				return
+			}
+			var saved Manifest
+			if err := json.Unmarshal(data, &saved); err != nil {
+				readerErr <- fmt.Errorf("invalid manifest on disk: %w\n%s", err, data)
+				return
+			}
+		}
+	}()
+
+	const chapters = 50
+	var wg sync.WaitGroup
+	for i := chapters; i > 0; i-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			title := fmt.Sprintf("Chapter %d", i)
+			completed, err := m.Add(dir, Chapter{Index: i, Title: title, Abstraction: title, Content: "# " + title})
+			if err != nil {
+				t.Errorf("Add() error = %v", err)
+				return
+			}
+			for j := 1; j < len(completed); j++ {
+				if completed[j-1].Index >= completed[j].Index {
+					t.Errorf("Expected the chapters ordered by index, got %d before %d", completed[j-1].Index, completed[j].Index)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	if err := <-readerErr; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if len(got.Chapters) != chapters {
+		t.Fatalf("Expected %d chapters, got %d", chapters, len(got.Chapters))
+	}
+	for i, chapter := range got.Chapters {
+		if chapter.Index != i+1 {
+			t.Errorf("Expected chapter %d at position %d, got %d", i+1, i, chapter.Index)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the manifest in the output directory, got %d entries", len(entries))
+	}
+}
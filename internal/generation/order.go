@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+// Chapter orders accepted by Order.
+const (
+	OrderTopological  = "topological"  // Abstractions before the ones that depend on them
+	OrderAlphabetical = "alphabetical" // By abstraction name, ignoring case
+	OrderAsAnalyzed   = "as-analyzed"  // As ranked by the analysis
+)
+
+// ChapterOrders lists the chapter orders, the default first.
+var ChapterOrders = []string{OrderTopological, OrderAlphabetical, OrderAsAnalyzed}
+
+// Order returns the abstractions of a in the order their chapters are
+// written. The topological order introduces each abstraction after the ones
+// it depends on (see analysis.Relationship), so that readers meet the
+// foundations first; among the abstractions that are ready, and to break
+// dependency cycles, the analysis's ranking decides.
+func Order(a *analysis.Analysis, order string) ([]analysis.Abstraction, error) {
+	ordered := slices.Clone(a.Abstractions)
+	switch order {
+	case OrderTopological:
+		return topological(ordered, a.Relationships), nil
+	case OrderAlphabetical:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return strings.ToLower(ordered[i].Name) < strings.ToLower(ordered[j].Name)
+		})
+		return ordered, nil
+	case OrderAsAnalyzed:
+		return ordered, nil
+	}
+	return nil, fmt.Errorf("unknown chapter order %q (use %s)", order, strings.Join(ChapterOrders, ", "))
+}
+
+// topological sorts abstractions so that each comes after its dependencies,
+// keeping their relative order otherwise. When every remaining abstraction
+// waits on another, a dependency cycle is logged and broken by placing its
+// highest ranked abstraction next.
+func topological(abstractions []analysis.Abstraction, relationships []analysis.Relationship) []analysis.Abstraction {
+	index := make(map[string]int, len(abstractions))
+	for i, abs := range abstractions {
+		index[abs.Name] = i
+	}
+	dependencies := make([][]int, len(abstractions))
+	for _, rel := range relationships {
+		from, okFrom := index[rel.From]
+		to, okTo := index[rel.To]
+		if okFrom && okTo && from != to && !slices.Contains(dependencies[from], to) {
+			dependencies[from] = append(dependencies[from], to)
+		}
+	}
+
+	placed := make([]bool, len(abstractions))
+	pending := func(i int) int {
+		n := 0
+		for _, dep := range dependencies[i] {
+			if !placed[dep] {
+				n++
+			}
+		}
+		return n
+	}
+
+	ordered := make([]analysis.Abstraction, 0, len(abstractions))
+	for len(ordered) < len(abstractions) {
+		next := -1
+		for i := range abstractions {
+			if !placed[i] && (next < 0 || pending(i) < pending(next)) {
+				next = i
+			}
+		}
+		if pending(next) > 0 {
+			// Every remaining abstraction waits on another: start with the
+			// highest ranked abstraction of a cycle
+			members := cycle(dependencies, placed)
+			var names []string
+			for _, i := range members {
+				names = append(names, abstractions[i].Name)
+			}
+			next = slices.Min(members)
+			slog.Warn("Chapter dependencies form a cycle; breaking it by rank", "cycle", strings.Join(append(names, names[0]), " -> "), "first", abstractions[next].Name)
+		}
+		placed[next] = true
+		ordered = append(ordered, abstractions[next])
+	}
+	return ordered
+}
+
+// cycle returns the indexes of a dependency cycle among the abstractions not
+// yet placed, found by following the first pending dependency of each from
+// the highest ranked one. It is only called when every such abstraction has
+// a pending dependency, so the walk comes back on itself.
+func cycle(dependencies [][]int, placed []bool) []int {
+	var path []int
+	for i := slices.Index(placed, false); i >= 0; {
+		if start := slices.Index(path, i); start >= 0 {
+			return path[start:]
+		}
+		path = append(path, i)
+		next := -1
+		for _, dep := range dependencies[i] {
+			if !placed[dep] {
+				next = dep
+				break
+			}
+		}
+		i = next
+	}
+	return path
+}
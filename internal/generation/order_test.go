@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package generation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+func orderAnalysis(relationships ...analysis.Relationship) *analysis.Analysis {
+	return &analysis.Analysis{
+		Abstractions: []analysis.Abstraction{
+			{Name: "CLI"}, {Name: "generator"}, {Name: "Config"}, {Name: "Provider"},
+		},
+		Relationships: relationships,
+	}
+}
+
+func TestOrder(t *testing.T) {
+	uses := func(from, to string) analysis.Relationship {
+		return analysis.Relationship{From: from, To: to, Label: "uses"}
+	}
+
+	tests := []struct {
+		name     string
+		analysis *analysis.Analysis
+		order    string
+		want     []string
+	}{
+		{
+			name:     "as analyzed",
+			analysis: orderAnalysis(uses("CLI", "Config")),
+			order:    OrderAsAnalyzed,
+			want:     []string{"CLI", "generator", "Config", "Provider"},
+		},
+		{
+			name:     "alphabetical ignores case",
+			analysis: orderAnalysis(),
+			order:    OrderAlphabetical,
+			want:     []string{"CLI", "Config", "generator", "Provider"},
+		},
+		{
+			name: "dependencies first",
+			analysis: orderAnalysis(
+				uses("CLI", "generator"), uses("CLI", "Config"), uses("generator", "Provider"),
+				uses("Provider", "Config"), uses("Ghost", "CLI"), uses("Config", "Config"),
+			),
+			order: OrderTopological,
+			want:  []string{"Config", "Provider", "generator", "CLI"},
+		},
+		{
+			name:     "without relationships keeps the ranking",
+			analysis: orderAnalysis(),
+			order:    OrderTopological,
+			want:     []string{"CLI", "generator", "Config", "Provider"},
+		},
+		{
+			name: "cycles are broken by rank",
+			analysis: orderAnalysis(
+				uses("generator", "Provider"), uses("Provider", "Config"), uses("Config", "generator"), uses("CLI", "Config"),
+			),
+			order: OrderTopological,
+			want:  []string{"generator", "Config", "CLI", "Provider"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := Order(tt.analysis, tt.order)
+			if err != nil {
+				t.Fatalf("Order() error = %v", err)
+			}
+			var got []string
+			for _, abs := range ordered {
+				got = append(got, abs.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+			if tt.analysis.Abstractions[0].Name != "CLI" {
+				t.Error("Order() must not reorder the analysis itself")
+			}
+		})
+	}
+}
+
+func TestOrderUnknown(t *testing.T) {
+	_, err := Order(orderAnalysis(), "random")
+	if err == nil || !strings.Contains(err.Error(), "topological, alphabetical, as-analyzed") {
+		t.Errorf("Expected an error listing the chapter orders, got %v", err)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	// 0 -> 1 -> 2 -> 3 -> 1, with 3 depending on 0 too once it is placed
+	dependencies := [][]int{{1}, {2}, {3}, {0, 1}}
+	placed := []bool{true, false, false, false}
+	if got, want := cycle(dependencies, placed), []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cycle() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// anthropicBaseURL is the base URL of the Anthropic API.
+	anthropicBaseURL = "https://api.anthropic.com/v1"
+	// anthropicVersion is the value sent in the required anthropic-version header.
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens is the max_tokens value sent when the options don't
+	// set one, since the Messages API requires it.
+	anthropicMaxTokens = 4096
+)
+
+// AnthropicProvider talks to the Anthropic Messages API.
+type AnthropicProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic API.
+func NewAnthropicProvider(apiKey, model string, client *http.Client) *AnthropicProvider {
+	return &AnthropicProvider{
+		baseURL: anthropicBaseURL,
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+// Name implements Provider.
+func (p *AnthropicProvider) Name() string { return "anthropic" }
+
+// BaseURL returns the URL the API paths are appended to.
+func (p *AnthropicProvider) BaseURL() string { return p.baseURL }
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Stream      bool               `json:"stream,omitempty"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+}
+
+func (p *AnthropicProvider) request(prompt string, opts CompletionOptions, streaming bool) anthropicRequest {
+	model := p.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	maxTokens := anthropicMaxTokens
+	if opts.MaxTokens > 0 {
+		maxTokens = opts.MaxTokens
+	}
+	return anthropicRequest{
+		Model:       model,
+		MaxTokens:   maxTokens,
+		System:      opts.System,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		Stream:      streaming,
+		Temperature: opts.Temperature,
+		TopP:        opts.TopP,
+	}
+}
+
+func (p *AnthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+// Complete implements Provider.
+func (p *AnthropicProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.baseURL+"/messages", p.headers(), p.request(prompt, opts, false))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := decodeJSON(p.Name(), resp, &result); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String(), nil
+}
+
+// CompleteStream implements Provider using the server-sent events stream.
+func (p *AnthropicProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.baseURL+"/messages", p.headers(), p.request(prompt, opts, true))
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(ctx, resp.Body, func(r io.Reader, emit emitFunc) error {
+		return readSSE(r, func(event, data string) (bool, error) {
+			switch event {
+			case "message_stop":
+				return true, nil
+			case "error":
+				var payload struct {
+					Error struct {
+						Type    string `json:"type"`
+						Message string `json:"message"`
+					} `json:"error"`
+				}
+				_ = json.Unmarshal([]byte(data), &payload)
+				return true, reportedError(p.Name(), payload.Error.Type, payload.Error.Message)
+			case "content_block_delta":
+				var payload struct {
+					Delta struct {
+						Type string `json:"type"`
+						Text string `json:"text"`
+					} `json:"delta"`
+				}
+				if err := json.Unmarshal([]byte(data), &payload); err != nil {
+					return true, fmt.Errorf("%s: failed to decode stream event: %w", p.Name(), err)
+				}
+				if payload.Delta.Text != "" && !emit(payload.Delta.Text) {
+					return true, ctx.Err()
+				}
+			}
+			return false, nil
+		})
+	}), nil
+}
+
+// TestConnection implements Provider by listing the available models.
+func (p *AnthropicProvider) TestConnection(ctx context.Context) error {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodGet, p.baseURL+"/models", p.headers(), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestAnthropic(t *testing.T, handler http.HandlerFunc) *AnthropicProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	p := NewAnthropicProvider("test-key", "claude-3-opus", server.Client())
+	p.baseURL = server.URL
+	return p
+}
+
+func TestAnthropicComplete(t *testing.T) {
+	p := newTestAnthropic(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("Missing Anthropic headers: %v", r.Header)
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.MaxTokens <= 0 {
+			t.Errorf("Expected max_tokens to be set, got %d", req.MaxTokens)
+		}
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hi"},{"type":"text","text":" there"}]}`)
+	})
+
+	got, err := p.Complete(context.Background(), "Say hi", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "Hi there" {
+		t.Errorf("Expected 'Hi there', got %q", got)
+	}
+}
+
+func TestAnthropicCompleteStream(t *testing.T) {
+	p := newTestAnthropic(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n")
+		fmt.Fprint(w, "event: ping\ndata: {\"type\":\"ping\"}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"lo\"}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	})
+
+	ch, err := p.CompleteStream(context.Background(), "Say hello", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	got, err := Collect(ch)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if got != "Hello" {
+		t.Errorf("Expected 'Hello', got %q", got)
+	}
+}
+
+func TestAnthropicStreamError(t *testing.T) {
+	p := newTestAnthropic(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"delta\":{\"text\":\"partial\"}}\n\n")
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+
+	ch, err := p.CompleteStream(context.Background(), "hi", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	got, err := Collect(ch)
+	if err == nil {
+		t.Fatal("Expected a stream error")
+	}
+	if got != "partial" {
+		t.Errorf("Expected partial text before the error, got %q", got)
+	}
+}
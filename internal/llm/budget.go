@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// budgetWarning is the share of a Budget spent at which it warns that the
+// run is about to stop.
+const budgetWarning = 0.9
+
+// Budget caps the estimated cost and tokens of the requests sent through
+// the BudgetProviders sharing it over a whole run. Once either ceiling is
+// reached, further requests fail with a *BudgetExceededError; requests
+// already in flight complete, so the ceiling may be overshot by as much.
+// It is safe for concurrent use.
+type Budget struct {
+	MaxCost   float64 // US dollars; 0 means no limit
+	MaxTokens int     // Input and output tokens; 0 means no limit
+
+	mu     sync.Mutex
+	usage  Usage
+	cost   float64
+	warned bool
+}
+
+// BudgetExceededError is returned for requests refused because the Budget
+// is spent. It reports how far the run got.
+type BudgetExceededError struct {
+	Calls     int     // Requests sent before the budget was spent
+	Cost      float64 // Estimated US dollars spent
+	MaxCost   float64 // The cost ceiling; 0 if the token ceiling was reached
+	Tokens    int     // Estimated input and output tokens used
+	MaxTokens int     // The token ceiling; 0 if the cost ceiling was reached
+}
+
+func (e *BudgetExceededError) Error() string {
+	requests := "requests"
+	if e.Calls == 1 {
+		requests = "request"
+	}
+	if e.MaxCost > 0 {
+		return fmt.Sprintf("budget exceeded after %d %s: ~$%.4f spent of the $%.2f maximum", e.Calls, requests, e.Cost, e.MaxCost)
+	}
+	return fmt.Sprintf("budget exceeded after %d %s: ~%d tokens used of the %d maximum", e.Calls, requests, e.Tokens, e.MaxTokens)
+}
+
+// Spent returns the usage and estimated cost counted so far.
+func (b *Budget) Spent() (Usage, float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usage, b.cost
+}
+
+// check returns a *BudgetExceededError if b is spent.
+func (b *Budget) check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := &BudgetExceededError{Calls: b.usage.Calls, Cost: b.cost, Tokens: b.usage.InputTokens + b.usage.OutputTokens}
+	switch {
+	case b.MaxCost > 0 && b.cost >= b.MaxCost:
+		err.MaxCost = b.MaxCost
+		return err
+	case b.MaxTokens > 0 && err.Tokens >= b.MaxTokens:
+		err.MaxTokens = b.MaxTokens
+		return err
+	}
+	return nil
+}
+
+// charge counts a request of input and output tokens costing cost, and
+// warns once when nearly all of b is spent.
+func (b *Budget) charge(input, output int, cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage.Calls++
+	b.usage.InputTokens += input
+	b.usage.OutputTokens += output
+	b.cost += cost
+	if b.warned {
+		return
+	}
+	tokens := b.usage.InputTokens + b.usage.OutputTokens
+	switch {
+	case b.MaxCost > 0 && b.cost >= budgetWarning*b.MaxCost && b.cost < b.MaxCost:
+		slog.Warn("Nearly out of budget; the run stops once it is spent", "spent", fmt.Sprintf("$%.4f", b.cost), "max_cost", fmt.Sprintf("$%.2f", b.MaxCost))
+	case b.MaxTokens > 0 && tokens >= int(budgetWarning*float64(b.MaxTokens)) && tokens < b.MaxTokens:
+		slog.Warn("Nearly out of budget; the run stops once it is spent", "tokens", tokens, "max_tokens_total", b.MaxTokens)
+	default:
+		return
+	}
+	b.warned = true
+}
+
+// BudgetProvider is a Provider decorator that charges each request to a
+// Budget, with tokens estimated by a Tokenizer and priced by a Pricing, and
+// refuses requests once the Budget is spent.
+type BudgetProvider struct {
+	Provider
+
+	budget    *Budget
+	pricing   Pricing
+	tokenizer Tokenizer
+}
+
+// NewBudgetProvider wraps p, charging its requests to budget at pricing,
+// with tokens counted by tokenizer.
+func NewBudgetProvider(p Provider, budget *Budget, pricing Pricing, tokenizer Tokenizer) *BudgetProvider {
+	return &BudgetProvider{Provider: p, budget: budget, pricing: pricing, tokenizer: tokenizer}
+}
+
+// Unwrap returns the decorated provider.
+func (b *BudgetProvider) Unwrap() Provider { return b.Provider }
+
+// Complete implements Provider.
+func (b *BudgetProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	if err := b.budget.check(); err != nil {
+		return "", err
+	}
+	response, err := b.Provider.Complete(ctx, prompt, opts)
+	b.charge(prompt, opts, response)
+	return response, err
+}
+
+// CompleteStream implements Provider. The request is charged once the
+// stream ends, with the text received until then.
+func (b *BudgetProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	if err := b.budget.check(); err != nil {
+		return nil, err
+	}
+	upstream, err := b.Provider.CompleteStream(ctx, prompt, opts)
+	if err != nil {
+		b.charge(prompt, opts, "")
+		return nil, err
+	}
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		var sb strings.Builder
+		for chunk := range upstream {
+			sb.WriteString(chunk.Text)
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				// Keep draining so the upstream goroutine can exit
+			}
+		}
+		b.charge(prompt, opts, sb.String())
+	}()
+	return ch, nil
+}
+
+// charge charges a request of prompt, sent with opts, that received
+// response to the budget.
+func (b *BudgetProvider) charge(prompt string, opts CompletionOptions, response string) {
+	input := b.tokenizer.CountTokens(opts.System) + b.tokenizer.CountTokens(prompt)
+	output := b.tokenizer.CountTokens(response)
+	b.budget.charge(input, output, b.pricing.Cost(Usage{Calls: 1, InputTokens: input, OutputTokens: output}))
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBudgetProvider(t *testing.T) {
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	dollarPerToken := Pricing{InputPerMillion: 1_000_000, OutputPerMillion: 1_000_000}
+
+	// Each completion of "a b" uses 2 input and 4 output tokens ("answer to a b")
+	tests := []struct {
+		name      string
+		budget    *Budget
+		pricing   Pricing
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name:      "token ceiling",
+			budget:    &Budget{MaxTokens: 13},
+			wantCalls: 3,
+			wantErr:   "budget exceeded after 3 requests: ~18 tokens used of the 13 maximum",
+		},
+		{
+			name:      "cost ceiling",
+			budget:    &Budget{MaxCost: 10},
+			pricing:   dollarPerToken,
+			wantCalls: 2,
+			wantErr:   "budget exceeded after 2 requests: ~$12.0000 spent of the $10.00 maximum",
+		},
+		{
+			name:      "free model under a cost ceiling",
+			budget:    &Budget{MaxCost: 10},
+			wantCalls: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &countingProvider{}
+			p := NewBudgetProvider(inner, tt.budget, tt.pricing, words)
+			var err error
+			for range 5 {
+				if _, err = p.Complete(context.Background(), "a b", CompletionOptions{}); err != nil {
+					break
+				}
+			}
+			if inner.calls != tt.wantCalls {
+				t.Errorf("Expected %d requests sent, got %d", tt.wantCalls, inner.calls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) {
+				t.Fatalf("Expected a *BudgetExceededError, got %v", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q, got %q", tt.wantErr, err.Error())
+			}
+			if !IsFatal(err) || IsProviderError(err) {
+				t.Errorf("Expected a spent budget to be fatal but not a provider error, got IsFatal %v, IsProviderError %v", IsFatal(err), IsProviderError(err))
+			}
+		})
+	}
+}
+
+func TestBudgetProviderStream(t *testing.T) {
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	budget := &Budget{MaxTokens: 10}
+	// Two providers share the budget, as the extraction and writing models do
+	first := NewBudgetProvider(&countingProvider{}, budget, Pricing{}, words)
+	second := NewBudgetProvider(&countingProvider{}, budget, Pricing{}, words)
+
+	ch, err := first.CompleteStream(context.Background(), "a b", CompletionOptions{System: "Be brief"})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	if _, err := Collect(ch); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	// Inputs: 2 system and 2 prompt words; output: "streamed a b"
+	if usage, _ := budget.Spent(); usage != (Usage{Calls: 1, InputTokens: 4, OutputTokens: 3}) {
+		t.Errorf("Expected the stream to be charged once it ends, got %+v", usage)
+	}
+	if _, err := second.Complete(context.Background(), "a b", CompletionOptions{}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if _, err := first.CompleteStream(context.Background(), "a b", CompletionOptions{}); err == nil {
+		t.Error("Expected the spent budget to refuse the stream")
+	}
+}
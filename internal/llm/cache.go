@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responsesDir is the subdirectory of the cache directory holding LLM responses.
+const responsesDir = "responses"
+
+// DefaultCacheDir returns the default cache directory
+// (e.g., ~/.cache/code-decoder on Linux).
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "code-decoder"), nil
+}
+
+// cacheEntry is a cached response as stored on disk.
+type cacheEntry struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Response  string    `json:"response"`
+}
+
+// CachingProvider is a Provider decorator that caches responses in memory and
+// on disk, keyed by a hash of the provider, model, prompt, and options, so
+// that identical prompts are only paid for once.
+type CachingProvider struct {
+	Provider
+
+	model string        // Configured model, used when the options don't override it
+	dir   string        // Directory holding the cache entries
+	ttl   time.Duration // Entries older than this are ignored; 0 means they never expire
+
+	// Refresh ignores the cached responses, so that every request is sent
+	// and its response replaces the cached one.
+	Refresh bool
+
+	mu     sync.Mutex
+	memory map[string]cacheEntry
+}
+
+// NewCachingProvider wraps p, storing responses under dir. The configured
+// model is recorded with each entry and used in the cache key.
+func NewCachingProvider(p Provider, model, dir string, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{
+		Provider: p,
+		model:    model,
+		dir:      filepath.Join(dir, responsesDir),
+		ttl:      ttl,
+		memory:   make(map[string]cacheEntry),
+	}
+}
+
+// Unwrap returns the decorated provider.
+func (c *CachingProvider) Unwrap() Provider { return c.Provider }
+
+// Complete implements Provider, answering from the cache when possible.
+func (c *CachingProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	key, model := c.key(prompt, opts)
+	if response, ok := c.lookup(key); ok {
+		return response, nil
+	}
+
+	response, err := c.Provider.Complete(ctx, prompt, opts)
+	if err != nil {
+		return "", err
+	}
+	c.store(key, model, response)
+	return response, nil
+}
+
+// CompleteStream implements Provider. A cached response is delivered as a
+// single chunk; otherwise the stream is passed through and cached once it
+// completes successfully.
+func (c *CachingProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	key, model := c.key(prompt, opts)
+	if response, ok := c.lookup(key); ok {
+		ch := make(chan Chunk, 1)
+		ch <- Chunk{Text: response}
+		close(ch)
+		return ch, nil
+	}
+
+	upstream, err := c.Provider.CompleteStream(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		var sb strings.Builder
+		for chunk := range upstream {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				// Keep draining so the upstream goroutine can exit
+				continue
+			}
+			if chunk.Err != nil {
+				return
+			}
+			sb.WriteString(chunk.Text)
+		}
+		if ctx.Err() == nil {
+			c.store(key, model, sb.String())
+		}
+	}()
+	return ch, nil
+}
+
+// key returns the cache key for a request and the model it targets.
+func (c *CachingProvider) key(prompt string, opts CompletionOptions) (string, string) {
+	model := c.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	data, _ := json.Marshal(struct {
+		Provider string            `json:"provider"`
+		Model    string            `json:"model"`
+		Prompt   string            `json:"prompt"`
+		Options  CompletionOptions `json:"options"`
+	}{c.Provider.Name(), model, prompt, opts})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), model
+}
+
+// lookup returns the cached response for key if there is a fresh entry.
+func (c *CachingProvider) lookup(key string) (string, bool) {
+	if c.Refresh {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.memory[key]
+	if !ok {
+		data, err := os.ReadFile(c.path(key))
+		if err != nil {
+			return "", false
+		}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			slog.Warn("Ignoring corrupt cache entry", "path", c.path(key), "error", err)
+			return "", false
+		}
+	}
+	if c.expired(entry) {
+		delete(c.memory, key)
+		os.Remove(c.path(key))
+		return "", false
+	}
+	c.memory[key] = entry
+	slog.Debug("LLM cache hit", "key", key[:12], "model", entry.Model)
+	return entry.Response, true
+}
+
+// store records a response in memory and on disk. Failing to write the disk
+// cache only costs money on a later run, so it is logged rather than returned.
+func (c *CachingProvider) store(key, model, response string) {
+	entry := cacheEntry{Provider: c.Provider.Name(), Model: model, CreatedAt: time.Now().UTC(), Response: response}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory[key] = entry
+
+	if err := writeFileAtomic(c.path(key), entry); err != nil {
+		slog.Warn("Failed to write LLM cache entry", "error", err)
+	}
+}
+
+func (c *CachingProvider) expired(entry cacheEntry) bool {
+	return c.ttl > 0 && time.Since(entry.CreatedAt) > c.ttl
+}
+
+func (c *CachingProvider) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// writeFileAtomic writes v as JSON to path via a temporary file and rename,
+// so readers never observe a partially written file.
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ClearCache removes all cached LLM responses under dir and returns how
+// many entries were removed.
+func ClearCache(dir string) (int, error) {
+	responses := filepath.Join(dir, responsesDir)
+	entries, err := os.ReadDir(responses)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(responses, e.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
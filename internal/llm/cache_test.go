@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingProvider is a minimal Provider that counts the completions it serves.
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	p.calls++
+	return "answer to " + prompt, nil
+}
+
+func (p *countingProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	p.calls++
+	ch := make(chan Chunk, 2)
+	ch <- Chunk{Text: "streamed "}
+	ch <- Chunk{Text: prompt}
+	close(ch)
+	return ch, nil
+}
+
+func (p *countingProvider) TestConnection(ctx context.Context) error { return nil }
+
+func TestCachingProvider(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	inner := &countingProvider{}
+	c := NewCachingProvider(inner, "model-a", dir, 0)
+
+	for i := 0; i < 2; i++ {
+		got, err := c.Complete(ctx, "question", CompletionOptions{})
+		if err != nil || got != "answer to question" {
+			t.Fatalf("Complete() = %q, %v", got, err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected 1 upstream call for identical prompts, got %d", inner.calls)
+	}
+
+	// A different model is a different key
+	if _, err := c.Complete(ctx, "question", CompletionOptions{Model: "model-b"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected a model override to miss the cache, got %d calls", inner.calls)
+	}
+
+	// A new provider sharing the directory reads the on-disk entries
+	inner2 := &countingProvider{}
+	c2 := NewCachingProvider(inner2, "model-a", dir, 0)
+	if got, _ := c2.Complete(ctx, "question", CompletionOptions{}); got != "answer to question" || inner2.calls != 0 {
+		t.Errorf("Expected an on-disk cache hit, got %q with %d calls", got, inner2.calls)
+	}
+
+	// Streams are cached once complete and replayed from the cache
+	for i := 0; i < 2; i++ {
+		ch, err := c.CompleteStream(ctx, "stream me", CompletionOptions{})
+		if err != nil {
+			t.Fatalf("CompleteStream() error = %v", err)
+		}
+		if got, err := Collect(ch); err != nil || got != "streamed stream me" {
+			t.Fatalf("Collect() = %q, %v", got, err)
+		}
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected the second stream to be served from the cache, got %d calls", inner.calls)
+	}
+
+	removed, err := ClearCache(dir)
+	if err != nil || removed != 3 {
+		t.Errorf("ClearCache() = %d, %v; want 3, nil", removed, err)
+	}
+	if removed, err := ClearCache(filepath.Join(dir, "missing")); err != nil || removed != 0 {
+		t.Errorf("ClearCache() on a missing directory = %d, %v", removed, err)
+	}
+}
+
+func TestCachingProviderTTL(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	inner := &countingProvider{}
+	c := NewCachingProvider(inner, "model-a", dir, time.Hour)
+
+	if _, err := c.Complete(ctx, "question", CompletionOptions{}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	// Age the on-disk entry past the TTL
+	key, _ := c.key("question", CompletionOptions{})
+	stale := cacheEntry{Provider: "counting", Model: "model-a", CreatedAt: time.Now().Add(-2 * time.Hour), Response: "old"}
+	if err := writeFileAtomic(c.path(key), stale); err != nil {
+		t.Fatalf("Failed to write stale entry: %v", err)
+	}
+
+	c2 := NewCachingProvider(inner, "model-a", dir, time.Hour)
+	got, err := c2.Complete(ctx, "question", CompletionOptions{})
+	if err != nil || got != "answer to question" {
+		t.Errorf("Expected a fresh answer after expiry, got %q, %v", got, err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected the stale entry to be refreshed, got %d calls", inner.calls)
+	}
+	if _, err := os.Stat(c.path(key)); err != nil {
+		t.Errorf("Expected the refreshed entry on disk: %v", err)
+	}
+}
+
+func TestCachingProviderRefresh(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	inner := &countingProvider{}
+	if _, err := NewCachingProvider(inner, "model-a", dir, 0).Complete(ctx, "question", CompletionOptions{}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	// Refreshed requests are sent again, and replace the cached responses
+	refreshing := NewCachingProvider(inner, "model-a", dir, 0)
+	refreshing.Refresh = true
+	if _, err := refreshing.Complete(ctx, "question", CompletionOptions{}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected the refreshed request to be sent, got %d calls", inner.calls)
+	}
+	key, _ := refreshing.key("question", CompletionOptions{})
+	if _, err := os.Stat(refreshing.path(key)); err != nil {
+		t.Errorf("Expected the new response on disk: %v", err)
+	}
+}
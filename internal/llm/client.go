@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+// NewHTTPClient returns the HTTP client the providers send their requests
+// with. It goes through the proxies named by the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables, trusts the certificates in
+// cfg.CACertFile besides the system's, and skips certificate verification
+// altogether if cfg.InsecureSkipVerify is set, which is logged as a warning.
+// Every request also carries the headers of cfg.ExtraHeaders and, when set,
+// cfg.Organization as OpenAI-Organization (see RequestHeaders).
+// It sets no overall timeout, since streamed responses take as long as the
+// model writes; requests are bounded by their contexts instead, which
+// NewProvider gives the deadline set by llm.request_timeout.
+func NewHTTPClient(cfg config.LLMConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("llm.ca_cert_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool() // No system pool (e.g., on some platforms): trust only the file
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("llm.ca_cert_file: no PEM certificates found in " + cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled (llm.insecure_skip_verify); anyone on the network path can read and alter LLM requests, API keys included")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = tlsConfig
+	headers := RequestHeaders(cfg)
+	if len(headers) == 0 {
+		return &http.Client{Transport: transport}, nil
+	}
+	// The names only, since the values may be keys
+	slog.Debug("Adding headers to LLM requests", "headers", strings.Join(slices.Sorted(maps.Keys(headers)), ", "))
+	return &http.Client{Transport: &headerTransport{base: transport, headers: headers}}, nil
+}
+
+// RequestHeaders returns the headers NewHTTPClient adds to every request for
+// cfg: llm.extra_headers and llm.organization, as OpenAI-Organization, which
+// takes precedence over an extra header of that name.
+func RequestHeaders(cfg config.LLMConfig) http.Header {
+	headers := http.Header{}
+	for name, value := range cfg.ExtraHeaders {
+		headers.Set(name, value)
+	}
+	if cfg.Organization != "" {
+		headers.Set("OpenAI-Organization", cfg.Organization)
+	}
+	return headers
+}
+
+// headerTransport is an http.RoundTripper adding headers to the requests
+// sent through base. A header the request already has is left as it is, so
+// that the ones the providers set, their API keys first, are never replaced.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context()) // A RoundTripper must not modify the request
+	for name, values := range t.headers {
+		if _, set := req.Header[name]; !set {
+			req.Header[name] = values
+		}
+	}
+	return t.base.RoundTrip(req)
+}
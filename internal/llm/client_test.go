@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.LLMConfig
+		wantErr   string // Creating the client fails with this
+		wantReach bool   // The self-signed server can be reached
+	}{
+		{name: "default", cfg: config.LLMConfig{}, wantReach: false},
+		{name: "custom CA", cfg: config.LLMConfig{CACertFile: caFile}, wantReach: true},
+		{name: "insecure", cfg: config.LLMConfig{InsecureSkipVerify: true}, wantReach: true},
+		{name: "missing CA file", cfg: config.LLMConfig{CACertFile: filepath.Join(dir, "missing.pem")}, wantErr: "llm.ca_cert_file"},
+		{name: "CA file without certificates", cfg: config.LLMConfig{CACertFile: garbage}, wantErr: "no PEM certificates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewHTTPClient() error = %v", err)
+			}
+			if client.Transport.(*http.Transport).Proxy == nil {
+				t.Error("Expected the client to honor the proxy environment variables")
+			}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err == nil) != tt.wantReach {
+				t.Errorf("Expected reaching the server to succeed: %v, got error %v", tt.wantReach, err)
+			}
+		})
+	}
+}
+
+func TestExtraHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"choices": [{"message": {"content": "Hello"}}]}`))
+	}))
+	defer server.Close()
+
+	// Viper lowercases the keys of maps read from the config
+	provider, err := NewProvider(config.LLMConfig{
+		Provider:     "openai",
+		Endpoint:     server.URL,
+		Model:        "gpt-4o",
+		APIKey:       "sk-test-key",
+		Organization: "org-123",
+		ExtraHeaders: map[string]string{"x-route-key": "blue", "authorization": "Bearer gateway-key", "openai-organization": "org-456"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if _, err := provider.Complete(t.Context(), "Hi", CompletionOptions{}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	want := map[string]string{
+		"X-Route-Key":         "blue",
+		"Openai-Organization": "org-123",            // llm.organization wins over an extra header
+		"Authorization":       "Bearer sk-test-key", // The provider's own header is kept
+		"Content-Type":        "application/json",
+	}
+	for name, value := range want {
+		if values := got.Values(name); len(values) != 1 || values[0] != value {
+			t.Errorf("Expected the %s header %q, got %q", name, value, values)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import "strings"
+
+// DefaultResponseReserve is how many tokens of the context window are kept
+// for the response when the completion options do not set MaxTokens.
+const DefaultResponseReserve = 2048
+
+// contextWindows holds the context window of models, in tokens, by model
+// name prefix. The longest matching prefix wins, so that versions and tags
+// (e.g., "gpt-4o-2024-08-06" or "llama3.1:8b") resolve to their family.
+var contextWindows = map[string]int{
+	"gpt-4o":           128_000,
+	"gpt-4.1":          1_047_576,
+	"gpt-4-turbo":      128_000,
+	"gpt-4":            8_192,
+	"gpt-3.5-turbo":    16_385,
+	"o1":               200_000,
+	"o1-mini":          128_000,
+	"o3":               200_000,
+	"o4":               200_000,
+	"claude":           200_000,
+	"gemini-1.5-pro":   2_097_152,
+	"gemini-1.5-flash": 1_048_576,
+	"gemini-2":         1_048_576,
+	"llama2":           4_096,
+	"llama3":           8_192,
+	"llama3.1":         131_072,
+	"llama3.2":         131_072,
+	"llama3.3":         131_072,
+	"codellama":        16_384,
+	"mistral":          32_768,
+	"mixtral":          32_768,
+	"qwen2.5":          32_768,
+	"qwen2.5-coder":    32_768,
+	"deepseek-coder":   16_384,
+	"gemma2":           8_192,
+	"gemma3":           131_072,
+	"phi3":             4_096,
+	"phi4":             16_384,
+}
+
+// LookupContextWindow returns the context window of model, in tokens. The
+// boolean is false when it is not known.
+func LookupContextWindow(model string) (int, bool) {
+	model = strings.ToLower(model)
+	var best string
+	var window int
+	for prefix, w := range contextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, window = prefix, w
+		}
+	}
+	return window, best != ""
+}
+
+// PromptBudget returns how many tokens a prompt may use in a context window
+// of window tokens, keeping maxTokens (or DefaultResponseReserve, if it is
+// not positive) for the response.
+func PromptBudget(window, maxTokens int) int {
+	if maxTokens <= 0 {
+		maxTokens = DefaultResponseReserve
+	}
+	return window - maxTokens
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import "strings"
+
+// Pricing is the list price of a model in US dollars per million tokens.
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// pricingTable holds list prices by provider and model name prefix. The
+// longest matching prefix wins, so dated variants (e.g.,
+// "gpt-4o-2024-08-06") resolve to their family.
+var pricingTable = map[string]map[string]Pricing{
+	"openai": {
+		"gpt-4o":        {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"gpt-4o-mini":   {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gpt-4.1":       {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+		"gpt-4.1-mini":  {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+		"gpt-4.1-nano":  {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"gpt-4-turbo":   {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+		"gpt-4":         {InputPerMillion: 30.00, OutputPerMillion: 60.00},
+		"gpt-3.5-turbo": {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+		"o1":            {InputPerMillion: 15.00, OutputPerMillion: 60.00},
+		"o1-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+		"o3-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+	},
+	"anthropic": {
+		"claude-3-opus":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+		"claude-opus-4":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+		"claude-3-sonnet":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-3-5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-3-7-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-sonnet-4":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+		"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+		"claude-3-haiku":    {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+	},
+	"gemini": {
+		"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+		"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+		"gemini-2.0-flash": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		"gemini-2.5-flash": {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+		"gemini-2.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	},
+}
+
+// localProviders run models on the user's machine at no API cost.
+var localProviders = map[string]bool{"ollama": true, "lmstudio": true}
+
+// LookupPricing returns the pricing of model on provider. Local providers
+// are free. The boolean is false when no pricing is known.
+func LookupPricing(provider, model string) (Pricing, bool) {
+	if localProviders[provider] {
+		return Pricing{}, true
+	}
+
+	// Azure OpenAI bills the same list prices as OpenAI
+	if provider == "azure" {
+		provider = "openai"
+	}
+
+	model = strings.ToLower(model)
+	var best string
+	var pricing Pricing
+	for prefix, p := range pricingTable[provider] {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, pricing = prefix, p
+		}
+	}
+	return pricing, best != ""
+}
+
+// InputCost returns the cost in US dollars of sending tokens input tokens.
+func (p Pricing) InputCost(tokens int) float64 {
+	return float64(tokens) * p.InputPerMillion / 1_000_000
+}
+
+// OutputCost returns the cost in US dollars of generating tokens output tokens.
+func (p Pricing) OutputCost(tokens int) float64 {
+	return float64(tokens) * p.OutputPerMillion / 1_000_000
+}
+
+// Cost returns the cost in US dollars of the requests counted by u.
+func (p Pricing) Cost(u Usage) float64 {
+	return p.InputCost(u.InputTokens) + p.OutputCost(u.OutputTokens)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookupPricing(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		wantInput       float64
+		wantOK          bool
+	}{
+		{"openai", "gpt-4", 30.00, true},
+		{"openai", "gpt-4o", 2.50, true},
+		{"openai", "gpt-4o-mini-2024-07-18", 0.15, true},
+		{"openai", "GPT-4-Turbo", 10.00, true},
+		{"anthropic", "claude-3-5-sonnet-20241022", 3.00, true},
+		{"ollama", "llama3", 0, true},
+		{"openai", "some-future-model", 0, false},
+		{"unknown", "gpt-4", 0, false},
+	}
+
+	for _, tt := range tests {
+		p, ok := LookupPricing(tt.provider, tt.model)
+		if ok != tt.wantOK || p.InputPerMillion != tt.wantInput {
+			t.Errorf("LookupPricing(%q, %q) = %+v, %v; want input %v, %v", tt.provider, tt.model, p, ok, tt.wantInput, tt.wantOK)
+		}
+	}
+
+	p, _ := LookupPricing("openai", "gpt-4")
+	if got := p.InputCost(500_000); math.Abs(got-15.0) > 1e-9 {
+		t.Errorf("InputCost(500000) = %v, want 15", got)
+	}
+}
+
+func TestLookupContextWindow(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   int
+		wantOK bool
+	}{
+		{"gpt-4", 8_192, true},
+		{"gpt-4o-2024-08-06", 128_000, true},
+		{"claude-3-5-sonnet-20241022", 200_000, true},
+		{"llama3:8b", 8_192, true},
+		{"Llama3.1:70b", 131_072, true},
+		{"qwen2.5-coder:7b", 32_768, true},
+		{"some-future-model", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := LookupContextWindow(tt.model); got != tt.want || ok != tt.wantOK {
+			t.Errorf("LookupContextWindow(%q) = %d, %v; want %d, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if got := PromptBudget(8192, 0); got != 8192-DefaultResponseReserve {
+		t.Errorf("PromptBudget(8192, 0) = %d, want %d", got, 8192-DefaultResponseReserve)
+	}
+	if got := PromptBudget(8192, 1000); got != 7192 {
+		t.Errorf("PromptBudget(8192, 1000) = %d, want 7192", got)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	if got := HeuristicTokens("abcdefgh"); got != 2 {
+		t.Errorf("HeuristicTokens() = %d, want 2", got)
+	}
+	if got := HeuristicTokens("abc"); got != 1 {
+		t.Errorf("HeuristicTokens() = %d, want 1", got)
+	}
+
+	// Models without a known tokenizer use the heuristic
+	text := "func main() { fmt.Println(\"hello, world\") }"
+	if got, want := CountTokens("llama3", text), HeuristicTokens(text); got != want {
+		t.Errorf("CountTokens(llama3) = %d, want heuristic %d", got, want)
+	}
+
+	// "Hello", " world", "!" are single tokens for GPT tokenizers
+	if got := CountTokens("gpt-4", "Hello world!"); got != 3 {
+		t.Errorf("CountTokens(gpt-4) = %d, want 3", got)
+	}
+	if got := CountTokens("gpt-4o", ""); got != 0 {
+		t.Errorf("CountTokens(empty) = %d, want 0", got)
+	}
+
+	// Counted with the BPE encoding itself, which the approximation is one off
+	code := "func main() {\n\tfmt.Println(\"こんにちは\")\n}"
+	for _, model := range []string{"gpt-4", "gpt-4o"} {
+		if got := CountTokens(model, code); got != 10 {
+			t.Errorf("CountTokens(%s) = %d, want 10", model, got)
+		}
+	}
+}
+
+func TestTokenizerFor(t *testing.T) {
+	tests := []struct {
+		model    string
+		encoding string
+	}{
+		{"gpt-4", encodingCL100K},
+		{"gpt-4-turbo", encodingCL100K},
+		{"gpt-3.5-turbo", encodingCL100K},
+		{"gpt-35-turbo", encodingCL100K},
+		{"gpt-4o", encodingO200K},
+		{"GPT-4o-mini", encodingO200K},
+		{"gpt-4.1-nano", encodingO200K},
+		{"gpt-5", encodingO200K},
+		{"o3-mini", encodingO200K},
+		{"chatgpt-4o-latest", encodingO200K},
+		{"llama3", ""},
+		{"claude-3-5-sonnet-latest", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		tokenizer := TokenizerFor(tt.model)
+		if tt.encoding == "" {
+			if tokenizer != HeuristicTokenizer {
+				t.Errorf("Expected the heuristic tokenizer for %q, got %T", tt.model, tokenizer)
+			}
+			continue
+		}
+		tiktoken, ok := tokenizer.(*tiktokenTokenizer)
+		if !ok || tiktoken.encoding != tt.encoding {
+			t.Errorf("Expected the %s tokenizer for %q, got %#v", tt.encoding, tt.model, tokenizer)
+		}
+	}
+
+	if TokenizerFor("gpt-4o") != TokenizerFor("o1") {
+		t.Error("Expected models of the same encoding to share a tokenizer")
+	}
+}
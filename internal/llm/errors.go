@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Kinds of provider errors. Each provider maps the errors of its API onto
+// them, so callers can branch with errors.Is regardless of the provider: an
+// *APIError matches its Kind, a *TimeoutError matches ErrTimeout, and a
+// *ModelNotFoundError matches ErrModelNotFound.
+var (
+	// ErrAuth reports an API key the provider rejected or that lacks access.
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimit reports a request refused for exceeding a rate limit or quota.
+	ErrRateLimit = errors.New("rate limit exceeded")
+	// ErrContextLengthExceeded reports a prompt longer than the model accepts.
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	// ErrModelNotFound reports a model the provider does not serve.
+	ErrModelNotFound = errors.New("model not found")
+	// ErrTimeout reports a request that took too long to complete.
+	ErrTimeout = errors.New("request timed out")
+)
+
+// kindHints tell how to fix each kind of provider error.
+var kindHints = map[error]string{
+	ErrAuth:                  "check llm.api_key",
+	ErrRateLimit:             "lower --concurrency or set llm.requests_per_minute",
+	ErrContextLengthExceeded: "set llm.context_window to the model's window so large files are split and prompts trimmed, or exclude large files with --max-size",
+	ErrModelNotFound:         "check llm.model or pass --model",
+	ErrTimeout:               "raise llm.request_timeout for slow models",
+}
+
+// apiErrorBody is the cause of an error response of the providers' APIs:
+// OpenAI and Anthropic report {"error": {"type", "code"}}, and Gemini reports
+// {"error": {"code", "status"}}. Ollama reports a message only.
+type apiErrorBody struct {
+	Type   string          `json:"type"`
+	Code   json.RawMessage `json:"code"` // A string for OpenAI, the HTTP status for Gemini
+	Status string          `json:"status"`
+}
+
+// errorCode returns the machine-readable cause of an error response, such
+// as "context_length_exceeded" (OpenAI), "authentication_error" (Anthropic)
+// or "RESOURCE_EXHAUSTED" (Gemini), or "" if it reports none.
+func errorCode(body []byte) string {
+	var structured struct {
+		Error apiErrorBody `json:"error"`
+	}
+	if json.Unmarshal(body, &structured) != nil {
+		return ""
+	}
+	var code string
+	if json.Unmarshal(structured.Error.Code, &code) == nil && code != "" {
+		return code
+	}
+	if structured.Error.Status != "" {
+		return structured.Error.Status
+	}
+	return structured.Error.Type
+}
+
+// contextLengthPhrases are how the providers word a prompt longer than the
+// model accepts, when they report no code for it.
+var contextLengthPhrases = []string{
+	"maximum context length",               // OpenAI, LM Studio
+	"prompt is too long",                   // Anthropic
+	"exceeds the maximum number of tokens", // Gemini
+	"context window",                       // Ollama, and gateways
+	"context length",
+}
+
+// classify returns the kind of an error a provider's API reported with
+// status (0 for an error in the middle of a stream), code (see errorCode)
+// and message, or nil if it is none of the known kinds.
+func classify(status int, code, message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case code == "context_length_exceeded" || containsAny(lower, contextLengthPhrases):
+		// OpenAI reports it as 400, Anthropic as 400 or 413
+		return ErrContextLengthExceeded
+	case status == http.StatusUnauthorized || status == http.StatusForbidden,
+		code == "invalid_api_key" || code == "authentication_error" || code == "permission_error",
+		code == "UNAUTHENTICATED" || code == "PERMISSION_DENIED",
+		strings.Contains(lower, "api key not valid"): // Gemini reports an invalid key as 400
+		return ErrAuth
+	case status == http.StatusTooManyRequests, code == "rate_limit_exceeded" || code == "rate_limit_error" || code == "RESOURCE_EXHAUSTED":
+		return ErrRateLimit
+	case code == "model_not_found", status == http.StatusNotFound && strings.Contains(lower, "model"):
+		return ErrModelNotFound
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	return nil
+}
+
+// reportedError returns the error a provider reported in the middle of a
+// streamed response, classified like the errors of its API.
+func reportedError(provider, code, message string) error {
+	return &APIError{Provider: provider, Message: message, Kind: classify(0, code, message)}
+}
+
+// containsAny reports whether s contains any of substrs.
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
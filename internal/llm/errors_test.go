@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		status   int
+		body     string
+		want     error
+		wantHint string
+	}{
+		{
+			name:     "openai context length",
+			provider: "openai",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"message":"This model's maximum context length is 8192 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			want:     ErrContextLengthExceeded,
+			wantHint: "set llm.context_window",
+		},
+		{
+			name:     "anthropic prompt too long",
+			provider: "anthropic",
+			status:   http.StatusBadRequest,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			want:     ErrContextLengthExceeded,
+		},
+		{
+			name:     "openai invalid key",
+			provider: "openai",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`,
+			want:     ErrAuth,
+			wantHint: "(check llm.api_key)",
+		},
+		{
+			name:     "gemini invalid key",
+			provider: "gemini",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`,
+			want:     ErrAuth,
+			wantHint: "CODEDECODER_GEMINI_API_KEY",
+		},
+		{
+			name:     "gemini quota",
+			provider: "gemini",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			want:     ErrRateLimit,
+			wantHint: "llm.requests_per_minute",
+		},
+		{
+			name:     "ollama model",
+			provider: "ollama",
+			status:   http.StatusNotFound,
+			body:     `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			want:     ErrModelNotFound,
+			wantHint: "check llm.model",
+		},
+		{
+			name:     "gateway timeout",
+			provider: "openai",
+			status:   http.StatusGatewayTimeout,
+			body:     "upstream timed out",
+			want:     ErrTimeout,
+		},
+		{
+			name:     "unknown",
+			provider: "openai",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"message":"bad prompt"}}`,
+		},
+	}
+	kinds := []error{ErrAuth, ErrRateLimit, ErrContextLengthExceeded, ErrModelNotFound, ErrTimeout}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := doJSON(context.Background(), server.Client(), tt.provider, http.MethodGet, server.URL, nil, nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %v", err)
+			}
+			for _, kind := range kinds {
+				if got := errors.Is(err, kind); got != (kind == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", err, kind, got, kind == tt.want)
+				}
+			}
+			if !strings.Contains(err.Error(), tt.wantHint) {
+				t.Errorf("Expected the error to contain %q, got %q", tt.wantHint, err)
+			}
+		})
+	}
+}
+
+func TestReportedError(t *testing.T) {
+	// A rate limit reported in the middle of a stream is worth retrying
+	err := reportedError("anthropic", "rate_limit_error", "Number of requests has exceeded your rate limit")
+	if !errors.Is(err, ErrRateLimit) || !Retryable(err) {
+		t.Errorf("Expected a retryable rate limit error, got %v", err)
+	}
+	if want := "anthropic API error: Number of requests"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Expected the error to start with %q, got %q", want, err)
+	}
+
+	err = reportedError("ollama", "", "input length exceeds the context length")
+	if !errors.Is(err, ErrContextLengthExceeded) || Retryable(err) || IsFatal(err) {
+		t.Errorf("Expected a permanent but not fatal context length error, got %v", err)
+	}
+
+	// The errors of the decorators match their kinds too
+	if err := fmt.Errorf("wrapped: %w", &TimeoutError{Provider: "openai"}); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a timeout to match ErrTimeout, got %v", err)
+	}
+	if err := (&ModelNotFoundError{Provider: "ollama", Model: "llama9"}); !errors.Is(err, ErrModelNotFound) || !IsFatal(err) {
+		t.Errorf("Expected a missing model to match ErrModelNotFound and be fatal, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+const (
+	// apiKeyEnvVar is the environment variable consulted when no API key is configured.
+	apiKeyEnvVar = "CODEDECODER_LLM_APIKEY"
+	// geminiAPIKeyEnvVar is the Gemini specific key variable, consulted first for gemini.
+	geminiAPIKeyEnvVar = "CODEDECODER_GEMINI_API_KEY"
+)
+
+// NewProvider creates the provider selected by cfg.Provider. Each request
+// fails after cfg.RequestTimeout when set, requests are limited to
+// cfg.RequestsPerMinute when set, and transient errors are retried as
+// configured by cfg.MaxRetries and cfg.RetryBaseDelay.
+func NewProvider(cfg config.LLMConfig) (Provider, error) {
+	p, err := newBaseProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RequestTimeout > 0 {
+		p = NewTimeoutProvider(p, cfg.RequestTimeout)
+	}
+	if cfg.RequestsPerMinute > 0 {
+		// A burst of one spreads requests evenly, since providers often
+		// enforce their limits over windows shorter than a minute
+		p = NewRateLimitedProvider(p, NewRateLimiter(cfg.RequestsPerMinute, 1))
+	}
+	if cfg.MaxRetries > 0 {
+		return NewRetryingProvider(p, RetryPolicy{MaxRetries: cfg.MaxRetries, BaseDelay: cfg.RetryBaseDelay}), nil
+	}
+	return p, nil
+}
+
+// newBaseProvider creates the provider selected by cfg.Provider without any decorators.
+func newBaseProvider(cfg config.LLMConfig) (Provider, error) {
+	if cfg.Provider == "" {
+		return nil, fmt.Errorf("no LLM provider configured: set llm.provider in the config")
+	}
+	info, ok := LookupProvider(cfg.Provider)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
+	}
+	// A provider cannot be created without its endpoint or deployment; a
+	// missing API key is reported by the API itself
+	values := map[string]string{"endpoint": cfg.Endpoint, "deployment": cfg.Deployment}
+	for _, field := range info.Fields {
+		if value, ok := values[field]; ok && value == "" {
+			return nil, fmt.Errorf("llm.%s is required for provider '%s'", field, cfg.Provider)
+		}
+	}
+
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return info.create(cfg, APIKey(cfg), client), nil
+}
+
+// APIKey returns the API key NewProvider uses for cfg: llm.api_key, else the
+// provider's environment variable.
+func APIKey(cfg config.LLMConfig) string {
+	apiKey := cfg.APIKey
+	if apiKey == "" && cfg.Provider == "gemini" {
+		apiKey = os.Getenv(geminiAPIKeyEnvVar)
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv(apiKeyEnvVar)
+	}
+	return apiKey
+}
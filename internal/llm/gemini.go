@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// geminiBaseURL is the base URL of the Generative Language API.
+	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	// geminiDefaultModel is used when no model is configured.
+	geminiDefaultModel = "gemini-2.0-flash"
+)
+
+// GeminiProvider talks to the Google Gemini (Generative Language) API.
+type GeminiProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewGeminiProvider creates a provider for the Gemini API.
+func NewGeminiProvider(apiKey, model string, client *http.Client) *GeminiProvider {
+	if model == "" {
+		model = geminiDefaultModel
+	}
+	return &GeminiProvider{
+		baseURL: geminiBaseURL,
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+// Name implements Provider.
+func (p *GeminiProvider) Name() string { return "gemini" }
+
+// BaseURL returns the URL the API paths are appended to.
+func (p *GeminiProvider) BaseURL() string { return p.baseURL }
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// geminiResponse is a complete response or a single streamed event.
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+func (p *GeminiProvider) request(prompt string, opts CompletionOptions) geminiRequest {
+	req := geminiRequest{
+		Contents: []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}},
+	}
+	if opts.System != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: opts.System}}}
+	}
+	if opts.Temperature != nil || opts.MaxTokens > 0 || opts.TopP != nil {
+		req.GenerationConfig = &geminiGenerationConfig{
+			Temperature:     opts.Temperature,
+			MaxOutputTokens: opts.MaxTokens,
+			TopP:            opts.TopP,
+		}
+	}
+	return req
+}
+
+func (p *GeminiProvider) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": p.apiKey}
+}
+
+// url returns the URL of a method of the model selected by opts.
+func (p *GeminiProvider) url(opts CompletionOptions, method string) string {
+	model := p.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	return p.baseURL + "/models/" + url.PathEscape(model) + ":" + method
+}
+
+// text returns the generated text of a response, or a *BlockedError if the
+// prompt or the response was blocked by Gemini's safety filters.
+func (r *geminiResponse) text() (string, error) {
+	if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+		return "", &BlockedError{Provider: "gemini", Reason: r.PromptFeedback.BlockReason}
+	}
+	if len(r.Candidates) == 0 {
+		return "", nil
+	}
+	// Only one candidate is requested
+	c := r.Candidates[0]
+	var sb strings.Builder
+	for _, part := range c.Content.Parts {
+		sb.WriteString(part.Text)
+	}
+	switch c.FinishReason {
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return sb.String(), &BlockedError{Provider: "gemini", Reason: c.FinishReason}
+	}
+	return sb.String(), nil
+}
+
+// Complete implements Provider.
+func (p *GeminiProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.url(opts, "generateContent"), p.headers(), p.request(prompt, opts))
+	if err != nil {
+		return "", err
+	}
+
+	var result geminiResponse
+	if err := decodeJSON(p.Name(), resp, &result); err != nil {
+		return "", err
+	}
+	if len(result.Candidates) == 0 && result.PromptFeedback == nil {
+		return "", fmt.Errorf("%s: response contained no candidates", p.Name())
+	}
+	return result.text()
+}
+
+// CompleteStream implements Provider using the server-sent events stream.
+func (p *GeminiProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.url(opts, "streamGenerateContent")+"?alt=sse", p.headers(), p.request(prompt, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(ctx, resp.Body, func(r io.Reader, emit emitFunc) error {
+		return readSSE(r, func(_, data string) (bool, error) {
+			var event geminiResponse
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return true, fmt.Errorf("%s: failed to decode stream event: %w", p.Name(), err)
+			}
+			text, err := event.text()
+			if text != "" && !emit(text) {
+				return true, ctx.Err()
+			}
+			return err != nil, err
+		})
+	}), nil
+}
+
+// TestConnection implements Provider by listing the available models.
+func (p *GeminiProvider) TestConnection(ctx context.Context) error {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodGet, p.baseURL+"/models", p.headers(), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestGemini(t *testing.T, handler http.HandlerFunc) *GeminiProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	p := NewGeminiProvider("test-key", "gemini-1.5-pro", server.Client())
+	p.baseURL = server.URL
+	return p
+}
+
+func TestGeminiComplete(t *testing.T) {
+	p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-1.5-pro:generateContent" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			t.Errorf("Expected API key header, got %q", got)
+		}
+		var req geminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Contents[0].Parts[0].Text != "Say hi" {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		if req.GenerationConfig == nil || *req.GenerationConfig.Temperature != 0.2 || req.GenerationConfig.MaxOutputTokens != 100 {
+			t.Errorf("Expected generationConfig to carry the options, got %+v", req.GenerationConfig)
+		}
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"},{"text":"!"}]},"finishReason":"STOP"}]}`)
+	})
+
+	temperature := 0.2
+	got, err := p.Complete(context.Background(), "Say hi", CompletionOptions{Temperature: &temperature, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "Hi!" {
+		t.Errorf("Expected 'Hi!', got %q", got)
+	}
+}
+
+func TestGeminiCompleteStream(t *testing.T) {
+	p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-1.5-pro:streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("Unexpected URL %s", r.URL)
+		}
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hel\"}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}]}\n\n")
+	})
+
+	ch, err := p.CompleteStream(context.Background(), "hi", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	got, err := Collect(ch)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if got != "Hello" {
+		t.Errorf("Expected 'Hello', got %q", got)
+	}
+}
+
+func TestGeminiBlocked(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string
+	}{
+		{name: "prompt blocked", body: `{"promptFeedback":{"blockReason":"SAFETY"}}`, wantReason: "SAFETY"},
+		{name: "response blocked", body: `{"candidates":[{"content":{"parts":[]},"finishReason":"RECITATION"}]}`, wantReason: "RECITATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestGemini(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			})
+
+			_, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+			var blocked *BlockedError
+			if !errors.As(err, &blocked) {
+				t.Fatalf("Expected *BlockedError, got %v", err)
+			}
+			if blocked.Reason != tt.wantReason {
+				t.Errorf("Expected reason %s, got %s", tt.wantReason, blocked.Reason)
+			}
+		})
+	}
+}
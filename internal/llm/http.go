@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody caps how much of an error response is read into an APIError.
+const maxErrorBody = 64 * 1024
+
+// doJSON sends a request with an optional JSON body and returns the response.
+// Responses with a non-2xx status are consumed and returned as *APIError,
+// classified by their status and body.
+func doJSON(ctx context.Context, client *http.Client, provider, method, url string, headers map[string]string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to encode request: %w", provider, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request: %w", provider, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Provider: provider, Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		message := errorMessage(data, resp.Status)
+		return nil, &APIError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			Message:    message,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Kind:       classify(resp.StatusCode, errorCode(data), message),
+		}
+	}
+	return resp, nil
+}
+
+// decodeJSON decodes a complete JSON response body into v and closes it.
+func decodeJSON(provider string, resp *http.Response, v any) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", provider, err)
+	}
+	return nil
+}
+
+// errorMessage extracts a human-readable message from an error response body.
+// It understands {"error": {"message": "..."}} (OpenAI, Anthropic) and
+// {"error": "..."} (Ollama), falling back to the raw body or status text.
+func errorMessage(body []byte, status string) string {
+	var structured struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &structured) == nil && structured.Error.Message != "" {
+		return structured.Error.Message
+	}
+	var simple struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &simple) == nil && simple.Error != "" {
+		return simple.Error
+	}
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return msg
+	}
+	return status
+}
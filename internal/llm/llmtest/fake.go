@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package llmtest provides a fake llm.Provider for tests.
+package llmtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ksylvan/code-decoder/internal/llm"
+)
+
+// Provider is a fake llm.Provider that answers prompts with Respond and
+// records every prompt it receives. It is safe for concurrent use.
+type Provider struct {
+	// Respond returns the response for a prompt. If nil, the prompt is echoed back.
+	Respond func(prompt string) (string, error)
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// Name implements llm.Provider.
+func (p *Provider) Name() string { return "fake" }
+
+// Complete implements llm.Provider.
+func (p *Provider) Complete(ctx context.Context, prompt string, opts llm.CompletionOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	p.prompts = append(p.prompts, prompt)
+	p.mu.Unlock()
+
+	if p.Respond == nil {
+		return prompt, nil
+	}
+	return p.Respond(prompt)
+}
+
+// CompleteStream implements llm.Provider, delivering the response one word at a time.
+func (p *Provider) CompleteStream(ctx context.Context, prompt string, opts llm.CompletionOptions) (<-chan llm.Chunk, error) {
+	text, err := p.Complete(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan llm.Chunk)
+	go func() {
+		defer close(ch)
+		start := 0
+		for i := 0; i <= len(text); i++ {
+			if (i == len(text) && start < i) || (i < len(text) && text[i] == ' ') {
+				end := min(i+1, len(text))
+				select {
+				case ch <- llm.Chunk{Text: text[start:end]}:
+				case <-ctx.Done():
+					return
+				}
+				start = end
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// TestConnection implements llm.Provider.
+func (p *Provider) TestConnection(ctx context.Context) error { return ctx.Err() }
+
+// Prompts returns the prompts received so far.
+func (p *Provider) Prompts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.prompts...)
+}
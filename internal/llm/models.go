@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// ModelManager is implemented by providers serving local models, which can
+// report whether a model has been downloaded and download it.
+type ModelManager interface {
+	// HasModel reports whether model is available locally.
+	HasModel(ctx context.Context, model string) (bool, error)
+
+	// PullModel downloads model, calling progress (if not nil) as the
+	// download proceeds.
+	PullModel(ctx context.Context, model string, progress func(PullProgress)) error
+}
+
+// PullProgress is a progress update of a model download.
+type PullProgress struct {
+	Status    string // Current step (e.g., "pulling manifest")
+	Completed int64  // Bytes downloaded of the current layer
+	Total     int64  // Size of the current layer in bytes, or 0 if unknown
+}
+
+// ModelNotFoundError is returned when the configured model is not available
+// from a local provider.
+type ModelNotFoundError struct {
+	Provider string // Provider name
+	Model    string // Model that was requested
+}
+
+func (e *ModelNotFoundError) Error() string {
+	return fmt.Sprintf("model %q not found on the %s server", e.Model, e.Provider)
+}
+
+// Is reports whether target is ErrModelNotFound.
+func (e *ModelNotFoundError) Is(target error) bool { return target == ErrModelNotFound }
+
+// ModelLister is implemented by providers that can list the models they
+// serve, such as the OpenAI-compatible API of LM Studio.
+type ModelLister interface {
+	// ListModels returns the identifiers of the available models.
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// AsModelManager returns the ModelManager implemented by p or by the
+// provider it decorates, if any.
+func AsModelManager(p Provider) (ModelManager, bool) {
+	return unwrapAs[ModelManager](p)
+}
+
+// AsModelLister returns the ModelLister implemented by p or by the provider
+// it decorates, if any.
+func AsModelLister(p Provider) (ModelLister, bool) {
+	return unwrapAs[ModelLister](p)
+}
+
+// BaseURL returns the URL that the requests of p, or of the provider it
+// decorates, are sent under, or "" if the provider does not report one.
+func BaseURL(p Provider) string {
+	if b, ok := unwrapAs[interface{ BaseURL() string }](p); ok {
+		return b.BaseURL()
+	}
+	return ""
+}
+
+// unwrapAs returns the first of p and the providers it decorates that
+// implements T.
+func unwrapAs[T any](p Provider) (T, bool) {
+	for p != nil {
+		if t, ok := p.(T); ok {
+			return t, true
+		}
+		w, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			break
+		}
+		p = w.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
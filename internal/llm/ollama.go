@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OllamaProvider talks to a local Ollama server.
+type OllamaProvider struct {
+	endpoint string
+	model    string
+	client   *http.Client
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at endpoint
+// (e.g., "http://localhost:11434").
+func NewOllamaProvider(endpoint, model string, client *http.Client) *OllamaProvider {
+	return &OllamaProvider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		model:    model,
+		client:   client,
+	}
+}
+
+// Name implements Provider.
+func (p *OllamaProvider) Name() string { return "ollama" }
+
+// BaseURL returns the URL of the Ollama server.
+func (p *OllamaProvider) BaseURL() string { return p.endpoint }
+
+type ollamaRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	System  string         `json:"system,omitempty"`
+	Stream  bool           `json:"stream"`
+	Options *ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaOptions holds the model parameters of a request.
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// ollamaResponse is a complete response, or one line of a streamed response.
+type ollamaResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
+}
+
+func (p *OllamaProvider) request(prompt string, opts CompletionOptions, streaming bool) ollamaRequest {
+	model := p.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	req := ollamaRequest{Model: model, Prompt: prompt, System: opts.System, Stream: streaming}
+	if opts.Temperature != nil || opts.MaxTokens > 0 || opts.TopP != nil {
+		req.Options = &ollamaOptions{Temperature: opts.Temperature, NumPredict: opts.MaxTokens, TopP: opts.TopP}
+	}
+	return req
+}
+
+// Complete implements Provider.
+func (p *OllamaProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.endpoint+"/api/generate", nil, p.request(prompt, opts, false))
+	if err != nil {
+		return "", err
+	}
+
+	var result ollamaResponse
+	if err := decodeJSON(p.Name(), resp, &result); err != nil {
+		return "", err
+	}
+	if result.Error != "" {
+		return "", reportedError(p.Name(), "", result.Error)
+	}
+	return result.Response, nil
+}
+
+// CompleteStream implements Provider using Ollama's newline-delimited JSON stream.
+func (p *OllamaProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.endpoint+"/api/generate", nil, p.request(prompt, opts, true))
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(ctx, resp.Body, func(r io.Reader, emit emitFunc) error {
+		return readLines(r, func(line []byte) (bool, error) {
+			var part ollamaResponse
+			if err := json.Unmarshal(line, &part); err != nil {
+				return true, fmt.Errorf("%s: failed to decode stream line: %w", p.Name(), err)
+			}
+			if part.Error != "" {
+				return true, reportedError(p.Name(), "", part.Error)
+			}
+			if part.Response != "" && !emit(part.Response) {
+				return true, ctx.Err()
+			}
+			return part.Done, nil
+		})
+	}), nil
+}
+
+// TestConnection implements Provider by listing the locally available models.
+func (p *OllamaProvider) TestConnection(ctx context.Context) error {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodGet, p.endpoint+"/api/tags", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ollamaModelName returns model with the tag Ollama assumes when none is
+// given, so that "llama3" matches "llama3:latest".
+func ollamaModelName(model string) string {
+	if !strings.Contains(model, ":") {
+		return model + ":latest"
+	}
+	return model
+}
+
+// HasModel implements ModelManager by listing the locally available models.
+func (p *OllamaProvider) HasModel(ctx context.Context, model string) (bool, error) {
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodGet, p.endpoint+"/api/tags", nil, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := decodeJSON(p.Name(), resp, &result); err != nil {
+		return false, err
+	}
+	want := ollamaModelName(model)
+	for _, m := range result.Models {
+		if ollamaModelName(m.Name) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PullModel implements ModelManager, following the progress Ollama streams
+// while it downloads the model.
+func (p *OllamaProvider) PullModel(ctx context.Context, model string, progress func(PullProgress)) error {
+	body := map[string]any{"model": model, "stream": true}
+	resp, err := doJSON(ctx, p.client, p.Name(), http.MethodPost, p.endpoint+"/api/pull", nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	success := false
+	err = readLines(resp.Body, func(line []byte) (bool, error) {
+		var update struct {
+			Status    string `json:"status"`
+			Completed int64  `json:"completed"`
+			Total     int64  `json:"total"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &update); err != nil {
+			return true, fmt.Errorf("%s: failed to decode pull progress: %w", p.Name(), err)
+		}
+		if update.Error != "" {
+			return true, fmt.Errorf("%s: pulling %s failed: %s", p.Name(), model, update.Error)
+		}
+		if progress != nil {
+			progress(PullProgress{Status: update.Status, Completed: update.Completed, Total: update.Total})
+		}
+		success = update.Status == "success"
+		return success, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("%s: pulling %s ended before it completed", p.Name(), model)
+	}
+	return nil
+}
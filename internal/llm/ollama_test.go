@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"llama3:latest"}]}`)
+		case "/api/generate":
+			var req ollamaRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Model != "llama3" {
+				t.Errorf("Expected model llama3, got %s", req.Model)
+			}
+			if !req.Stream {
+				fmt.Fprint(w, `{"response":"Hello","done":true}`)
+				return
+			}
+			fmt.Fprintln(w, `{"response":"Hel","done":false}`)
+			fmt.Fprintln(w, `{"response":"lo","done":false}`)
+			fmt.Fprintln(w, `{"response":"","done":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL+"/", "llama3", server.Client())
+	ctx := context.Background()
+
+	if err := p.TestConnection(ctx); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+
+	got, err := p.Complete(ctx, "Say hello", CompletionOptions{})
+	if err != nil || got != "Hello" {
+		t.Errorf("Complete() = %q, %v; want 'Hello', nil", got, err)
+	}
+
+	ch, err := p.CompleteStream(ctx, "Say hello", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	got, err = Collect(ch)
+	if err != nil || got != "Hello" {
+		t.Errorf("streamed = %q, %v; want 'Hello', nil", got, err)
+	}
+}
+
+func TestOllamaModelNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model 'missing' not found, try pulling it first"}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "missing", server.Client())
+	_, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected a 404 *APIError, got %v", err)
+	}
+}
+
+func TestOllamaHasModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"llama3:latest"},{"name":"qwen2.5-coder:7b"}]}`)
+	}))
+	defer server.Close()
+
+	p := NewOllamaProvider(server.URL, "llama3", server.Client())
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"llama3", true},
+		{"llama3:latest", true},
+		{"qwen2.5-coder:7b", true},
+		{"qwen2.5-coder", false},
+		{"mistral", false},
+	}
+	for _, tt := range tests {
+		got, err := p.HasModel(context.Background(), tt.model)
+		if err != nil {
+			t.Fatalf("HasModel(%q) error = %v", tt.model, err)
+		}
+		if got != tt.want {
+			t.Errorf("HasModel(%q): expected %v, got %v", tt.model, tt.want, got)
+		}
+	}
+}
+
+func TestOllamaPullModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		updates int
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			stream:  "{\"status\":\"pulling manifest\"}\n{\"status\":\"pulling abc\",\"completed\":50,\"total\":100}\n{\"status\":\"success\"}\n",
+			updates: 3,
+		},
+		{
+			name:    "error reported in stream",
+			stream:  "{\"status\":\"pulling manifest\"}\n{\"error\":\"file does not exist\"}\n",
+			updates: 1,
+			wantErr: true,
+		},
+		{
+			name:    "stream ends early",
+			stream:  "{\"status\":\"pulling manifest\"}\n",
+			updates: 1,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				if r.URL.Path != "/api/pull" || req["model"] != "llama3" {
+					t.Errorf("Unexpected pull request %s %v", r.URL.Path, req)
+				}
+				fmt.Fprint(w, tt.stream)
+			}))
+			defer server.Close()
+
+			p := NewOllamaProvider(server.URL, "llama3", server.Client())
+			var updates []PullProgress
+			err := p.PullModel(context.Background(), "llama3", func(u PullProgress) {
+				updates = append(updates, u)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PullModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(updates) != tt.updates {
+				t.Errorf("Expected %d progress updates, got %d", tt.updates, len(updates))
+			}
+			if tt.name == "success" && (updates[1].Completed != 50 || updates[1].Total != 100) {
+				t.Errorf("Expected 50 of 100 bytes, got %+v", updates[1])
+			}
+		})
+	}
+}
+
+func TestAsModelManager(t *testing.T) {
+	ollama := NewOllamaProvider("http://localhost:11434", "llama3", http.DefaultClient)
+	wrapped := NewCachingProvider(NewRetryingProvider(NewRateLimitedProvider(ollama, NewRateLimiter(60, 1)), RetryPolicy{}), "llama3", t.TempDir(), 0)
+	if m, ok := AsModelManager(wrapped); !ok || m != ollama {
+		t.Errorf("Expected the wrapped Ollama provider, got %v, %v", m, ok)
+	}
+
+	cloud := NewRetryingProvider(NewOpenAIProvider("key", "gpt-4", http.DefaultClient), RetryPolicy{})
+	if _, ok := AsModelManager(cloud); ok {
+		t.Error("Expected no ModelManager for a cloud provider")
+	}
+	if _, ok := AsModelLister(cloud); !ok {
+		t.Error("Expected the wrapped OpenAI provider to be a ModelLister")
+	}
+}
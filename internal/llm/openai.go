@@ -0,0 +1,267 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// openAIBaseURL is the base URL of the OpenAI API.
+	openAIBaseURL = "https://api.openai.com/v1"
+	// azureAPIVersion is the Azure OpenAI REST API version requested.
+	azureAPIVersion = "2024-10-21"
+)
+
+// OpenAIProvider talks to the OpenAI chat completions API, either directly,
+// through an OpenAI-compatible gateway, through an Azure OpenAI resource or
+// on a local LM Studio server.
+type OpenAIProvider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+
+	// Azure only: requests are routed through a deployment, versioned with
+	// the api-version query parameter, and authenticated with an api-key header.
+	deployment string
+	apiVersion string
+}
+
+// NewOpenAIProvider creates a provider for the OpenAI API.
+func NewOpenAIProvider(apiKey, model string, client *http.Client) *OpenAIProvider {
+	return &OpenAIProvider{
+		name:    "openai",
+		baseURL: openAIBaseURL,
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+// NewOpenAICompatibleProvider creates a provider for a gateway speaking the
+// OpenAI API, such as LiteLLM, OpenRouter or vLLM. baseURL is the URL the
+// API paths (/chat/completions, /models) are appended to, usually ending in
+// the API version (e.g., https://openrouter.ai/api/v1). Requests are
+// authenticated with apiKey as a bearer token, as with OpenAI.
+func NewOpenAICompatibleProvider(baseURL, apiKey, model string, client *http.Client) *OpenAIProvider {
+	p := NewOpenAIProvider(apiKey, model, client)
+	p.baseURL = strings.TrimSuffix(baseURL, "/")
+	return p
+}
+
+// NewAzureOpenAIProvider creates a provider for an Azure OpenAI resource.
+// endpoint is the resource URL (e.g., https://my-resource.openai.azure.com)
+// and deployment the name of the model deployment to use.
+func NewAzureOpenAIProvider(endpoint, deployment, apiKey, model string, client *http.Client) *OpenAIProvider {
+	return &OpenAIProvider{
+		name:       "azure",
+		baseURL:    strings.TrimSuffix(endpoint, "/") + "/openai",
+		apiKey:     apiKey,
+		model:      model,
+		client:     client,
+		deployment: deployment,
+		apiVersion: azureAPIVersion,
+	}
+}
+
+// NewLMStudioProvider creates a provider for the OpenAI-compatible API of a
+// local LM Studio server. endpoint is the server URL (e.g.,
+// http://localhost:1234), with or without the /v1 suffix. LM Studio requires
+// no API key unless authentication is enabled on the server, so apiKey may
+// be empty.
+func NewLMStudioProvider(endpoint, apiKey, model string, client *http.Client) *OpenAIProvider {
+	endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1")
+	return &OpenAIProvider{
+		name:    "lmstudio",
+		baseURL: endpoint + "/v1",
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+// Name implements Provider.
+func (p *OpenAIProvider) Name() string { return p.name }
+
+// BaseURL returns the URL the API paths are appended to.
+func (p *OpenAIProvider) BaseURL() string { return p.baseURL }
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Stream      bool            `json:"stream,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+}
+
+func (p *OpenAIProvider) request(prompt string, opts CompletionOptions, streaming bool) openAIRequest {
+	model := p.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	var messages []openAIMessage
+	if opts.System != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: opts.System})
+	}
+	return openAIRequest{
+		Model:       model,
+		Messages:    append(messages, openAIMessage{Role: "user", Content: prompt}),
+		Stream:      streaming,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		TopP:        opts.TopP,
+	}
+}
+
+func (p *OpenAIProvider) headers() map[string]string {
+	headers := map[string]string{}
+	switch {
+	case p.apiKey == "":
+	case p.apiVersion != "":
+		headers["api-key"] = p.apiKey
+	default:
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return headers
+}
+
+// url returns the URL of an API path. On Azure, chat completions go through
+// the deployment and every request carries the api-version query parameter.
+func (p *OpenAIProvider) url(path string) string {
+	if p.apiVersion == "" {
+		return p.baseURL + path
+	}
+	if path == "/chat/completions" {
+		path = "/deployments/" + url.PathEscape(p.deployment) + path
+	}
+	return p.baseURL + path + "?api-version=" + url.QueryEscape(p.apiVersion)
+}
+
+// Complete implements Provider.
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	resp, err := doJSON(ctx, p.client, p.name, http.MethodPost, p.url("/chat/completions"), p.headers(), p.request(prompt, opts, false))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := decodeJSON(p.name, resp, &result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("%s: response contained no choices", p.name)
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// CompleteStream implements Provider using the server-sent events stream.
+func (p *OpenAIProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	resp, err := doJSON(ctx, p.client, p.name, http.MethodPost, p.url("/chat/completions"), p.headers(), p.request(prompt, opts, true))
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(ctx, resp.Body, func(r io.Reader, emit emitFunc) error {
+		return readSSE(r, func(_, data string) (bool, error) {
+			if strings.TrimSpace(data) == "[DONE]" {
+				return true, nil
+			}
+			var event struct {
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+				Error *struct {
+					Message string `json:"message"`
+					Code    any    `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return true, fmt.Errorf("%s: failed to decode stream event: %w", p.name, err)
+			}
+			if event.Error != nil {
+				code, _ := event.Error.Code.(string)
+				return true, reportedError(p.name, code, event.Error.Message)
+			}
+			if len(event.Choices) > 0 && event.Choices[0].Delta.Content != "" {
+				if !emit(event.Choices[0].Delta.Content) {
+					return true, ctx.Err()
+				}
+			}
+			return false, nil
+		})
+	}), nil
+}
+
+// TestConnection implements Provider by listing the available models.
+func (p *OpenAIProvider) TestConnection(ctx context.Context) error {
+	resp, err := p.models(ctx)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListModels implements ModelLister. For LM Studio, these are the models
+// currently loaded on the server.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := p.models(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := decodeJSON(p.name, resp, &result); err != nil {
+		return nil, err
+	}
+	models := make([]string, len(result.Data))
+	for i, m := range result.Data {
+		models[i] = m.ID
+	}
+	return models, nil
+}
+
+// models requests the list of available models, explaining the likely cause
+// of a failure.
+func (p *OpenAIProvider) models(ctx context.Context) (*http.Response, error) {
+	resp, err := doJSON(ctx, p.client, p.name, http.MethodGet, p.url("/models"), p.headers(), nil)
+	if err != nil {
+		var apiErr *APIError
+		gateway := p.name == "openai" && p.baseURL != openAIBaseURL
+		switch {
+		case gateway && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			return nil, fmt.Errorf("%w (check that llm.endpoint is the base URL of an OpenAI-compatible API, usually ending in /v1)", err)
+		case p.name == "lmstudio" && !errors.As(err, &apiErr) && ctx.Err() == nil:
+			return nil, fmt.Errorf("%w (check that the LM Studio server is running at llm.endpoint)", err)
+		case gateway && !errors.As(err, &apiErr) && ctx.Err() == nil:
+			return nil, fmt.Errorf("%w (check that the gateway is reachable at llm.endpoint)", err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestOpenAI(t *testing.T, handler http.HandlerFunc) *OpenAIProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	p := NewOpenAIProvider("test-key", "gpt-4", server.Client())
+	p.baseURL = server.URL
+	return p
+}
+
+func TestOpenAIComplete(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Expected bearer auth, got %q", got)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Model != "gpt-4o" || req.Stream || req.Messages[0].Content != "Say hi" {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi!"}}]}`)
+	})
+
+	got, err := p.Complete(context.Background(), "Say hi", CompletionOptions{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "Hi!" {
+		t.Errorf("Expected 'Hi!', got %q", got)
+	}
+}
+
+func TestOpenAICompleteStream(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	ch, err := p.CompleteStream(context.Background(), "Say hello", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	got, err := Collect(ch)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if got != "Hello" {
+		t.Errorf("Expected 'Hello', got %q", got)
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"Incorrect API key provided"}}`)
+	})
+
+	_, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Incorrect API key provided" {
+		t.Errorf("Unexpected API error: %+v", apiErr)
+	}
+
+	if err := p.TestConnection(context.Background()); err == nil {
+		t.Error("TestConnection() expected an error")
+	}
+}
+
+func TestAzureOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("api-version"); got != azureAPIVersion {
+			t.Errorf("Expected api-version %s, got %q", azureAPIVersion, got)
+		}
+		if got := r.Header.Get("api-key"); got != "azure-key" {
+			t.Errorf("Expected api-key header, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Expected no bearer auth, got %q", got)
+		}
+		switch r.URL.Path {
+		case "/openai/deployments/my-gpt4/chat/completions":
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi from Azure"}}]}`)
+		case "/openai/models":
+			fmt.Fprint(w, `{"data":[]}`)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p := NewAzureOpenAIProvider(server.URL+"/", "my-gpt4", "azure-key", "gpt-4", server.Client())
+	if p.Name() != "azure" {
+		t.Errorf("Expected provider 'azure', got '%s'", p.Name())
+	}
+	got, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != "Hi from Azure" {
+		t.Errorf("Expected 'Hi from Azure', got %q", got)
+	}
+	if err := p.TestConnection(context.Background()); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+}
+
+func TestLMStudio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Expected no auth without an API key, got %q", got)
+		}
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi from LM Studio"}}]}`)
+		case "/v1/models":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-coder-7b-instruct","object":"model"},{"id":"llama-3.2-3b","object":"model"}]}`)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// The endpoint may be given with or without the /v1 suffix
+	for _, endpoint := range []string{server.URL, server.URL + "/v1/"} {
+		p := NewLMStudioProvider(endpoint, "", "llama-3.2-3b", server.Client())
+		if p.Name() != "lmstudio" {
+			t.Errorf("Expected provider 'lmstudio', got '%s'", p.Name())
+		}
+		got, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+		if err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if got != "Hi from LM Studio" {
+			t.Errorf("Expected 'Hi from LM Studio', got %q", got)
+		}
+		models, err := p.ListModels(context.Background())
+		if err != nil {
+			t.Fatalf("ListModels() error = %v", err)
+		}
+		if len(models) != 2 || models[0] != "qwen2.5-coder-7b-instruct" || models[1] != "llama-3.2-3b" {
+			t.Errorf("Unexpected models: %v", models)
+		}
+	}
+
+	// A server that is not running is reported with a hint
+	server.Close()
+	p := NewLMStudioProvider(server.URL, "", "llama-3.2-3b", http.DefaultClient)
+	if err := p.TestConnection(context.Background()); err == nil || !strings.Contains(err.Error(), "LM Studio server is running") {
+		t.Errorf("Expected a hint to start the server, got %v", err)
+	}
+}
+
+func TestOpenAICompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer gw-key" {
+			t.Errorf("Expected bearer auth, got %q", got)
+		}
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi!"}}]}`)
+	}))
+	defer server.Close()
+
+	p := NewOpenAICompatibleProvider(server.URL+"/v1/", "gw-key", "mistral", server.Client())
+	if p.Name() != "openai" || p.BaseURL() != server.URL+"/v1" {
+		t.Errorf("Unexpected name %q or base URL %q", p.Name(), p.BaseURL())
+	}
+	if got, err := p.Complete(context.Background(), "Say hi", CompletionOptions{}); err != nil || got != "Hi!" {
+		t.Errorf("Complete() = %q, %v", got, err)
+	}
+	if err := p.TestConnection(context.Background()); err == nil || !strings.Contains(err.Error(), "usually ending in /v1") {
+		t.Errorf("Expected a hint about the base URL for a 404, got %v", err)
+	}
+
+	wrapped := NewRetryingProvider(p, RetryPolicy{})
+	if got := BaseURL(wrapped); got != server.URL+"/v1" {
+		t.Errorf("BaseURL() through a decorator = %q", got)
+	}
+	if got := BaseURL(NewOpenAIProvider("k", "gpt-4", nil)); got != openAIBaseURL {
+		t.Errorf("BaseURL() = %q, want the OpenAI API", got)
+	}
+}
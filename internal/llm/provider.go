@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package llm provides a unified interface for interacting with the
+// supported Large Language Model providers.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Provider is the interface implemented by every LLM backend.
+type Provider interface {
+	// Name returns the provider name (e.g., "openai").
+	Name() string
+
+	// Complete sends prompt to the LLM and returns the full response text.
+	Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error)
+
+	// CompleteStream sends prompt to the LLM and returns a channel delivering
+	// the response text as it is generated. The channel is closed when the
+	// response is complete; a failure is delivered as a final Chunk with Err
+	// set. Canceling ctx aborts the request, closes the underlying HTTP
+	// response, and closes the channel.
+	CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error)
+
+	// TestConnection verifies that the provider is reachable and that the
+	// configured credentials are accepted.
+	TestConnection(ctx context.Context) error
+}
+
+// CompletionOptions holds per-request settings for a completion.
+// Temperature and TopP are pointers so that 0 can be requested explicitly.
+type CompletionOptions struct {
+	System      string   // Instructions on how to behave, sent apart from the prompt; empty sends none
+	Model       string   // Overrides the configured model when not empty
+	Temperature *float64 // Sampling temperature (0-2); nil uses the provider default
+	MaxTokens   int      // Maximum tokens to generate; 0 uses the provider default
+	TopP        *float64 // Nucleus sampling probability mass (0-1); nil uses the provider default
+}
+
+// Chunk is a piece of a streamed completion.
+type Chunk struct {
+	Text string // Generated text
+	Err  error  // Set on the final chunk if the stream failed
+}
+
+// Collect drains a stream returned by CompleteStream and returns the
+// concatenated text, or the first error delivered.
+func Collect(ch <-chan Chunk) (string, error) {
+	var sb strings.Builder
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return sb.String(), chunk.Err
+		}
+		sb.WriteString(chunk.Text)
+	}
+	return sb.String(), nil
+}
+
+// ErrEmptyResponse reports a completion that succeeded but holds no text,
+// which some models produce when overloaded or confused by a prompt.
+var ErrEmptyResponse = errors.New("the model returned an empty response")
+
+// APIError is returned when a provider's API responds with an error status,
+// or reports an error in an otherwise successful response (e.g., in the
+// middle of a stream).
+type APIError struct {
+	Provider   string        // Provider name
+	StatusCode int           // HTTP status code, or 0 for an error in a successful response
+	Message    string        // Error message reported by the API
+	RetryAfter time.Duration // Delay requested by the Retry-After header, if any
+	Kind       error         // Kind of the error (e.g., ErrAuth), or nil if unknown
+}
+
+// Error describes the error, with a hint at how to fix it if its kind has one.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+	if e.StatusCode == 0 {
+		msg = fmt.Sprintf("%s API error: %s", e.Provider, e.Message)
+	}
+	if hint, ok := kindHints[e.Kind]; ok {
+		if e.Kind == ErrAuth && e.Provider == "gemini" {
+			hint += " or CODEDECODER_GEMINI_API_KEY"
+		}
+		msg += " (" + hint + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the kind of the error, so errors.Is matches it.
+func (e *APIError) Unwrap() error { return e.Kind }
+
+// ConnectionError is returned when a request could not be sent to a
+// provider or its response did not arrive (e.g., the server is down or the
+// connection dropped).
+type ConnectionError struct {
+	Provider string // Provider name
+	Err      error  // The error the request failed with
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%s: request failed: %v", e.Provider, e.Err)
+}
+
+// Unwrap returns the error the request failed with.
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// BlockedError is returned when a provider's safety filters blocked the
+// prompt or the response, which otherwise shows up as empty output.
+type BlockedError struct {
+	Provider string // Provider name
+	Reason   string // Block reason reported by the provider (e.g., "SAFETY")
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s blocked the request (reason: %s); try rephrasing the prompt or excluding the offending files", e.Provider, e.Reason)
+}
+
+// IsProviderError reports whether err is a failure of the provider rather
+// than of the caller: the provider could not be reached, its API returned an
+// error, it blocked the request or returned nothing, the request timed out,
+// or it does not serve the model.
+func IsProviderError(err error) bool {
+	var (
+		connErr    *ConnectionError
+		apiErr     *APIError
+		blockedErr *BlockedError
+		timeoutErr *TimeoutError
+		modelErr   *ModelNotFoundError
+	)
+	return errors.As(err, &connErr) || errors.As(err, &apiErr) || errors.As(err, &blockedErr) ||
+		errors.As(err, &timeoutErr) || errors.As(err, &modelErr) || errors.Is(err, ErrEmptyResponse)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.LLMConfig
+		wantName string
+		wantErr  bool
+	}{
+		{name: "openai", cfg: config.LLMConfig{Provider: "openai", APIKey: "k"}, wantName: "openai"},
+		{name: "azure", cfg: config.LLMConfig{Provider: "azure", APIKey: "k", Endpoint: "https://r.openai.azure.com", Deployment: "d"}, wantName: "azure"},
+		{name: "azure without deployment", cfg: config.LLMConfig{Provider: "azure", APIKey: "k", Endpoint: "https://r.openai.azure.com"}, wantErr: true},
+		{name: "anthropic", cfg: config.LLMConfig{Provider: "anthropic", APIKey: "k"}, wantName: "anthropic"},
+		{name: "gemini", cfg: config.LLMConfig{Provider: "gemini", APIKey: "k"}, wantName: "gemini"},
+		{name: "ollama", cfg: config.LLMConfig{Provider: "ollama", Endpoint: "http://localhost:11434"}, wantName: "ollama"},
+		{name: "ollama without endpoint", cfg: config.LLMConfig{Provider: "ollama"}, wantErr: true},
+		{name: "empty provider", cfg: config.LLMConfig{}, wantErr: true},
+		{name: "unknown provider", cfg: config.LLMConfig{Provider: "nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProvider(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p.Name() != tt.wantName {
+				t.Errorf("Expected provider '%s', got '%s'", tt.wantName, p.Name())
+			}
+		})
+	}
+}
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\n\nevent: first\ndata: one\n\ndata: two\ndata: lines\n\nevent: last\ndata: three"
+
+	type event struct{ name, data string }
+	var got []event
+	err := readSSE(strings.NewReader(input), func(name, data string) (bool, error) {
+		got = append(got, event{name, data})
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE() error = %v", err)
+	}
+
+	want := []event{{"first", "one"}, {"", "two\nlines"}, {"last", "three"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readSSE() events = %v, want %v", got, want)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	ch := make(chan Chunk, 3)
+	ch <- Chunk{Text: "Hello, "}
+	ch <- Chunk{Text: "world"}
+	close(ch)
+	if got, err := Collect(ch); err != nil || got != "Hello, world" {
+		t.Errorf("Collect() = %q, %v; want %q, nil", got, err, "Hello, world")
+	}
+
+	failed := make(chan Chunk, 2)
+	failed <- Chunk{Text: "partial"}
+	failed <- Chunk{Err: errors.New("boom")}
+	close(failed)
+	if got, err := Collect(failed); err == nil || got != "partial" {
+		t.Errorf("Collect() = %q, %v; want partial text and an error", got, err)
+	}
+}
+
+func TestCompleteStreamCancel(t *testing.T) {
+	// The server sends one event, then holds the connection open until the client goes away
+	clientGone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"first\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(clientGone)
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider("key", "gpt-4", server.Client())
+	p.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := p.CompleteStream(ctx, "hi", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	if chunk := <-ch; chunk.Text != "first" {
+		t.Fatalf("Expected first chunk 'first', got %+v", chunk)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			// Drain anything left; the channel must close promptly
+			for range ch {
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the stream channel to close after cancellation")
+	}
+
+	select {
+	case <-clientGone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the HTTP response to be closed after cancellation")
+	}
+}
+
+func TestCompletionOptionsMapping(t *testing.T) {
+	temperature, topP := 0.0, 0.9
+	opts := CompletionOptions{Temperature: &temperature, MaxTokens: 256, TopP: &topP}
+
+	tests := []struct {
+		name    string
+		request any
+		want    string
+	}{
+		{
+			name:    "openai",
+			request: NewOpenAIProvider("k", "gpt-4", nil).request("p", opts, false),
+			want:    `"temperature":0,"max_tokens":256,"top_p":0.9`,
+		},
+		{
+			name:    "anthropic",
+			request: NewAnthropicProvider("k", "claude", nil).request("p", opts, false),
+			want:    `"max_tokens":256,`,
+		},
+		{
+			name:    "ollama",
+			request: NewOllamaProvider("http://localhost:11434", "llama3", nil).request("p", opts, false),
+			want:    `"options":{"temperature":0,"num_predict":256,"top_p":0.9}`,
+		},
+		{
+			name:    "gemini",
+			request: NewGeminiProvider("k", "gemini-2.0-flash", nil).request("p", opts),
+			want:    `"generationConfig":{"temperature":0,"maxOutputTokens":256,"topP":0.9}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.request)
+			if err != nil {
+				t.Fatalf("Failed to encode request: %v", err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("Expected request to contain %s, got %s", tt.want, data)
+			}
+		})
+	}
+
+	// Unset options leave the provider defaults alone
+	data, _ := json.Marshal(NewOpenAIProvider("k", "gpt-4", nil).request("p", CompletionOptions{}, false))
+	if strings.Contains(string(data), "temperature") || strings.Contains(string(data), "max_tokens") {
+		t.Errorf("Expected no sampling parameters, got %s", data)
+	}
+	anthropic := NewAnthropicProvider("k", "claude", nil).request("p", CompletionOptions{}, false)
+	if anthropic.MaxTokens != anthropicMaxTokens {
+		t.Errorf("Expected the default max_tokens %d, got %d", anthropicMaxTokens, anthropic.MaxTokens)
+	}
+}
+
+func TestSystemInstruction(t *testing.T) {
+	opts := CompletionOptions{System: "Be terse."}
+
+	tests := []struct {
+		name    string
+		request any
+		want    string
+	}{
+		{
+			name:    "openai",
+			request: NewOpenAIProvider("k", "gpt-4", nil).request("p", opts, false),
+			want:    `"messages":[{"role":"system","content":"Be terse."},{"role":"user","content":"p"}]`,
+		},
+		{
+			name:    "anthropic",
+			request: NewAnthropicProvider("k", "claude", nil).request("p", opts, false),
+			want:    `"system":"Be terse.","messages":[{"role":"user","content":"p"}]`,
+		},
+		{
+			name:    "ollama",
+			request: NewOllamaProvider("http://localhost:11434", "llama3", nil).request("p", opts, false),
+			want:    `"prompt":"p","system":"Be terse."`,
+		},
+		{
+			name:    "gemini",
+			request: NewGeminiProvider("k", "gemini-2.0-flash", nil).request("p", opts),
+			want:    `"systemInstruction":{"parts":[{"text":"Be terse."}]},"contents":[{"role":"user","parts":[{"text":"p"}]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.request)
+			if err != nil {
+				t.Fatalf("Failed to encode request: %v", err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("Expected request to contain %s, got %s", tt.want, data)
+			}
+		})
+	}
+
+	// Without a system instruction, the prompt is the only message
+	data, _ := json.Marshal(NewAnthropicProvider("k", "claude", nil).request("p", CompletionOptions{}, false))
+	if strings.Contains(string(data), "system") {
+		t.Errorf("Expected no system instruction, got %s", data)
+	}
+	if openAI := NewOpenAIProvider("k", "gpt-4", nil).request("p", CompletionOptions{}, false); len(openAI.Messages) != 1 {
+		t.Errorf("Expected only the user message, got %+v", openAI.Messages)
+	}
+}
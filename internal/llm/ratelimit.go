@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how many requests are sent per
+// minute. It is safe for concurrent use, so a single limiter enforces the
+// rate across all the workers sharing a provider.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Time to refill one token
+	burst    float64       // Bucket capacity
+	tokens   float64       // Available tokens; negative when requests are waiting
+	last     time.Time     // When tokens was last refilled
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per minute,
+// of which up to burst may be sent at once. The bucket starts full.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{
+		interval: time.Minute / time.Duration(max(perMinute, 1)),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent, taking a token from the bucket.
+// It returns the context's error, without taking a token, if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Reserve a token now, so that concurrent waiters queue up in order
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// Give the reserved token back to the requests still waiting
+		l.mu.Lock()
+		l.tokens = min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimitedProvider is a Provider decorator that waits for its limiter
+// before each request.
+//
+// NewProvider places it beneath the RetryingProvider, so every attempt sent
+// to the server takes exactly one token: waiting for the limiter never counts
+// as a failed attempt, and a retried request is counted again because the
+// provider counts it again too. Responses served from the CachingProvider
+// never reach the limiter.
+type RateLimitedProvider struct {
+	Provider
+	limiter *RateLimiter
+}
+
+// NewRateLimitedProvider wraps p so that its requests wait for limiter.
+func NewRateLimitedProvider(p Provider, limiter *RateLimiter) *RateLimitedProvider {
+	return &RateLimitedProvider{Provider: p, limiter: limiter}
+}
+
+// Unwrap returns the decorated provider.
+func (r *RateLimitedProvider) Unwrap() Provider { return r.Provider }
+
+// Complete implements Provider.
+func (r *RateLimitedProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	return r.Provider.Complete(ctx, prompt, opts)
+}
+
+// CompleteStream implements Provider.
+func (r *RateLimitedProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.Provider.CompleteStream(ctx, prompt, opts)
+}
+
+// TestConnection implements Provider.
+func (r *RateLimitedProvider) TestConnection(ctx context.Context) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return r.Provider.TestConnection(ctx)
+}
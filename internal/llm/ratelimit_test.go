@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterShared(t *testing.T) {
+	// 6000 requests per minute refill a token every 10ms
+	limiter := NewRateLimiter(6000, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Two requests use the burst, the other four wait for a token each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 6 requests to take at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Wait() to return when the context expired, took %s", elapsed)
+	}
+}
+
+func TestRateLimitedProvider(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewRateLimitedProvider(inner, NewRateLimiter(6000, 1))
+
+	start := time.Now()
+	for range 3 {
+		if _, err := p.Complete(context.Background(), "hi", CompletionOptions{}); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", inner.calls)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected 3 requests to take at least 20ms, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Complete(ctx, "hi", CompletionOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected a canceled request not to reach the provider, got %d calls", inner.calls)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"net/http"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+// ProviderInfo describes a provider that NewProvider can create.
+type ProviderInfo struct {
+	Name        string   // Value of llm.provider selecting it
+	Description string   // Short human-readable description
+	Local       bool     // Runs models on the user's machine instead of a cloud API
+	Fields      []string // Config fields of the llm section it requires
+
+	// create returns the provider for cfg, with the API key resolved from
+	// the config or the environment, sending its requests with client.
+	create func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider
+}
+
+// registry lists the supported providers. Adding a provider here makes it
+// available to NewProvider and to the providers list command.
+var registry = []ProviderInfo{
+	{
+		Name:        "openai",
+		Description: "OpenAI API, or an OpenAI-compatible gateway at llm.endpoint",
+		Fields:      []string{"api_key", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			if cfg.Endpoint != "" {
+				return NewOpenAICompatibleProvider(cfg.Endpoint, apiKey, cfg.Model, client)
+			}
+			return NewOpenAIProvider(apiKey, cfg.Model, client)
+		},
+	},
+	{
+		Name:        "azure",
+		Description: "OpenAI models deployed on an Azure OpenAI resource",
+		Fields:      []string{"api_key", "endpoint", "deployment", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			return NewAzureOpenAIProvider(cfg.Endpoint, cfg.Deployment, apiKey, cfg.Model, client)
+		},
+	},
+	{
+		Name:        "anthropic",
+		Description: "Anthropic API",
+		Fields:      []string{"api_key", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			return NewAnthropicProvider(apiKey, cfg.Model, client)
+		},
+	},
+	{
+		Name:        "gemini",
+		Description: "Google Gemini API",
+		Fields:      []string{"api_key", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			return NewGeminiProvider(apiKey, cfg.Model, client)
+		},
+	},
+	{
+		Name:        "ollama",
+		Description: "Local Ollama server",
+		Local:       true,
+		Fields:      []string{"endpoint", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			return NewOllamaProvider(cfg.Endpoint, cfg.Model, client)
+		},
+	},
+	{
+		Name:        "lmstudio",
+		Description: "Local LM Studio server (OpenAI-compatible API)",
+		Local:       true,
+		Fields:      []string{"endpoint", "model"},
+		create: func(cfg config.LLMConfig, apiKey string, client *http.Client) Provider {
+			return NewLMStudioProvider(cfg.Endpoint, apiKey, cfg.Model, client)
+		},
+	},
+}
+
+// Providers returns the supported providers, in the order they are documented.
+func Providers() []ProviderInfo {
+	return append([]ProviderInfo(nil), registry...)
+}
+
+// LookupProvider returns the supported provider with the given name.
+func LookupProvider(name string) (ProviderInfo, bool) {
+	for _, info := range registry {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return ProviderInfo{}, false
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+func TestProviders(t *testing.T) {
+	providers := Providers()
+	if len(providers) == 0 {
+		t.Fatal("Expected registered providers")
+	}
+
+	for _, info := range providers {
+		// Every registered provider can be created from its required fields
+		cfg := config.LLMConfig{Provider: info.Name, APIKey: "k", Endpoint: "http://localhost:1234", Deployment: "d", Model: "m"}
+		p, err := NewProvider(cfg)
+		if err != nil {
+			t.Errorf("NewProvider(%s) error = %v", info.Name, err)
+			continue
+		}
+		if p.Name() != info.Name {
+			t.Errorf("Expected provider '%s', got '%s'", info.Name, p.Name())
+		}
+		if _, local := LookupPricing(info.Name, "m"); info.Local && !local {
+			t.Errorf("Expected local provider '%s' to be free", info.Name)
+		}
+		if len(info.Fields) == 0 || info.Description == "" {
+			t.Errorf("Expected required fields and a description for '%s'", info.Name)
+		}
+	}
+
+	// Callers cannot modify the registry through the returned slice
+	providers[0].Name = "changed"
+	if Providers()[0].Name == "changed" {
+		t.Error("Expected Providers() to return a copy")
+	}
+
+	if _, ok := LookupProvider("ollama"); !ok {
+		t.Error("Expected to find provider 'ollama'")
+	}
+	if _, ok := LookupProvider("nope"); ok {
+		t.Error("Expected no provider 'nope'")
+	}
+}
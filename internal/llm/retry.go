@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxBackoff caps the exponential backoff between retries. A Retry-After
+// header sent by the server is honored even when it is longer.
+const maxBackoff = 60 * time.Second
+
+// RetryPolicy controls how transient provider errors are retried.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // Delay before the first retry, doubled on each retry
+}
+
+// RetryingProvider is a Provider decorator that retries requests failing
+// with transient errors (rate limits, server errors, timeouts) using
+// exponential backoff with jitter.
+type RetryingProvider struct {
+	Provider
+	policy RetryPolicy
+}
+
+// NewRetryingProvider wraps p so that its requests are retried according to policy.
+func NewRetryingProvider(p Provider, policy RetryPolicy) *RetryingProvider {
+	return &RetryingProvider{Provider: p, policy: policy}
+}
+
+// Unwrap returns the decorated provider.
+func (r *RetryingProvider) Unwrap() Provider { return r.Provider }
+
+// Complete implements Provider.
+func (r *RetryingProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	return retry(ctx, r.policy, r.Name(), func() (string, error) {
+		return r.Provider.Complete(ctx, prompt, opts)
+	})
+}
+
+// CompleteStream implements Provider. Only establishing the stream is
+// retried; once text has been delivered a failure ends the stream.
+func (r *RetryingProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	return retry(ctx, r.policy, r.Name(), func() (<-chan Chunk, error) {
+		return r.Provider.CompleteStream(ctx, prompt, opts)
+	})
+}
+
+// TestConnection implements Provider.
+func (r *RetryingProvider) TestConnection(ctx context.Context) error {
+	_, err := retry(ctx, r.policy, r.Name(), func() (struct{}, error) {
+		return struct{}{}, r.Provider.TestConnection(ctx)
+	})
+	return err
+}
+
+// retry calls op until it succeeds, fails with a non-retryable error, or the
+// retries allowed by policy are exhausted.
+func retry[T any](ctx context.Context, policy RetryPolicy, provider string, op func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := op()
+		if err == nil || attempt >= policy.MaxRetries || ctx.Err() != nil || !Retryable(err) {
+			return result, err
+		}
+
+		delay := backoff(policy, attempt, err)
+		slog.Warn("Retrying LLM request", "provider", provider, "attempt", attempt+1, "max_retries", policy.MaxRetries, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// Retryable reports whether err is a transient failure worth retrying: rate
+// limiting (ErrRateLimit, 429), server errors (500, 502, 503), a request that
+// timed out (ErrTimeout), or a network timeout. Other API errors, such as
+// ErrAuth or ErrContextLengthExceeded, are permanent.
+func Retryable(err error) bool {
+	if errors.Is(err, ErrRateLimit) || errors.Is(err, ErrTimeout) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsFatal reports whether err means that no further request to the provider
+// can succeed either: the context is done, the Budget is spent, the provider
+// rejected the key (ErrAuth) or does not serve the model (ErrModelNotFound),
+// or the API answered 401, 403 or 404. Callers working through many items
+// use it to tell a failure worth skipping from one worth stopping for. A
+// request that timed out is not fatal, although it may wrap a context error.
+func IsFatal(err error) bool {
+	if errors.Is(err, ErrTimeout) {
+		return false
+	}
+	var budgetErr *BudgetExceededError
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &budgetErr) ||
+		errors.Is(err, ErrAuth) || errors.Is(err, ErrModelNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before retry number attempt (counting from 0).
+// A Retry-After value reported by the server takes precedence; otherwise the
+// base delay is doubled per attempt, capped, and jittered so concurrent
+// clients don't retry in lockstep. A zero base delay retries at once.
+func backoff(policy RetryPolicy, attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	if policy.BaseDelay <= 0 {
+		return 0 // Retry at once
+	}
+	// Cap before shifting, so that late attempts cannot overflow
+	delay := maxBackoff
+	if policy.BaseDelay <= maxBackoff>>attempt {
+		delay = policy.BaseDelay << attempt
+	}
+	// Full delay for the first half, random jitter for the second
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryingProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // Status served per attempt; 200 succeeds
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "succeeds after rate limit", statuses: []int{429, 503, 200}, wantCalls: 3},
+		{name: "server errors exhaust retries", statuses: []int{500, 502, 500, 503}, wantCalls: 4, wantErr: true},
+		{name: "unauthorized fails fast", statuses: []int{401, 200}, wantCalls: 1, wantErr: true},
+		{name: "bad request fails fast", statuses: []int{400, 200}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					fmt.Fprint(w, `{"error":{"message":"try again"}}`)
+					return
+				}
+				fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+			})
+			r := NewRetryingProvider(p, RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond})
+
+			got, err := r.Complete(context.Background(), "hi", CompletionOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "ok" {
+				t.Errorf("Expected 'ok', got %q", got)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limited", err: &APIError{StatusCode: 429}, want: true},
+		{name: "bad gateway", err: fmt.Errorf("wrapped: %w", &APIError{StatusCode: 502}), want: true},
+		{name: "unauthorized", err: &APIError{StatusCode: 401}, want: false},
+		{name: "not found", err: &APIError{StatusCode: 404}, want: false},
+		{name: "timeout", err: fmt.Errorf("request failed: %w", timeoutError{}), want: true},
+		{name: "other error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Retryable(tt.err); got != tt.want {
+				t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond}
+	for attempt := 0; attempt < 3; attempt++ {
+		full := policy.BaseDelay << attempt
+		got := backoff(policy, attempt, &APIError{StatusCode: 503})
+		if got < full/2 || got > full {
+			t.Errorf("Attempt %d: expected a delay in [%s, %s], got %s", attempt, full/2, full, got)
+		}
+	}
+
+	for _, attempt := range []int{40, 63, 100} {
+		if got := backoff(policy, attempt, &APIError{StatusCode: 503}); got < maxBackoff/2 || got > maxBackoff {
+			t.Errorf("Attempt %d: expected the delay to be capped at %s, got %s", attempt, maxBackoff, got)
+		}
+	}
+
+	zero := RetryPolicy{MaxRetries: 5}
+	for _, attempt := range []int{0, 3, 100} {
+		if got := backoff(zero, attempt, &APIError{StatusCode: 503}); got != 0 {
+			t.Errorf("Attempt %d: expected a zero base delay to retry at once, got %s", attempt, got)
+		}
+	}
+
+	retryAfter := &APIError{StatusCode: 429, RetryAfter: 7 * time.Second}
+	if got := backoff(policy, 0, retryAfter); got != 7*time.Second {
+		t.Errorf("Expected Retry-After to be honored, got %s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "12", want: 12 * time.Second},
+		{value: "-1", want: 0},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// maxStreamLine is the longest single line accepted in a streamed response.
+const maxStreamLine = 1024 * 1024
+
+// emitFunc delivers a piece of generated text to the consumer of a stream.
+// It returns false if the stream was canceled and reading should stop.
+type emitFunc func(text string) bool
+
+// stream runs read in a goroutine, forwarding the text it emits as chunks on
+// the returned channel. The channel is closed and body is closed when read
+// returns; an error from read is delivered as a final chunk. If ctx is
+// canceled, emit returns false and no further chunks are delivered.
+func stream(ctx context.Context, body io.ReadCloser, read func(r io.Reader, emit emitFunc) error) <-chan Chunk {
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		emit := func(text string) bool {
+			select {
+			case ch <- Chunk{Text: text}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		err := read(body, emit)
+		if ctx.Err() != nil {
+			// The consumer canceled; it may no longer be receiving
+			return
+		}
+		if err != nil {
+			select {
+			case ch <- Chunk{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return ch
+}
+
+// readSSE parses a server-sent events stream, calling fn with the name and
+// data of each event. Reading stops when fn returns done or an error.
+func readSSE(r io.Reader, fn func(event, data string) (done bool, err error)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+
+	var event string
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the pending event
+			if len(data) > 0 {
+				done, err := fn(event, strings.Join(data, "\n"))
+				if err != nil || done {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment line (often used as a keep-alive)
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		_, err := fn(event, strings.Join(data, "\n"))
+		return err
+	}
+	return nil
+}
+
+// readLines calls fn with each non-empty line of a newline-delimited stream
+// (such as Ollama's NDJSON). Reading stops when fn returns done or an error.
+func readLines(r io.Reader, fn func(line []byte) (done bool, err error)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		done, err := fn(line)
+		if err != nil || done {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a request to a provider did not complete
+// within llm.request_timeout. Unlike the caller's own deadline, it is a
+// transient failure: the request is worth retrying, and the requests after it
+// may still succeed.
+type TimeoutError struct {
+	Provider string        // Provider name
+	Timeout  time.Duration // The timeout that expired
+	Err      error         // The error the request failed with, if any
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s request timed out after %s (raise llm.request_timeout for slow models)", e.Provider, e.Timeout)
+}
+
+// Unwrap returns the error the request failed with.
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrTimeout.
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+// TimeoutProvider is a Provider decorator giving each request a deadline,
+// so that a server that stops responding fails the request rather than
+// stalling the run. The deadline covers the whole request, including the
+// time a streamed response takes to arrive.
+//
+// NewProvider places it beneath the RateLimitedProvider, so waiting for the
+// limiter does not count against the timeout, and each retry gets a deadline
+// of its own.
+type TimeoutProvider struct {
+	Provider
+	timeout time.Duration
+}
+
+// NewTimeoutProvider wraps p so that its requests fail with a *TimeoutError
+// after timeout.
+func NewTimeoutProvider(p Provider, timeout time.Duration) *TimeoutProvider {
+	return &TimeoutProvider{Provider: p, timeout: timeout}
+}
+
+// Unwrap returns the decorated provider.
+func (t *TimeoutProvider) Unwrap() Provider { return t.Provider }
+
+// Complete implements Provider.
+func (t *TimeoutProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	text, err := t.Provider.Complete(callCtx, prompt, opts)
+	return text, t.timedOut(ctx, callCtx, err)
+}
+
+// CompleteStream implements Provider.
+func (t *TimeoutProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	in, err := t.Provider.CompleteStream(callCtx, prompt, opts)
+	if err != nil {
+		cancel()
+		return nil, t.timedOut(ctx, callCtx, err)
+	}
+
+	// A stream whose context expires just ends, so the timeout is reported
+	// here as the final chunk
+	out := make(chan Chunk)
+	go func() {
+		defer close(out)
+		defer cancel()
+		failed := false
+		for chunk := range in {
+			if chunk.Err != nil {
+				chunk.Err, failed = t.timedOut(ctx, callCtx, chunk.Err), true
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !failed && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			select {
+			case out <- Chunk{Err: &TimeoutError{Provider: t.Name(), Timeout: t.timeout}}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out, nil
+}
+
+// TestConnection implements Provider.
+func (t *TimeoutProvider) TestConnection(ctx context.Context) error {
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.timedOut(ctx, callCtx, t.Provider.TestConnection(callCtx))
+}
+
+// timedOut returns err, or a *TimeoutError wrapping it if the request failed
+// because its deadline expired while the caller's context ctx is still live.
+func (t *TimeoutProvider) timedOut(ctx, callCtx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return err
+	}
+	return &TimeoutError{Provider: t.Name(), Timeout: t.timeout, Err: err}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/config"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	// The server starts streamed responses, then stalls until the client goes away
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			fmt.Fprintln(w, `{"response":"partial","done":false}`)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(config.LLMConfig{Provider: "ollama", Endpoint: server.URL, Model: "llama3", RequestTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "complete", call: func() error {
+			_, err := p.Complete(context.Background(), "hi", CompletionOptions{})
+			return err
+		}},
+		{name: "stream", call: func() error {
+			ch, err := p.CompleteStream(context.Background(), "hi", CompletionOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = Collect(ch)
+			return err
+		}},
+		{name: "test connection", call: func() error { return p.TestConnection(context.Background()) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := tt.call()
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected the request to fail at its deadline, took %s", elapsed)
+			}
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Expected a *TimeoutError, got %T: %v", err, err)
+			}
+			if timeoutErr.Provider != "ollama" || timeoutErr.Timeout != 50*time.Millisecond {
+				t.Errorf("Expected the provider and timeout in the error, got %+v", timeoutErr)
+			}
+			if !Retryable(err) || IsFatal(err) {
+				t.Errorf("Expected a timeout to be retryable and not fatal, got Retryable %v, IsFatal %v", Retryable(err), IsFatal(err))
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutCallerDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// The caller's own deadline expires first: that is not a request timeout
+	p := NewTimeoutProvider(NewOllamaProvider(server.URL, "llama3", server.Client()), time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.Complete(ctx, "hi", CompletionOptions{})
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("Expected the caller's deadline to be reported as such, got %v", err)
+	}
+	if !IsFatal(err) {
+		t.Errorf("Expected the caller's deadline to be fatal, got %v", err)
+	}
+}
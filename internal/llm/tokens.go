@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Tokenizer counts the tokens text occupies for a model. Token counts are
+// what the context window guard, the cost estimates and the run summary are
+// based on.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+// CountTokens implements Tokenizer.
+func (f TokenizerFunc) CountTokens(text string) int { return f(text) }
+
+// HeuristicTokenizer is the Tokenizer of models whose tokenizer is not
+// known: one token per four characters (see HeuristicTokens).
+var HeuristicTokenizer Tokenizer = heuristicTokenizer{}
+
+type heuristicTokenizer struct{}
+
+// CountTokens implements Tokenizer.
+func (heuristicTokenizer) CountTokens(text string) int { return HeuristicTokens(text) }
+
+// Encodings of OpenAI models, as named by tiktoken.
+const (
+	encodingO200K  = "o200k_base"
+	encodingCL100K = "cl100k_base"
+)
+
+// gptEncodings maps prefixes of OpenAI model names to their encodings. The
+// longest matching prefix wins, so that gpt-4o is not taken for gpt-4.
+var gptEncodings = map[string]string{
+	"gpt-3.5":  encodingCL100K,
+	"gpt-35":   encodingCL100K, // As Azure OpenAI names gpt-3.5
+	"gpt-4":    encodingCL100K,
+	"gpt-4o":   encodingO200K,
+	"gpt-4.1":  encodingO200K,
+	"gpt-4.5":  encodingO200K,
+	"gpt-":     encodingO200K, // GPT models newer than those above
+	"chatgpt-": encodingO200K,
+	"o1":       encodingO200K,
+	"o3":       encodingO200K,
+	"o4":       encodingO200K,
+}
+
+var (
+	tokenizersMu sync.Mutex
+	tokenizers   = map[string]*tiktokenTokenizer{}
+)
+
+// TokenizerFor returns the Tokenizer of model. OpenAI GPT and o-series
+// models use their BPE encoding through tiktoken, with the encodings
+// embedded in the binary so that nothing is downloaded. Other models, whose
+// tokenizers are not available, fall back to HeuristicTokenizer.
+func TokenizerFor(model string) Tokenizer {
+	encoding := gptEncoding(model)
+	if encoding == "" {
+		return HeuristicTokenizer
+	}
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	t, ok := tokenizers[encoding]
+	if !ok {
+		t = &tiktokenTokenizer{encoding: encoding}
+		tokenizers[encoding] = t
+	}
+	return t
+}
+
+// CountTokens counts how many tokens text occupies for model, with the
+// Tokenizer TokenizerFor returns.
+func CountTokens(model, text string) int {
+	return TokenizerFor(model).CountTokens(text)
+}
+
+// HeuristicTokens estimates a token count as one token per four characters.
+func HeuristicTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// gptEncoding returns the encoding of model, or "" when it is not an
+// OpenAI model.
+func gptEncoding(model string) string {
+	model = strings.ToLower(model)
+	encoding, longest := "", 0
+	for prefix, e := range gptEncodings {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			encoding, longest = e, len(prefix)
+		}
+	}
+	return encoding
+}
+
+// tiktokenTokenizer counts tokens with a tiktoken encoding, loaded on first
+// use since building it takes a noticeable moment. Should the encoding fail
+// to load, tokens are approximated by countGPTTokens instead.
+type tiktokenTokenizer struct {
+	encoding string
+
+	once sync.Once
+	enc  *tiktoken.Tiktoken
+}
+
+// CountTokens implements Tokenizer.
+func (t *tiktokenTokenizer) CountTokens(text string) int {
+	t.once.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+		enc, err := tiktoken.GetEncoding(t.encoding)
+		if err != nil {
+			slog.Warn("Failed to load tokenizer; approximating token counts", "encoding", t.encoding, "error", err)
+			return
+		}
+		t.enc = enc
+	})
+	if t.enc == nil {
+		return countGPTTokens(text)
+	}
+	// Special tokens such as <|endoftext|> are counted as ordinary text
+	return len(t.enc.EncodeOrdinary(text))
+}
+
+// gptPieceRe approximates the pre-tokenization pattern of OpenAI's
+// cl100k/o200k BPE encodings: contractions, words with an optional leading
+// non-letter, numbers of up to three digits, punctuation runs, and whitespace.
+var gptPieceRe = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// countGPTTokens splits text into pre-tokenization pieces and counts one
+// token per piece, plus one for every further eight bytes of long pieces
+// (which BPE splits into several tokens).
+func countGPTTokens(text string) int {
+	count := 0
+	for _, piece := range gptPieceRe.FindAllString(text, -1) {
+		count += 1 + (len(piece)-1)/8
+	}
+	return count
+}
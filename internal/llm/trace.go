@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/logging"
+)
+
+// TracingProvider is a Provider decorator that writes each request and the
+// raw response text (or the error) to a file of its own in a directory, to
+// debug what a model was asked and what it answered. The files are named
+// after the time the request was sent, e.g., 20250601T153045.123Z-0001.txt.
+//
+// The prompts hold the analyzed source code, so the files may contain
+// sensitive data; the secrets given to NewTracingProvider (such as the API
+// key), and anything logging.Redact takes for a secret, are redacted from
+// them.
+type TracingProvider struct {
+	Provider
+
+	model   string   // Configured model, used when the options don't override it
+	dir     string   // Directory the trace files are written to
+	secrets []string // Values replaced with [REDACTED]
+	seq     atomic.Int64
+}
+
+// NewTracingProvider wraps p, writing traces to dir, which is created if
+// needed. Empty secrets are ignored.
+func NewTracingProvider(p Provider, model, dir string, secrets ...string) (*TracingProvider, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create debug directory: %w", err)
+	}
+	t := &TracingProvider{Provider: p, model: model, dir: dir}
+	for _, secret := range secrets {
+		if secret != "" {
+			t.secrets = append(t.secrets, secret)
+		}
+	}
+	return t, nil
+}
+
+// Unwrap returns the decorated provider.
+func (t *TracingProvider) Unwrap() Provider { return t.Provider }
+
+// Complete implements Provider.
+func (t *TracingProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	start := time.Now()
+	response, err := t.Provider.Complete(ctx, prompt, opts)
+	t.write(start, prompt, opts, response, err)
+	return response, err
+}
+
+// CompleteStream implements Provider. The trace is written once the stream
+// ends, with the text received until then.
+func (t *TracingProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	start := time.Now()
+	upstream, err := t.Provider.CompleteStream(ctx, prompt, opts)
+	if err != nil {
+		t.write(start, prompt, opts, "", err)
+		return nil, err
+	}
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		var sb strings.Builder
+		var streamErr error
+		for chunk := range upstream {
+			sb.WriteString(chunk.Text)
+			if chunk.Err != nil {
+				streamErr = chunk.Err
+			}
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				// Keep draining so the upstream goroutine can exit
+			}
+		}
+		if streamErr == nil {
+			streamErr = ctx.Err()
+		}
+		t.write(start, prompt, opts, sb.String(), streamErr)
+	}()
+	return ch, nil
+}
+
+// write records a request and its outcome. Failing to write a trace does
+// not fail the request, so it is logged rather than returned.
+func (t *TracingProvider) write(start time.Time, prompt string, opts CompletionOptions, response string, err error) {
+	model := t.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Provider: %s\nModel: %s\nTime: %s\nDuration: %s\n", t.Provider.Name(), model,
+		start.UTC().Format(time.RFC3339Nano), time.Since(start).Round(time.Millisecond))
+	if opts.Temperature != nil {
+		fmt.Fprintf(&sb, "Temperature: %g\n", *opts.Temperature)
+	}
+	if opts.MaxTokens > 0 {
+		fmt.Fprintf(&sb, "Max tokens: %d\n", opts.MaxTokens)
+	}
+	if opts.TopP != nil {
+		fmt.Fprintf(&sb, "Top P: %g\n", *opts.TopP)
+	}
+	if opts.System != "" {
+		fmt.Fprintf(&sb, "\n=== SYSTEM ===\n%s\n", opts.System)
+	}
+	fmt.Fprintf(&sb, "\n=== PROMPT ===\n%s\n\n=== RESPONSE ===\n%s\n", prompt, response)
+	if err != nil {
+		fmt.Fprintf(&sb, "\n=== ERROR ===\n%v\n", err)
+	}
+
+	text := sb.String()
+	for _, secret := range t.secrets {
+		text = strings.ReplaceAll(text, secret, logging.Redacted)
+	}
+	text = logging.Redact(text)
+	name := fmt.Sprintf("%s-%04d.txt", start.UTC().Format("20060102T150405.000Z"), t.seq.Add(1))
+	path := filepath.Join(t.dir, name)
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		slog.Warn("Failed to write LLM trace", "path", path, "error", err)
+		return
+	}
+	slog.Debug("LLM request traced", "path", path)
+}
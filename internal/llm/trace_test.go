@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTracingProvider(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Messages[len(req.Messages)-1].Content == "Fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad prompt"}}`)
+			return
+		}
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Streamed \"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"answer\"}}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Raw {not json"}}]}`)
+	})
+	dir := filepath.Join(t.TempDir(), "debug")
+	traced, err := NewTracingProvider(p, "gpt-4", dir, "test-key", "")
+	if err != nil {
+		t.Fatalf("NewTracingProvider() error = %v", err)
+	}
+
+	if _, err := traced.Complete(context.Background(), "Summarize main.go (key: test-key)", CompletionOptions{System: "Be brief"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	ch, err := traced.CompleteStream(context.Background(), "Write a chapter", CompletionOptions{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	if _, err := Collect(ch); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if _, err := traced.Complete(context.Background(), "Fail", CompletionOptions{}); err == nil {
+		t.Fatal("Complete() expected an error")
+	}
+
+	// The stream's trace is written before its channel is closed
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Expected 3 trace files, got %v (%v)", entries, err)
+	}
+
+	traces := make([]string, len(entries))
+	for i, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read trace: %v", err)
+		}
+		traces[i] = string(data)
+	}
+	for _, s := range []string{"Provider: openai", "Model: gpt-4\n", "=== SYSTEM ===\nBe brief", "Summarize main.go (key: [REDACTED])", "=== RESPONSE ===\nRaw {not json"} {
+		if !strings.Contains(traces[0], s) {
+			t.Errorf("Expected the first trace to contain %q, got:\n%s", s, traces[0])
+		}
+	}
+	if strings.Contains(traces[0], "test-key") {
+		t.Errorf("Expected the API key to be redacted, got:\n%s", traces[0])
+	}
+	for _, s := range []string{"Model: gpt-4o", "=== PROMPT ===\nWrite a chapter", "=== RESPONSE ===\nStreamed answer"} {
+		if !strings.Contains(traces[1], s) {
+			t.Errorf("Expected the stream trace to contain %q, got:\n%s", s, traces[1])
+		}
+	}
+	if !strings.Contains(traces[2], "=== ERROR ===\nopenai API error (status 400): bad prompt") {
+		t.Errorf("Expected the error to be traced, got:\n%s", traces[2])
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// elisionNote replaces the middle of the text TrimToTokens cuts, with the
+// number of tokens left out.
+const elisionNote = "[... %d tokens elided ...]"
+
+// TrimToTokens returns text cut to at most limit tokens, as tokenizer counts
+// them (HeuristicTokenizer if nil). It keeps the head and the tail of text,
+// which tell the most about a file (its imports and declarations, and how it
+// ends), and replaces the middle with a line noting how many tokens were
+// left out. The cuts fall at line boundaries unless that would lose more
+// than half of the head or tail. Text that fits is returned unchanged, and a
+// limit too small for the note gets the head alone.
+func TrimToTokens(text string, limit int, tokenizer Tokenizer) string {
+	if tokenizer == nil {
+		tokenizer = HeuristicTokenizer
+	}
+	count := tokenizer.CountTokens
+	total := count(text)
+	switch {
+	case total <= limit:
+		return text
+	case limit <= 0:
+		return ""
+	}
+	room := limit - count(fmt.Sprintf(elisionNote, total))
+	if room <= 0 {
+		return text[:prefixLen(text, limit, count)]
+	}
+	for {
+		// Tokenizers need not count the parts of a text as the whole, so
+		// the room is narrowed until the result fits
+		head := text[:prefixLen(text, room-room/2, count)]
+		rest := text[len(head):]
+		tail := rest[suffixStart(rest, room/2, count):]
+		var sb strings.Builder
+		sb.WriteString(head)
+		if head != "" && !strings.HasSuffix(head, "\n") {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, elisionNote+"\n", total-count(head)-count(tail))
+		sb.WriteString(tail)
+		if trimmed := sb.String(); room == 0 || count(trimmed) <= limit {
+			return trimmed
+		}
+		room -= max(1, room/10)
+	}
+}
+
+// prefixLen returns the length of the longest head of text within n tokens,
+// as estimated by count, cut after its last line if that keeps at least half
+// of it.
+func prefixLen(text string, n int, count func(string) int) int {
+	bounds := runeBounds(text)
+	i := sort.Search(len(bounds), func(i int) bool { return count(text[:bounds[i]]) > n })
+	cut := bounds[max(i-1, 0)]
+	if line := strings.LastIndexByte(text[:cut], '\n'); line >= cut/2 {
+		cut = line + 1
+	}
+	return cut
+}
+
+// suffixStart returns where the longest tail of text within n tokens, as
+// estimated by count, starts, moved to the start of its first whole line if
+// that keeps at least half of it.
+func suffixStart(text string, n int, count func(string) int) int {
+	bounds := runeBounds(text)
+	start := bounds[sort.Search(len(bounds), func(i int) bool { return count(text[bounds[i]:]) <= n })]
+	if start > 0 && text[start-1] != '\n' {
+		if line := strings.IndexByte(text[start:], '\n'); line >= 0 && line < (len(text)-start)/2 {
+			start += line + 1
+		}
+	}
+	return start
+}
+
+// runeBounds returns the byte offsets of the runes of text, followed by its
+// length: the places text can be cut at.
+func runeBounds(text string) []int {
+	bounds := make([]int, 0, len(text)+1)
+	for i := range text {
+		bounds = append(bounds, i)
+	}
+	return append(bounds, len(text))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrimToTokens(t *testing.T) {
+	// One token per word, so that the boundaries are easy to tell
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	var lines strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&lines, "w%d\n", i)
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "fits", text: "a b c", limit: 3, want: "a b c"},
+		{name: "lines", text: lines.String(), limit: 10, want: "w1\nw2\nw3\n[... 15 tokens elided ...]\nw19\nw20\n"},
+		{name: "words", text: "a b c d e f g h i j k l m n o p", limit: 9, want: "a b \n[... 12 tokens elided ...]\n o p"},
+		{name: "no room for the note", text: lines.String(), limit: 4, want: "w1\nw2\nw3\nw4\n"},
+		{name: "no room at all", text: "a b", limit: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TrimToTokens(tt.text, tt.limit, words)
+			if got != tt.want {
+				t.Errorf("TrimToTokens(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+			if n := words.CountTokens(got); n > tt.limit {
+				t.Errorf("Expected at most %d tokens, got %d", tt.limit, n)
+			}
+		})
+	}
+}
+
+func TestTrimToTokens_Runes(t *testing.T) {
+	// Cuts never split a multibyte character
+	text := strings.Repeat("héllo wörld ", 200)
+	got := TrimToTokens(text, 50, HeuristicTokenizer)
+	if !strings.Contains(got, "tokens elided") || !strings.HasPrefix(got, "héllo") {
+		t.Errorf("Expected the head, the note and the tail, got %q", got)
+	}
+	if n := HeuristicTokens(got); n > 50 {
+		t.Errorf("Expected at most 50 tokens, got %d", n)
+	}
+	for _, r := range got {
+		if r == '�' {
+			t.Fatalf("Expected whole characters only, got %q", got)
+		}
+	}
+}
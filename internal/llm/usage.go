@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Usage counts LLM requests and the tokens they used. The tokens are
+// estimated from the text sent and received (see Tokenizer), since not
+// every provider reports them.
+type Usage struct {
+	Calls        int `json:"calls"`         // Requests sent to the provider
+	InputTokens  int `json:"input_tokens"`  // Estimated tokens of the prompts and system instructions
+	OutputTokens int `json:"output_tokens"` // Estimated tokens of the responses
+}
+
+// Meter accumulates the Usage of the requests sent through the
+// MeteringProviders sharing it. It is safe for concurrent use.
+type Meter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Usage returns the usage counted so far.
+func (m *Meter) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+func (m *Meter) add(input, output int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Calls++
+	m.usage.InputTokens += input
+	m.usage.OutputTokens += output
+}
+
+// MeteringProvider is a Provider decorator that counts each request and
+// its estimated tokens in a Meter, failed requests included, since they may
+// be billed too.
+type MeteringProvider struct {
+	Provider
+
+	meter     *Meter
+	tokenizer Tokenizer
+}
+
+// NewMeteringProvider wraps p, counting its requests in meter with tokens
+// counted by tokenizer.
+func NewMeteringProvider(p Provider, meter *Meter, tokenizer Tokenizer) *MeteringProvider {
+	return &MeteringProvider{Provider: p, meter: meter, tokenizer: tokenizer}
+}
+
+// Unwrap returns the decorated provider.
+func (m *MeteringProvider) Unwrap() Provider { return m.Provider }
+
+// Complete implements Provider.
+func (m *MeteringProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (string, error) {
+	response, err := m.Provider.Complete(ctx, prompt, opts)
+	m.meter.add(m.input(prompt, opts), m.tokenizer.CountTokens(response))
+	return response, err
+}
+
+// CompleteStream implements Provider. The request is counted once the
+// stream ends, with the text received until then.
+func (m *MeteringProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions) (<-chan Chunk, error) {
+	upstream, err := m.Provider.CompleteStream(ctx, prompt, opts)
+	if err != nil {
+		m.meter.add(m.input(prompt, opts), 0)
+		return nil, err
+	}
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		var sb strings.Builder
+		for chunk := range upstream {
+			sb.WriteString(chunk.Text)
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				// Keep draining so the upstream goroutine can exit
+			}
+		}
+		m.meter.add(m.input(prompt, opts), m.tokenizer.CountTokens(sb.String()))
+	}()
+	return ch, nil
+}
+
+// input estimates the tokens sent with a request.
+func (m *MeteringProvider) input(prompt string, opts CompletionOptions) int {
+	return m.tokenizer.CountTokens(opts.System) + m.tokenizer.CountTokens(prompt)
+}
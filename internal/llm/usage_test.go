@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMeteringProvider(t *testing.T) {
+	p := newTestOpenAI(t, func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Messages[len(req.Messages)-1].Content == "Fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad prompt"}}`)
+			return
+		}
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"three streamed \"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"words\"}}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"two words"}}]}`)
+	})
+
+	// Two providers share the meter, as when analyze and generate run in one command
+	meter := &Meter{}
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	first := NewMeteringProvider(p, meter, words)
+	second := NewMeteringProvider(NewRetryingProvider(p, RetryPolicy{}), meter, words)
+
+	if _, err := first.Complete(context.Background(), "Summarize main.go", CompletionOptions{System: "Be brief"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	ch, err := second.CompleteStream(context.Background(), "Write a chapter", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("CompleteStream() error = %v", err)
+	}
+	if _, err := Collect(ch); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if _, err := first.Complete(context.Background(), "Fail", CompletionOptions{}); err == nil {
+		t.Fatal("Complete() expected an error")
+	}
+
+	// Inputs: 2 + 2 system words, 3 words, 1 word; outputs: 2 words, 3 words
+	want := Usage{Calls: 3, InputTokens: 8, OutputTokens: 5}
+	if got := meter.Usage(); got != want {
+		t.Errorf("Usage() = %+v, want %+v", got, want)
+	}
+	if cost := (Pricing{InputPerMillion: 1_000_000, OutputPerMillion: 2_000_000}).Cost(want); cost != 18 {
+		t.Errorf("Cost() = %g, want 18", cost)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package logging configures the application's leveled logger, built on log/slog.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Levels lists the accepted values for the --log-level flag.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// level is shared by the default logger so it can be changed after Setup
+// (e.g., when a command's --verbose flag is set).
+var level slog.LevelVar
+
+// ParseLevel converts a level name (debug, info, warn, error) into a slog.Level.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level '%s'. Must be one of %s", name, strings.Join(Levels, ", "))
+	}
+}
+
+// Setup installs a text logger writing records at or above the named level
+// to w as the slog default logger. The messages and attributes it writes
+// pass through Redact, so secrets never reach the log.
+func Setup(w io.Writer, levelName string) error {
+	lvl, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	level.Set(lvl)
+
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: &level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// Timestamps are noise for an interactive CLI
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return redactAttr(a)
+		},
+	})
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the level of the logger installed by Setup.
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+// Level returns the current level of the logger installed by Setup.
+func Level() slog.Level {
+	return level.Level()
+}
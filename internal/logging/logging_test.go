@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{name: "debug", want: slog.LevelDebug},
+		{name: "INFO", want: slog.LevelInfo},
+		{name: "", want: slog.LevelInfo},
+		{name: "warn", want: slog.LevelWarn},
+		{name: "error", want: slog.LevelError},
+		{name: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, "warn"); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	slog.Info("hidden")
+	slog.Warn("shown", "key", "value")
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("Expected info record to be filtered at warn level, got %q", out)
+	}
+	if !strings.Contains(out, "level=WARN msg=shown key=value") {
+		t.Errorf("Expected warn record in output, got %q", out)
+	}
+	if strings.Contains(out, "time=") {
+		t.Errorf("Expected no timestamp in output, got %q", out)
+	}
+
+	// Raising verbosity after setup takes effect immediately
+	SetLevel(slog.LevelDebug)
+	slog.Debug("debugging")
+	if !strings.Contains(buf.String(), "msg=debugging") {
+		t.Errorf("Expected debug record after SetLevel, got %q", buf.String())
+	}
+
+	if err := Setup(&buf, "loud"); err == nil {
+		t.Error("Setup() expected error for an invalid level")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the secrets Redact finds.
+const Redacted = "[REDACTED]"
+
+// minSecretLen is the length below which a value given to AddSecret is
+// ignored, since replacing it everywhere would mangle unrelated text.
+const minSecretLen = 6
+
+// secretPatterns match secrets by their shape, whatever their value. The
+// first group of a pattern, if any, is kept, so that the redacted text still
+// tells what the secret was.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\bBearer\s+)[A-Za-z0-9\-._~+/]+=*`),                                    // Authorization headers
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{16,}`),                                                  // OpenAI and Anthropic keys
+	regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{20,}|\bgithub_pat_[A-Za-z0-9_]{20,}`), // GitHub tokens
+	regexp.MustCompile(`\bAIza[0-9A-Za-z\-_]{35}`),                                                  // Google API keys
+	regexp.MustCompile(`(?i)([?&](?:key|api_key|access_token)=)[^&\s"']+`),                          // Keys in URLs
+	regexp.MustCompile(`(?i)((?:api[_-]?key|\btoken)["']?\s*[:=]\s*["']?)[^\s"',}]{6,}`),            // Settings such as api_key: ...
+}
+
+// secrets are the values registered with AddSecret.
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// AddSecret registers values, such as the configured API key and GitHub
+// token, to be redacted wherever they appear. Values too short to be
+// secrets, including empty ones, are ignored.
+func AddSecret(values ...string) {
+	secrets.Lock()
+	defer secrets.Unlock()
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) >= minSecretLen && !slices.Contains(secrets.values, value) {
+			secrets.values = append(secrets.values, value)
+		}
+	}
+}
+
+// Redact returns s with the secrets it holds replaced with Redacted: the
+// values registered with AddSecret, and anything shaped like a secret (bearer
+// tokens, OpenAI, Anthropic and Google API keys, GitHub tokens, keys in URLs
+// and api_key settings). It is the safety net of everything logged or
+// printed, so it errs on the side of redacting too much.
+func Redact(s string) string {
+	secrets.RLock()
+	for _, value := range secrets.values {
+		s = strings.ReplaceAll(s, value, Redacted)
+	}
+	secrets.RUnlock()
+	for _, pattern := range secretPatterns {
+		if pattern.NumSubexp() > 0 {
+			s = pattern.ReplaceAllString(s, "${1}"+Redacted)
+		} else {
+			s = pattern.ReplaceAllString(s, Redacted)
+		}
+	}
+	return s
+}
+
+// redactAttr redacts the secrets of the value of a, including those of the
+// errors and other values logged, which are formatted first.
+func redactAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindAny:
+		text := fmt.Sprint(a.Value.Any())
+		if redacted := Redact(text); redacted != text {
+			a.Value = slog.StringValue(redacted)
+		}
+	}
+	return a
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package progress reports the progress of long-running operations, such as
+// analyzing every file of a codebase, to a terminal or to the log.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reporter receives progress updates from a long-running operation.
+// Implementations must be safe for concurrent use.
+type Reporter interface {
+	// Update reports that done of total items are complete and that item
+	// is now in the given phase (e.g., "extracting").
+	Update(phase string, done, total int, item string)
+
+	// Finish reports that the operation is complete.
+	Finish()
+}
+
+// Nop is a Reporter that discards all updates.
+type Nop struct{}
+
+// Update implements Reporter.
+func (Nop) Update(phase string, done, total int, item string) {}
+
+// Finish implements Reporter.
+func (Nop) Finish() {}
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// barWidth is the number of cells in the progress bar.
+const barWidth = 30
+
+// Bar is a Reporter that draws a progress bar on a single terminal line,
+// redrawn in place on every update.
+type Bar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	drawn bool
+}
+
+// NewBar creates a progress bar drawn to w, which should be a terminal.
+func NewBar(w io.Writer) *Bar {
+	return &Bar{w: w}
+}
+
+// Update implements Reporter.
+func (b *Bar) Update(phase string, done, total int, item string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	filled := 0
+	if total > 0 {
+		filled = min(done*barWidth/total, barWidth)
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	// Return to the start of the line and clear it before redrawing
+	fmt.Fprintf(b.w, "\r\033[K[%s] %d/%d %s %s", bar, done, total, phase, item)
+	b.drawn = true
+}
+
+// Finish implements Reporter, ending the bar's line.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.drawn {
+		fmt.Fprintln(b.w)
+		b.drawn = false
+	}
+}
+
+// Logger is a Reporter for non-interactive output: it logs a progress line
+// at most once per interval, plus the first and last update.
+type Logger struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+	done     int
+	total    int
+	now      func() time.Time
+}
+
+// NewLogger creates a Reporter that logs progress at most once per interval.
+func NewLogger(interval time.Duration) *Logger {
+	return &Logger{interval: interval, now: time.Now}
+}
+
+// Update implements Reporter.
+func (l *Logger) Update(phase string, done, total int, item string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.done, l.total = done, total
+	now := l.now()
+	if !l.last.IsZero() && now.Sub(l.last) < l.interval {
+		return
+	}
+	l.last = now
+	slog.Info("Progress", "phase", phase, "done", done, "total", total, "item", item)
+}
+
+// Finish implements Reporter.
+func (l *Logger) Finish() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		slog.Info("Progress", "phase", "done", "done", l.done, "total", l.total)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package progress
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBar(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBar(&buf)
+
+	bar.Update("extracting", 0, 4, "a.go")
+	bar.Update("extracting", 2, 4, "c.go")
+	bar.Finish()
+	bar.Finish() // A second Finish must not print another newline
+
+	lines := strings.Split(buf.String(), "\r\033[K")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 redraws, got %q", buf.String())
+	}
+	if want := "[" + strings.Repeat(" ", barWidth) + "] 0/4 extracting a.go"; lines[1] != want {
+		t.Errorf("Expected %q, got %q", want, lines[1])
+	}
+	half := strings.Repeat("=", barWidth/2) + strings.Repeat(" ", barWidth-barWidth/2)
+	if want := "[" + half + "] 2/4 extracting c.go\n"; lines[2] != want {
+		t.Errorf("Expected %q, got %q", want, lines[2])
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLogger(10 * time.Second)
+	l.now = func() time.Time { return now }
+
+	l.Update("extracting", 0, 3, "a.go") // Logged: first update
+	now = now.Add(time.Second)
+	l.Update("extracting", 1, 3, "b.go") // Throttled
+	now = now.Add(10 * time.Second)
+	l.Update("extracting", 2, 3, "c.go") // Logged: interval elapsed
+	l.Finish()
+
+	out := buf.String()
+	if got := strings.Count(out, "msg=Progress"); got != 3 {
+		t.Errorf("Expected 3 progress lines, got %d:\n%s", got, out)
+	}
+	if strings.Contains(out, "b.go") || !strings.Contains(out, "item=c.go") || !strings.Contains(out, "phase=done done=2 total=3") {
+		t.Errorf("Unexpected progress log:\n%s", out)
+	}
+}
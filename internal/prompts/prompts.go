@@ -0,0 +1,264 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package prompts renders the prompts sent to the LLM from text/template
+// templates. Built-in defaults are embedded in the binary, and each can be
+// overridden by a file of the same name in a user-supplied directory.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Template file names, looked up in the override directory and the defaults.
+const (
+	SystemTemplate        = "system.tmpl"        // Tells the LLM how to behave; sent apart from every prompt
+	ExtractTemplate       = "extract.tmpl"       // Extracts the summary and abstractions of a file
+	AbstractionsTemplate  = "abstractions.tmpl"  // Consolidates the abstractions of all files into a ranked list
+	RelationshipsTemplate = "relationships.tmpl" // Finds how the core abstractions depend on each other
+	ChapterTemplate       = "chapter.tmpl"       // Writes the tutorial chapter about an abstraction
+	GlossaryTemplate      = "glossary.tmpl"      // Lists the key terms of the chapters with their definitions
+)
+
+// Audiences a tutorial can be written for, which the chapter template tailors
+// its instructions to (see ChapterData).
+const (
+	AudienceBeginner    = "beginner"    // New to the codebase and its domain: concepts and analogies
+	AudienceDeveloper   = "developer"   // Using the project: its APIs and how to use them
+	AudienceContributor = "contributor" // Changing the project: its internals and extension points
+)
+
+// Audiences lists the audiences, the default first.
+var Audiences = []string{AudienceDeveloper, AudienceBeginner, AudienceContributor}
+
+//go:embed templates/*.tmpl
+var defaultsFS embed.FS
+
+// funcs are the functions available to prompt templates, besides the
+// text/template builtins.
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// ExtractData is the data the extraction template is executed with.
+type ExtractData struct {
+	Project         string // Project name
+	Path            string // Slash-separated path of the file
+	Language        string // Language of the file; empty if unknown
+	Content         string // Content of the file
+	MaxAbstractions int    // Maximum number of abstractions to list
+
+	// Part and Parts number the part of the file that Content holds when the
+	// file is too large for the model's context window; Parts is 0 or 1 when
+	// Content is the whole file.
+	Part, Parts int
+}
+
+// AbstractionsData is the data the abstractions template is executed with.
+type AbstractionsData struct {
+	Project         string               // Project name
+	Files           []FileSummary        // Every analyzed file
+	Candidates      []AbstractionSummary // Abstractions found in the individual files
+	Docs            []DocExcerpt         // The project's own documentation, most general first; may be empty
+	MaxAbstractions int                  // Maximum number of abstractions to list
+}
+
+// DocExcerpt is the start of one of the project's documents (e.g., its README).
+type DocExcerpt struct {
+	Path    string
+	Content string // Text of the document, possibly cut short
+}
+
+// AbstractionSummary is an abstraction and the files implementing it.
+type AbstractionSummary struct {
+	Name        string
+	Description string
+	Files       []string // Paths of the files implementing it
+}
+
+// RelationshipsData is the data the relationships template is executed with.
+type RelationshipsData struct {
+	Project      string               // Project name
+	Abstractions []AbstractionSummary // The core abstractions of the codebase
+}
+
+// ChapterData is the data the chapter template is executed with.
+type ChapterData struct {
+	Project     string        // Project name
+	Name        string        // Name of the abstraction the chapter covers
+	Description string        // Description of the abstraction
+	Files       []FileSummary // Files implementing the abstraction
+	Others      []string      // Names of the project's other abstractions
+	Audience    string        // Who the tutorial is for; one of Audiences
+	Language    string        // Language to write the chapter in (e.g., "English")
+	Words       int           // Approximate length of the chapter in words; 0 leaves it to the model
+}
+
+// GlossaryData is the data the glossary template is executed with.
+type GlossaryData struct {
+	Project  string            // Project name
+	Chapters []GlossaryChapter // The chapters of the tutorial
+	Audience string            // Who the tutorial is for; one of Audiences
+	Language string            // Language to write the definitions in (e.g., "English")
+	MaxTerms int               // Maximum number of terms to list; 0 leaves it to the model
+}
+
+// GlossaryChapter is a chapter the glossary draws its terms from.
+type GlossaryChapter struct {
+	Index       int    // 1-based position of the chapter in the tutorial
+	Title       string // Chapter title
+	Description string // Description of the abstraction the chapter covers
+	Excerpt     string // Start of the chapter's text; may be empty
+}
+
+// FileSummary is a file implementing an abstraction.
+type FileSummary struct {
+	Path    string
+	Summary string // What the file does; may be empty
+}
+
+// Set holds the parsed prompt templates.
+type Set struct {
+	system        string // Rendered once, since it takes no data
+	extract       *template.Template
+	abstractions  *template.Template
+	relationships *template.Template
+	chapter       *template.Template
+	glossary      *template.Template
+}
+
+// sample data used to check templates when they are loaded, so that a
+// reference to an unknown field fails up front rather than mid-run.
+var (
+	sampleExtract       = ExtractData{Project: "p", Path: "main.go", Language: "Go", Content: "package main", MaxAbstractions: 1}
+	sampleAbstractions  = AbstractionsData{Project: "p", Files: []FileSummary{{Path: "a.go", Summary: "s"}}, Candidates: []AbstractionSummary{{Name: "A", Description: "d", Files: []string{"a.go"}}}, Docs: []DocExcerpt{{Path: "README.md", Content: "# p"}}, MaxAbstractions: 1}
+	sampleRelationships = RelationshipsData{Project: "p", Abstractions: []AbstractionSummary{{Name: "A", Description: "d", Files: []string{"a.go"}}, {Name: "B", Description: "d", Files: []string{"b.go"}}}}
+	sampleChapter       = ChapterData{Project: "p", Name: "A", Description: "d", Files: []FileSummary{{Path: "a.go", Summary: "s"}}, Others: []string{"B"}, Audience: AudienceDeveloper, Language: "English", Words: 1500}
+	sampleGlossary      = GlossaryData{Project: "p", Chapters: []GlossaryChapter{{Index: 1, Title: "A", Description: "d", Excerpt: "# A"}}, Audience: AudienceDeveloper, Language: "English", MaxTerms: 1}
+)
+
+// Default returns the built-in prompt templates.
+func Default() *Set {
+	s, err := Load("")
+	if err != nil {
+		panic(err) // The embedded templates are checked by the tests
+	}
+	return s
+}
+
+// Load parses the prompt templates, taking each from dir when it contains a
+// file of that name and from the built-in defaults otherwise. An empty dir
+// uses only the defaults. Templates that fail to parse or to execute with
+// sample data are reported with their file name.
+func Load(dir string) (*Set, error) {
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("prompts directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("prompts directory %s is not a directory", dir)
+		}
+	}
+
+	s := &Set{}
+	system, err := load(dir, SystemTemplate, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	if s.system, err = execute(system, struct{}{}); err != nil {
+		return nil, err
+	}
+	if s.extract, err = load(dir, ExtractTemplate, sampleExtract); err != nil {
+		return nil, err
+	}
+	if s.abstractions, err = load(dir, AbstractionsTemplate, sampleAbstractions); err != nil {
+		return nil, err
+	}
+	if s.relationships, err = load(dir, RelationshipsTemplate, sampleRelationships); err != nil {
+		return nil, err
+	}
+	if s.chapter, err = load(dir, ChapterTemplate, sampleChapter); err != nil {
+		return nil, err
+	}
+	if s.glossary, err = load(dir, GlossaryTemplate, sampleGlossary); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load parses the template name from dir, falling back to the default, and
+// checks it by executing it with sample.
+func load(dir, name string, sample any) (*template.Template, error) {
+	path := "templates/" + name
+	text, err := defaultsFS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		override := filepath.Join(dir, name)
+		data, err := os.ReadFile(override)
+		switch {
+		case err == nil:
+			path, text = override, data
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+	}
+	if err := tmpl.Execute(new(bytes.Buffer), sample); err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// System returns the system instruction, which tells the LLM how to behave
+// in every request (see llm.CompletionOptions).
+func (s *Set) System() string {
+	return s.system
+}
+
+// Extract renders the prompt extracting knowledge from a file.
+func (s *Set) Extract(data ExtractData) (string, error) {
+	return execute(s.extract, data)
+}
+
+// Abstractions renders the prompt consolidating the abstractions of a codebase.
+func (s *Set) Abstractions(data AbstractionsData) (string, error) {
+	return execute(s.abstractions, data)
+}
+
+// Relationships renders the prompt finding how the abstractions of a
+// codebase depend on each other.
+func (s *Set) Relationships(data RelationshipsData) (string, error) {
+	return execute(s.relationships, data)
+}
+
+// Chapter renders the prompt writing a tutorial chapter.
+func (s *Set) Chapter(data ChapterData) (string, error) {
+	return execute(s.chapter, data)
+}
+
+// Glossary renders the prompt listing the key terms of a tutorial.
+func (s *Set) Glossary(data GlossaryData) (string, error) {
+	return execute(s.glossary, data)
+}
+
+func execute(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	s := Default()
+
+	got, err := s.Extract(ExtractData{Project: "demo", Path: "main.go", Language: "Go", Content: "package main", MaxAbstractions: 3})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	for _, want := range []string{`project "demo"`, "File: main.go\nLanguage: Go\n", "<file>\npackage main\n</file>", "at most 3 abstractions"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected extraction prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got, err = s.Extract(ExtractData{Project: "demo", Path: "big.go", Content: "package big", Part: 2, Parts: 3})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if want := "File: big.go (part 2 of 3;"; !strings.Contains(got, want) {
+		t.Errorf("Expected extraction prompt to contain %q, got:\n%s", want, got)
+	}
+
+	got, err = s.Abstractions(AbstractionsData{
+		Project:         "demo",
+		Files:           []FileSummary{{Path: "config.go", Summary: "Defines the config."}, {Path: "load.go"}},
+		Candidates:      []AbstractionSummary{{Name: "Config", Description: "Settings", Files: []string{"config.go", "load.go"}}},
+		MaxAbstractions: 5,
+	})
+	if err != nil {
+		t.Fatalf("Abstractions() error = %v", err)
+	}
+	for _, want := range []string{"- config.go: Defines the config.\n- load.go\n", "- Config: Settings (files: config.go, load.go)", "at most 5 abstractions"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected abstractions prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got, err = s.Relationships(RelationshipsData{
+		Project:      "demo",
+		Abstractions: []AbstractionSummary{{Name: "Config", Description: "Settings", Files: []string{"config.go"}}, {Name: "CLI", Description: "Flags", Files: []string{"main.go"}}},
+	})
+	if err != nil {
+		t.Fatalf("Relationships() error = %v", err)
+	}
+	for _, want := range []string{"- Config: Settings (files: config.go)\n- CLI: Flags (files: main.go)\n", `"from": "<name`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected relationships prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got, err = s.Chapter(ChapterData{
+		Project:     "demo",
+		Name:        "Config",
+		Description: "Settings",
+		Files:       []FileSummary{{Path: "config.go", Summary: "Defines the config."}, {Path: "load.go"}},
+		Others:      []string{"CLI", "Loader"},
+	})
+	if err != nil {
+		t.Fatalf("Chapter() error = %v", err)
+	}
+	for _, want := range []string{`abstraction "Config": Settings`, "- config.go: Defines the config.\n- load.go\n", "Other abstractions in the project: CLI, Loader"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected chapter prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got, err = s.Glossary(GlossaryData{
+		Project:  "demo",
+		Chapters: []GlossaryChapter{{Index: 1, Title: "Config", Description: "Settings", Excerpt: "# Config\n\nA profile is a named LLM setup."}, {Index: 2, Title: "CLI", Description: "Flags"}},
+		Audience: AudienceBeginner,
+		Language: "English",
+		MaxTerms: 20,
+	})
+	if err != nil {
+		t.Fatalf("Glossary() error = %v", err)
+	}
+	for _, want := range []string{"Chapter 1: Config\nAbstraction: Settings\nText:\n# Config\n\nA profile is a named LLM setup.\n", "Chapter 2: CLI\nAbstraction: Flags\n\n", "at most 20 terms", "in plain words", `"chapters": [`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected glossary prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ChapterTemplate), []byte("Write about {{.Name}} in {{.Project}}."), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SystemTemplate), []byte("  Be brief.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := s.Chapter(ChapterData{Project: "demo", Name: "Config", Audience: AudienceBeginner}); got != "Write about Config in demo." {
+		t.Errorf("Expected the overridden chapter prompt, got %q", got)
+	}
+	// The extraction template was not overridden, so the default is used
+	if got, _ := s.Extract(ExtractData{Project: "demo", Path: "a.go"}); !strings.Contains(got, "File: a.go") {
+		t.Errorf("Expected the default extraction prompt, got %q", got)
+	}
+	if got := s.System(); got != "Be brief." {
+		t.Errorf("Expected the overridden system instruction, got %q", got)
+	}
+	if got := Default().System(); !strings.Contains(got, "technical writer") {
+		t.Errorf("Expected the default system instruction, got %q", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{name: "syntax error", template: "{{if .Name}}unterminated", wantErr: ChapterTemplate},
+		{name: "unknown field", template: "{{.Nonexistent}}", wantErr: "Nonexistent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ChapterTemplate), []byte(tt.template), 0644); err != nil {
+				t.Fatalf("Failed to write template: %v", err)
+			}
+			_, err := Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), filepath.Join(dir, ChapterTemplate)) {
+				t.Errorf("Expected an error naming %s and %q, got %v", filepath.Join(dir, ChapterTemplate), tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing prompts directory")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unlinkableRe matches the inline text a cross-link must not be added to:
+// code spans, Markdown links and images, HTML links with their text, other
+// HTML tags, and bare URLs.
+var unlinkableRe = regexp.MustCompile("`+[^`]*`+|!?\\[[^\\]]*\\]\\([^)]*\\)|(?is)<a\\b[^>]*>.*?</a>|<[^>]*>|https?://\\S+")
+
+// crossLink links, in the content of each chapter of links, the first
+// mention of the abstraction of every other chapter to that chapter's file,
+// as a Markdown link that each format then points at the chapter as it does
+// the links the LLM wrote: the HTML page, or an anchor of a single document.
+// Mentions are matched as whole words, with their case, longest names first
+// so that "Config Loader" wins over "Config". Code blocks, headings, code
+// spans and existing links are left alone, and so are the chapters that
+// already link to the chapter mentioned.
+func crossLink(links []chapterLink) {
+	targets := slices.Clone(links)
+	slices.SortStableFunc(targets, func(a, b chapterLink) int {
+		return cmp.Compare(len(b.Abstraction), len(a.Abstraction))
+	})
+	for i := range links {
+		content := links[i].Content
+		for _, target := range targets {
+			if target.Index == links[i].Index || target.Abstraction == "" || strings.ContainsAny(target.File, " \t()") ||
+				strings.Contains(content, "]("+target.File) {
+				continue
+			}
+			content = linkFirstMention(content, target.Abstraction, target.File)
+		}
+		links[i].Content = content
+	}
+}
+
+// linkFirstMention turns the first mention of name in content that is a
+// whole word outside of the text that must not be linked into a Markdown
+// link to file.
+func linkFirstMention(content, name, file string) string {
+	protected := unlinkableRanges(content)
+	for from := 0; ; {
+		j := strings.Index(content[from:], name)
+		if j < 0 {
+			return content
+		}
+		start, end := from+j, from+j+len(name)
+		if wordBoundary(content, start, end) && !overlaps(protected, start, end) {
+			return content[:start] + "[" + name + "](" + file + ")" + content[end:]
+		}
+		from = start + 1
+	}
+}
+
+// unlinkableRanges returns the byte ranges of content that must not hold a
+// cross-link: fenced code blocks, headings, and the inline text matched by
+// unlinkableRe.
+func unlinkableRanges(content string) [][2]int {
+	var ranges [][2]int
+	fence := ""
+	offset := 0
+	for line := range strings.SplitAfterSeq(content, "\n") {
+		start := offset
+		offset += len(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			ranges = append(ranges, [2]int{start, offset})
+			continue
+		case fenceRe.MatchString(line):
+			fence = fenceRe.FindStringSubmatch(line)[1]
+			ranges = append(ranges, [2]int{start, offset})
+			continue
+		case strings.HasPrefix(strings.TrimLeft(line, " "), "#"):
+			ranges = append(ranges, [2]int{start, offset})
+			continue
+		}
+		for _, m := range unlinkableRe.FindAllStringIndex(line, -1) {
+			ranges = append(ranges, [2]int{start + m[0], start + m[1]})
+		}
+	}
+	return ranges
+}
+
+// overlaps reports whether the range from start to end overlaps any of ranges.
+func overlaps(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// wordBoundary reports whether the text of s from start to end is a whole
+// word (or words): neither preceded nor followed by a letter, digit or
+// underscore.
+func wordBoundary(s string, start, end int) bool {
+	isWord := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	if before, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && isWord(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && isWord(after) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+func TestCrossLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "first mention only",
+			content: "The Loader reads files. The Loader caches them.",
+			want:    "The [Loader](02_loader.md) reads files. The Loader caches them.",
+		},
+		{
+			name:    "longest name first",
+			content: "The Config Loader uses the Config.",
+			want:    "The [Config Loader](04_config-loader.md) uses the [Config](03_config.md).",
+		},
+		{
+			name:    "whole words",
+			content: "Loaders and ConfigLoader are not it, but Loader is.",
+			want:    "Loaders and ConfigLoader are not it, but [Loader](02_loader.md) is.",
+		},
+		{
+			name:    "own abstraction",
+			content: "The Parser calls the Loader.",
+			want:    "The Parser calls the [Loader](02_loader.md).",
+		},
+		{
+			name:    "code",
+			content: "```go\nLoader.Load()\n```\n\n`Loader` is used. So is Loader.",
+			want:    "```go\nLoader.Load()\n```\n\n`Loader` is used. So is [Loader](02_loader.md).",
+		},
+		{
+			name:    "headings and links",
+			content: "## The Loader\n\nSee [the Loader](https://example.com/Loader) and <a href=\"x\">Loader</a>, then Loader.",
+			want:    "## The Loader\n\nSee [the Loader](https://example.com/Loader) and <a href=\"x\">Loader</a>, then [Loader](02_loader.md).",
+		},
+		{
+			name:    "already linked",
+			content: "Read [its chapter](02_loader.md) about the Loader.",
+			want:    "Read [its chapter](02_loader.md) about the Loader.",
+		},
+		{
+			name:    "unclosed fence",
+			content: "```\nLoader",
+			want:    "```\nLoader",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := []chapterLink{
+				{Chapter: generation.Chapter{Index: 1, Abstraction: "Parser", Content: tt.content}, File: "01_parser.md"},
+				{Chapter: generation.Chapter{Index: 2, Abstraction: "Loader"}, File: "02_loader.md"},
+				{Chapter: generation.Chapter{Index: 3, Abstraction: "Config"}, File: "03_config.md"},
+				{Chapter: generation.Chapter{Index: 4, Abstraction: "Config Loader"}, File: "04_config-loader.md"},
+			}
+			crossLink(links)
+			if got := links[0].Content; got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCrossLinkRendered(t *testing.T) {
+	tutorial := &Tutorial{
+		ProjectName: "demo",
+		Chapters: []generation.Chapter{
+			{Index: 1, Title: "Parser", Abstraction: "Parser", Content: "# Parser\n\nThe Parser calls the Loader."},
+			{Index: 2, Title: "Loader", Abstraction: "Loader", Content: "# Loader\n\nLoads files for the Parser."},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+		file   string
+		want   string
+	}{
+		{name: "markdown", format: "markdown", opts: Options{CrossLinks: true}, file: "01_parser.md", want: "The Parser calls the [Loader](02_loader.md)."},
+		{name: "html", format: "html", opts: Options{CrossLinks: true}, file: "01_parser.html", want: `The Parser calls the <a href="02_loader.html">Loader</a>.`},
+		{name: "single document", format: "markdown", opts: Options{CrossLinks: true, SingleFile: true}, file: "demo.md", want: "Loads files for the [Parser](#chapter-01)."},
+		{name: "disabled", format: "markdown", opts: Options{}, file: "01_parser.md", want: "The Parser calls the Loader."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := New(tt.format, tt.opts)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			dir := t.TempDir()
+			if _, err := renderer.Render(tutorial, dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", tt.file, err)
+			}
+			if !strings.Contains(string(content), tt.want) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", tt.file, tt.want, content)
+			}
+		})
+	}
+	if tutorial.Chapters[0].Content != "# Parser\n\nThe Parser calls the Loader." {
+		t.Errorf("Expected the tutorial's chapters to be left as they are, got %q", tutorial.Chapters[0].Content)
+	}
+}
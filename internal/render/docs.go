@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/source"
+)
+
+// docLink is one of the project's own documents, listed in the index so
+// readers can turn to the original.
+type docLink struct {
+	Path string // Slash-separated path in the source
+	URL  string // Where to read it; empty if it cannot be linked (e.g., the source was an archive)
+}
+
+// docLinks returns the documents of t's analysis (see analysis.IsDoc),
+// linked from the index written to dir: documents of a repository link to
+// the repository's web pages, and those of a local directory are linked by
+// relative path.
+func docLinks(t *Tutorial, dir string) []docLink {
+	if t.Analysis == nil {
+		return nil
+	}
+	var links []docLink
+	for _, doc := range t.Analysis.Docs() {
+		links = append(links, docLink{Path: doc.Path, URL: docURL(t.Analysis.Source, doc.Path, dir)})
+	}
+	return links
+}
+
+// docURL returns the link to the document at the slash-separated path p of
+// src from the directory dir, or "" if it cannot be linked.
+func docURL(src analysis.Source, p, dir string) string {
+	switch src.Type {
+	case analysis.SourceRepo:
+		repo, err := source.NormalizeRepoURL(src.Location)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(repo, ".git") + "/blob/HEAD/" + p
+	case analysis.SourceDir:
+		target, err := filepath.Abs(filepath.Join(src.Location, filepath.FromSlash(p)))
+		if err != nil {
+			return ""
+		}
+		base, err := filepath.Abs(dir)
+		if err != nil {
+			return ""
+		}
+		rel, err := filepath.Rel(base, target)
+		if err != nil {
+			return filepath.ToSlash(target)
+		}
+		return filepath.ToSlash(rel)
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+func TestDocLinks(t *testing.T) {
+	files := []analysis.File{{Path: "main.go"}, {Path: "docs/guide.md", Docs: true}, {Path: "README.md", Docs: true}}
+	root := t.TempDir()
+	tests := []struct {
+		name   string
+		source analysis.Source
+		format string
+		want   []string
+	}{
+		{
+			name:   "repo",
+			source: analysis.Source{Type: analysis.SourceRepo, Location: "owner/demo"},
+			format: "markdown",
+			want:   []string{"- [README.md](https://github.com/owner/demo/blob/HEAD/README.md)\n- [docs/guide.md](https://github.com/owner/demo/blob/HEAD/docs/guide.md)"},
+		},
+		{
+			name:   "dir",
+			source: analysis.Source{Type: analysis.SourceDir, Location: filepath.Join(root, "src")},
+			format: "html",
+			want:   []string{`<a href="../src/README.md">README.md</a>`, `<a href="../src/docs/guide.md">docs/guide.md</a>`},
+		},
+		{
+			name:   "archive",
+			source: analysis.Source{Type: analysis.SourceArchive, Location: "demo.tar.gz"},
+			format: "markdown",
+			want:   []string{"- README.md\n- docs/guide.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.format, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(root, "out-"+tt.name)
+			tutorial := &Tutorial{
+				ProjectName: "demo",
+				Chapters:    []generation.Chapter{{Index: 1, Title: "Loader", Abstraction: "Loader", Content: "# Loader\n"}},
+				Analysis:    &analysis.Analysis{Source: tt.source, Files: files},
+			}
+			if _, err := r.Render(tutorial, dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			index, err := os.ReadFile(filepath.Join(dir, "index."+map[string]string{"markdown": "md", "html": "html"}[tt.format]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range append(tt.want, "Project Documentation") {
+				if !strings.Contains(string(index), s) {
+					t.Errorf("Expected the index to contain %q, got:\n%s", s, index)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+// ExportFile is the file the json format writes to the output directory.
+const ExportFile = "tutorial.json"
+
+// ExportSchema names the schema of exported analyses, so that tools can
+// tell an export apart from other JSON documents (such as a saved analysis).
+const ExportSchema = "code-decoder/export"
+
+// ExportSchemaVersion is the version of the export schema. Unlike the
+// analysis file written by --save-analysis, which may change with any
+// release, the export schema is public: fields are only ever added within a
+// version, and removing or changing the meaning of one bumps it.
+const ExportSchemaVersion = 1
+
+// Export is the structured analysis of a codebase, and the chapters written
+// from it if any, in the public schema documented in the README. The types
+// of its fields are separate from those of the analysis file, so that
+// changing the latter does not change the schema.
+type Export struct {
+	Schema        string              `json:"schema"`         // Always ExportSchema
+	SchemaVersion int                 `json:"schema_version"` // Always ExportSchemaVersion
+	Project       string              `json:"project"`
+	Source        ExportSource        `json:"source"`
+	AnalyzedAt    time.Time           `json:"analyzed_at"`
+	Languages     []ExportLanguage    `json:"languages"`
+	Files         []ExportFileInfo    `json:"files"`
+	Abstractions  []ExportAbstraction `json:"abstractions"`
+	Relationships []ExportRelation    `json:"relationships"`
+	Chapters      []ExportChapter     `json:"chapters"` // Empty when no tutorial was written
+}
+
+// ExportSource describes where the analyzed codebase came from.
+type ExportSource struct {
+	Type     string   `json:"type"`            // "dir", "repo" or "archive"
+	Location string   `json:"location"`        // Directory path, repository URL or archive path
+	Paths    []string `json:"paths"`           // Subdirectories the analysis was restricted to; empty for all
+	Since    string   `json:"since,omitempty"` // Git revision the analysis was restricted to the changes since, if any
+}
+
+// ExportLanguage is the share of the analyzed files written in a language.
+type ExportLanguage struct {
+	Language string  `json:"language"`
+	Files    int     `json:"files"`
+	Percent  float64 `json:"percent"`
+}
+
+// ExportFileInfo is an analyzed file.
+type ExportFileInfo struct {
+	Path     string `json:"path"`     // Slash-separated, relative to the source root
+	Language string `json:"language"` // Programming language, or "unknown"
+	Size     int64  `json:"size"`     // In bytes
+	SHA256   string `json:"sha256"`   // Hex-encoded hash of the content
+	Summary  string `json:"summary"`  // What the file does
+}
+
+// ExportAbstraction is a core abstraction of the codebase.
+type ExportAbstraction struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Files       []string `json:"files"` // Paths of the files implementing it
+}
+
+// ExportRelation is a directed edge between two abstractions, by name.
+type ExportRelation struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+// ExportChapter is a chapter of the tutorial, in Markdown.
+type ExportChapter struct {
+	Index       int    `json:"index"` // 1-based position in the tutorial
+	Title       string `json:"title"`
+	Abstraction string `json:"abstraction"` // Name of the abstraction it covers
+	Content     string `json:"content"`
+}
+
+// NewExport converts a, and the chapters written from it, to the export
+// schema. Lists are never null, so that consumers need not check.
+func NewExport(a *analysis.Analysis, chapters []generation.Chapter) *Export {
+	e := &Export{
+		Schema:        ExportSchema,
+		SchemaVersion: ExportSchemaVersion,
+		Project:       a.ProjectName,
+		Source:        ExportSource{Type: a.Source.Type, Location: a.Source.Location, Paths: orEmpty(a.Source.Paths), Since: a.Source.Since},
+		AnalyzedAt:    a.CreatedAt.UTC(),
+		Languages:     []ExportLanguage{},
+		Files:         make([]ExportFileInfo, 0, len(a.Files)),
+		Abstractions:  make([]ExportAbstraction, 0, len(a.Abstractions)),
+		Relationships: make([]ExportRelation, 0, len(a.Relationships)),
+		Chapters:      make([]ExportChapter, 0, len(chapters)),
+	}
+	if len(a.Files) > 0 {
+		for _, share := range a.Languages() {
+			e.Languages = append(e.Languages, ExportLanguage(share))
+		}
+	}
+	for _, f := range a.Files {
+		language := f.Language
+		if language == "" {
+			language = "unknown"
+		}
+		e.Files = append(e.Files, ExportFileInfo{Path: f.Path, Language: language, Size: f.Size, SHA256: f.Hash, Summary: f.Summary})
+	}
+	for _, abs := range a.Abstractions {
+		e.Abstractions = append(e.Abstractions, ExportAbstraction{Name: abs.Name, Description: abs.Description, Files: orEmpty(abs.Files)})
+	}
+	for _, rel := range a.Relationships {
+		e.Relationships = append(e.Relationships, ExportRelation(rel))
+	}
+	for _, c := range chapters {
+		e.Chapters = append(e.Chapters, ExportChapter(c))
+	}
+	return e
+}
+
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// WriteExport writes e to w as indented JSON.
+func WriteExport(w io.Writer, e *Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(e); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// JSONRenderer writes a tutorial and the analysis it was written from as a
+// single ExportFile, for consumption by other tools.
+type JSONRenderer struct{}
+
+// NewJSONRenderer creates a JSON renderer.
+func NewJSONRenderer() *JSONRenderer {
+	return &JSONRenderer{}
+}
+
+// Render implements Renderer. The tutorial's Analysis must be set.
+func (r *JSONRenderer) Render(t *Tutorial, dir string) ([]string, error) {
+	if t.Analysis == nil {
+		return nil, fmt.Errorf("the json format needs the analysis the tutorial was written from")
+	}
+	if len(t.Keep) > 0 {
+		return nil, fmt.Errorf("the json format cannot keep chapters, since it exports them all to one file")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	path := filepath.Join(dir, ExportFile)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := WriteExport(f, NewExport(t.Analysis, t.Chapters)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return []string{path}, nil
+}
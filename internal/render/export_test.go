@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata")
+
+func testAnalysis() *analysis.Analysis {
+	return &analysis.Analysis{
+		SchemaVersion: analysis.SchemaVersion,
+		ProjectName:   "demo",
+		Source:        analysis.Source{Type: analysis.SourceDir, Location: "/src/demo"},
+		CreatedAt:     time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC),
+		Files: []analysis.File{
+			{Path: "config/loader.go", Size: 1200, Language: "Go", Hash: "9f86d081884c7d65", Summary: "Loads settings from YAML"},
+			{Path: "main.go", Size: 300, Language: "Go", Hash: "2c26b46b68ffc68f", Summary: "Parses flags"},
+			{Path: "Makefile", Size: 80},
+		},
+		Failed: map[string]string{"broken.go": "invalid JSON"},
+		Abstractions: []analysis.Abstraction{
+			{Name: "Config Loader", Description: "Reads the configuration", Files: []string{"config/loader.go"}},
+			{Name: "CLI", Description: "Parses flags"},
+		},
+		Relationships: []analysis.Relationship{{From: "CLI", To: "Config Loader", Label: "uses"}},
+	}
+}
+
+// TestExportGolden guards the public export schema: a failure means the
+// schema changed, which must be deliberate (see ExportSchemaVersion). Run
+// go test ./internal/render -run TestExportGolden -update to accept it.
+func TestExportGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		tutorial *Tutorial
+	}{
+		{name: "analysis", tutorial: &Tutorial{}},
+		{name: "tutorial", tutorial: testTutorial()},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteExport(&buf, NewExport(testAnalysis(), tt.tutorial.Chapters)); err != nil {
+			t.Fatalf("WriteExport() error = %v", err)
+		}
+		golden := filepath.Join("testdata", "export_"+tt.name+".golden.json")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("Failed to update %s: %v", golden, err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", golden, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("Export of %s does not match %s; got:\n%s", tt.name, golden, buf.String())
+		}
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	renderer, err := New("json", Options{Diagrams: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	dir := t.TempDir()
+	tutorial := testTutorial()
+	if _, err := renderer.Render(tutorial, dir); err == nil {
+		t.Error("Render() expected an error without the analysis")
+	}
+
+	tutorial.Analysis = testAnalysis()
+	paths, err := renderer.Render(tutorial, dir)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(dir, ExportFile) {
+		t.Fatalf("Expected only %s to be written, got %v", ExportFile, paths)
+	}
+	got, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read the export: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "export_tutorial.golden.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected the rendered export to match the golden file, got:\n%s", got)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFrontMatter is the default template of the YAML front matter
+// written at the top of each Markdown chapter file, in the fields Hugo and
+// Jekyll read. Strings are quoted with printf "%q", which gives valid YAML
+// whatever quotes or colons they contain.
+const DefaultFrontMatter = `title: {{printf "%q" .Title}}
+weight: {{.Index}}
+{{- with .Audience}}
+audience: {{printf "%q" .}}
+{{- end}}
+{{- with .Language}}
+language: {{printf "%q" .}}
+{{- end}}
+{{- with .LanguageCode}}
+language_code: {{printf "%q" .}}
+{{- end}}
+date: {{.Date.Format "2006-01-02"}}`
+
+// FrontMatterData is the data the front matter template is executed with.
+type FrontMatterData struct {
+	Title        string    // Chapter title
+	Index        int       // 1-based position of the chapter, for use as its weight or order
+	Abstraction  string    // Name of the abstraction the chapter covers
+	Project      string    // Project name
+	Audience     string    // Who the tutorial is for; empty if not recorded
+	Language     string    // Name of the language the chapter is written in; empty if not recorded
+	LanguageCode string    // Code of that language (e.g., "pt-BR"); empty if unknown
+	Date         time.Time // When the chapter was written to disk
+}
+
+// frontMatter renders the front matter of chapters with a template.
+type frontMatter struct {
+	tmpl *template.Template
+}
+
+// newFrontMatter parses the front matter template text (DefaultFrontMatter
+// if empty), and checks that it gives valid YAML for a sample chapter whose
+// title needs quoting.
+func newFrontMatter(text string) (*frontMatter, error) {
+	if text == "" {
+		text = DefaultFrontMatter
+	}
+	tmpl, err := template.New("front-matter").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter template: %w", err)
+	}
+	f := &frontMatter{tmpl: tmpl}
+	sample := FrontMatterData{Title: `Config: the "Loader"`, Index: 1, Abstraction: "Loader", Project: "p", Date: time.Now()}
+	if _, err := f.render(sample); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// render returns the front matter block for data, between --- lines and
+// followed by a blank line. It fails if the template does not give a YAML
+// mapping.
+func (f *frontMatter) render(data FrontMatterData) (string, error) {
+	var sb strings.Builder
+	if err := f.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("invalid front matter template: %w", err)
+	}
+	body := strings.Trim(sb.String(), "\n")
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(body), &fields); err != nil {
+		return "", fmt.Errorf("the front matter template gives invalid YAML for chapter %d (quote strings with printf \"%%q\"): %w", data.Index, err)
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("the front matter template gives no fields for chapter %d", data.Index)
+	}
+	return "---\n" + body + "\n---\n\n", nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/generation"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New("markdown", Options{FrontMatter: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tutorial := testTutorial()
+	tutorial.Chapters[0].Title = `Config: the "Loader"`
+	tutorial.Audience = "beginner"
+	tutorial.Language = generation.Language{Name: "Portuguese (BR)", Code: "pt-BR"}
+	if _, err := r.Render(tutorial, dir); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "01_config-the-loader.md"))
+	if err != nil {
+		t.Fatalf("Failed to read the chapter: %v", err)
+	}
+	content := string(data)
+	header, body, ok := strings.Cut(strings.TrimPrefix(content, "---\n"), "\n---\n\n")
+	if !strings.HasPrefix(content, "---\n") || !ok {
+		t.Fatalf("Expected the chapter to start with front matter, got:\n%s", content)
+	}
+	if !strings.HasPrefix(body, "# Config Loader") {
+		t.Errorf("Expected the chapter content after the front matter, got:\n%s", body)
+	}
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(header), &fields); err != nil {
+		t.Fatalf("Expected valid YAML, got %v:\n%s", err, header)
+	}
+	want := map[string]any{
+		"title":         `Config: the "Loader"`,
+		"weight":        1,
+		"audience":      "beginner",
+		"language":      "Portuguese (BR)",
+		"language_code": "pt-BR",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("Expected %s: %v, got %v", key, value, fields[key])
+		}
+	}
+	if date, ok := fields["date"].(time.Time); !ok || time.Since(date) > 48*time.Hour {
+		t.Errorf("Expected the generation date, got %v", fields["date"])
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatalf("Failed to read index.md: %v", err)
+	}
+	if strings.Contains(string(index), "title:") {
+		t.Errorf("Expected no chapter front matter in index.md, got:\n%s", index)
+	}
+}
+
+func TestFrontMatterTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		opts    Options
+		want    string
+		wantErr string
+	}{
+		{name: "custom fields", format: "markdown", opts: Options{FrontMatter: true, FrontMatterTemplate: "title: {{printf \"%q\" .Title}}\nslug: {{printf \"%q\" .Abstraction}}\n"},
+			want: "---\ntitle: \"CLI\"\nslug: \"CLI\"\n---\n\n# CLI"},
+		{name: "unquoted title", format: "markdown", opts: Options{FrontMatter: true, FrontMatterTemplate: "title: {{.Title}}"}, wantErr: "invalid YAML"},
+		{name: "unknown field", format: "markdown", opts: Options{FrontMatter: true, FrontMatterTemplate: "title: {{.Name}}"}, wantErr: "invalid front matter template"},
+		{name: "html", format: "html", opts: Options{FrontMatter: true}, wantErr: "only supported for the markdown format"},
+		{name: "single file", format: "markdown", opts: Options{FrontMatter: true, SingleFile: true}, wantErr: "single-file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.format, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			dir := t.TempDir()
+			if _, err := r.Render(testTutorial(), dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "02_cli.md"))
+			if err != nil {
+				t.Fatalf("Failed to read the chapter: %v", err)
+			}
+			if !strings.HasPrefix(string(data), tt.want) {
+				t.Errorf("Expected the chapter to start with %q, got:\n%s", tt.want, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// glossaryName is the name of the glossary file, without its extension.
+const glossaryName = "glossary"
+
+// glossaryMarkdown returns the glossary of t as a Markdown page, each term
+// linking to the files of the chapters (links) that introduce it. Links
+// are relative to the directory of the chapter files, and the HTML renderers
+// rewrite them to pages like the links of the chapters.
+func glossaryMarkdown(t *Tutorial, links []chapterLink) string {
+	files := make(map[int]chapterLink, len(links))
+	for _, link := range links {
+		files[link.Index] = link
+	}
+	var sb strings.Builder
+	sb.WriteString("# Glossary\n\nThe key terms of this tutorial, with the chapters that introduce them.\n\n")
+	for _, entry := range t.Glossary {
+		fmt.Fprintf(&sb, "- **%s**", entry.Term)
+		if entry.Definition != "" {
+			fmt.Fprintf(&sb, ": %s", entry.Definition)
+		}
+		var refs []string
+		for _, index := range entry.Chapters {
+			if link, ok := files[index]; ok {
+				refs = append(refs, fmt.Sprintf("[Chapter %d: %s](%s)", link.Index, link.Title, link.File))
+			}
+		}
+		if len(refs) > 0 {
+			fmt.Fprintf(&sb, " (see %s)", strings.Join(refs, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+func TestGlossary(t *testing.T) {
+	glossary := []generation.GlossaryEntry{
+		{Term: "CLI", Definition: "The command line.", Chapters: []int{2}},
+		{Term: "profile", Definition: "A named LLM setup.", Chapters: []int{1, 2}},
+	}
+	hrefRe := regexp.MustCompile(`(?:\]\(|href=")([^)"#]+)`)
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		index  string
+		want   []string
+	}{
+		{
+			name:   "markdown",
+			format: "markdown",
+			file:   "glossary.md",
+			index:  "index.md",
+			want: []string{
+				"- **CLI**: The command line. (see [Chapter 2: CLI](02_cli.md))\n",
+				"- **profile**: A named LLM setup. (see [Chapter 1: Config Loader](01_config-loader.md), [Chapter 2: CLI](02_cli.md))\n",
+				"[Table of Contents](index.md)",
+			},
+		},
+		{
+			name:   "html",
+			format: "html",
+			file:   "glossary.html",
+			index:  "index.html",
+			want: []string{
+				`<strong>profile</strong>: A named LLM setup. (see <a href="01_config-loader.html">Chapter 1: Config Loader</a>, <a href="02_cli.html">Chapter 2: CLI</a>)`,
+				`<a class="glossary current" href="glossary.html" aria-current="page">Glossary</a>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.format, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tutorial := testTutorial()
+			tutorial.Glossary = glossary
+			dir := t.TempDir()
+			if _, err := r.Render(tutorial, dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Expected %s to be written: %v", tt.file, err)
+			}
+			page := string(data)
+			for _, want := range tt.want {
+				if !strings.Contains(page, want) {
+					t.Errorf("Expected %s to contain %q, got:\n%s", tt.file, want, page)
+				}
+			}
+			// Every local link of the glossary leads to a file written
+			for _, m := range hrefRe.FindAllStringSubmatch(page, -1) {
+				if target := m[1]; !strings.Contains(target, ":") {
+					if _, err := os.Stat(filepath.Join(dir, target)); err != nil {
+						t.Errorf("Expected the link to %s to lead to a file: %v", target, err)
+					}
+				}
+			}
+			index, err := os.ReadFile(filepath.Join(dir, tt.index))
+			if err != nil || !strings.Contains(string(index), tt.file) {
+				t.Errorf("Expected %s to link to %s, got %v:\n%s", tt.index, tt.file, err, index)
+			}
+		})
+	}
+
+	// Without a glossary, none is written
+	r, err := New("markdown", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if _, err := r.Render(testTutorial(), dir); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "glossary.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected no glossary.md, got %v", err)
+	}
+}
+
+func TestGlossarySingleFile(t *testing.T) {
+	r, err := New("markdown", Options{SingleFile: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tutorial := testTutorial()
+	tutorial.Glossary = []generation.GlossaryEntry{{Term: "CLI", Definition: "The command line.", Chapters: []int{2}}}
+	var sb strings.Builder
+	if err := r.(*MarkdownRenderer).WriteDocument(tutorial, &sb); err != nil {
+		t.Fatalf("WriteDocument() error = %v", err)
+	}
+	document := sb.String()
+	for _, want := range []string{
+		"[Glossary](#glossary)",
+		"<a id=\"glossary\"></a>\n\n# Glossary",
+		"- **CLI**: The command line. (see [Chapter 2: CLI](#chapter-02))",
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("Expected the document to contain %q, got:\n%s", want, document)
+		}
+	}
+}
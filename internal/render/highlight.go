@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// syntax describes the lexical conventions of a language family, enough to
+// highlight keywords, strings, comments and numbers.
+type syntax struct {
+	keywords     map[string]bool
+	lineComment  []string // Line comment markers (e.g., "//", "#")
+	blockComment [2]string
+	quotes       string // Characters that delimit strings
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	cFamilyComments = [2]string{"/*", "*/"}
+
+	syntaxes = map[string]*syntax{
+		"go": {
+			keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+				import interface map package range return select struct switch type var nil true false iota`),
+			lineComment: []string{"//"}, blockComment: cFamilyComments, quotes: "\"'`",
+		},
+		"python": {
+			keywords: words(`False None True and as assert async await break class continue def del elif else
+				except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield self`),
+			lineComment: []string{"#"}, quotes: `"'`,
+		},
+		"javascript": {
+			keywords: words(`async await break case catch class const continue debugger default delete do else export
+				extends false finally for function if import in instanceof let new null return super switch this throw
+				true try typeof undefined var void while with yield interface type enum implements private public readonly`),
+			lineComment: []string{"//"}, blockComment: cFamilyComments, quotes: "\"'`",
+		},
+		"java": {
+			keywords: words(`abstract boolean break byte case catch char class const continue default do double else
+				enum extends final finally float for if implements import instanceof int interface long native new null
+				package private protected public return short static super switch synchronized this throw throws try void
+				volatile while true false var val fun object when override`),
+			lineComment: []string{"//"}, blockComment: cFamilyComments, quotes: `"'`,
+		},
+		"rust": {
+			keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl in let
+				loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while`),
+			lineComment: []string{"//"}, blockComment: cFamilyComments, quotes: `"`,
+		},
+		"c": {
+			keywords: words(`auto break case char class const continue default delete do double else enum extern float
+				for goto if inline int long namespace new nullptr private protected public register return short signed
+				sizeof static struct switch template this typedef union unsigned using virtual void volatile while true false NULL`),
+			lineComment: []string{"//"}, blockComment: cFamilyComments, quotes: `"'`,
+		},
+		"shell": {
+			keywords:    words(`if then else elif fi for while until do done case esac in function return export local echo exit`),
+			lineComment: []string{"#"}, quotes: `"'`,
+		},
+		"yaml": {
+			keywords:    words(`true false null yes no`),
+			lineComment: []string{"#"}, quotes: `"'`,
+		},
+	}
+
+	// languageAliases maps fence info strings to their syntax.
+	languageAliases = map[string]string{
+		"golang": "go", "py": "python", "js": "javascript", "jsx": "javascript", "ts": "javascript",
+		"tsx": "javascript", "typescript": "javascript", "kotlin": "java", "kt": "java", "scala": "java",
+		"csharp": "java", "cs": "java", "rs": "rust", "h": "c", "cpp": "c", "c++": "c", "cc": "c", "hpp": "c",
+		"sh": "shell", "bash": "shell", "zsh": "shell", "console": "shell", "yml": "yaml",
+	}
+)
+
+// highlight returns code as HTML with keywords, strings, comments and numbers
+// wrapped in spans (classes "kw", "str", "com", "num") styled by the theme.
+// Code in an unknown language is only escaped.
+func highlight(lang, code string) string {
+	if alias, ok := languageAliases[lang]; ok {
+		lang = alias
+	}
+	syn, ok := syntaxes[lang]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var sb strings.Builder
+	span := func(class, text string) {
+		sb.WriteString(`<span class="` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if marker := prefixOf(rest, syn.lineComment); marker != "" {
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			span("com", rest[:end])
+			i += end
+			continue
+		}
+		if open := syn.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], syn.blockComment[1])
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += len(open) + len(syn.blockComment[1])
+			}
+			span("com", rest[:end])
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case strings.ContainsRune(syn.quotes, r):
+			end := stringEnd(rest, r)
+			span("str", rest[:end])
+			i += end
+		case unicode.IsDigit(r):
+			end := size
+			for end < len(rest) && (isNumberByte(rest[end]) || rest[end] == '.') {
+				end++
+			}
+			span("num", rest[:end])
+			i += end
+		case r == '_' || unicode.IsLetter(r):
+			end := size
+			for end < len(rest) {
+				r, n := utf8.DecodeRuneInString(rest[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += n
+			}
+			if word := rest[:end]; syn.keywords[word] {
+				span("kw", word)
+			} else {
+				sb.WriteString(html.EscapeString(word))
+			}
+			i += end
+		default:
+			sb.WriteString(html.EscapeString(rest[:size]))
+			i += size
+		}
+	}
+	return sb.String()
+}
+
+// prefixOf returns the marker that s starts with, or "".
+func prefixOf(s string, markers []string) string {
+	for _, m := range markers {
+		if strings.HasPrefix(s, m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// stringEnd returns the length of the string literal at the start of s,
+// delimited by quote and honoring backslash escapes. Unterminated strings end
+// at the end of the line (or of s, for raw backquoted strings).
+func stringEnd(s string, quote rune) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++
+		case rune(s[i]) == quote:
+			return i + 1
+		case s[i] == '\n' && quote != '`':
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isNumberByte(b byte) bool {
+	return b == '_' || b == 'x' || b == 'X' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
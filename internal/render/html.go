@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// stylesheet is the name of the CSS stylesheet written next to the pages (and
+// of the embedded layout the themes share).
+const stylesheet = "style.css"
+
+// HTMLRenderer writes a tutorial as standalone HTML pages: an index.html with
+// a table of contents and one page per chapter, sharing a CSS theme and a
+// navigation sidebar. All links are relative, so the pages work when opened
+// straight from disk.
+type HTMLRenderer struct {
+	templates *template.Template
+	css       []byte
+	names     *namer
+	opts      Options
+}
+
+// NewHTMLRenderer creates an HTML renderer using the embedded default templates, styled as
+// opts.Style says.
+// Pages containing Mermaid diagrams load the Mermaid runtime from opts.MermaidURL.
+func NewHTMLRenderer(opts Options) (*HTMLRenderer, error) {
+	if opts.MermaidURL == "" {
+		opts.MermaidURL = DefaultMermaidURL
+	}
+	templates, err := template.ParseFS(templatesFS, "templates/html/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html templates: %w", err)
+	}
+	css, err := opts.Style.stylesheetCSS()
+	if err != nil {
+		return nil, err
+	}
+	names, err := newNamer(opts.OutputName)
+	if err != nil {
+		return nil, err
+	}
+	return &HTMLRenderer{templates: templates, css: css, names: names, opts: opts}, nil
+}
+
+// Render implements Renderer.
+func (r *HTMLRenderer) Render(t *Tutorial, dir string) ([]string, error) {
+	links, err := r.names.chapterLinks(t.Chapters, ".html", reservedFiles(t, ".html", stylesheet)...)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.CrossLinks {
+		crossLink(links)
+	}
+	kept, err := keptFiles(t, links, dir, ".html")
+	if err != nil {
+		return nil, err
+	}
+	glossary := ""
+	if len(t.Glossary) > 0 || slices.Contains(kept, glossaryName+".html") {
+		glossary = glossaryName + ".html"
+	}
+
+	names := []string{"index.html"}
+	contents := map[string]string{stylesheet: string(r.css)}
+	indexData := map[string]any{
+		"Tutorial":   t,
+		"Chapters":   links,
+		"Title":      "Tutorial: " + t.ProjectName,
+		"Stylesheet": stylesheet,
+		"Docs":       docLinks(t, dir),
+		"Glossary":   glossary,
+	}
+	if r.opts.Diagrams {
+		if diagram := mermaidGraph(links, t.Relationships); diagram != "" {
+			indexData["Diagram"] = diagram
+			indexData["MermaidURL"] = r.opts.MermaidURL
+		}
+	}
+	index, err := r.execute(indexData)
+	if err != nil {
+		return nil, err
+	}
+	contents["index.html"] = index
+
+	for i, link := range links {
+		if slices.Contains(kept, link.File) {
+			continue
+		}
+		content := markdownToHTML(link.Content)
+		data := map[string]any{
+			"Tutorial":   t,
+			"Chapters":   links,
+			"Current":    link.File,
+			"Title":      link.Title,
+			"Stylesheet": stylesheet,
+			"Content":    template.HTML(content),
+			"Glossary":   glossary,
+		}
+		if r.opts.Diagrams && strings.Contains(content, `<pre class="mermaid">`) {
+			// The chapter itself contains diagrams
+			data["MermaidURL"] = r.opts.MermaidURL
+		}
+		if i > 0 {
+			data["Prev"] = links[i-1]
+		}
+		if i+1 < len(links) {
+			data["Next"] = links[i+1]
+		}
+		page, err := r.execute(data)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, link.File)
+		contents[link.File] = page
+	}
+
+	if len(t.Glossary) > 0 {
+		page, err := r.execute(map[string]any{
+			"Tutorial":   t,
+			"Chapters":   links,
+			"Current":    glossary,
+			"Title":      "Glossary",
+			"Stylesheet": stylesheet,
+			"Content":    template.HTML(markdownToHTML(glossaryMarkdown(t, links))),
+			"Glossary":   glossary,
+		})
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, glossary)
+		contents[glossary] = page
+	}
+
+	paths, err := writeFiles(dir, append(names, stylesheet), contents)
+	for _, name := range kept {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths, err
+}
+
+func (r *HTMLRenderer) execute(data any) (string, error) {
+	var sb strings.Builder
+	if err := r.templates.ExecuteTemplate(&sb, "page.html.tmpl", data); err != nil {
+		return "", fmt.Errorf("failed to render page.html.tmpl: %w", err)
+	}
+	return sb.String(), nil
+}
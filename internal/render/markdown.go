@@ -0,0 +1,246 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+// MarkdownRenderer writes a tutorial as Markdown files: an index.md with a
+// table of contents, and one file per chapter linked from it.
+type MarkdownRenderer struct {
+	templates   *template.Template
+	names       *namer
+	frontMatter *frontMatter // Nil unless the FrontMatter option is set
+	opts        Options
+}
+
+// NewMarkdownRenderer creates a Markdown renderer using the embedded default templates.
+func NewMarkdownRenderer(opts Options) (*MarkdownRenderer, error) {
+	templates, err := template.ParseFS(templatesFS, "templates/markdown/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markdown templates: %w", err)
+	}
+	names, err := newNamer(opts.OutputName)
+	if err != nil {
+		return nil, err
+	}
+	r := &MarkdownRenderer{templates: templates, names: names, opts: opts}
+	if opts.FrontMatter {
+		if r.frontMatter, err = newFrontMatter(opts.FrontMatterTemplate); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// chapterLink is a chapter together with the file it is written to.
+type chapterLink struct {
+	generation.Chapter
+	File string
+}
+
+// Render implements Renderer.
+func (r *MarkdownRenderer) Render(t *Tutorial, dir string) ([]string, error) {
+	links, err := r.names.chapterLinks(t.Chapters, ".md", reservedFiles(t, ".md")...)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.CrossLinks {
+		crossLink(links)
+	}
+	if r.opts.SingleFile {
+		if len(t.Keep) > 0 {
+			return nil, fmt.Errorf("single-file output cannot keep chapters, since it writes them all to one document")
+		}
+		return r.renderDocument(t, links, dir)
+	}
+	kept, err := keptFiles(t, links, dir, ".md")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{"index.md"}
+	contents := make(map[string]string, len(links)+2)
+	indexData := map[string]any{"Tutorial": t, "Chapters": links, "Docs": docLinks(t, dir)}
+	if glossary := glossaryName + ".md"; len(t.Glossary) > 0 {
+		indexData["Glossary"] = glossary
+		names = append(names, glossary)
+		contents[glossary] = glossaryMarkdown(t, links) + "\n---\n\n[Table of Contents](index.md)\n"
+	} else if slices.Contains(kept, glossary) {
+		indexData["Glossary"] = glossary
+	}
+	if r.opts.Diagrams {
+		indexData["Diagram"] = mermaidGraph(links, t.Relationships)
+	}
+	index, err := r.execute("index.md.tmpl", indexData)
+	if err != nil {
+		return nil, err
+	}
+	contents["index.md"] = index
+
+	now := time.Now()
+	for i, link := range links {
+		if slices.Contains(kept, link.File) {
+			continue
+		}
+		data := map[string]any{"Tutorial": t, "Chapter": link}
+		if i > 0 {
+			data["Prev"] = links[i-1]
+		}
+		if i+1 < len(links) {
+			data["Next"] = links[i+1]
+		}
+		content, err := r.execute("chapter.md.tmpl", data)
+		if err != nil {
+			return nil, err
+		}
+		if r.frontMatter != nil {
+			header, err := r.frontMatter.render(FrontMatterData{
+				Title:        link.Title,
+				Index:        link.Index,
+				Abstraction:  link.Abstraction,
+				Project:      t.ProjectName,
+				Audience:     t.Audience,
+				Language:     t.Language.Name,
+				LanguageCode: t.Language.Code,
+				Date:         now,
+			})
+			if err != nil {
+				return nil, err
+			}
+			content = header + content
+		}
+		names = append(names, link.File)
+		contents[link.File] = content
+	}
+
+	paths, err := writeFiles(dir, names, contents)
+	for _, name := range kept {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths, err
+}
+
+// markdownSection is a chapter of the single Markdown document.
+type markdownSection struct {
+	chapterLink
+	ID string
+}
+
+// renderDocument writes the tutorial to dir as a single Markdown document
+// named after the project.
+func (r *MarkdownRenderer) renderDocument(t *Tutorial, links []chapterLink, dir string) ([]string, error) {
+	document, err := r.document(t, links)
+	if err != nil {
+		return nil, err
+	}
+	name := slug(t.ProjectName) + ".md"
+	return writeFiles(dir, []string{name}, map[string]string{name: document})
+}
+
+// WriteDocument writes t to w as the single Markdown document written by
+// Render with the SingleFile option, e.g., to stream it to stdout.
+func (r *MarkdownRenderer) WriteDocument(t *Tutorial, w io.Writer) error {
+	links, err := r.names.chapterLinks(t.Chapters, ".md", reservedFiles(t, ".md")...)
+	if err != nil {
+		return err
+	}
+	if r.opts.CrossLinks {
+		crossLink(links)
+	}
+	document, err := r.document(t, links)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, document); err != nil {
+		return fmt.Errorf("failed to write the tutorial: %w", err)
+	}
+	return nil
+}
+
+// document renders the tutorial as a single Markdown document: a table of
+// contents linking to an anchor before each chapter, followed by the
+// chapters in order and the glossary, if any.
+func (r *MarkdownRenderer) document(t *Tutorial, links []chapterLink) (string, error) {
+	// Links between chapter files become links within the document
+	ids := map[string]string{"index.md": "contents"}
+	for _, link := range links {
+		ids[link.File] = fmt.Sprintf("chapter-%02d", link.Index)
+	}
+
+	sections := make([]markdownSection, len(links))
+	for i, link := range links {
+		link.Content = documentChapter(link.Content, ids)
+		sections[i] = markdownSection{chapterLink: link, ID: ids[link.File]}
+	}
+
+	data := map[string]any{"Tutorial": t, "Sections": sections}
+	if len(t.Glossary) > 0 {
+		data["Glossary"] = documentChapter(glossaryMarkdown(t, links), ids)
+	}
+	if r.opts.Diagrams {
+		data["Diagram"] = mermaidGraph(links, t.Relationships)
+	}
+	return r.execute("document.md.tmpl", data)
+}
+
+// markdownLinkRe matches the target of a Markdown link or image.
+var markdownLinkRe = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+
+// documentChapter prepares a chapter's Markdown for the single document.
+// Links to other chapter files (ids maps file names to anchors) are rewritten
+// into links within the document; links to a heading of another chapter
+// keep just the heading's fragment. Code blocks are left alone, and a code
+// fence the chapter leaves open is closed so it cannot swallow the chapters
+// that follow.
+func documentChapter(content string, ids map[string]string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+		lines[i] = markdownLinkRe.ReplaceAllStringFunc(line, func(link string) string {
+			target := markdownLinkRe.FindStringSubmatch(link)[1]
+			file, fragment, hasFragment := strings.Cut(target, "#")
+			id, ok := ids[file]
+			switch {
+			case !ok:
+				return link
+			case hasFragment && fragment != "":
+				return "](#" + fragment + ")"
+			default:
+				return "](#" + id + ")"
+			}
+		})
+	}
+	if fence != "" {
+		lines = append(lines, fence)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *MarkdownRenderer) execute(name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := r.templates.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
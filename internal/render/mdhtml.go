@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// markdownToHTML converts the Markdown written by the LLM to HTML. It covers
+// the CommonMark subset tutorials use: headings, paragraphs, fenced code
+// blocks (syntax highlighted), lists, block quotes, tables, rules, and inline
+// code, emphasis, links and images. Raw HTML in the input is escaped.
+func markdownToHTML(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var sb strings.Builder
+	convertBlocks(&sb, lines)
+	return sb.String()
+}
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRe      = regexp.MustCompile(`^\s{0,3}(-(\s*-){2,}|\*(\s*\*){2,}|_(\s*_){2,})\s*$`)
+	fenceRe     = regexp.MustCompile("^\\s{0,3}(```+|~~~+)\\s*([^\\s`]*)")
+	bulletRe    = regexp.MustCompile(`^(\s*)([-*+])\s+(.*)$`)
+	orderedRe   = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	tableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	quoteRe     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	imageRe     = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkRe      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRe    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	emphasisRe  = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+	strikeRe    = regexp.MustCompile(`~~(.+?)~~`)
+	localLinkRe = regexp.MustCompile(`^([^:/?#]+)\.md(#.*)?$`)
+)
+
+// convertBlocks writes the HTML for a sequence of block-level lines.
+func convertBlocks(sb *strings.Builder, lines []string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(sb, "<p>%s</p>\n", inline(strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case fenceRe.MatchString(line):
+			flush()
+			m := fenceRe.FindStringSubmatch(line)
+			fence, lang := m[1], strings.ToLower(m[2])
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			writeCodeBlock(sb, lang, strings.Join(code, "\n"))
+
+		case headingRe.MatchString(trimmed):
+			flush()
+			m := headingRe.FindStringSubmatch(trimmed)
+			level := len(m[1])
+			fmt.Fprintf(sb, "<h%d id=\"%s\">%s</h%d>\n", level, slug(m[2]), inline(m[2]), level)
+
+		case ruleRe.MatchString(line):
+			flush()
+			sb.WriteString("<hr>\n")
+
+		case quoteRe.MatchString(line):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteRe.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			sb.WriteString("<blockquote>\n")
+			convertBlocks(sb, quoted)
+			sb.WriteString("</blockquote>\n")
+
+		case bulletRe.MatchString(line) || orderedRe.MatchString(line):
+			flush()
+			i = convertList(sb, lines, i) - 1
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			flush()
+			i = convertTable(sb, lines, i) - 1
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+}
+
+// convertList writes the list starting at lines[start] and returns the index
+// of the first line after it. Lines indented deeper than an item's marker
+// belong to that item and are converted recursively, so lists can nest.
+func convertList(sb *strings.Builder, lines []string, start int) int {
+	ordered := orderedRe.MatchString(lines[start]) && !bulletRe.MatchString(lines[start])
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " \t"))
+
+	if ordered {
+		if n := orderedRe.FindStringSubmatch(lines[start])[2]; n != "1" {
+			fmt.Fprintf(sb, "<%s start=\"%s\">\n", tag, strings.TrimLeft(n, "0"))
+		} else {
+			fmt.Fprintf(sb, "<%s>\n", tag)
+		}
+	} else {
+		fmt.Fprintf(sb, "<%s>\n", tag)
+	}
+
+	i := start
+	for i < len(lines) {
+		m := listItem(lines[i], ordered)
+		if m == nil || len(m[1]) != indent {
+			break
+		}
+		item := []string{m[3]}
+		loose := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line continues the item only if indented content follows
+				if i+1 < len(lines) && leadingSpace(lines[i+1]) > indent {
+					item = append(item, "")
+					loose = true
+					continue
+				}
+				break
+			}
+			if leadingSpace(line) <= indent {
+				break
+			}
+			item = append(item, dedent(line, indent+2))
+		}
+
+		var content strings.Builder
+		convertBlocks(&content, item)
+		body := strings.TrimSuffix(content.String(), "\n")
+		if !loose && strings.HasPrefix(body, "<p>") && strings.Count(body, "<p>") == 1 {
+			// Tight list items are rendered without paragraph tags
+			body = strings.Replace(strings.Replace(body, "<p>", "", 1), "</p>", "", 1)
+		}
+		fmt.Fprintf(sb, "<li>%s</li>\n", body)
+
+		// Skip blank lines between items of the same list
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" && i+1 < len(lines) && listItem(lines[i+1], ordered) != nil {
+			i++
+		}
+	}
+	fmt.Fprintf(sb, "</%s>\n", tag)
+	return i
+}
+
+// listItem matches a list item line of the given kind, returning the
+// indentation, marker and text, or nil.
+func listItem(line string, ordered bool) []string {
+	if ordered {
+		return orderedRe.FindStringSubmatch(line)
+	}
+	return bulletRe.FindStringSubmatch(line)
+}
+
+func leadingSpace(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// dedent removes up to n leading spaces from line.
+func dedent(line string, n int) string {
+	for n > 0 && strings.HasPrefix(line, " ") {
+		line, n = line[1:], n-1
+	}
+	return strings.TrimPrefix(line, "\t")
+}
+
+// convertTable writes the table whose header is lines[start] and returns the
+// index of the first line after it.
+func convertTable(sb *strings.Builder, lines []string, start int) int {
+	header := tableCells(lines[start])
+	aligns := make([]string, len(header))
+	for j, cell := range tableCells(lines[start+1]) {
+		if j >= len(aligns) {
+			break
+		}
+		switch left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":"); {
+		case left && right:
+			aligns[j] = ` style="text-align:center"`
+		case right:
+			aligns[j] = ` style="text-align:right"`
+		case left:
+			aligns[j] = ` style="text-align:left"`
+		}
+	}
+
+	sb.WriteString("<table>\n<thead>\n<tr>")
+	for j, cell := range header {
+		fmt.Fprintf(sb, "<th%s>%s</th>", aligns[j], inline(cell))
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	i := start + 2
+	for ; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		cells := tableCells(lines[i])
+		sb.WriteString("<tr>")
+		for j := range header {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			fmt.Fprintf(sb, "<td%s>%s</td>", aligns[j], inline(cell))
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// tableCells splits a table row into its trimmed cells.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for j := range cells {
+		cells[j] = strings.TrimSpace(cells[j])
+	}
+	return cells
+}
+
+// writeCodeBlock writes a fenced code block, highlighting it when the
+// language is known.
+func writeCodeBlock(sb *strings.Builder, lang, code string) {
+	if lang == "mermaid" {
+		// Rendered in the browser by the Mermaid runtime, when the page loads it
+		fmt.Fprintf(sb, "<pre class=\"mermaid\">%s</pre>\n", html.EscapeString(code))
+		return
+	}
+	if lang != "" {
+		fmt.Fprintf(sb, "<pre><code class=\"language-%s\">%s</code></pre>\n", html.EscapeString(lang), highlight(lang, code))
+		return
+	}
+	fmt.Fprintf(sb, "<pre><code>%s</code></pre>\n", html.EscapeString(code))
+}
+
+// inline converts the inline Markdown of a block of text to HTML. Code spans
+// are escaped verbatim; everything else gets emphasis, links and images.
+func inline(text string) string {
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(text, '`')
+		if open < 0 {
+			break
+		}
+		ticks := len(text[open:]) - len(strings.TrimLeft(text[open:], "`"))
+		closing := strings.Index(text[open+ticks:], strings.Repeat("`", ticks))
+		if closing < 0 {
+			break
+		}
+		code := strings.TrimSpace(text[open+ticks : open+ticks+closing])
+		sb.WriteString(inlineText(text[:open]))
+		fmt.Fprintf(&sb, "<code>%s</code>", html.EscapeString(code))
+		text = text[open+ticks+closing+ticks:]
+	}
+	sb.WriteString(inlineText(text))
+	return sb.String()
+}
+
+// inlineText converts emphasis, links and images in text without code spans.
+func inlineText(text string) string {
+	s := html.EscapeString(text)
+	s = imageRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := imageRe.FindStringSubmatch(m)
+		return fmt.Sprintf(`<img src="%s" alt="%s">`, linkTarget(parts[2]), parts[1])
+	})
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, linkTarget(parts[2]), parts[1])
+	})
+	s = strongRe.ReplaceAllString(s, "<strong>$1$2</strong>")
+	s = emphasisRe.ReplaceAllString(s, "<em>$1$2</em>")
+	s = strikeRe.ReplaceAllString(s, "<del>$1</del>")
+	return strings.ReplaceAll(s, "  \n", "<br>\n")
+}
+
+// linkTarget rewrites relative links to Markdown files so they point at the
+// corresponding HTML pages, and neutralizes javascript: URLs.
+func linkTarget(target string) string {
+	if strings.HasPrefix(strings.ToLower(target), "javascript:") {
+		return "#"
+	}
+	if m := localLinkRe.FindStringSubmatch(target); m != nil {
+		return m[1] + ".html" + m[2]
+	}
+	return target
+}
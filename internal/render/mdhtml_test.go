@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "heading and paragraph",
+			in:   "# Config Loader\n\nLoads **settings** from *disk*\nand the env.",
+			want: "<h1 id=\"config-loader\">Config Loader</h1>\n<p>Loads <strong>settings</strong> from <em>disk</em>\nand the env.</p>\n",
+		},
+		{
+			name: "inline code is verbatim",
+			in:   "Call `Load(**path**)` or ``a ` b``.",
+			want: "<p>Call <code>Load(**path**)</code> or <code>a ` b</code>.</p>\n",
+		},
+		{
+			name: "html is escaped",
+			in:   "<script>alert(1)</script> & more",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp; more</p>\n",
+		},
+		{
+			name: "links to chapters point at html pages",
+			in:   "See [the CLI](02_cli.md#flags), [docs](https://example.com/a_b.md) and ![logo](img/logo.png).",
+			want: "<p>See <a href=\"02_cli.html#flags\">the CLI</a>, <a href=\"https://example.com/a_b.md\">docs</a> and <img src=\"img/logo.png\" alt=\"logo\">.</p>\n",
+		},
+		{
+			name: "javascript links are neutralized",
+			in:   "[click](javascript:alert(1))",
+			want: "<p><a href=\"#\">click</a>)</p>\n",
+		},
+		{
+			name: "nested lists",
+			in:   "- one\n- two\n  1. a\n  2. b\n- three",
+			want: "<ul>\n<li>one</li>\n<li>two\n<ol>\n<li>a</li>\n<li>b</li>\n</ol></li>\n<li>three</li>\n</ul>\n",
+		},
+		{
+			name: "ordered list start",
+			in:   "3. c\n4. d",
+			want: "<ol start=\"3\">\n<li>c</li>\n<li>d</li>\n</ol>\n",
+		},
+		{
+			name: "block quote and rule",
+			in:   "> Note: _careful_\n\n---",
+			want: "<blockquote>\n<p>Note: <em>careful</em></p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "table",
+			in:   "| Name | Size |\n|:-----|-----:|\n| a.go | 10 |",
+			want: "<table>\n<thead>\n<tr><th style=\"text-align:left\">Name</th><th style=\"text-align:right\">Size</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align:left\">a.go</td><td style=\"text-align:right\">10</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "mermaid blocks are left for the browser",
+			in:   "```mermaid\ngraph TD\n  A --> B\n```",
+			want: "<pre class=\"mermaid\">graph TD\n  A --&gt; B</pre>\n",
+		},
+		{
+			name: "fenced code without language",
+			in:   "```\n<b>x</b>\n```",
+			want: "<pre><code>&lt;b&gt;x&lt;/b&gt;</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownToHTML(tt.in); got != tt.want {
+				t.Errorf("markdownToHTML() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := markdownToHTML("```go\n// Load reads a file\nfunc Load(path string) int { return 42 + len(\"a<b\") }\n```")
+	for _, want := range []string{
+		`<pre><code class="language-go">`,
+		`<span class="com">// Load reads a file</span>`,
+		`<span class="kw">func</span> Load(path string)`,
+		`<span class="kw">return</span> <span class="num">42</span>`,
+		`<span class="str">&#34;a&lt;b&#34;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected highlighted code to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if got := highlight("py", "def f(): # hi\n    return 'x'"); !strings.Contains(got, `<span class="kw">def</span>`) || !strings.Contains(got, `<span class="com"># hi</span>`) {
+		t.Errorf("Expected the py alias to highlight Python, got %s", got)
+	}
+	if got := highlight("brainfuck", "<+>"); got != "&lt;+&gt;" {
+		t.Errorf("Expected unknown languages to be escaped only, got %s", got)
+	}
+}
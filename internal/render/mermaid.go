@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+)
+
+// DefaultMermaidURL is the Mermaid ES module loaded by HTML pages that
+// contain diagrams. Point Options.MermaidURL at a local copy to view the
+// tutorial offline.
+const DefaultMermaidURL = "https://cdn.jsdelivr.net/npm/mermaid@11/dist/mermaid.esm.min.mjs"
+
+// mermaidGraph returns a Mermaid flowchart of how the abstractions connect,
+// or "" if there are no relationships. Nodes follow the chapter order so the
+// diagram is stable across runs; abstractions without a chapter are added
+// after them.
+func mermaidGraph(chapters []chapterLink, relationships []analysis.Relationship) string {
+	if len(relationships) == 0 {
+		return ""
+	}
+
+	ids := make(map[string]string)
+	var nodes []string
+	node := func(name string) string {
+		key := strings.ToLower(name)
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := fmt.Sprintf("A%d", len(ids))
+		ids[key] = id
+		nodes = append(nodes, fmt.Sprintf("    %s[\"%s\"]", id, mermaidText(name)))
+		return id
+	}
+	for _, chapter := range chapters {
+		node(chapter.Abstraction)
+	}
+
+	var edges []string
+	for _, rel := range relationships {
+		from, to := node(rel.From), node(rel.To)
+		if rel.Label != "" {
+			edges = append(edges, fmt.Sprintf("    %s -->|\"%s\"| %s", from, mermaidText(rel.Label), to))
+		} else {
+			edges = append(edges, fmt.Sprintf("    %s --> %s", from, to))
+		}
+	}
+
+	return "graph TD\n" + strings.Join(nodes, "\n") + "\n" + strings.Join(edges, "\n")
+}
+
+// mermaidText makes text safe inside a quoted Mermaid label.
+func mermaidText(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(text)
+}
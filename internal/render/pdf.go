@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pdfConverter is an external program that prints an HTML file to PDF,
+// turning its headings into bookmarks.
+type pdfConverter struct {
+	name string
+	args func(input, output string) []string
+}
+
+// pdfConverters are the supported converters, in order of preference.
+var pdfConverters = []pdfConverter{
+	{name: "wkhtmltopdf", args: wkhtmltopdfArgs},
+	{name: "chromium", args: chromeArgs},
+	{name: "chromium-browser", args: chromeArgs},
+	{name: "google-chrome", args: chromeArgs},
+	{name: "google-chrome-stable", args: chromeArgs},
+}
+
+func wkhtmltopdfArgs(input, output string) []string {
+	return []string{"--quiet", "--enable-local-file-access", "--outline", "--outline-depth", "2", input, output}
+}
+
+func chromeArgs(input, output string) []string {
+	return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--generate-pdf-document-outline",
+		"--print-to-pdf=" + output, "file://" + filepath.ToSlash(input)}
+}
+
+// PDFRenderer writes a tutorial as a single PDF document: the chapters are
+// rendered as one HTML document, which an external converter (wkhtmltopdf,
+// or Chromium/Google Chrome in headless mode) prints to PDF, with the
+// chapter headings as bookmarks. Mermaid diagrams are shown as source, since
+// the converters do not run the Mermaid runtime.
+type PDFRenderer struct {
+	templates *template.Template
+	css       template.CSS
+	names     *namer
+	crossLink bool   // Link mentions of other chapters' abstractions (see Options.CrossLinks)
+	converter string // Path of the converter program
+	args      func(input, output string) []string
+}
+
+// NewPDFRenderer creates a PDF renderer using the first converter found on
+// the PATH, and fails if there is none.
+func NewPDFRenderer(opts Options) (*PDFRenderer, error) {
+	path, converter, err := findPDFConverter()
+	if err != nil {
+		return nil, err
+	}
+	templates, err := template.ParseFS(templatesFS, "templates/html/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html templates: %w", err)
+	}
+	css, err := opts.Style.stylesheetCSS()
+	if err != nil {
+		return nil, err
+	}
+	names, err := newNamer(opts.OutputName)
+	if err != nil {
+		return nil, err
+	}
+	return &PDFRenderer{templates: templates, css: template.CSS(css), names: names, crossLink: opts.CrossLinks, converter: path, args: converter.args}, nil
+}
+
+// findPDFConverter returns the path of the first supported converter on the PATH.
+func findPDFConverter() (string, pdfConverter, error) {
+	names := make([]string, len(pdfConverters))
+	for i, converter := range pdfConverters {
+		if path, err := exec.LookPath(converter.name); err == nil {
+			return path, converter, nil
+		}
+		names[i] = converter.name
+	}
+	return "", pdfConverter{}, fmt.Errorf("PDF output requires one of %s on the PATH; install wkhtmltopdf or Chromium, or use --format html",
+		strings.Join(names, ", "))
+}
+
+// documentSection is a chapter of the single HTML document.
+type documentSection struct {
+	ID      string
+	Title   string
+	Content template.HTML
+}
+
+// Render implements Renderer.
+func (r *PDFRenderer) Render(t *Tutorial, dir string) ([]string, error) {
+	if len(t.Keep) > 0 {
+		return nil, fmt.Errorf("the pdf format cannot keep chapters, since it writes them all to one document")
+	}
+	document, err := r.document(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp("", "code-decoder-pdf-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	input := filepath.Join(tmp, "tutorial.html")
+	if err := os.WriteFile(input, []byte(document), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", input, err)
+	}
+
+	output, err := filepath.Abs(filepath.Join(dir, slug(t.ProjectName)+".pdf"))
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(r.converter, r.args(input, output)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed to convert the tutorial to PDF: %w: %s", filepath.Base(r.converter), err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(output); err != nil {
+		return nil, fmt.Errorf("%s did not write %s", filepath.Base(r.converter), output)
+	}
+	return []string{output}, nil
+}
+
+// document returns the tutorial as a single HTML document, with a table of
+// contents linking to each chapter and to the glossary, if any, which ends
+// the document.
+func (r *PDFRenderer) document(t *Tutorial) (string, error) {
+	// Links between chapter pages become links within the document
+	links, err := r.names.chapterLinks(t.Chapters, ".html", reservedFiles(t, ".html")...)
+	if err != nil {
+		return "", err
+	}
+	if r.crossLink {
+		crossLink(links)
+	}
+	ids := make(map[string]string, len(links))
+	for _, link := range links {
+		ids[link.File] = fmt.Sprintf("chapter-%02d", link.Index)
+	}
+	ids["index.html"] = "contents"
+
+	sections := make([]documentSection, len(links))
+	for i, link := range links {
+		id := ids[link.File]
+		content := localizeIDs(markdownToHTML(link.Content), id, ids)
+		sections[i] = documentSection{ID: id, Title: link.Title, Content: template.HTML(content)}
+	}
+
+	var sb strings.Builder
+	data := map[string]any{
+		"Tutorial": t,
+		"Title":    "Tutorial: " + t.ProjectName,
+		"CSS":      r.css,
+		"Sections": sections,
+	}
+	if len(t.Glossary) > 0 {
+		data["Glossary"] = template.HTML(localizeIDs(markdownToHTML(glossaryMarkdown(t, links)), glossaryName, ids))
+	}
+	if err := r.templates.ExecuteTemplate(&sb, "document.html.tmpl", data); err != nil {
+		return "", fmt.Errorf("failed to render document.html.tmpl: %w", err)
+	}
+	return sb.String(), nil
+}
+
+var (
+	idAttrRe   = regexp.MustCompile(` id="([^"]*)"`)
+	hrefAttrRe = regexp.MustCompile(` href="([^"]*)"`)
+)
+
+// localizeIDs prefixes the heading ids of a chapter's HTML with the
+// chapter's id, so they stay unique in the document, and rewrites links to
+// chapter pages (ids maps page files to chapter ids) into document anchors.
+func localizeIDs(content, id string, ids map[string]string) string {
+	content = idAttrRe.ReplaceAllString(content, ` id="`+id+`-$1"`)
+	return hrefAttrRe.ReplaceAllStringFunc(content, func(attr string) string {
+		href := hrefAttrRe.FindStringSubmatch(attr)[1]
+		page, fragment, _ := strings.Cut(href, "#")
+		switch target, ok := ids[page]; {
+		case page == "" && fragment != "":
+			return ` href="#` + id + "-" + fragment + `"`
+		case ok && fragment != "" && target != "contents":
+			return ` href="#` + target + "-" + fragment + `"`
+		case ok:
+			return ` href="#` + target + `"`
+		}
+		return attr
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// RecordFile is the name of the record kept in an output directory of the
+// files the tutorial written there consists of, so that a later run can
+// tell them from files of the user's own.
+const RecordFile = ".code-decoder.json"
+
+// legacyIndexes are the index files of tutorials written before records
+// were kept, which mark a directory as holding a tutorial too.
+var legacyIndexes = []string{"index.md", "index.html"}
+
+// Record lists the files of the tutorial written to an output directory.
+type Record struct {
+	ProjectName string   `json:"project_name"`
+	Files       []string `json:"files"`           // Names of the files, relative to the directory
+	Style       *Style   `json:"style,omitempty"` // Look of HTML and PDF tutorials
+}
+
+// LoadRecord reads the record of the output directory dir. The error
+// matches fs.ErrNotExist when there is none.
+func LoadRecord(dir string) (*Record, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RecordFile))
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Join(dir, RecordFile), err)
+	}
+	return &r, nil
+}
+
+// HasTutorial reports whether the output directory dir holds a tutorial
+// written earlier: it has a record or, for tutorials written before records
+// were kept, an index file. A missing directory holds none.
+func HasTutorial(dir string) (bool, error) {
+	for _, name := range append([]string{RecordFile}, legacyIndexes...) {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, fmt.Errorf("failed to inspect output directory: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// UpdateRecord records paths, as returned by Renderer.Render, as the files
+// of the tutorial of project in dir, written with style (nil for formats
+// without one). The files of the previous record that
+// are not among them, such as the chapters of abstractions that no longer
+// exist or the files of another format, are removed; files the record does
+// not list are left alone. It returns the paths of the files removed.
+func UpdateRecord(dir, project string, style *Style, paths []string) ([]string, error) {
+	record := &Record{ProjectName: project, Files: make([]string, len(paths)), Style: style}
+	for i, path := range paths {
+		record.Files[i] = filepath.Base(path)
+	}
+
+	var removed []string
+	previous, err := LoadRecord(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		for _, name := range previous.Files {
+			// Only plain names are removed, whatever the record says
+			if name != filepath.Base(name) || name == "." || name == ".." || slices.Contains(record.Files, name) {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			switch err := os.Remove(path); {
+			case err == nil:
+				removed = append(removed, path)
+			case !errors.Is(err, fs.ErrNotExist):
+				return removed, fmt.Errorf("failed to remove stale file: %w", err)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return removed, fmt.Errorf("failed to encode output record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, RecordFile), append(data, '\n'), 0644); err != nil {
+		return removed, fmt.Errorf("failed to write output record: %w", err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestUpdateRecord(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "out")
+	if has, err := HasTutorial(dir); has || err != nil {
+		t.Fatalf("HasTutorial() of a missing directory = %v, %v", has, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range []string{"index.md", "01_a.md", "02_b.md", "notes.txt", "../outside.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if has, err := HasTutorial(dir); !has || err != nil {
+		t.Errorf("Expected a directory with an index.md to hold a tutorial, got %v, %v", has, err)
+	}
+	if _, err := LoadRecord(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist before recording, got %v", err)
+	}
+
+	// Only the files of the previous record are removed, and never outside the directory
+	previous := `{"project_name":"demo","files":["index.md","01_a.md","02_b.md","../outside.md"]}`
+	if err := os.WriteFile(filepath.Join(dir, RecordFile), []byte(previous), 0644); err != nil {
+		t.Fatalf("Failed to write record: %v", err)
+	}
+	removed, err := UpdateRecord(dir, "demo", &Style{Theme: "dark"}, []string{filepath.Join(dir, "index.md"), filepath.Join(dir, "01_a.md")})
+	if err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+	if want := []string{filepath.Join(dir, "02_b.md")}; !slices.Equal(removed, want) {
+		t.Errorf("Removed %q, want %q", removed, want)
+	}
+	for name, want := range map[string]bool{"02_b.md": false, "notes.txt": true, "../outside.md": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("Expected %s to exist: %v, got %v", name, want, err)
+		}
+	}
+
+	record, err := LoadRecord(dir)
+	if err != nil || record.ProjectName != "demo" || !slices.Equal(record.Files, []string{"index.md", "01_a.md"}) ||
+		record.Style == nil || record.Style.Theme != "dark" {
+		t.Errorf("LoadRecord() = %+v, %v", record, err)
+	}
+}
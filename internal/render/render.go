@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package render writes generated tutorials to disk in the supported output formats.
+package render
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+// templatesFS holds the default templates of every output format.
+//
+//go:embed templates
+var templatesFS embed.FS
+
+// Tutorial is a generated tutorial ready to be rendered.
+type Tutorial struct {
+	ProjectName   string
+	Chapters      []generation.Chapter
+	Relationships []analysis.Relationship    // How the abstractions connect, drawn as a diagram
+	Analysis      *analysis.Analysis         // Analysis the chapters were written from, exported by the json format
+	Audience      string                     // Who the chapters are written for, recorded in their front matter; empty records none
+	Glossary      []generation.GlossaryEntry // Key terms, written to a glossary page linked from the index; empty writes none
+
+	// Keep holds the indexes of chapters whose files are left as they are
+	// in the output directory rather than written, such as when only some
+	// chapters are regenerated. They are listed in the index and linked from
+	// the other chapters all the same, and so is a glossary already in the
+	// directory when t has none. Only the formats writing a file per chapter support it.
+	Keep []int
+
+	// Language is the language the chapters are written in, recorded in the
+	// output's metadata: the front matter of the Markdown index and the lang
+	// attribute of HTML pages. The zero value records none.
+	Language generation.Language
+}
+
+// Options controls optional parts of the rendered output.
+type Options struct {
+	Diagrams   bool   // Draw the abstraction relationships as a Mermaid diagram
+	CrossLinks bool   // Link the first mention of another chapter's abstraction in each chapter to it
+	MermaidURL string // Mermaid ES module loaded by HTML pages (default DefaultMermaidURL)
+	SingleFile bool   // Write Markdown as one document instead of a file per chapter
+	Style      Style  // Look of the HTML pages and PDF documents
+
+	// OutputName is the text/template naming chapter files, executed with
+	// an OutputNameData; empty uses DefaultOutputName. The format's file
+	// extension is added to the name.
+	OutputName string
+
+	// FrontMatter writes a block of YAML front matter at the top of each
+	// Markdown chapter file, for static site generators such as Hugo and
+	// Jekyll. FrontMatterTemplate is the text/template of its fields,
+	// executed with a FrontMatterData; empty uses DefaultFrontMatter.
+	FrontMatter         bool
+	FrontMatterTemplate string
+}
+
+// DefaultOutputName is the default template naming chapter files, e.g.,
+// "01_config-loader".
+const DefaultOutputName = `{{printf "%02d" .Index}}_{{.Slug}}`
+
+// OutputNameData is the data the output name template is executed with.
+type OutputNameData struct {
+	Index int    // 1-based position of the chapter in the tutorial
+	Slug  string // Lowercase, hyphen-separated form of the title
+	Title string // Chapter title
+}
+
+// Renderer writes a tutorial in a specific output format.
+type Renderer interface {
+	// Render writes t to dir, creating it if needed, and returns the paths
+	// of the files written.
+	Render(t *Tutorial, dir string) ([]string, error)
+}
+
+// New returns the renderer for an output format.
+func New(format string, opts Options) (Renderer, error) {
+	if opts.SingleFile && format != "markdown" && format != "md" {
+		return nil, fmt.Errorf("single-file output is only supported for the markdown format, not %q", format)
+	}
+	if opts.FrontMatter && format != "markdown" && format != "md" {
+		return nil, fmt.Errorf("front matter is only supported for the markdown format, not %q", format)
+	}
+	if opts.FrontMatter && opts.SingleFile {
+		return nil, fmt.Errorf("front matter is written to each chapter file, so it cannot be combined with single-file output")
+	}
+	switch format {
+	case "markdown", "md":
+		return NewMarkdownRenderer(opts)
+	case "html":
+		return NewHTMLRenderer(opts)
+	case "pdf":
+		return NewPDFRenderer(opts)
+	case "json":
+		return NewJSONRenderer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %q (supported: markdown, html, pdf, json)", format)
+	}
+}
+
+// chapterExtensions are the extensions stripped from the names given by an
+// output name template before adding the format's, so that a template
+// written for one format (e.g., "{{.Slug}}.md") works for the others.
+var chapterExtensions = []string{".md", ".markdown", ".html", ".htm"}
+
+// namer names chapter files with an output name template.
+type namer struct {
+	tmpl *template.Template
+}
+
+// newNamer parses the output name template text (DefaultOutputName if
+// empty), and checks that it names a sample chapter.
+func newNamer(text string) (*namer, error) {
+	if text == "" {
+		text = DefaultOutputName
+	}
+	tmpl, err := template.New("output-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output name template: %w", err)
+	}
+	n := &namer{tmpl: tmpl}
+	if _, err := n.chapterFile(generation.Chapter{Index: 1, Title: "Chapter"}, ".md"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// chapterFile returns the file name of a chapter, e.g., "01_config-loader.md".
+// Characters that are not safe in file names are replaced with hyphens.
+func (n *namer) chapterFile(chapter generation.Chapter, ext string) (string, error) {
+	var sb strings.Builder
+	data := OutputNameData{Index: chapter.Index, Slug: slug(chapter.Title), Title: chapter.Title}
+	if err := n.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("invalid output name template: %w", err)
+	}
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '-'
+		}
+		return r
+	}, sb.String())
+	name = strings.Trim(name, " .")
+	for _, known := range chapterExtensions {
+		if len(name) > len(known) && strings.EqualFold(name[len(name)-len(known):], known) {
+			name = name[:len(name)-len(known)]
+			break
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("invalid output name template: it gives chapter %d an empty file name", chapter.Index)
+	}
+	return name + ext, nil
+}
+
+// chapterLinks names the file of each chapter, which must be distinct from
+// each other and from reserved, the other files written by the renderer.
+func (n *namer) chapterLinks(chapters []generation.Chapter, ext string, reserved ...string) ([]chapterLink, error) {
+	links := make([]chapterLink, len(chapters))
+	owners := make(map[string]string, len(chapters)+len(reserved))
+	for _, name := range reserved {
+		owners[strings.ToLower(name)] = name
+	}
+	for i, chapter := range chapters {
+		file, err := n.chapterFile(chapter, ext)
+		if err != nil {
+			return nil, err
+		}
+		// Compared ignoring case, for case-insensitive file systems
+		if owner, ok := owners[strings.ToLower(file)]; ok {
+			return nil, fmt.Errorf("the output name template names chapter %d %s, which is already used by %s; include {{.Index}} in the template",
+				chapter.Index, file, owner)
+		}
+		owners[strings.ToLower(file)] = fmt.Sprintf("chapter %d", chapter.Index)
+		links[i] = chapterLink{Chapter: chapter, File: file}
+	}
+	return links, nil
+}
+
+// reservedFiles returns the names of the files with extension ext that a
+// renderer writes besides the chapters of t: the index and, when t has one
+// or keeps that of dir, the glossary.
+func reservedFiles(t *Tutorial, ext string, others ...string) []string {
+	reserved := append([]string{"index" + ext}, others...)
+	if len(t.Glossary) > 0 || len(t.Keep) > 0 {
+		reserved = append(reserved, glossaryName+ext)
+	}
+	return reserved
+}
+
+// keptFiles returns the names of the files of t that are kept as they are in
+// dir rather than written (see Tutorial.Keep): those of the chapters of
+// t.Keep, among links, and the glossary with extension ext that dir holds
+// when t has none. It fails if a kept chapter has no file in dir.
+func keptFiles(t *Tutorial, links []chapterLink, dir, ext string) ([]string, error) {
+	if len(t.Keep) == 0 {
+		return nil, nil
+	}
+	var kept []string
+	for _, link := range links {
+		if !slices.Contains(t.Keep, link.Index) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, link.File)); err != nil {
+			return nil, fmt.Errorf("cannot keep chapter %d (%s), since %s has no file %s: regenerate it too", link.Index, link.Title, dir, link.File)
+		}
+		kept = append(kept, link.File)
+	}
+	if len(t.Glossary) == 0 {
+		if _, err := os.Stat(filepath.Join(dir, glossaryName+ext)); err == nil {
+			kept = append(kept, glossaryName+ext)
+		}
+	}
+	return kept, nil
+}
+
+// ProjectDir returns the name of the directory the tutorial of project is
+// written to within a shared output directory: the project name as a
+// lowercase, hyphen-separated name that is safe on any file system.
+func ProjectDir(project string) string {
+	if dir := slugOr(project, ""); dir != "" {
+		return dir
+	}
+	return "tutorial"
+}
+
+// slug turns a title into a lowercase, hyphen-separated file name component.
+func slug(title string) string {
+	return slugOr(title, "chapter")
+}
+
+// slugOr is slug with fallback returned for titles without any letters or
+// digits.
+func slugOr(title, fallback string) string {
+	var sb strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	if sb.Len() == 0 {
+		return fallback
+	}
+	return sb.String()
+}
+
+// writeFiles writes each file's content to dir, creating dir if needed,
+// and returns the paths written in order.
+func writeFiles(dir string, names []string, contents map[string]string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents[name]), 0644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
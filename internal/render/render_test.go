@@ -0,0 +1,547 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ksylvan/code-decoder/internal/analysis"
+	"github.com/ksylvan/code-decoder/internal/generation"
+)
+
+func testTutorial() *Tutorial {
+	return &Tutorial{
+		ProjectName: "demo",
+		Chapters: []generation.Chapter{
+			{Index: 1, Title: "Config Loader", Abstraction: "Config Loader", Content: "# Config Loader\n\nLoads settings."},
+			{Index: 2, Title: "CLI", Abstraction: "CLI", Content: "# CLI\n\nParses flags."},
+		},
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "Config Loader", want: "config-loader"},
+		{title: "  HTTP/2 Client (v2)  ", want: "http-2-client-v2"},
+		{title: "Überblick", want: "überblick"},
+		{title: "!!!", want: "chapter"},
+	}
+
+	for _, tt := range tests {
+		if got := slug(tt.title); got != tt.want {
+			t.Errorf("slug(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	tests := []struct {
+		project string
+		want    string
+	}{
+		{project: "My Project", want: "my-project"},
+		{project: "../../etc", want: "etc"},
+		{project: "acme/widgets.v2", want: "acme-widgets-v2"},
+		{project: "...", want: "tutorial"},
+	}
+
+	for _, tt := range tests {
+		if got := ProjectDir(tt.project); got != tt.want {
+			t.Errorf("ProjectDir(%q) = %q, want %q", tt.project, got, tt.want)
+		}
+	}
+}
+
+func TestOutputNameTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		ext      string
+		want     []string
+		wantErr  string
+	}{
+		{template: "", ext: ".md", want: []string{"01_config-loader.md", "02_cli.md"}},
+		{template: "{{.Index}}-{{.Slug}}.md", ext: ".md", want: []string{"1-config-loader.md", "2-cli.md"}},
+		{template: "{{.Index}}-{{.Slug}}.md", ext: ".html", want: []string{"1-config-loader.html", "2-cli.html"}},
+		{template: "{{.Index}} {{.Title}}", ext: ".md", want: []string{"1 Config Loader.md", "2 CLI.md"}},
+		{template: "../{{.Index}}/{{.Slug}}", ext: ".md", want: []string{"-1-config-loader.md", "-2-cli.md"}},
+		{template: "{{.Slug", ext: ".md", wantErr: "invalid output name template"},
+		{template: "{{.Name}}", ext: ".md", wantErr: "invalid output name template"},
+		{template: "{{if false}}x{{end}}", ext: ".md", wantErr: "empty file name"},
+		{template: "chapter", ext: ".md", wantErr: "already used by chapter 1"},
+		{template: "{{if eq .Index 2}}index{{else}}a{{end}}", ext: ".md", wantErr: "already used by index.md"},
+	}
+
+	chapters := testTutorial().Chapters
+	for _, tt := range tests {
+		names, err := newNamer(tt.template)
+		var links []chapterLink
+		if err == nil {
+			links, err = names.chapterLinks(chapters, tt.ext, "index"+tt.ext)
+		}
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Template %q: expected an error containing %q, got %v", tt.template, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Template %q: unexpected error %v", tt.template, err)
+			continue
+		}
+		var got []string
+		for _, link := range links {
+			got = append(got, link.File)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Template %q: files = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if _, err := New("html", Options{OutputName: "{{.Nope"}); err == nil {
+		t.Error("Expected New() to reject an invalid output name template")
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out", "tutorial")
+	r, err := New("markdown", Options{Diagrams: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	paths, err := r.Render(testTutorial(), dir)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := []string{"index.md", "01_config-loader.md", "02_cli.md"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %d files, got %v", len(want), paths)
+	}
+	for i, name := range want {
+		if paths[i] != filepath.Join(dir, name) {
+			t.Errorf("Expected file %s, got %s", name, paths[i])
+		}
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	index := read("index.md")
+	for _, s := range []string{"# Tutorial: demo", "1. [Config Loader](01_config-loader.md)", "2. [CLI](02_cli.md)"} {
+		if !strings.Contains(index, s) {
+			t.Errorf("Expected index.md to contain %q, got:\n%s", s, index)
+		}
+	}
+
+	first := read("01_config-loader.md")
+	if !strings.HasPrefix(first, "# Config Loader\n\nLoads settings.") {
+		t.Errorf("Expected the chapter content first, got:\n%s", first)
+	}
+	if strings.Contains(first, "Previous:") || !strings.Contains(first, "Next: [CLI](02_cli.md)") {
+		t.Errorf("Unexpected navigation in the first chapter:\n%s", first)
+	}
+	last := read("02_cli.md")
+	if !strings.Contains(last, "Previous: [Config Loader](01_config-loader.md)") || strings.Contains(last, "Next:") {
+		t.Errorf("Unexpected navigation in the last chapter:\n%s", last)
+	}
+}
+
+func TestNewUnsupportedFormat(t *testing.T) {
+	_, err := New("docx", Options{})
+	if err == nil || !strings.Contains(err.Error(), "markdown, html, pdf") {
+		t.Errorf("New() expected an error listing the supported formats, got %v", err)
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New("html", Options{Diagrams: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	paths, err := r.Render(testTutorial(), dir)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := []string{"index.html", "01_config-loader.html", "02_cli.html", "style.css"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %d files, got %v", len(want), paths)
+	}
+	for i, name := range want {
+		if paths[i] != filepath.Join(dir, name) {
+			t.Errorf("Expected file %s, got %s", name, paths[i])
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "01_config-loader.html"))
+	if err != nil {
+		t.Fatalf("Failed to read chapter: %v", err)
+	}
+	page := string(data)
+	for _, s := range []string{
+		`<link rel="stylesheet" href="style.css">`,
+		`<h1 id="config-loader">Config Loader</h1>`,
+		`<a href="01_config-loader.html" class="current" aria-current="page">Config Loader</a>`,
+		`<a href="02_cli.html">CLI</a>`,
+		`<a class="next" href="02_cli.html">CLI →</a>`,
+		`<a href="index.html">Table of Contents</a>`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("Expected chapter page to contain %q, got:\n%s", s, page)
+		}
+	}
+	if strings.Contains(page, "/"+dir) || strings.Contains(page, `class="prev"`) || strings.Contains(page, "mermaid") {
+		t.Errorf("Expected only relative links and no previous link in the first chapter:\n%s", page)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if !strings.Contains(string(index), `<li><a href="02_cli.html">CLI</a></li>`) {
+		t.Errorf("Expected the table of contents to link the chapters, got:\n%s", index)
+	}
+}
+
+func TestHTMLThemes(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "brand.css")
+	if err := os.WriteFile(custom, []byte("body { color: teal; }\n"), 0644); err != nil {
+		t.Fatalf("Failed to write stylesheet: %v", err)
+	}
+	tests := []struct {
+		name    string
+		style   Style
+		want    []string
+		notWant string
+	}{
+		{name: "default", want: []string{"--bg: #ffffff", ".sidebar {"}, notWant: "prefers-color-scheme"},
+		{name: "dark", style: Style{Theme: "dark"}, want: []string{"--bg: #0d1117", ".sidebar {"}},
+		{name: "minimal", style: Style{Theme: "minimal"}, want: []string{"Georgia", ".sidebar {"}},
+		{name: "custom", style: Style{Theme: "dark", CSS: custom}, want: []string{"body { color: teal; }"}, notWant: ".sidebar {"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New("html", Options{Style: tt.style})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			dir := t.TempDir()
+			if _, err := r.Render(testTutorial(), dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, stylesheet))
+			if err != nil {
+				t.Fatalf("Failed to read stylesheet: %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("Expected the stylesheet to contain %q, got:\n%s", s, data)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(string(data), tt.notWant) {
+				t.Errorf("Expected the stylesheet not to contain %q, got:\n%s", tt.notWant, data)
+			}
+		})
+	}
+
+	if _, err := New("html", Options{Style: Style{Theme: "solarized"}}); err == nil || !strings.Contains(err.Error(), "available: light, dark, minimal") {
+		t.Errorf("Expected an error for an unknown theme, got %v", err)
+	}
+	if _, err := New("html", Options{Style: Style{CSS: filepath.Join(t.TempDir(), "missing.css")}}); err == nil {
+		t.Error("Expected an error for a missing stylesheet")
+	}
+}
+
+func TestMermaidGraph(t *testing.T) {
+	links := []chapterLink{
+		{Chapter: generation.Chapter{Index: 1, Title: "CLI", Abstraction: "CLI"}},
+		{Chapter: generation.Chapter{Index: 2, Title: "Config", Abstraction: "Config"}},
+	}
+	rels := []analysis.Relationship{
+		{From: "CLI", To: "config", Label: `reads "settings"`},
+		{From: "Config", To: "Env"},
+	}
+
+	want := "graph TD\n" +
+		"    A0[\"CLI\"]\n" +
+		"    A1[\"Config\"]\n" +
+		"    A2[\"Env\"]\n" +
+		"    A0 -->|\"reads #quot;settings#quot;\"| A1\n" +
+		"    A1 --> A2"
+	if got := mermaidGraph(links, rels); got != want {
+		t.Errorf("mermaidGraph() =\n%s\nwant\n%s", got, want)
+	}
+	if got := mermaidGraph(links, nil); got != "" {
+		t.Errorf("Expected no diagram without relationships, got %q", got)
+	}
+}
+
+func TestDiagrams(t *testing.T) {
+	tutorial := testTutorial()
+	tutorial.Relationships = []analysis.Relationship{{From: "CLI", To: "Config Loader", Label: "uses"}}
+
+	tests := []struct {
+		format string
+		opts   Options
+		file   string
+		want   []string
+		absent string
+	}{
+		{format: "markdown", opts: Options{Diagrams: true}, file: "index.md", want: []string{"```mermaid\ngraph TD\n", "A1 -->|\"uses\"| A0"}},
+		{format: "markdown", opts: Options{}, file: "index.md", absent: "mermaid"},
+		{
+			format: "html", opts: Options{Diagrams: true, MermaidURL: "mermaid.esm.min.mjs"}, file: "index.html",
+			want: []string{`<pre class="mermaid">`, `import mermaid from "mermaid.esm.min.mjs";`},
+		},
+		{format: "html", opts: Options{}, file: "index.html", absent: "mermaid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s diagrams=%v", tt.format, tt.opts.Diagrams), func(t *testing.T) {
+			dir := t.TempDir()
+			r, err := New(tt.format, tt.opts)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := r.Render(tutorial, dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", tt.file, err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Expected %s to contain %q, got:\n%s", tt.file, want, data)
+				}
+			}
+			if tt.absent != "" && strings.Contains(string(data), tt.absent) {
+				t.Errorf("Expected %s not to contain %q, got:\n%s", tt.file, tt.absent, data)
+			}
+		})
+	}
+}
+
+func TestPDFRenderer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake converter is a shell script")
+	}
+	path := os.Getenv("PATH")
+
+	// Without a converter on the PATH, the format is rejected up front
+	t.Setenv("PATH", t.TempDir())
+	if _, err := New("pdf", Options{}); err == nil || !strings.Contains(err.Error(), "wkhtmltopdf") {
+		t.Fatalf("New() expected an error naming the converters, got %v", err)
+	}
+
+	// A fake wkhtmltopdf copies the HTML document to the output file
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor last; do :; done\neval input=\\${$(($# - 1))}\ncp \"$input\" \"$last\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "wkhtmltopdf"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake converter: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+
+	r, err := New("pdf", Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tutorial := testTutorial()
+	tutorial.Chapters[1].Content = "# CLI\n\n## Flags\n\nSee [the loader](01_config-loader.md#config-loader), [flags](#flags) and [the contents](index.md)."
+	dir := t.TempDir()
+	paths, err := r.Render(tutorial, dir)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(dir, "demo.pdf") {
+		t.Fatalf("Expected demo.pdf, got %v", paths)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	document := string(data)
+	for _, s := range []string{
+		`<li><a href="#chapter-01">Config Loader</a></li>`,
+		`<section class="chapter" id="chapter-02">`,
+		`<h1 id="chapter-01-config-loader">Config Loader</h1>`,
+		`<h2 id="chapter-02-flags">Flags</h2>`,
+		`<a href="#chapter-01-config-loader">the loader</a>`,
+		`<a href="#chapter-02-flags">flags</a>`,
+		`<a href="#contents">the contents</a>`,
+	} {
+		if !strings.Contains(document, s) {
+			t.Errorf("Expected the document to contain %q, got:\n%s", s, document)
+		}
+	}
+}
+
+func TestMarkdownRendererSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New("markdown", Options{SingleFile: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tutorial := testTutorial()
+	// The first chapter leaves its code fence open
+	tutorial.Chapters[0].Content = "# Config Loader\n\nSee [the CLI](02_cli.md).\n\n```go\n// [not a link](02_cli.md)\nfunc Load() {}"
+	tutorial.Chapters[1].Content = "# CLI\n\nBack to [loading](01_config-loader.md#config-loader), [the contents](index.md) or [the docs](https://example.com/a.md)."
+	paths, err := r.Render(tutorial, dir)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(dir, "demo.md") {
+		t.Fatalf("Expected only demo.md, got %v", paths)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read document: %v", err)
+	}
+	document := string(data)
+	for _, s := range []string{
+		"# Tutorial: demo",
+		"1. [Config Loader](#chapter-01)\n2. [CLI](#chapter-02)",
+		"<a id=\"chapter-01\"></a>\n\n# Config Loader",
+		"See [the CLI](#chapter-02).",
+		"// [not a link](02_cli.md)\nfunc Load() {}\n```\n",
+		"<a id=\"chapter-02\"></a>\n\n# CLI",
+		"[loading](#config-loader)",
+		"[the contents](#contents)",
+		"[the docs](https://example.com/a.md)",
+	} {
+		if !strings.Contains(document, s) {
+			t.Errorf("Expected the document to contain %q, got:\n%s", s, document)
+		}
+	}
+
+	// Streaming the document writes the same content
+	var sb strings.Builder
+	if err := r.(*MarkdownRenderer).WriteDocument(tutorial, &sb); err != nil {
+		t.Fatalf("WriteDocument() error = %v", err)
+	}
+	if sb.String() != document {
+		t.Errorf("Expected WriteDocument to write the rendered document, got:\n%s", sb.String())
+	}
+
+	if _, err := New("html", Options{SingleFile: true}); err == nil {
+		t.Error("New() expected an error for single-file HTML output")
+	}
+}
+
+func TestLanguageMetadata(t *testing.T) {
+	tests := []struct {
+		format   string
+		opts     Options
+		file     string
+		language generation.Language
+		want     string
+		absent   string
+	}{
+		{format: "markdown", file: "index.md", language: generation.Language{Name: "Chinese", Code: "zh"},
+			want: "---\nlanguage: \"Chinese\"\nlanguage_code: \"zh\"\n---\n\n# Tutorial: demo"},
+		{format: "markdown", file: "index.md", language: generation.Language{Name: "Klingon"},
+			want: "---\nlanguage: \"Klingon\"\n---\n\n# Tutorial: demo", absent: "language_code"},
+		{format: "markdown", file: "index.md", absent: "---\nlanguage"},
+		{format: "markdown", opts: Options{SingleFile: true}, file: "demo.md", language: generation.Language{Name: "French", Code: "fr"},
+			want: "language_code: \"fr\"\n---\n\n# Tutorial: demo"},
+		{format: "html", file: "index.html", language: generation.Language{Name: "Chinese", Code: "zh"}, want: `<html lang="zh">`},
+		{format: "html", file: "02_cli.html", language: generation.Language{Name: "Portuguese (BR)", Code: "pt-BR"}, want: `<html lang="pt-BR">`},
+		{format: "html", file: "index.html", language: generation.Language{Name: "Klingon"}, want: "<html>"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		r, err := New(tt.format, tt.opts)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		tutorial := testTutorial()
+		tutorial.Language = tt.language
+		if _, err := r.Render(tutorial, dir); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.file, err)
+		}
+		if tt.want != "" && !strings.Contains(string(data), tt.want) {
+			t.Errorf("Expected %s for %+v to contain %q, got:\n%s", tt.file, tt.language, tt.want, data)
+		}
+		if tt.absent != "" && strings.Contains(string(data), tt.absent) {
+			t.Errorf("Expected %s for %+v not to contain %q, got:\n%s", tt.file, tt.language, tt.absent, data)
+		}
+	}
+}
+
+func TestKeepChapters(t *testing.T) {
+	for _, format := range []string{"markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			ext := ".md"
+			if format == "html" {
+				ext = ".html"
+			}
+			r, err := New(format, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			tutorial := testTutorial()
+			tutorial.Glossary = []generation.GlossaryEntry{{Term: "CLI", Definition: "The command line.", Chapters: []int{2}}}
+			if _, err := r.Render(tutorial, dir); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			// Regenerate the second chapter only: the first, and the
+			// glossary, are kept as they are
+			tutorial = testTutorial()
+			tutorial.Chapters[0].Content = ""
+			tutorial.Chapters[1].Content = "# CLI\n\nParses flags again."
+			tutorial.Keep = []int{1}
+			paths, err := r.Render(tutorial, dir)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, name := range []string{"01_config-loader" + ext, "glossary" + ext} {
+				if !slices.Contains(paths, filepath.Join(dir, name)) {
+					t.Errorf("Expected the kept %s among the paths, got %v", name, paths)
+				}
+			}
+			if data, _ := os.ReadFile(filepath.Join(dir, "01_config-loader"+ext)); !strings.Contains(string(data), "Loads settings.") {
+				t.Errorf("Expected the first chapter to be kept, got:\n%s", data)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dir, "02_cli"+ext)); !strings.Contains(string(data), "Parses flags again.") {
+				t.Errorf("Expected the second chapter to be rewritten, got:\n%s", data)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dir, "index"+ext)); !strings.Contains(string(data), "01_config-loader"+ext) || !strings.Contains(string(data), "glossary"+ext) {
+				t.Errorf("Expected the index to link to the kept files, got:\n%s", data)
+			}
+
+			// A chapter cannot be kept without a file
+			if err := os.Remove(filepath.Join(dir, "01_config-loader"+ext)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Render(tutorial, dir); err == nil || !strings.Contains(err.Error(), "regenerate it too") {
+				t.Errorf("Expected an error for the missing chapter file, got %v", err)
+			}
+		})
+	}
+}
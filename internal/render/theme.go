@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package render
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultTheme is the HTML theme used when none is chosen.
+const DefaultTheme = "light"
+
+// Themes lists the embedded HTML themes, each a stylesheet in
+// templates/html/themes added to the shared layout of style.css.
+var Themes = []string{"light", "dark", "minimal"}
+
+// Style is how the HTML pages and PDF documents of a tutorial look: an
+// embedded theme, or a custom stylesheet replacing the built-in one
+// altogether. It is kept in the output record, so that a later run can
+// write the tutorial the same way.
+type Style struct {
+	Theme string `json:"theme,omitempty"` // One of Themes; empty means DefaultTheme
+	CSS   string `json:"css,omitempty"`   // Path of a custom stylesheet, which wins over Theme
+}
+
+// CheckTheme returns an error if name is not one of Themes.
+func CheckTheme(name string) error {
+	if !slices.Contains(Themes, name) {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Themes, ", "))
+	}
+	return nil
+}
+
+// stylesheetCSS returns the stylesheet of s: the content of its custom
+// stylesheet, or the shared layout followed by its theme.
+func (s Style) stylesheetCSS() ([]byte, error) {
+	if s.CSS != "" {
+		css, err := os.ReadFile(s.CSS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stylesheet: %w", err)
+		}
+		return css, nil
+	}
+	theme := s.Theme
+	if theme == "" {
+		theme = DefaultTheme
+	}
+	if err := CheckTheme(theme); err != nil {
+		return nil, err
+	}
+	layout, err := fs.ReadFile(templatesFS, "templates/html/"+stylesheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read html theme: %w", err)
+	}
+	colors, err := fs.ReadFile(templatesFS, "templates/html/themes/"+theme+".css")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read html theme: %w", err)
+	}
+	// The theme comes last, so that it can override the layout
+	return append(append(layout, '\n'), colors...), nil
+}
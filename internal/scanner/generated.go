@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Reasons reported by Classify for files not worth sending to the LLM.
+const (
+	ReasonBinary    = "binary"    // Contains NUL bytes, like images, archives and compiled code
+	ReasonLockfile  = "lockfile"  // Dependency lockfile written by a package manager
+	ReasonMinified  = "minified"  // Minified bundle or source map
+	ReasonGenerated = "generated" // Marked as generated by a tool (e.g., "DO NOT EDIT")
+)
+
+const (
+	// sniffSize is how much of a file Classify looks at.
+	sniffSize = 32 << 10
+	// minifiedLineLength and minifiedAverageLength flag minified content: a
+	// line at least this long in a sample averaging at least that per line.
+	minifiedLineLength    = 1000
+	minifiedAverageLength = 200
+	// markerLines is how many leading lines are searched for generated-code markers.
+	markerLines = 10
+)
+
+// lockfiles are the lowercase names of dependency lockfiles.
+var lockfiles = map[string]bool{
+	"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"bun.lockb": true, "cargo.lock": true, "go.sum": true, "poetry.lock": true, "pipfile.lock": true,
+	"uv.lock": true, "composer.lock": true, "gemfile.lock": true, "podfile.lock": true, "mix.lock": true,
+	"flake.lock": true, "packages.lock.json": true, "pubspec.lock": true,
+}
+
+// generatedMarkerRe matches the comments tools leave in the files they
+// generate, such as Go's "Code generated ... DO NOT EDIT." and "@generated".
+var generatedMarkerRe = regexp.MustCompile(`(?i)\bgenerated\b.*\bdo not (?:edit|modify)\b|@generated\b`)
+
+// Classify reports why the file at the slash-separated path name is not
+// worth analyzing, as one of the Reason constants, or "" if it should be
+// analyzed. sample is the start of the file (see sniff): files are binary
+// if it contains a NUL byte, so UTF-8 text is never mistaken for binary
+// whatever its characters, and minified if its lines are very long.
+func Classify(name string, sample []byte) string {
+	base := strings.ToLower(path.Base(name))
+	switch {
+	case lockfiles[base]:
+		return ReasonLockfile
+	case strings.Contains(base, ".min.") || strings.HasSuffix(base, ".js.map") || strings.HasSuffix(base, ".css.map"):
+		return ReasonMinified
+	}
+
+	if bytes.HasPrefix(sample, []byte{0xff, 0xfe}) || bytes.HasPrefix(sample, []byte{0xfe, 0xff}) {
+		return "" // UTF-16 text, whose NUL bytes are half of ASCII characters
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return ReasonBinary
+	}
+
+	lines := bytes.Split(sample, []byte("\n"))
+	if len(lines) > 1 && len(sample) == sniffSize {
+		lines = lines[:len(lines)-1] // The last line of a truncated sample is incomplete
+	}
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len(line))
+	}
+	if longest >= minifiedLineLength && len(sample)/len(lines) >= minifiedAverageLength {
+		return ReasonMinified
+	}
+	for _, line := range lines[:min(markerLines, len(lines))] {
+		if generatedMarkerRe.Match(line) {
+			return ReasonGenerated
+		}
+	}
+	return ""
+}
+
+// sniff returns the start of the file at p, as much as Classify looks at.
+func sniff(p string) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sample := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return sample[:n], nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	tests := []struct {
+		name   string
+		path   string
+		sample string
+		want   string
+	}{
+		{name: "source code", path: "main.go", sample: code, want: ""},
+		{name: "UTF-8 text with high bytes", path: "README.md", sample: "Café, naïve, 日本語, emoji 🎉\n", want: ""},
+		{name: "Latin-1 text", path: "legacy.txt", sample: "caf\xe9 na\xefve\n", want: ""},
+		{name: "UTF-16 text", path: "notes.txt", sample: "\xff\xfeh\x00i\x00", want: ""},
+		{name: "empty file", path: "empty.go", sample: "", want: ""},
+		{name: "binary", path: "logo.png", sample: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: ReasonBinary},
+		{name: "lockfile", path: "web/package-lock.json", sample: "{\n}\n", want: ReasonLockfile},
+		{name: "go.sum", path: "go.sum", sample: "example.com/x v1.0.0 h1:abc=\n", want: ReasonLockfile},
+		{name: "minified by name", path: "static/app.min.js", sample: "var a=1;\n", want: ReasonMinified},
+		{name: "source map", path: "static/app.js.map", sample: "{}", want: ReasonMinified},
+		{name: "minified by line length", path: "static/bundle.js", sample: strings.Repeat("a=b;", 500), want: ReasonMinified},
+		{name: "one long line among short ones", path: "data.go", sample: code + "var blob = \"" + strings.Repeat("x", 2000) + "\"\n" + strings.Repeat(code, 20), want: ""},
+		{name: "go generated", path: "api.pb.go", sample: "// Code generated by protoc-gen-go. DO NOT EDIT.\n" + code, want: ReasonGenerated},
+		{name: "generated marker", path: "schema.ts", sample: "/**\n * @generated\n */\nexport {}\n", want: ReasonGenerated},
+		{name: "marker far down", path: "main.go", sample: strings.Repeat("\n", 20) + "// Code generated by hand. DO NOT EDIT.\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.path, []byte(tt.sample)); got != tt.want {
+				t.Errorf("Classify(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanSkipsGenerated(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":           "package main\n",
+		"go.sum":            "example.com/x v1.0.0 h1:abc=\n",
+		"assets/logo.png":   "\x89PNG\x00\x00",
+		"assets/font.woff2": "wOF2\x00\x01",
+		"web/app.min.js":    "var a=1;",
+		"api/api.pb.go":     "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api\n",
+	})
+
+	result, err := Scan(root, ScanOptions{SkipGenerated: true})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if want := []string{"main.go"}; !reflect.DeepEqual(result.Files, want) {
+		t.Errorf("Files = %v, want %v", result.Files, want)
+	}
+	wantSkipped := map[string]int{ReasonBinary: 2, ReasonLockfile: 1, ReasonMinified: 1, ReasonGenerated: 1}
+	if !reflect.DeepEqual(result.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, wantSkipped)
+	}
+
+	// Without SkipGenerated, every file is listed
+	files, err := ListFiles(root, ScanOptions{})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 6 {
+		t.Errorf("Expected all 6 files without SkipGenerated, got %v", files)
+	}
+}
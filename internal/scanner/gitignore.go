@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a single pattern line from a .gitignore file.
+type ignoreRule struct {
+	segments []string // Pattern split on "/"
+	negate   bool     // Line started with "!" (re-include)
+	dirOnly  bool     // Line ended with "/" (matches directories only)
+	anchored bool     // Pattern contains a slash, so it is relative to the .gitignore directory
+}
+
+// parseGitignore parses the contents of a .gitignore file.
+func parseGitignore(data []byte) []ignoreRule {
+	var rules []ignoreRule
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// "\#" and "\!" escape a literal leading character
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.anchored = strings.Contains(line, "/")
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule matches rel, a slash-separated path
+// relative to the directory containing the .gitignore file.
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], path.Base(rel))
+		return ok
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// gitignore holds the rules of every .gitignore file found along the walk,
+// keyed by the slash-separated directory (relative to the scan root) that
+// contains it.
+type gitignore struct {
+	rules map[string][]ignoreRule
+}
+
+func newGitignore() *gitignore {
+	return &gitignore{rules: make(map[string][]ignoreRule)}
+}
+
+// load reads the .gitignore file in dir (relative to root), if there is one.
+func (g *gitignore) load(root, dir string) error {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if rules := parseGitignore(data); len(rules) > 0 {
+		g.rules[dir] = rules
+	}
+	return nil
+}
+
+// ignored reports whether rel is ignored. Rules in deeper directories and
+// later lines take precedence, so a negated pattern such as "!keep.me" can
+// re-include a file excluded by an earlier pattern.
+func (g *gitignore) ignored(rel string, isDir bool) bool {
+	ignored := false
+	dir := "."
+	remaining := rel
+	for {
+		for _, rule := range g.rules[dir] {
+			if rule.matches(remaining, isDir) {
+				ignored = !rule.negate
+			}
+		}
+		i := strings.Index(remaining, "/")
+		if i < 0 {
+			return ignored
+		}
+		dir = path.Join(dir, remaining[:i])
+		remaining = remaining[i+1:]
+	}
+}
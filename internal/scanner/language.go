@@ -0,0 +1,209 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"bytes"
+	"path"
+	"slices"
+	"strings"
+)
+
+// UnknownLanguage is reported for files whose language is not recognized.
+const UnknownLanguage = "unknown"
+
+// languagesByExtension maps lowercase file extensions to language names.
+var languagesByExtension = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".pyi":    "Python",
+	".js":     "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".jsx":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".scala":  "Scala",
+	".rs":     "Rust",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".cxx":    "C++",
+	".hpp":    "C++",
+	".hh":     "C++",
+	".cs":     "C#",
+	".swift":  "Swift",
+	".m":      "Objective-C",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".pl":     "Perl",
+	".pm":     "Perl",
+	".lua":    "Lua",
+	".r":      "R",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".erl":    "Erlang",
+	".hs":     "Haskell",
+	".clj":    "Clojure",
+	".zig":    "Zig",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".zsh":    "Shell",
+	".ps1":    "PowerShell",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".htm":    "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".json":   "JSON",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+	".toml":   "TOML",
+	".xml":    "XML",
+	".proto":  "Protocol Buffers",
+	".md":     "Markdown",
+	".tf":     "Terraform",
+	".tmpl":   "Go Template",
+}
+
+// languagesByName maps lowercase file names without a telling extension.
+var languagesByName = map[string]string{
+	"dockerfile":     "Dockerfile",
+	"makefile":       "Makefile",
+	"gnumakefile":    "Makefile",
+	"cmakelists.txt": "CMake",
+	"rakefile":       "Ruby",
+	"gemfile":        "Ruby",
+	"jenkinsfile":    "Groovy",
+}
+
+// languagesByInterpreter maps the interpreter named on a shebang line.
+var languagesByInterpreter = map[string]string{
+	"sh":      "Shell",
+	"bash":    "Shell",
+	"zsh":     "Shell",
+	"dash":    "Shell",
+	"ksh":     "Shell",
+	"python":  "Python",
+	"node":    "JavaScript",
+	"deno":    "TypeScript",
+	"ruby":    "Ruby",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"lua":     "Lua",
+	"Rscript": "R",
+}
+
+// DetectLanguage returns the programming language of the file at the
+// slash-separated path, from its name or extension, falling back to the
+// shebang line or leading markers of content (which may be just the start of
+// the file). Unrecognized files are reported as UnknownLanguage.
+func DetectLanguage(filePath string, content []byte) string {
+	name := strings.ToLower(path.Base(filePath))
+	if lang, ok := languagesByName[name]; ok {
+		return lang
+	}
+	if strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile") {
+		return "Dockerfile"
+	}
+	if lang, ok := languagesByExtension[path.Ext(name)]; ok {
+		return lang
+	}
+
+	content = bytes.TrimPrefix(content, []byte("\ufeff")) // Byte order mark
+	if line, ok := bytes.CutPrefix(content, []byte("#!")); ok {
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		if lang, ok := languagesByInterpreter[interpreter(string(line))]; ok {
+			return lang
+		}
+	}
+	switch {
+	case bytes.HasPrefix(content, []byte("<?php")):
+		return "PHP"
+	case bytes.HasPrefix(content, []byte("<?xml")):
+		return "XML"
+	}
+	return UnknownLanguage
+}
+
+// LanguageNames returns the names of the languages DetectLanguage reports,
+// sorted, including UnknownLanguage.
+func LanguageNames() []string {
+	names := []string{UnknownLanguage}
+	for _, m := range []map[string]string{languagesByExtension, languagesByName, languagesByInterpreter} {
+		for _, lang := range m {
+			if !slices.Contains(names, lang) {
+				names = append(names, lang)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupLanguage returns the name DetectLanguage reports for a language
+// given by name, case-insensitively (e.g., "go" or "c++"), or by one of its
+// file extensions (e.g., "rs" or "py").
+func LookupLanguage(name string) (string, bool) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, lang := range LanguageNames() {
+		if strings.ToLower(lang) == lower {
+			return lang, true
+		}
+	}
+	if lang, ok := languagesByExtension["."+strings.TrimPrefix(lower, ".")]; ok {
+		return lang, true
+	}
+	return "", false
+}
+
+// LanguageFilter selects files by their detected language (see
+// DetectLanguage), with the names DetectLanguage reports. Files of
+// UnknownLanguage are kept unless Exclude names it.
+type LanguageFilter struct {
+	Include []string // Keep only the files in these languages; empty keeps all
+	Exclude []string // Drop the files in these languages
+}
+
+// IsZero reports whether f keeps every file.
+func (f LanguageFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// keeps reports whether f keeps a file in lang.
+func (f LanguageFilter) keeps(lang string) bool {
+	if slices.Contains(f.Exclude, lang) {
+		return false
+	}
+	return len(f.Include) == 0 || lang == UnknownLanguage || slices.Contains(f.Include, lang)
+}
+
+// interpreter returns the program run by a shebang line (without the "#!"),
+// looking through env and trailing version numbers (e.g., python3.12).
+func interpreter(shebang string) string {
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+	program := path.Base(fields[0])
+	if program == "env" {
+		// Skip env's options, such as -S
+		program = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				program = path.Base(field)
+				break
+			}
+		}
+	}
+	return strings.TrimRight(program, "0123456789.")
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash-separated relative path name matches the
+// glob pattern. Patterns follow doublestar conventions:
+//
+//   - "*", "?" and "[...]" match within a single path segment (see path.Match)
+//   - "**" as a whole segment matches zero or more segments
+//   - a pattern without a slash (e.g. "*.go") matches the base name at any depth
+//   - a leading "/" anchors the pattern to the scan root
+//
+// A pattern that matches a directory also matches everything beneath it, so
+// "vendor" and "**/vendor/**" both cover "vendor/pkg/file.go".
+func Match(pattern, name string) bool {
+	segs := strings.Split(name, "/")
+
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		// Base-name pattern: match any segment of the path
+		pattern = strings.TrimSuffix(pattern, "/")
+		for _, seg := range segs {
+			if ok, _ := path.Match(pattern, seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	patSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	// Try the path itself and each of its ancestor directories
+	for i := len(segs); i > 0; i-- {
+		if matchSegments(patSegs, segs[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches pattern segments against path segments, expanding "**".
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			// Collapse consecutive "**" segments
+			for len(pat) > 1 && pat[1] == "**" {
+				pat = pat[1:]
+			}
+			if len(pat) == 1 {
+				return true
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pat[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// ValidatePattern returns an error if pattern is not a well-formed glob.
+func ValidatePattern(pattern string) error {
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
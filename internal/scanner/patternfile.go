@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadPatternFile reads glob patterns from file, one per line, in the syntax
+// of a .gitignore file: blank lines and lines starting with "#" are skipped,
+// trailing whitespace is ignored and a leading "\" escapes a literal "#" or
+// "!". Negated ("!") patterns are rejected, since a PatternSet has no way to
+// re-include what it excludes. Patterns are otherwise returned as written, to
+// be matched with Match.
+func ReadPatternFile(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patterns: %w", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "!"):
+			return nil, fmt.Errorf("%s:%d: negated pattern '%s' is not supported", file, n, line)
+		case strings.HasPrefix(line, `\`):
+			line = line[1:]
+		}
+		if err := ValidatePattern(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read patterns from %s: %w", file, err)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"context"
+	"slices"
+)
+
+// LanguageExcludes are the directories that the tooling of each language
+// (by the name DetectLanguage reports) leaves in a source tree: build
+// output, fetched dependencies and caches. The analyze command adds those
+// of a codebase's primary language (see PrimaryLanguage) to DefaultExcludes,
+// so that they are skipped the same way.
+var LanguageExcludes = map[string][]string{
+	"C":          {"CMakeFiles"},
+	"C++":        {"CMakeFiles"},
+	"C#":         {"bin", "obj"},
+	"Dart":       {".dart_tool"},
+	"Elixir":     {"_build", "deps"},
+	"Go":         {"vendor"},
+	"Haskell":    {".stack-work", "dist-newstyle"},
+	"Java":       {"target", ".gradle"},
+	"JavaScript": {"coverage", ".next", ".nuxt", "bower_components"},
+	"Kotlin":     {".gradle"},
+	"PHP":        {"vendor"},
+	"Python":     {"__pycache__", ".pytest_cache", ".mypy_cache", ".tox", "*.egg-info", "venv"},
+	"Ruby":       {".bundle"},
+	"Rust":       {"target"},
+	"Scala":      {"target", ".bloop", ".metals"},
+	"Swift":      {".build", "Pods"},
+	"TypeScript": {"coverage", ".next", ".nuxt"},
+	"Zig":        {"zig-cache", ".zig-cache", "zig-out"},
+}
+
+// notProgramming are the languages DetectLanguage reports for data, markup
+// and build files, which never make the primary language of a codebase.
+var notProgramming = []string{
+	UnknownLanguage, "JSON", "YAML", "TOML", "XML", "Markdown", "HTML", "CSS", "SCSS",
+	"Protocol Buffers", "Dockerfile", "Makefile", "Go Template",
+}
+
+// PrimaryLanguage returns the programming language of most of the files of
+// root selected by opts, or "" if none of them is in a programming language.
+// It is meant to run before the scan proper, so it detects languages from
+// file names alone rather than reading the files, and ignores the filters of
+// opts that would (SkipGenerated and Languages). Ties go to the language
+// that sorts first.
+func PrimaryLanguage(root string, opts ScanOptions) (string, error) {
+	opts.SkipGenerated, opts.Languages = false, LanguageFilter{}
+	paths := make(chan string, scanBuffer)
+	counts := make(map[string]int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range paths {
+			if lang := DetectLanguage(p, nil); !slices.Contains(notProgramming, lang) {
+				counts[lang]++
+			}
+		}
+	}()
+	_, err := Stream(context.Background(), root, opts, paths)
+	<-done
+	if err != nil {
+		return "", err
+	}
+
+	primary := ""
+	for lang, n := range counts {
+		if n > counts[primary] || (n == counts[primary] && lang < primary) {
+			primary = lang
+		}
+	}
+	return primary, nil
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package scanner lists the files of a codebase that should be analyzed.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PatternSet is a group of include and exclude glob patterns from one source
+// (e.g., command-line flags or the config file).
+type PatternSet struct {
+	Include []string // Patterns of files to include; empty means include everything
+	Exclude []string // Patterns of files or directories to exclude
+}
+
+// DefaultExcludes are the directories of dependencies, version control,
+// build output and virtual environments, which are skipped at any depth
+// unless ScanOptions.DefaultExcludes is cleared (see --no-default-excludes).
+var DefaultExcludes = []string{"node_modules", ".git", "vendor", "dist", "build", ".venv"}
+
+// ScanOptions controls which files ListFiles returns.
+type ScanOptions struct {
+	// Patterns are consulted in order of precedence. For each file, the first
+	// set with a matching pattern decides: an exclude match drops the file and
+	// an include match keeps it. Files matched by no set are kept only if no
+	// set has include patterns.
+	Patterns []PatternSet
+	MaxSize  int64 // Maximum file size in bytes; 0 means no limit
+
+	// DefaultExcludes are excluded under Patterns (e.g., DefaultExcludes):
+	// a file or directory matching one is skipped unless an include pattern
+	// matches it too, such as "vendor/**". Include patterns that only match
+	// the files within, such as "*.go", do not bring a directory back. The
+	// analyze command sets them unless --no-default-excludes is given.
+	DefaultExcludes []string
+
+	// RespectGitignore skips files ignored by .gitignore files found along
+	// the walk (including nested ones), as well as the .git directory itself.
+	// The analyze command enables it unless --no-gitignore is given.
+	RespectGitignore bool
+
+	// Paths restricts the scan to these subdirectories of the root (e.g., one
+	// service of a monorepo); empty means the whole root. Returned paths and
+	// patterns remain relative to the root, and .gitignore files above the
+	// subdirectories still apply.
+	Paths []string
+
+	// SkipGenerated skips binary files and generated ones such as minified
+	// bundles and lockfiles (see Classify). The analyze command enables it
+	// unless --include-generated is given.
+	SkipGenerated bool
+
+	// TestPatterns are the patterns of test files to skip (e.g.,
+	// DefaultTestPatterns); empty keeps them. A pattern naming a directory
+	// skips every file beneath it. The analyze command sets them unless tests
+	// are included, which by default they are only for contributors.
+	TestPatterns []string
+
+	// Languages selects the files by detected language, after the patterns
+	// and the other filters; the zero value keeps every language.
+	Languages LanguageFilter
+}
+
+// scanBuffer is the capacity of the channel Scan collects the files of
+// Stream from.
+const scanBuffer = 64
+
+// Result is the outcome of Scan.
+type Result struct {
+	Files   []string       // Selected files, as returned by ListFiles
+	Skipped map[string]int // Number of files left out by SkipGenerated and TestPatterns, by reason (e.g., ReasonBinary)
+
+	// FilteredLanguages is the number of files left out by Languages, by language.
+	FilteredLanguages map[string]int
+}
+
+// ListFiles walks root and returns the slash-separated paths, relative to
+// root, of the regular files selected by opts. The result is sorted so that
+// analysis is reproducible.
+func ListFiles(root string, opts ScanOptions) ([]string, error) {
+	result, err := Scan(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.Files, nil
+}
+
+// Scan is like ListFiles, but also reports the files it skipped as binary,
+// generated or tests.
+func Scan(root string, opts ScanOptions) (*Result, error) {
+	paths := make(chan string, scanBuffer)
+	done := make(chan struct{})
+	var files []string
+	go func() {
+		defer close(done)
+		for p := range paths {
+			files = append(files, p)
+		}
+	}()
+	result, err := Stream(context.Background(), root, opts, paths)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+	result.Files = files
+	return result, nil
+}
+
+// Stream is like Scan, but sends the selected files on paths as the walk
+// finds them, in the order ListFiles returns them, instead of listing them
+// all first; the Result it returns has no Files. The walk waits while paths
+// is full, so a consumer slower than the scanner (e.g., one sending each
+// file to an LLM) holds it back, and the capacity of paths bounds how far
+// ahead it runs. Stream closes paths when done, and stops, failing with the
+// error of ctx, once ctx is canceled.
+func Stream(ctx context.Context, root string, opts ScanOptions, paths chan<- string) (*Result, error) {
+	defer close(paths)
+	for _, set := range append(opts.Patterns, PatternSet{Exclude: opts.TestPatterns}, PatternSet{Exclude: opts.DefaultExcludes}) {
+		for _, pattern := range append(append([]string{}, set.Include...), set.Exclude...) {
+			if err := ValidatePattern(pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	subpaths, err := cleanSubpaths(root, opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignores *gitignore
+	if opts.RespectGitignore {
+		ignores = newGitignore()
+	}
+
+	result := &Result{Skipped: make(map[string]int), FilteredLanguages: make(map[string]int)}
+	err = walk(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			if ignores != nil {
+				return ignores.load(root, ".")
+			}
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if ignores != nil {
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if ignores.ignored(rel, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if !withinSubpaths(subpaths, rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if opts.defaultExcluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if opts.prunable(rel) {
+				return filepath.SkipDir
+			}
+			if ignores != nil {
+				return ignores.load(root, rel)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !opts.selected(rel) {
+			return nil
+		}
+		if opts.MaxSize > 0 {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() > opts.MaxSize {
+				return nil
+			}
+		}
+		if matchAny(opts.TestPatterns, rel) {
+			result.Skipped[ReasonTest]++
+			return nil
+		}
+		var sample []byte
+		if opts.SkipGenerated {
+			if sample, err = sniff(p); err != nil {
+				return err
+			}
+			if reason := Classify(rel, sample); reason != "" {
+				result.Skipped[reason]++
+				return nil
+			}
+		}
+		if !opts.Languages.IsZero() {
+			if sample == nil {
+				if sample, err = sniff(p); err != nil {
+					return err
+				}
+			}
+			if lang := DetectLanguage(rel, sample); !opts.Languages.keeps(lang) {
+				result.FilteredLanguages[lang]++
+				return nil
+			}
+		}
+		select {
+		case paths <- rel:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return result, nil
+}
+
+// walk is filepath.WalkDir, except that it visits the entries of each
+// directory in the order sort.Strings puts their paths in, so that files are
+// found sorted without listing them all first: a directory sorts as its name
+// followed by a slash, which is how its files' paths start.
+func walk(root string, fn fs.WalkDirFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walkDir(p string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(p, d, nil); err != nil || !d.IsDir() {
+		if err == filepath.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		if err = fn(p, d, err); err != nil {
+			if err == filepath.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return walkKey(entries[i]) < walkKey(entries[j]) })
+	for _, entry := range entries {
+		if err := walkDir(filepath.Join(p, entry.Name()), entry, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// walkKey is what walk sorts the entry of a directory by.
+func walkKey(d fs.DirEntry) string {
+	if d.IsDir() {
+		return d.Name() + "/"
+	}
+	return d.Name()
+}
+
+// cleanSubpaths returns paths as clean slash-separated paths relative to
+// root, checking that each is a directory within it. A path naming the root
+// itself lifts the restriction, so nil is returned.
+func cleanSubpaths(root string, paths []string) ([]string, error) {
+	var subpaths []string
+	for _, p := range paths {
+		clean := path.Clean(filepath.ToSlash(p))
+		if clean == "." {
+			return nil, nil
+		}
+		if path.IsAbs(clean) || filepath.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, fmt.Errorf("subpath %q must be relative to the source root", p)
+		}
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(clean)))
+		if err != nil {
+			return nil, fmt.Errorf("subpath %q does not exist in %s", p, root)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("subpath %q is not a directory", p)
+		}
+		subpaths = append(subpaths, clean)
+	}
+	return subpaths, nil
+}
+
+// withinSubpaths reports whether the walk should visit rel: a file or
+// directory inside one of subpaths, or a directory leading to one. No
+// subpaths means everything is visited.
+func withinSubpaths(subpaths []string, rel string, dir bool) bool {
+	if len(subpaths) == 0 {
+		return true
+	}
+	for _, sub := range subpaths {
+		if rel == sub || strings.HasPrefix(rel, sub+"/") || (dir && strings.HasPrefix(sub, rel+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected reports whether the file at rel passes the include/exclude patterns.
+func (o ScanOptions) selected(rel string) bool {
+	hasInclude := false
+	for _, set := range o.Patterns {
+		if matchAny(set.Exclude, rel) {
+			return false
+		}
+		if matchAny(set.Include, rel) {
+			return true
+		}
+		hasInclude = hasInclude || len(set.Include) > 0
+	}
+	return !hasInclude
+}
+
+// defaultExcluded reports whether rel is skipped by DefaultExcludes: it
+// matches one of them, and no include pattern of Patterns matches it.
+func (o ScanOptions) defaultExcluded(rel string) bool {
+	if !matchAny(o.DefaultExcludes, rel) {
+		return false
+	}
+	for _, set := range o.Patterns {
+		if matchAny(set.Include, rel) {
+			return false
+		}
+	}
+	return true
+}
+
+// prunable reports whether the directory at rel and everything beneath it can
+// be skipped. A directory excluded by one set is only pruned when no set of
+// higher precedence could include files within it.
+func (o ScanOptions) prunable(rel string) bool {
+	for _, set := range o.Patterns {
+		if matchAny(set.Exclude, rel) {
+			return true
+		}
+		if len(set.Include) > 0 {
+			return false
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
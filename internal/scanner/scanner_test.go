@@ -0,0 +1,647 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// writeTree creates the given files (relative path -> content) under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", rel, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/config/config.go", true},
+		{"*.go", "README.md", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/sub/main.go", false},
+		{"cmd/**/*.go", "cmd/sub/main.go", true},
+		{"**/vendor/**", "vendor/pkg/x.go", true},
+		{"**/vendor/**", "a/vendor", true},
+		{"vendor/*", "vendor/x.go", true},
+		{"vendor/*", "a/vendor/x.go", false},
+		{"/docs", "docs/index.md", true},
+		{"/docs", "sub/docs/index.md", false},
+		{"vendor", "a/vendor/x.go", true},
+		{"**", "anything/at/all", true},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":              "package main",
+		"README.md":            "# readme",
+		"internal/a/a.go":      "package a",
+		"internal/a/a_test.go": "package a",
+		"vendor/dep/dep.go":    "package dep",
+		"big.go":               "package big // padded to exceed the size limit",
+	})
+
+	tests := []struct {
+		name string
+		opts ScanOptions
+		want []string
+	}{
+		{
+			name: "no patterns lists everything sorted",
+			opts: ScanOptions{},
+			want: []string{"README.md", "big.go", "internal/a/a.go", "internal/a/a_test.go", "main.go", "vendor/dep/dep.go"},
+		},
+		{
+			name: "include and exclude",
+			opts: ScanOptions{Patterns: []PatternSet{{
+				Include: []string{"**/*.go"},
+				Exclude: []string{"**/vendor/**", "*_test.go"},
+			}}},
+			want: []string{"big.go", "internal/a/a.go", "main.go"},
+		},
+		{
+			name: "max size",
+			opts: ScanOptions{
+				Patterns: []PatternSet{{Include: []string{"*.go"}, Exclude: []string{"vendor"}}},
+				MaxSize:  20,
+			},
+			want: []string{"internal/a/a.go", "internal/a/a_test.go", "main.go"},
+		},
+		{
+			name: "flag include takes precedence over config exclude",
+			opts: ScanOptions{Patterns: []PatternSet{
+				{Include: []string{"vendor/**"}},
+				{Include: []string{"*.go"}, Exclude: []string{"**/vendor/**"}},
+			}},
+			want: []string{"big.go", "internal/a/a.go", "internal/a/a_test.go", "main.go", "vendor/dep/dep.go"},
+		},
+		{
+			name: "flag exclude takes precedence over config include",
+			opts: ScanOptions{Patterns: []PatternSet{
+				{Exclude: []string{"internal"}},
+				{Include: []string{"*.go"}},
+			}},
+			want: []string{"big.go", "main.go", "vendor/dep/dep.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListFiles(root, tt.opts)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilesErrors(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"file.txt": "x"})
+
+	if _, err := ListFiles(filepath.Join(root, "missing"), ScanOptions{}); err == nil {
+		t.Error("ListFiles() expected error for a missing root")
+	}
+	if _, err := ListFiles(filepath.Join(root, "file.txt"), ScanOptions{}); err == nil {
+		t.Error("ListFiles() expected error when root is a file")
+	}
+	bad := ScanOptions{Patterns: []PatternSet{{Include: []string{"[unclosed"}}}}
+	if _, err := ListFiles(root, bad); err == nil {
+		t.Error("ListFiles() expected error for a malformed pattern")
+	}
+}
+
+func TestStream(t *testing.T) {
+	root := t.TempDir()
+	// Names sorting around the slash that follows a directory name
+	writeTree(t, root, map[string]string{
+		"a.go":     "package a",
+		"a-b.go":   "package a",
+		"a/x.go":   "package a",
+		"a/y/z.go": "package y",
+		"a0.go":    "package a",
+		"b.go":     "package b",
+	})
+	want := []string{"a-b.go", "a.go", "a/x.go", "a/y/z.go", "a0.go", "b.go"}
+
+	paths := make(chan string)
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range paths {
+			got = append(got, p)
+		}
+	}()
+	if _, err := Stream(context.Background(), root, ScanOptions{}, paths); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	<-done
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stream() = %v, want %v", got, want)
+	}
+
+	// A canceled scan stops at the next file instead of waiting for a reader
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Stream(ctx, root, ScanOptions{}, make(chan string)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the scan to be canceled, got %v", err)
+	}
+}
+
+func TestListFilesSubpaths(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":                    "*.log\n",
+		"README.md":                     "# Monorepo",
+		"services/api/main.go":          "package main",
+		"services/api/debug.log":        "ignored by the root .gitignore",
+		"services/api/vendor/dep.go":    "package dep",
+		"services/apigateway/main.go":   "package main",
+		"services/web/index.js":         "export {}",
+		"libs/shared/util.go":           "package shared",
+		"libs/shared/testdata/fixture":  "data",
+		"services/api/internal/auth.go": "package internal",
+	})
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{name: "one subpath", paths: []string{"services/api"}, want: []string{
+			"services/api/internal/auth.go", "services/api/main.go",
+		}},
+		{name: "several subpaths, not cleaned", paths: []string{"./libs/shared/", "services/web"}, want: []string{
+			"libs/shared/util.go", "services/web/index.js",
+		}},
+		{name: "root", paths: []string{"."}, want: []string{
+			".gitignore", "README.md", "libs/shared/util.go", "services/api/internal/auth.go", "services/api/main.go",
+			"services/apigateway/main.go", "services/web/index.js",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ScanOptions{
+				Patterns:         []PatternSet{{Exclude: []string{"vendor", "testdata"}}},
+				RespectGitignore: true,
+				Paths:            tt.paths,
+			}
+			got, err := ListFiles(root, opts)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"services/missing", "README.md", "../outside", "/services/api"} {
+		if _, err := ListFiles(root, ScanOptions{Paths: []string{bad}}); err == nil {
+			t.Errorf("ListFiles() expected error for subpath %q", bad)
+		}
+	}
+}
+
+func TestListFilesGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":               "# build output\nbuild/\n*.log\n!keep.log\nnode_modules\n/root-only.txt\n",
+		".git/config":              "[core]",
+		"main.go":                  "package main",
+		"debug.log":                "log",
+		"keep.log":                 "log",
+		"root-only.txt":            "x",
+		"build/out.bin":            "bin",
+		"node_modules/x/index.js":  "js",
+		"sub/root-only.txt":        "x",
+		"sub/.gitignore":           "*.tmp\n!important.log\n",
+		"sub/scratch.tmp":          "tmp",
+		"sub/important.log":        "log",
+		"sub/other.log":            "log",
+		"sub/deeper/notes.tmp":     "tmp",
+		"sub/deeper/code.go":       "package deeper",
+		"sub/build/nested/file.go": "package nested",
+	})
+
+	got, err := ListFiles(root, ScanOptions{RespectGitignore: true})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	want := []string{".gitignore", "keep.log", "main.go", "sub/.gitignore", "sub/deeper/code.go", "sub/important.log", "sub/root-only.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListFiles() = %v, want %v", got, want)
+	}
+
+	// Disabling gitignore support lists the ignored files again
+	got, err = ListFiles(root, ScanOptions{})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(got) != 16 {
+		t.Errorf("Expected 16 files without gitignore support, got %d: %v", len(got), got)
+	}
+}
+
+func TestScanTests(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":                  "package main",
+		"main_test.go":             "package main",
+		"app/models.py":            "class Model: pass",
+		"app/test_models.py":       "def test_model(): pass",
+		"web/button.tsx":           "export {}",
+		"web/button.spec.tsx":      "export {}",
+		"web/__tests__/helpers.js": "export {}",
+		"tests/integration.rs":     "fn main() {}",
+		"testdata/golden.txt":      "golden",
+		"src/Contest.java":         "class Contest {}",
+	})
+
+	result, err := Scan(root, ScanOptions{TestPatterns: DefaultTestPatterns})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []string{"app/models.py", "main.go", "src/Contest.java", "web/button.tsx"}
+	if !reflect.DeepEqual(result.Files, want) {
+		t.Errorf("Scan() = %v, want %v", result.Files, want)
+	}
+	if result.Skipped[ReasonTest] != 6 {
+		t.Errorf("Expected 6 test files skipped, got %v", result.Skipped)
+	}
+
+	// Custom patterns replace the defaults
+	result, err = Scan(root, ScanOptions{TestPatterns: []string{"tests"}})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(result.Files) != 9 || result.Skipped[ReasonTest] != 1 {
+		t.Errorf("Expected only tests/ to be skipped, got %v (skipped %v)", result.Files, result.Skipped)
+	}
+
+	if _, err := Scan(root, ScanOptions{TestPatterns: []string{"[a-"}}); err == nil {
+		t.Error("Expected an error for an invalid test pattern")
+	}
+}
+
+func TestListFilesDefaultExcludes(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":                         "package main",
+		"node_modules/left-pad/index.js":  "module.exports = {}",
+		"web/node_modules/react/index.js": "module.exports = {}",
+		"vendor/lib/lib.go":               "package lib",
+		"dist/app.js":                     "export {}",
+		".venv/lib/site.py":               "import os",
+		"cmd/builder/build.go":            "package builder",
+	})
+
+	tests := []struct {
+		name string
+		opts ScanOptions
+		want []string
+	}{
+		{
+			name: "defaults",
+			opts: ScanOptions{DefaultExcludes: DefaultExcludes},
+			want: []string{"cmd/builder/build.go", "main.go"},
+		},
+		{
+			name: "include patterns of files do not bring directories back",
+			opts: ScanOptions{DefaultExcludes: DefaultExcludes, Patterns: []PatternSet{{Include: []string{"*.go", "*.js"}}}},
+			want: []string{"cmd/builder/build.go", "main.go"},
+		},
+		{
+			name: "include patterns of the directory do",
+			opts: ScanOptions{DefaultExcludes: DefaultExcludes, Patterns: []PatternSet{{Include: []string{"vendor/**"}}, {Include: []string{"*.go"}}}},
+			want: []string{"cmd/builder/build.go", "main.go", "vendor/lib/lib.go"},
+		},
+		{
+			name: "user excludes still apply",
+			opts: ScanOptions{DefaultExcludes: DefaultExcludes, Patterns: []PatternSet{{Exclude: []string{"cmd"}}}},
+			want: []string{"main.go"},
+		},
+		{
+			name: "opted out",
+			opts: ScanOptions{},
+			want: []string{".venv/lib/site.py", "cmd/builder/build.go", "dist/app.js", "main.go", "node_modules/left-pad/index.js", "vendor/lib/lib.go", "web/node_modules/react/index.js"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListFiles(root, tt.opts)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		opts  ScanOptions
+		want  string
+	}{
+		{
+			name:  "most files",
+			files: map[string]string{"src/main.rs": "", "src/lib.rs": "", "build.py": "", "README.md": "", "docs/a.md": "", "docs/b.md": ""},
+			want:  "Rust",
+		},
+		{
+			name:  "tie goes to the first name",
+			files: map[string]string{"a.py": "", "b.go": ""},
+			want:  "Go",
+		},
+		{
+			name:  "selected files only",
+			files: map[string]string{"a.py": "", "b.py": "", "c.go": ""},
+			opts:  ScanOptions{Patterns: []PatternSet{{Exclude: []string{"*.py"}}}},
+			want:  "Go",
+		},
+		{
+			name:  "no programming language",
+			files: map[string]string{"README.md": "", "config.yaml": "", "notes": ""},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTree(t, root, tt.files)
+			got, err := PrimaryLanguage(root, tt.opts)
+			if err != nil {
+				t.Fatalf("PrimaryLanguage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PrimaryLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageExcludes(t *testing.T) {
+	names := LanguageNames()
+	for lang, excludes := range LanguageExcludes {
+		if !slices.Contains(names, lang) {
+			t.Errorf("Expected %q to be a language DetectLanguage reports", lang)
+		}
+		for _, exclude := range excludes {
+			if err := ValidatePattern(exclude); err != nil {
+				t.Errorf("Expected a valid pattern for %s, got %q: %v", lang, exclude, err)
+			}
+		}
+	}
+
+	// The directories of the primary language are skipped like the default ones
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"src/main.rs": "", "target/debug/build.rs": "", "pkg/__pycache__/x.py": ""})
+	got, err := ListFiles(root, ScanOptions{DefaultExcludes: append(slices.Clone(DefaultExcludes), LanguageExcludes["Rust"]...)})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if want := []string{"pkg/__pycache__/x.py", "src/main.rs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestReadPatternFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "exclude.txt")
+	writeTree(t, dir, map[string]string{
+		"exclude.txt": "# generated code\n\nbuild/\n*.pb.go  \r\n/docs\n\\#notes.md\n\\!important\n",
+	})
+
+	got, err := ReadPatternFile(file)
+	if err != nil {
+		t.Fatalf("ReadPatternFile() error = %v", err)
+	}
+	want := []string{"build/", "*.pb.go", "/docs", "#notes.md", "!important"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadPatternFile() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"negated pattern", "*.go\n!main.go\n"},
+		{"invalid pattern", "[a-\n"},
+	}
+	for _, tt := range tests {
+		writeTree(t, dir, map[string]string{"bad.txt": tt.content})
+		if _, err := ReadPatternFile(filepath.Join(dir, "bad.txt")); err == nil {
+			t.Errorf("Expected an error for a %s", tt.name)
+		}
+	}
+	if _, err := ReadPatternFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{path: "main.go", want: "Go"},
+		{path: "web/App.TSX", want: "TypeScript"},
+		{path: "config/settings.yml", want: "YAML"},
+		{path: "build/Dockerfile", want: "Dockerfile"},
+		{path: "Makefile", want: "Makefile"},
+		{path: "bin/deploy", content: "#!/bin/bash\nset -e\n", want: "Shell"},
+		{path: "bin/tool", content: "#!/usr/bin/env python3.12\nimport sys\n", want: "Python"},
+		{path: "bin/run", content: "#!/usr/bin/env -S node --no-warnings\n", want: "JavaScript"},
+		{path: "index", content: "<?php echo 1;", want: "PHP"},
+		{path: "LICENSE", content: "MIT License", want: UnknownLanguage},
+		{path: "data.bin", want: UnknownLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := DetectLanguage(tt.path, []byte(tt.content)); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{name: "go", want: "Go", ok: true},
+		{name: " RUST ", want: "Rust", ok: true},
+		{name: "c++", want: "C++", ok: true},
+		{name: "rs", want: "Rust", ok: true},
+		{name: ".py", want: "Python", ok: true},
+		{name: "Unknown", want: UnknownLanguage, ok: true},
+		{name: "makefile", want: "Makefile", ok: true},
+		{name: "cobol", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := LookupLanguage(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("LookupLanguage(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScanLanguages(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":        "package main",
+		"lib/parse.rs":   "fn main() {}",
+		"web/app.js":     "export {}",
+		"web/view.ts":    "export {}",
+		"bin/deploy":     "#!/bin/sh\necho deploy\n",
+		"LICENSE":        "MIT License",
+		"docs/readme.md": "# Docs",
+	})
+
+	tests := []struct {
+		name     string
+		filter   LanguageFilter
+		want     []string
+		filtered map[string]int
+	}{
+		{
+			name:     "include keeps files of unknown language",
+			filter:   LanguageFilter{Include: []string{"Go", "Rust"}},
+			want:     []string{"LICENSE", "lib/parse.rs", "main.go"},
+			filtered: map[string]int{"JavaScript": 1, "Shell": 1, "Markdown": 1},
+		},
+		{
+			name:     "unknown excluded explicitly",
+			filter:   LanguageFilter{Include: []string{"Go"}, Exclude: []string{UnknownLanguage}},
+			want:     []string{"main.go"},
+			filtered: map[string]int{"JavaScript": 1, "Shell": 1, "Markdown": 1, "Rust": 1, UnknownLanguage: 1},
+		},
+		{
+			name:     "exclude detects by content",
+			filter:   LanguageFilter{Exclude: []string{"Shell", "JavaScript"}},
+			want:     []string{"LICENSE", "docs/readme.md", "lib/parse.rs", "main.go"},
+			filtered: map[string]int{"JavaScript": 1, "Shell": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Languages apply after the patterns, which leave out web/view.ts
+			result, err := Scan(root, ScanOptions{Patterns: []PatternSet{{Exclude: []string{"*.ts"}}}, Languages: tt.filter})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Files, tt.want) {
+				t.Errorf("Scan() = %v, want %v", result.Files, tt.want)
+			}
+			if !maps.Equal(result.FilteredLanguages, tt.filtered) {
+				t.Errorf("Expected %v filtered out, got %v", tt.filtered, result.FilteredLanguages)
+			}
+		})
+	}
+}
+
+// BenchmarkScan compares the memory the files of a synthetic tree take while
+// they are read from Stream, a few at a time, with listing them all with
+// Scan: the peak heap growth of Stream stays flat as the tree grows.
+func BenchmarkScan(b *testing.B) {
+	for _, files := range []int{1000, 10000, 50000} {
+		root := b.TempDir()
+		for i := range files {
+			p := filepath.Join(root, fmt.Sprintf("pkg%03d", i/100), fmt.Sprintf("deeply/nested/source_file_%05d.go", i))
+			if i%100 == 0 {
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					b.Fatalf("Failed to create directory: %v", err)
+				}
+			}
+			if err := os.WriteFile(p, nil, 0644); err != nil {
+				b.Fatalf("Failed to write file: %v", err)
+			}
+		}
+
+		b.Run(fmt.Sprintf("Stream/files=%d", files), func(b *testing.B) {
+			for b.Loop() {
+				base := liveHeap()
+				peak := base
+				paths := make(chan string, 64)
+				go func() {
+					if _, err := Stream(context.Background(), root, ScanOptions{}, paths); err != nil {
+						b.Error(err)
+					}
+				}()
+				n := 0
+				for range paths {
+					if n++; n%1000 == 0 {
+						peak = max(peak, liveHeap())
+					}
+				}
+				b.ReportMetric(float64(peak-base), "peak-heap-B")
+			}
+		})
+		b.Run(fmt.Sprintf("Scan/files=%d", files), func(b *testing.B) {
+			for b.Loop() {
+				base := liveHeap()
+				result, err := Scan(root, ScanOptions{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportMetric(float64(liveHeap()-base), "peak-heap-B")
+				runtime.KeepAlive(result)
+			}
+		})
+	}
+}
+
+// liveHeap returns the bytes of live heap objects, after collecting the
+// garbage so that only what is still referenced counts.
+func liveHeap() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
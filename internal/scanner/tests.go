@@ -0,0 +1,20 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package scanner
+
+// ReasonTest is reported in Result.Skipped for the test files left out by
+// ScanOptions.TestPatterns.
+const ReasonTest = "test"
+
+// DefaultTestPatterns match the test files and test directories of common
+// languages and frameworks.
+var DefaultTestPatterns = []string{
+	// Go, pytest, Jest/Vitest/Mocha and React component tests
+	"*_test.go", "test_*.py", "*_test.py", "conftest.py",
+	"*.test.[jt]s", "*.spec.[jt]s", "*.test.[jt]sx", "*.spec.[jt]sx",
+	// JUnit, NUnit/xUnit, RSpec/Minitest, ExUnit, PHPUnit and XCTest
+	"*Test.java", "*Tests.java", "*Test.kt", "*Test.cs", "*Tests.cs",
+	"*_spec.rb", "*_test.rb", "*_test.exs", "*Test.php", "*Tests.swift",
+	// Test directories and fixtures
+	"__tests__", "test", "tests", "testdata",
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafeArchive is returned for an archive with an entry that would be
+// written outside the extraction directory (a "zip slip").
+var ErrUnsafeArchive = errors.New("archive entry escapes the extraction directory")
+
+// ArchiveFormats lists the archive file extensions ExtractArchive accepts.
+var ArchiveFormats = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// ExtractArchive extracts the archive at file (see ArchiveFormats) into a
+// new temporary directory and returns the root of its contents: the
+// archive's single top-level directory if it has one, as source snapshots
+// usually do (e.g., project-1.0/), and the temporary directory otherwise.
+// The returned cleanup func removes the temporary directory and must be
+// called once the contents are no longer needed.
+//
+// Only regular files and directories are extracted; symbolic links and
+// other special entries are skipped, so that no entry can point outside
+// the directory. An entry whose path is absolute or climbs out with ".."
+// fails the extraction with ErrUnsafeArchive.
+func ExtractArchive(file string) (root string, cleanup func(), err error) {
+	var extract func(file, dir string) error
+	lower := strings.ToLower(file)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		extract = func(file, dir string) error { return extractTar(file, dir, true) }
+	case strings.HasSuffix(lower, ".tar"):
+		extract = func(file, dir string) error { return extractTar(file, dir, false) }
+	case strings.HasSuffix(lower, ".zip"):
+		extract = extractZip
+	default:
+		return "", nil, fmt.Errorf("unsupported archive %s: use one of %s", file, strings.Join(ArchiveFormats, ", "))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "code-decoder-archive-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(tmpDir) }
+	if err := extract(file, tmpDir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("extracting %s: %w", file, err)
+	}
+
+	root = tmpDir
+	if entries, err := os.ReadDir(tmpDir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmpDir, entries[0].Name())
+	}
+	return root, cleanup, nil
+}
+
+// extractTar extracts the tar archive at file, gzip-compressed if gzipped
+// is set, into dir.
+func extractTar(file, dir string, gzipped bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := entryPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeEntry(target, tr, header.FileInfo().Mode())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts the zip archive at file into dir.
+func extractZip(file, dir string) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		target, err := entryPath(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+		case mode.IsRegular():
+			err = func() error {
+				rc, err := entry.Open()
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+				return writeEntry(target, rc, mode)
+			}()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryPath returns where the archive entry name is extracted to in dir, or
+// "" for the entry of dir itself (e.g., "./"). Names use forward slashes,
+// but backslashes are treated as separators too, since some Windows tools
+// write them into zip files.
+func entryPath(dir, name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(clean) || filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchive, name)
+	}
+	if clean == "." {
+		return "", nil
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// writeEntry writes the content of a file entry to target, creating its
+// parent directories. The file keeps the entry's permissions, but is always
+// readable and writable by the owner and never writable by others.
+func writeEntry(target string, r io.Reader, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()&0755|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveEntry is an entry of a test archive; a name ending in "/" is a
+// directory, and a non-empty link makes it a symbolic link.
+type archiveEntry struct {
+	name, content, link string
+}
+
+func writeTarGz(t *testing.T, file string, entries []archiveEntry) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.link != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, e.link, 0
+		case e.name[len(e.name)-1] == '/':
+			header.Typeflag, header.Mode = tar.TypeDir, 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, file string, entries []archiveEntry) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name}
+		content := e.content
+		if e.link != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+			content = e.link
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "project-1.0/"},
+		{name: "project-1.0/main.go", content: "package main\n"},
+		{name: "project-1.0/internal/util.go", content: "package internal\n"},
+		{name: "project-1.0/link.go", link: "/etc/passwd"},
+	}
+	for _, name := range []string{"project.tar.gz", "project.zip"} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), name)
+			if filepath.Ext(name) == ".zip" {
+				writeZip(t, file, entries)
+			} else {
+				writeTarGz(t, file, entries)
+			}
+
+			root, cleanup, err := ExtractArchive(file)
+			if err != nil {
+				t.Fatalf("ExtractArchive failed: %v", err)
+			}
+			if filepath.Base(root) != "project-1.0" {
+				t.Errorf("Expected the root to be the top-level directory, got %s", root)
+			}
+			data, err := os.ReadFile(filepath.Join(root, "internal", "util.go"))
+			if err != nil || string(data) != "package internal\n" {
+				t.Errorf("Expected internal/util.go to be extracted, got %q (%v)", data, err)
+			}
+			if _, err := os.Lstat(filepath.Join(root, "link.go")); !os.IsNotExist(err) {
+				t.Errorf("Expected the symbolic link to be skipped, got %v", err)
+			}
+
+			cleanup()
+			if _, err := os.Stat(root); !os.IsNotExist(err) {
+				t.Errorf("Expected cleanup to remove the extracted files, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractArchiveWithoutTopLevelDirectory(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+	}{
+		{
+			name:    "flat.zip",
+			entries: []archiveEntry{{name: "main.go", content: "package main\n"}, {name: "go.mod", content: "module flat\n"}},
+		},
+		{
+			// As written by tar czf flat.tar.gz -C dir .
+			name:    "flat.tar.gz",
+			entries: []archiveEntry{{name: "./"}, {name: "./main.go", content: "package main\n"}, {name: "./go.mod", content: "module flat\n"}},
+		},
+		{
+			name:    "dot.zip",
+			entries: []archiveEntry{{name: "./"}, {name: "./main.go", content: "package main\n"}, {name: "./go.mod", content: "module flat\n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.name)
+			if filepath.Ext(tt.name) == ".zip" {
+				writeZip(t, file, tt.entries)
+			} else {
+				writeTarGz(t, file, tt.entries)
+			}
+
+			root, cleanup, err := ExtractArchive(file)
+			if err != nil {
+				t.Fatalf("ExtractArchive failed: %v", err)
+			}
+			defer cleanup()
+			for _, name := range []string{"main.go", "go.mod"} {
+				if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+					t.Errorf("Expected %s at the root, got %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchiveRejectsUnsafeEntries(t *testing.T) {
+	for _, name := range []string{"../evil.go", "project/../../evil.go", "/etc/evil.go", `..\evil.go`} {
+		for _, ext := range []string{".tar.gz", ".zip"} {
+			file := filepath.Join(t.TempDir(), "evil"+ext)
+			entries := []archiveEntry{{name: "project/main.go", content: "package main\n"}, {name: name, content: "evil"}}
+			if ext == ".zip" {
+				writeZip(t, file, entries)
+			} else {
+				writeTarGz(t, file, entries)
+			}
+
+			_, _, err := ExtractArchive(file)
+			if !errors.Is(err, ErrUnsafeArchive) {
+				t.Errorf("Expected ErrUnsafeArchive for %s entry %q, got %v", ext, name, err)
+			}
+		}
+	}
+}
+
+func TestExtractArchiveErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := ExtractArchive(filepath.Join(dir, "project.rar")); err == nil {
+		t.Error("Expected an error for an unsupported archive format")
+	}
+	if _, _, err := ExtractArchive(filepath.Join(dir, "missing.zip")); err == nil {
+		t.Error("Expected an error for a missing archive")
+	}
+	corrupt := filepath.Join(dir, "corrupt.tar.gz")
+	if err := os.WriteFile(corrupt, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ExtractArchive(corrupt); err == nil {
+		t.Error("Expected an error for a corrupt archive")
+	}
+}
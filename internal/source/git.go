@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNotGitRepo is returned by ChangedFiles when the directory is not in
+	// a git working tree.
+	ErrNotGitRepo = errors.New("not a git repository")
+	// ErrUnknownRef is returned by ChangedFiles when the ref names no commit
+	// of the repository.
+	ErrUnknownRef = errors.New("unknown git revision")
+)
+
+// ChangedFiles returns the slash-separated paths, relative to dir, of the
+// files under dir that were added or modified since ref (a commit, branch or
+// tag) in the git repository dir belongs to, as listed by git diff
+// --name-only against the working tree. Deleted files are left out, as are
+// untracked files, which git does not diff.
+func ChangedFiles(ctx context.Context, dir, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRef, ref)
+	}
+	if _, err := git(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("--since requires git to be installed: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotGitRepo, dir)
+	}
+	if _, err := git(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRef, ref)
+	}
+	out, err := git(ctx, dir, "diff", "--name-only", "--relative", "--diff-filter=d", "--no-renames", "-z", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files changed since %s: %w", ref, err)
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// git runs git with args in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], msg, err)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	git("init", "--quiet")
+	write("main.go", "package main")
+	write("old.go", "package main")
+	write("src/a.go", "package src")
+	write("src/b.go", "package src")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+	git("tag", "v1")
+
+	write("src/a.go", "package src // changed")
+	write("src/c.go", "package src")
+	git("add", ".")
+	git("rm", "--quiet", "old.go")
+	git("commit", "--quiet", "-m", "second")
+	write("main.go", "package main // uncommitted")
+	write("src/untracked.go", "package src")
+
+	got, err := ChangedFiles(context.Background(), repo, "v1")
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if want := []string{"main.go", "src/a.go", "src/c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Paths are relative to the directory given, which may be a subdirectory
+	got, err = ChangedFiles(context.Background(), filepath.Join(repo, "src"), "v1")
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if want := []string{"a.go", "c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v in the subdirectory, got %v", want, got)
+	}
+
+	for _, ref := range []string{"v9", "--output=x", ""} {
+		if _, err := ChangedFiles(context.Background(), repo, ref); !errors.Is(err, ErrUnknownRef) {
+			t.Errorf("Expected ErrUnknownRef for %q, got %v", ref, err)
+		}
+	}
+	if _, err := ChangedFiles(context.Background(), t.TempDir(), "v1"); !errors.Is(err, ErrNotGitRepo) {
+		t.Errorf("Expected ErrNotGitRepo outside a repository, got %v", err)
+	}
+}
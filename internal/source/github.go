@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// githubAPIURL is the base URL of the GitHub REST API for github.com.
+	githubAPIURL = "https://api.github.com"
+	// tokenCheckTimeout bounds the request validating a token, so that an
+	// unreachable API fails fast rather than stalling the analysis.
+	tokenCheckTimeout = 15 * time.Second
+)
+
+// ErrInvalidToken is returned when GitHub rejects the token.
+var ErrInvalidToken = errors.New("GitHub token is invalid or expired")
+
+// TokenInfo describes the account a GitHub token authenticates as.
+type TokenInfo struct {
+	Login     string    // User name; empty if the token may not read the user (e.g., an app token)
+	RateLimit RateLimit // API requests left for the token
+}
+
+// RateLimit is the state of a token's GitHub API rate limit.
+type RateLimit struct {
+	Limit     int       // Requests allowed per window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets; zero if unknown
+}
+
+// APIURL returns the base URL of the GitHub REST API serving the repository
+// repo (in any form accepted by NormalizeRepoURL): api.github.com for
+// github.com, and the /api/v3 path of the host for GitHub Enterprise Server.
+func APIURL(repo string) (string, error) {
+	cloneURL, err := NormalizeRepoURL(repo)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL '%s': %w", cloneURL, err)
+	}
+	if u.Host == "github.com" || u.Host == "www.github.com" {
+		return githubAPIURL, nil
+	}
+	return "https://" + u.Host + "/api/v3", nil
+}
+
+// CheckToken validates token against the GitHub API at apiURL (see APIURL)
+// by requesting the authenticated user, and reports who it belongs to and
+// its remaining rate limit. The error wraps ErrInvalidToken if GitHub
+// rejects the token.
+func CheckToken(ctx context.Context, apiURL, token string) (*TokenInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/user", nil)
+	if err != nil {
+		return nil, fmt.Errorf("checking the GitHub token: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("checking the GitHub token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	info := &TokenInfo{RateLimit: parseRateLimit(resp.Header)}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("%w (check --token or github.token)", ErrInvalidToken)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if info.RateLimit.Remaining == 0 && resp.Header.Get("X-RateLimit-Remaining") != "" {
+			return nil, fmt.Errorf("GitHub API rate limit exceeded for the token (resets at %s)", info.RateLimit.Reset.Local().Format(time.Kitchen))
+		}
+		// The token is valid, but may not read the user (e.g., a GitHub App
+		// installation token); the clone will tell whether it can read the repository
+		return info, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("checking the GitHub token: unexpected status %s", resp.Status)
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("checking the GitHub token: failed to decode the user: %w", err)
+	}
+	info.Login = user.Login
+	return info, nil
+}
+
+// parseRateLimit reads the rate limit headers of a GitHub API response.
+// Missing or malformed headers leave the corresponding fields zero.
+func parseRateLimit(h http.Header) RateLimit {
+	var rl RateLimit
+	rl.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	rl.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{repo: "ksylvan/code-decoder", want: "https://api.github.com"},
+		{repo: "https://github.com/ksylvan/code-decoder", want: "https://api.github.com"},
+		{repo: "https://git.example.com/team/project", want: "https://git.example.com/api/v3"},
+	}
+	for _, tt := range tests {
+		if got, err := APIURL(tt.repo); err != nil || got != tt.want {
+			t.Errorf("APIURL(%q) = %q, %v, want %q", tt.repo, got, err, tt.want)
+		}
+	}
+	if _, err := APIURL("not a repo"); err == nil {
+		t.Error("APIURL() expected error for an invalid repository")
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	reset := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rateLimit := func(w http.ResponseWriter, remaining string) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset", "1748779200")
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    *TokenInfo
+		wantErr string
+	}{
+		{
+			name: "valid token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/user" || r.Header.Get("Authorization") != "Bearer secret" {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+				rateLimit(w, "4990")
+				w.Write([]byte(`{"login": "octocat"}`))
+			},
+			want: &TokenInfo{Login: "octocat", RateLimit: RateLimit{Limit: 5000, Remaining: 4990, Reset: reset}},
+		},
+		{
+			name: "token that may not read the user",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				rateLimit(w, "4000")
+				http.Error(w, `{"message": "Resource not accessible by integration"}`, http.StatusForbidden)
+			},
+			want: &TokenInfo{RateLimit: RateLimit{Limit: 5000, Remaining: 4000, Reset: reset}},
+		},
+		{
+			name: "invalid token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+			},
+			wantErr: "GitHub token is invalid or expired",
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				rateLimit(w, "0")
+				http.Error(w, `{"message": "API rate limit exceeded"}`, http.StatusForbidden)
+			},
+			wantErr: "rate limit exceeded",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "oops", http.StatusBadGateway)
+			},
+			wantErr: "unexpected status 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			got, err := CheckToken(context.Background(), server.URL, "secret")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckToken() error = %v", err)
+			}
+			if got.Login != tt.want.Login || got.RateLimit.Limit != tt.want.RateLimit.Limit ||
+				got.RateLimit.Remaining != tt.want.RateLimit.Remaining || !got.RateLimit.Reset.Equal(tt.want.RateLimit.Reset) {
+				t.Errorf("CheckToken() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	if _, err := CheckToken(context.Background(), server.URL, "expired"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+// Package source prepares codebases for analysis, such as fetching remote
+// GitHub repositories into a local working copy.
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrRepoNotFound is returned when the repository does not exist or is
+	// private and not visible with the supplied credentials.
+	ErrRepoNotFound = errors.New("repository not found")
+	// ErrAuthFailed is returned when the repository requires credentials that
+	// were missing or rejected.
+	ErrAuthFailed = errors.New("authentication failed")
+)
+
+// shorthandRe matches the "owner/repo" shorthand for GitHub repositories.
+var shorthandRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+$`)
+
+// NormalizeRepoURL turns a repository reference into a clonable HTTPS URL.
+// It accepts "owner/repo" shorthand (resolved against github.com),
+// "github.com/owner/repo", and full https:// URLs.
+func NormalizeRepoURL(repo string) (string, error) {
+	repo = strings.TrimSpace(repo)
+	switch {
+	case repo == "":
+		return "", errors.New("repository URL is empty")
+	case strings.HasPrefix(repo, "https://"):
+		return strings.TrimSuffix(repo, "/"), nil
+	case strings.HasPrefix(repo, "http://"):
+		return "", fmt.Errorf("insecure repository URL '%s': use https://", repo)
+	case strings.HasPrefix(repo, "github.com/"):
+		return "https://" + strings.TrimSuffix(repo, "/"), nil
+	case shorthandRe.MatchString(repo):
+		return "https://github.com/" + repo, nil
+	default:
+		return "", fmt.Errorf("unsupported repository '%s': use https://github.com/owner/repo or owner/repo", repo)
+	}
+}
+
+// FetchRepo shallow-clones the repository at url into a temporary directory
+// and returns its path. The token, when not empty, is used to authenticate
+// against private repositories. The returned cleanup func removes the
+// temporary directory and must be called once the clone is no longer needed.
+func FetchRepo(ctx context.Context, url, token string) (localPath string, cleanup func(), err error) {
+	cloneURL, err := NormalizeRepoURL(url)
+	if err != nil {
+		return "", nil, err
+	}
+	return cloneRepo(ctx, cloneURL, token)
+}
+
+// cloneRepo performs the shallow clone of cloneURL into a new temporary directory.
+func cloneRepo(ctx context.Context, cloneURL, token string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "code-decoder-repo-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--quiet", "--", cloneURL, tmpDir)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0") // Fail instead of prompting for credentials
+	if token != "" {
+		// Pass the token through the environment rather than the URL or
+		// command line so it does not show up in process listings or errors.
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+		)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		if ctx.Err() != nil {
+			return "", nil, fmt.Errorf("cloning %s: %w", cloneURL, ctx.Err())
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", nil, fmt.Errorf("cloning %s requires git to be installed: %w", cloneURL, err)
+		}
+		return "", nil, classifyCloneError(cloneURL, token != "", stderr.String(), err)
+	}
+	return tmpDir, cleanup, nil
+}
+
+// classifyCloneError turns git's stderr output into a descriptive error.
+func classifyCloneError(cloneURL string, hasToken bool, stderr string, err error) error {
+	msg := strings.TrimSpace(stderr)
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "not found"):
+		if hasToken {
+			return fmt.Errorf("%w: %s (check the URL and that the token can access it)", ErrRepoNotFound, cloneURL)
+		}
+		return fmt.Errorf("%w: %s (if it is private, provide a token with --token or github.token)", ErrRepoNotFound, cloneURL)
+	case strings.Contains(lower, "authentication failed") ||
+		strings.Contains(lower, "could not read username") ||
+		strings.Contains(lower, "could not read password") ||
+		strings.Contains(lower, "403"):
+		if hasToken {
+			return fmt.Errorf("%w for %s: the token was rejected or lacks access", ErrAuthFailed, cloneURL)
+		}
+		return fmt.Errorf("%w for %s: provide a token with --token or github.token", ErrAuthFailed, cloneURL)
+	case msg != "":
+		return fmt.Errorf("failed to clone %s: %s", cloneURL, msg)
+	default:
+		return fmt.Errorf("failed to clone %s: %w", cloneURL, err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Kayvan Sylvan. This project is licensed under the MIT License
+
+package source
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		repo    string
+		want    string
+		wantErr bool
+	}{
+		{repo: "https://github.com/ksylvan/code-decoder", want: "https://github.com/ksylvan/code-decoder"},
+		{repo: "https://github.com/ksylvan/code-decoder/", want: "https://github.com/ksylvan/code-decoder"},
+		{repo: "https://github.com/ksylvan/code-decoder.git", want: "https://github.com/ksylvan/code-decoder.git"},
+		{repo: "github.com/ksylvan/code-decoder", want: "https://github.com/ksylvan/code-decoder"},
+		{repo: "ksylvan/code-decoder", want: "https://github.com/ksylvan/code-decoder"},
+		{repo: " ksylvan/code-decoder ", want: "https://github.com/ksylvan/code-decoder"},
+		{repo: "", wantErr: true},
+		{repo: "http://github.com/ksylvan/code-decoder", wantErr: true},
+		{repo: "git@github.com:ksylvan/code-decoder.git", wantErr: true},
+		{repo: "just-a-name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeRepoURL(tt.repo)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeRepoURL(%q) error = %v, wantErr %v", tt.repo, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeRepoURL(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCloneError(t *testing.T) {
+	runErr := errors.New("exit status 128")
+
+	err := classifyCloneError("https://github.com/o/r", false, "remote: Repository not found.\nfatal: repository 'https://github.com/o/r/' not found", runErr)
+	if !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("Expected ErrRepoNotFound, got %v", err)
+	}
+
+	err = classifyCloneError("https://github.com/o/r", false, "fatal: could not read Username for 'https://github.com': terminal prompts disabled", runErr)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+
+	err = classifyCloneError("https://github.com/o/r", true, "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/o/r/'", runErr)
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestCloneRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Create a local repository to clone from
+	origin := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	if err := os.WriteFile(filepath.Join(origin, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+
+	localPath, cleanup, err := cloneRepo(context.Background(), "file://"+origin, "")
+	if err != nil {
+		t.Fatalf("cloneRepo() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(localPath, "main.go")); err != nil {
+		t.Errorf("Expected main.go in the clone: %v", err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+		t.Errorf("Expected cleanup to remove %s", localPath)
+	}
+
+	if _, _, err := cloneRepo(context.Background(), "file://"+filepath.Join(origin, "missing"), ""); err == nil {
+		t.Error("cloneRepo() expected error for a missing repository")
+	}
+}